@@ -0,0 +1,35 @@
+"""Tests for the option parsers of the detect command."""
+
+import importlib
+
+import click
+import pytest
+
+detect_module = importlib.import_module("treepeat.cli.commands.detect")
+
+
+class TestParseMaxGap:
+    def test_lines_and_percentages(self):
+        assert detect_module._parse_max_gap(None, None, "3") == 3
+        assert detect_module._parse_max_gap(None, None, "10%") == "10%"
+        assert detect_module._parse_max_gap(None, None, "2.5 %") == "2.5%"
+        assert detect_module._parse_max_gap(None, None, None) is None
+
+    def test_invalid_value(self):
+        with pytest.raises(click.BadParameter):
+            detect_module._parse_max_gap(None, None, "1.5")
+
+
+class TestParseDuration:
+    def test_units(self):
+        assert detect_module._parse_duration(None, None, "500ms") == 0.5
+        assert detect_module._parse_duration(None, None, "2s") == 2.0
+        assert detect_module._parse_duration(None, None, "1m") == 60.0
+        assert detect_module._parse_duration(None, None, "1.5") == 1.5
+
+    def test_missing_value(self):
+        assert detect_module._parse_duration(None, None, None) is None
+
+    def test_invalid_value(self):
+        with pytest.raises(click.BadParameter):
+            detect_module._parse_duration(None, None, "soon")
@@ -47,6 +47,18 @@ def assert_regions_in_same_group(
     )
 
 
+def make_region(path: str, start_line: int) -> Region:
+    """A five-line Python function region, for formatter tests."""
+    return Region(
+        path=Path(path),
+        language="python",
+        region_type="function_definition",
+        region_name="total",
+        start_line=start_line,
+        end_line=start_line + 4,
+    )
+
+
 def parsed_fixture(path):
     """Legacy function for backward compatibility - parses Python files."""
     return parse_fixture(path, "python")
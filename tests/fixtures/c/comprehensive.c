@@ -0,0 +1,28 @@
+/* A comprehensive C file for testing similarity detection. */
+#include <stdio.h>
+#include <stdlib.h>
+
+struct buffer {
+    char *data;
+    size_t length;
+    size_t capacity;
+};
+
+static int buffer_grow(struct buffer *buf, size_t needed)
+{
+    size_t capacity = buf->capacity ? buf->capacity : 16;
+    while (capacity < needed) {
+        capacity *= 2;
+    }
+#ifdef _WIN32
+    char *data = HeapReAlloc(GetProcessHeap(), 0, buf->data, capacity);
+#else
+    char *data = realloc(buf->data, capacity);
+#endif
+    if (data == NULL) {
+        return -1;
+    }
+    buf->data = data;
+    buf->capacity = capacity;
+    return 0;
+}
@@ -0,0 +1,34 @@
+// A comprehensive C++ file for testing similarity detection.
+#include <string>
+#include <vector>
+
+namespace inventory {
+
+class Stock {
+public:
+    explicit Stock(std::string sku) : sku_(std::move(sku)) {}
+
+    int reserve(int quantity) {
+        if (quantity > available_) {
+            return -1;
+        }
+        available_ -= quantity;
+        reserved_ += quantity;
+        return available_;
+    }
+
+private:
+    std::string sku_;
+    int available_ = 0;
+    int reserved_ = 0;
+};
+
+int Stock_total(const std::vector<Stock>& items) {
+    int total = 0;
+    for (const auto& item : items) {
+        total += 1;
+    }
+    return total;
+}
+
+}  // namespace inventory
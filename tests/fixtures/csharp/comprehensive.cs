@@ -0,0 +1,66 @@
+using System;
+using System.Linq;
+
+// A comprehensive C# sample for testing similarity detection.
+namespace Billing
+{
+    public interface IRenderable
+    {
+        string Render();
+    }
+
+    public record Money(decimal Amount, string Currency);
+
+    public struct Point
+    {
+        public int X;
+        public int Y;
+    }
+
+    [Serializable]
+    public partial class InvoiceService : IRenderable
+    {
+        private readonly int[] amounts;
+
+        public InvoiceService(int[] amounts)
+        {
+            this.amounts = amounts;
+        }
+
+        public int Total
+        {
+            get
+            {
+                var total = 0;
+                foreach (var amount in amounts)
+                {
+                    total = total + amount;
+                }
+                return total;
+            }
+        }
+
+        public string Render()
+        {
+            var large = from amount in amounts
+                        where amount > 100
+                        orderby amount
+                        select amount;
+            return "Large: " + string.Join(", ", large);
+        }
+    }
+
+    public partial class InvoiceService
+    {
+        /* Duplicate of Total */
+        public int Sum()
+        {
+            var sum = 0;
+            foreach (var value in amounts)
+            {
+                sum = sum + value;
+            }
+            return sum;
+        }
+    }
+}
@@ -0,0 +1,23 @@
+using System.Collections.Generic;
+
+namespace Shop
+{
+    // The generated half of Order, which repeats Total
+    public partial class Order
+    {
+        public string Label { get; set; }
+
+        public decimal Sum()
+        {
+            var total = 0m;
+            foreach (var price in prices)
+            {
+                if (price > 0)
+                {
+                    total = total + price;
+                }
+            }
+            return total;
+        }
+    }
+}
@@ -0,0 +1,28 @@
+using System.Collections.Generic;
+
+namespace Shop
+{
+    // The hand-written half of Order
+    public partial class Order
+    {
+        private readonly List<decimal> prices = new List<decimal>();
+
+        public void Add(decimal price)
+        {
+            prices.Add(price);
+        }
+
+        public decimal Total()
+        {
+            var total = 0m;
+            foreach (var price in prices)
+            {
+                if (price > 0)
+                {
+                    total = total + price;
+                }
+            }
+            return total;
+        }
+    }
+}
@@ -0,0 +1,35 @@
+# A comprehensive Terraform sample for testing similarity detection.
+variable "region" {
+  type    = string
+  default = "us-east-1"
+}
+
+resource "aws_instance" "web_prod" {
+  ami           = "ami-0123456789"
+  instance_type = "t3.large"
+  count         = 3
+  monitoring    = true
+
+  tags = {
+    Name        = "web"
+    Environment = "prod"
+  }
+}
+
+# Copy of web_prod for staging
+resource "aws_instance" "web_staging" {
+  ami           = "ami-0123456789"
+  instance_type = "t3.large"
+  count         = 3
+  monitoring    = true
+
+  tags = {
+    Name        = "web"
+    Environment = "staging"
+  }
+}
+
+module "vpc" {
+  source     = "./modules/vpc"
+  cidr_block = "10.0.0.0/16"
+}
@@ -28,3 +28,14 @@ class AnotherClass {
         return z
     }
 }
+
+object StatusLabels {
+    fun label(code: Int): String {
+        return when (code) {
+            200 -> "OK"
+            404 -> "Not Found"
+            500 -> "Server Error"
+            else -> "Unknown"
+        }
+    }
+}
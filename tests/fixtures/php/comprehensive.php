@@ -0,0 +1,56 @@
+<?php
+namespace App\Billing;
+
+use App\Models\Invoice;
+require_once 'helpers.php';
+
+// A comprehensive PHP sample for testing similarity detection.
+interface Renderable
+{
+    public function render(): string;
+}
+
+trait Logs
+{
+    protected function log(string $message): void
+    {
+        error_log($message);
+    }
+}
+
+class InvoiceController implements Renderable
+{
+    use Logs;
+
+    public function render(): string
+    {
+        $total = 0;
+        foreach ($this->invoices as $invoice) {
+            $total = $total + $invoice->amount;
+        }
+        return "Total: " . $total;
+    }
+
+    /* Duplicate of render */
+    public function summary(): string
+    {
+        $sum = 0;
+        foreach ($this->invoices as $item) {
+            $sum = $sum + $item->amount;
+        }
+        return "Total: " . $sum;
+    }
+}
+
+function format_amount($amount)
+{
+    $rounded = round($amount, 2);
+    $formatted = number_format($rounded, 2);
+    return "$" . $formatted;
+}
+?>
+<html>
+<body>
+  <h1><?php echo format_amount(42); ?></h1>
+</body>
+</html>
@@ -0,0 +1,31 @@
+require "json"
+
+# A comprehensive Ruby sample for testing similarity detection.
+module Billing
+  class InvoicesController
+    def index
+      invoices = Invoice.where(paid: false)
+      invoices.each do |invoice|
+        total = invoice.amount + invoice.tax
+        puts "Invoice #{invoice.id}: #{total}"
+      end
+      render json: invoices
+    end
+
+    # Duplicate of index
+    def overdue
+      invoices = Invoice.where(paid: false)
+      invoices.each do |invoice|
+        total = invoice.amount + invoice.tax
+        puts "Invoice #{invoice.id}: #{total}"
+      end
+      render json: invoices
+    end
+
+    def self.build(params)
+      @invoice = Invoice.new(params)
+      @invoice.save
+      @invoice
+    end
+  end
+end
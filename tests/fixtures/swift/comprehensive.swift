@@ -0,0 +1,43 @@
+import Foundation
+
+// A comprehensive Swift sample for testing similarity detection.
+protocol Greeter {
+    func greet() -> String
+}
+
+struct Person: Greeter {
+    let name: String
+
+    init(name: String) {
+        self.name = name
+    }
+
+    func greet() -> String {
+        return "Hello, \(name)"
+    }
+}
+
+class Calculator {
+    /* A method that will have a duplicate */
+    func calculateSum(a: Int, b: Int) -> Int {
+        let result = a + b
+        print("Calculating sum: \(result)")
+        return result
+    }
+}
+
+extension Calculator {
+    // Duplicate of calculateSum
+    func mySum(x: Int, y: Int) -> Int {
+        let z = x + y
+        print("Calculating sum: \(z)")
+        return z
+    }
+
+    func doubled(values: [Int]) -> [Int] {
+        return values.map { value in
+            let twice = value * 2
+            return twice
+        }
+    }
+}
@@ -0,0 +1,26 @@
+// A comprehensive Thrift file for testing similarity detection.
+namespace py billing
+include "shared.thrift"
+
+enum Currency {
+  USD = 1,
+  EUR = 2,
+}
+
+struct Address {
+  1: string line1,
+  2: string line2,
+  3: string city,
+  4: string postal_code,
+  5: string country,
+}
+
+exception InvoiceNotFound {
+  1: string id,
+  2: string message,
+}
+
+service InvoiceService {
+  Address getBillingAddress(1: string invoice_id) throws (1: InvoiceNotFound missing),
+  list<Address> listAddresses(1: i32 limit),
+}
@@ -0,0 +1,44 @@
+import json
+
+from tests.conftest import make_region
+from treepeat.formatters.codeclimate import CHECK_NAME, format_as_codeclimate
+from treepeat.models.similarity import SimilarityResult, SimilarRegionGroup
+
+
+def _result() -> SimilarityResult:
+    group = SimilarRegionGroup(
+        regions=[make_region("a.py", 1), make_region("a.py", 20), make_region("b.py", 3)],
+        similarity=0.9,
+        fingerprint="clone-1a2b3c4d",
+    )
+    return SimilarityResult(similar_groups=[group])
+
+
+def test_one_issue_per_instance():
+    issues = json.loads(format_as_codeclimate(_result()))
+
+    assert len(issues) == 3
+    first = issues[0]
+    assert first["check_name"] == CHECK_NAME
+    assert first["severity"] == "minor"
+    assert first["location"] == {"path": "a.py", "lines": {"begin": 1, "end": 5}}
+    assert [loc["path"] for loc in first["other_locations"]] == ["a.py", "b.py"]
+
+
+def test_fingerprints_are_unique_and_stable():
+    issues = json.loads(format_as_codeclimate(_result()))
+    again = json.loads(format_as_codeclimate(_result()))
+
+    fingerprints = [issue["fingerprint"] for issue in issues]
+    assert len(set(fingerprints)) == 3
+    assert fingerprints == [issue["fingerprint"] for issue in again]
+
+
+def test_fingerprints_differ_for_instances_sharing_a_start_line():
+    outer = make_region("a.py", 10)
+    inner = outer.model_copy(update={"end_line": 12})
+    group = SimilarRegionGroup(regions=[outer, inner], similarity=0.9, fingerprint="clone-1a2b3c4d")
+
+    issues = json.loads(format_as_codeclimate(SimilarityResult(similar_groups=[group])))
+
+    assert len({issue["fingerprint"] for issue in issues}) == 2
@@ -0,0 +1,49 @@
+import xml.etree.ElementTree as ET
+from pathlib import Path
+
+from datasketch import MinHash
+
+from treepeat.formatters.cpd import format_as_cpd_xml
+from treepeat.models.similarity import Region, RegionSignature, SimilarityResult, SimilarRegionGroup
+
+
+def _region(path: Path, start_line: int, end_line: int) -> Region:
+    return Region(
+        path=path,
+        language="python",
+        region_type="function_definition",
+        region_name="total",
+        start_line=start_line,
+        end_line=end_line,
+    )
+
+
+def test_cpd_duplication(tmp_path):
+    source = tmp_path / "a.py"
+    source.write_text("def total(a, b):\n    return a + b\n")
+    regions = [_region(source, 1, 2), _region(tmp_path / "b.py", 4, 5)]
+    result = SimilarityResult(
+        signatures=[RegionSignature(region=regions[0], minhash=MinHash(), shingle_count=12)],
+        similar_groups=[SimilarRegionGroup(regions=regions, similarity=1.0)],
+    )
+
+    root = ET.fromstring(format_as_cpd_xml(result))
+
+    assert root.tag == "pmd-cpd"
+    duplication = root.find("duplication")
+    assert duplication.attrib == {"lines": "2", "tokens": "12", "similarity": "1.0000"}
+    files = duplication.findall("file")
+    assert [(f.get("path"), f.get("line"), f.get("endline")) for f in files] == [
+        (str(source), "1", "2"),
+        (str(tmp_path / "b.py"), "4", "5"),
+    ]
+    assert duplication.find("codefragment").text == "def total(a, b):\n    return a + b"
+
+
+def test_cpd_duplication_fingerprint():
+    regions = [_region(Path("a.py"), 1, 2), _region(Path("b.py"), 4, 5)]
+    group = SimilarRegionGroup(regions=regions, similarity=1.0, fingerprint="clone-1a2b3c4d")
+
+    root = ET.fromstring(format_as_cpd_xml(SimilarityResult(similar_groups=[group])))
+
+    assert root.find("duplication").get("fingerprint") == "clone-1a2b3c4d"
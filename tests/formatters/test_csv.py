@@ -0,0 +1,27 @@
+import csv
+import io
+
+from datasketch import MinHash
+
+from tests.conftest import make_region
+from treepeat.formatters.csv import COLUMNS, format_as_csv
+from treepeat.models.similarity import RegionSignature, SimilarityResult, SimilarRegionGroup
+
+
+def test_one_row_per_instance():
+    regions = [make_region("a.py", 1), make_region("b, c.py", 10)]
+    result = SimilarityResult(
+        signatures=[RegionSignature(region=regions[0], minhash=MinHash(), shingle_count=42)],
+        similar_groups=[SimilarRegionGroup(regions=regions, similarity=0.91234, fingerprint="clone-1a2b3c4d")],
+    )
+
+    rows = list(csv.DictReader(io.StringIO(format_as_csv(result))))
+
+    assert list(rows[0]) == COLUMNS
+    assert len(rows) == 2
+    assert rows[0]["clone_id"] == "clone-1a2b3c4d"
+    assert rows[0]["tokens"] == "42"
+    assert rows[0]["similarity"] == "0.9123"
+    assert rows[1]["path"] == "b, c.py"
+    assert rows[1]["lines"] == "5"
+    assert rows[1]["group_size"] == "2"
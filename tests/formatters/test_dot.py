@@ -0,0 +1,45 @@
+from pathlib import Path
+
+from treepeat.formatters.dot import format_as_dot
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+
+
+def _region(path: str, name: str, start_line: int, end_line: int) -> Region:
+    return Region(
+        path=Path(path),
+        language="python",
+        region_type="function_definition",
+        region_name=name,
+        start_line=start_line,
+        end_line=end_line,
+    )
+
+
+def test_dot_graph():
+    a = _region("a.py", "total", 1, 10)
+    b = _region("b.py", 'say "hi"', 5, 9)
+    result = SimilarityResult(
+        similar_groups=[
+            SimilarRegionGroup(regions=[a, b], similarity=1.0),
+            SimilarRegionGroup(regions=[b, a], similarity=0.9),
+        ]
+    )
+
+    dot = format_as_dot(result)
+
+    assert dot.startswith("graph treepeat {")
+    assert 'label="a.py";' in dot
+    assert '"b.py:5" [label="say \\"hi\\"\\n5-9"];' in dot
+    # Both groups relate the same pair of regions, so their duplicated lines add up on one edge
+    assert '"a.py:1" -- "b.py:5" [label="10", weight=10, penwidth=5.0, tooltip="100.0% similar"];' in dot
+    assert dot.count(" -- ") == 1
+
+
+def test_dot_edge_tooltip_lists_fingerprints():
+    a = _region("a.py", "total", 1, 10)
+    b = _region("b.py", "total", 5, 14)
+    result = SimilarityResult(
+        similar_groups=[SimilarRegionGroup(regions=[a, b], similarity=1.0, fingerprint="clone-1a2b3c4d")]
+    )
+
+    assert 'tooltip="100.0% similar (clone-1a2b3c4d)"' in format_as_dot(result)
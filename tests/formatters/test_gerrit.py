@@ -0,0 +1,38 @@
+import json
+
+from tests.conftest import make_region
+from treepeat.formatters.gerrit import REVIEW_TAG, ROBOT_ID, format_as_gerrit
+from treepeat.models.similarity import SimilarityResult, SimilarRegionGroup
+
+
+def _result(*paths: str) -> SimilarityResult:
+    group = SimilarRegionGroup(
+        regions=[make_region(path, 1 + 10 * i) for i, path in enumerate(paths)],
+        similarity=0.9,
+        fingerprint="clone-1a2b3c4d",
+    )
+    return SimilarityResult(similar_groups=[group])
+
+
+def test_one_robot_comment_per_instance_naming_the_other_copies():
+    review = json.loads(format_as_gerrit(_result("a.py", "a.py", "b.py")))
+
+    assert review["tag"] == REVIEW_TAG
+    assert sorted(review["robot_comments"]) == ["a.py", "b.py"]
+    [first, second] = review["robot_comments"]["a.py"]
+    assert (first["robot_id"], first["line"], second["line"]) == (ROBOT_ID, 1, 11)
+    assert first["message"] == (
+        "Duplicated code (90.0% similar, clone-1a2b3c4d): lines 1-5 are also found at a.py:11-15, b.py:21-25"
+    )
+    assert first["properties"] == {"fingerprint": "clone-1a2b3c4d"}
+
+
+def test_run_id_follows_the_clone_classes():
+    def run_id(result):
+        return json.loads(format_as_gerrit(result))["robot_comments"]["a.py"][0]["robot_run_id"]
+
+    assert run_id(_result("a.py", "b.py")) == run_id(_result("a.py", "c.py"))
+
+
+def test_no_clones_no_comments():
+    assert json.loads(format_as_gerrit(SimilarityResult())) == {"tag": REVIEW_TAG, "robot_comments": {}}
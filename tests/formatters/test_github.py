@@ -0,0 +1,32 @@
+from tests.conftest import make_region
+from treepeat.formatters.github import format_as_github
+from treepeat.models.similarity import SimilarityResult, SimilarRegionGroup
+
+
+def _result(*paths: str) -> SimilarityResult:
+    group = SimilarRegionGroup(
+        regions=[make_region(path, 1 + 10 * i) for i, path in enumerate(paths)],
+        similarity=0.9,
+        fingerprint="clone-1a2b3c4d",
+    )
+    return SimilarityResult(similar_groups=[group])
+
+
+def test_one_warning_per_instance_naming_the_other_copies():
+    lines = format_as_github(_result("a.py", "b.py")).splitlines()
+
+    assert lines == [
+        "::warning file=a.py,line=1,endLine=5,title=Duplicated code::90.0% similar to b.py:11-15 (clone-1a2b3c4d)",
+        "::warning file=b.py,line=11,endLine=15,title=Duplicated code::90.0% similar to a.py:1-5 (clone-1a2b3c4d)",
+    ]
+
+
+def test_properties_and_messages_are_escaped():
+    first = format_as_github(_result("dir,x/a:b.py", "100%.py")).splitlines()[0]
+
+    assert first.startswith("::warning file=dir%2Cx/a%3Ab.py,line=1,")
+    assert first.endswith("::90.0%25 similar to 100%25.py:11-15 (clone-1a2b3c4d)")
+
+
+def test_no_clones_no_annotations():
+    assert format_as_github(SimilarityResult()) == ""
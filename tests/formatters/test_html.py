@@ -0,0 +1,62 @@
+from pathlib import Path
+
+from treepeat.formatters.html import format_as_html
+from treepeat.models.similarity import Ownership, Region, SimilarityResult, SimilarRegionGroup
+
+
+def _region(path: Path, language: str = "python") -> Region:
+    return Region(
+        path=path,
+        language=language,
+        region_type="function_definition",
+        region_name="total",
+        start_line=1,
+        end_line=3,
+    )
+
+
+def test_html_report_renders_groups_side_by_side(tmp_path):
+    a = tmp_path / "a.py"
+    b = tmp_path / "b.py"
+    a.write_text("def total(xs):\n    s = sum(xs)\n    return s\n")
+    b.write_text("def total(xs):\n    s = max(xs)\n    return s\n")
+    group = SimilarRegionGroup(regions=[_region(a), _region(b)], similarity=0.9, fingerprint="clone-1a2b3c4d")
+
+    html = format_as_html(SimilarityResult(similar_groups=[group]))
+
+    assert html.startswith("<!DOCTYPE html>")
+    assert html.count('<div class="instance">') == 2
+    assert "clone-1a2b3c4d" in html
+    assert 'data-languages="python"' in html
+    assert '<span class="changed">    s = max(xs)</span>' in html
+    assert '<option value="python">python</option>' in html
+
+
+def test_html_report_escapes_source(tmp_path):
+    a = tmp_path / "a.html"
+    a.write_text("<div>\n<script>alert(1)</script>\n</div>\n")
+    group = SimilarRegionGroup(regions=[_region(a, "html"), _region(a, "html")], similarity=1.0)
+
+    html = format_as_html(SimilarityResult(similar_groups=[group]))
+
+    assert "<script>alert(1)</script>" not in html
+    assert "&lt;script&gt;alert(1)&lt;/script&gt;" in html
+
+
+def test_html_report_shows_instance_owners(tmp_path):
+    a = tmp_path / "a.py"
+    a.write_text("x = 1\n")
+    owned = _region(a).model_copy(update={"owner": Ownership(author="Ada", owners=["@org/core", "@ada"])})
+    group = SimilarRegionGroup(regions=[owned, _region(a)], similarity=1.0)
+
+    html = format_as_html(SimilarityResult(similar_groups=[group]))
+
+    assert html.count('<p class="owner">') == 1
+    assert '<p class="owner">by Ada, owned by @org/core @ada</p>' in html
+
+
+def test_html_report_without_groups():
+    html = format_as_html(SimilarityResult())
+
+    assert "0 clone group(s)" in html
+    assert "0 of 0 compared lines duplicated" in html
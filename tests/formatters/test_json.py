@@ -0,0 +1,86 @@
+import json
+from pathlib import Path
+
+from treepeat.formatters.json import SCHEMA_VERSION, format_as_json, region_from_dict, region_to_dict
+from treepeat.models.similarity import Ownership, Region, SimilarityResult, SimilarRegionGroup
+
+SCHEMA_PATH = Path(__file__).parent.parent.parent / "docs" / "schema" / "report-v1.schema.json"
+
+
+def _result() -> SimilarityResult:
+    regions = [
+        Region(
+            path=Path(name),
+            language="python",
+            region_type="function_definition",
+            region_name="total",
+            start_line=10,
+            end_line=14,
+        )
+        for name in ("a.py", "b.py")
+    ]
+    group = SimilarRegionGroup(regions=regions, similarity=0.91234, fingerprint="clone-1a2b3c4d")
+    return SimilarityResult(similar_groups=[group])
+
+
+def test_json_report_structure():
+    report = json.loads(format_as_json(_result()))
+
+    assert report["schema_version"] == SCHEMA_VERSION
+    assert report["summary"]["clone_classes"] == 1
+    assert report["summary"]["clone_instances"] == 2
+    clone = report["clone_classes"][0]
+    assert clone["fingerprint"] == "clone-1a2b3c4d"
+    assert clone["similarity"] == 0.9123
+    assert clone["instances"][1] == {
+        "path": "b.py",
+        "language": "python",
+        "region_type": "function_definition",
+        "region_name": "total",
+        "start_line": 10,
+        "end_line": 14,
+        "lines": 5,
+    }
+
+
+def test_json_report_matches_published_schema_fields():
+    schema = json.loads(SCHEMA_PATH.read_text())
+    report = json.loads(format_as_json(_result()))
+
+    assert set(schema["required"]) == set(report)
+    assert schema["properties"]["schema_version"]["const"] == SCHEMA_VERSION
+    assert set(schema["$defs"]["clone_class"]["required"]) == set(report["clone_classes"][0])
+    assert set(schema["$defs"]["instance"]["required"]) == set(report["clone_classes"][0]["instances"][0])
+    assert set(schema["$defs"]["line_counts"]["required"]) <= set(report["duplication"])
+    assert set(schema["properties"]["duplication"]["required"]) <= set(report["duplication"])
+
+
+def test_json_report_includes_fragment_types():
+    result = _result()
+    result.fragment_counts = {("python", "function_definition"): 7}
+
+    report = json.loads(format_as_json(result))
+
+    assert report["fragment_types"] == [
+        {"language": "python", "region_type": "function_definition", "extracted": 7, "in_clones": 2}
+    ]
+
+
+def test_json_report_lists_test_clone_classes_apart():
+    result = SimilarityResult(test_groups=_result().similar_groups)
+
+    report = json.loads(format_as_json(result))
+
+    assert report["clone_classes"] == []
+    assert report["test_clone_classes"][0]["fingerprint"] == "clone-1a2b3c4d"
+
+
+def test_json_instance_owner_round_trips():
+    region = _result().similar_groups[0].regions[0]
+    owned = region.model_copy(update={"owner": Ownership(author="Ada", owners=["@org/core"])})
+
+    instance = region_to_dict(owned)
+
+    assert "owner" not in region_to_dict(region)
+    assert instance["owner"] == {"author": "Ada", "owners": ["@org/core"]}
+    assert region_from_dict(instance) == owned
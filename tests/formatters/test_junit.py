@@ -0,0 +1,28 @@
+import xml.etree.ElementTree as ET
+
+from tests.conftest import make_region
+from treepeat.formatters.junit import format_as_junit
+from treepeat.models.similarity import SimilarityResult, SimilarRegionGroup
+
+
+def test_each_clone_class_is_a_failed_testcase():
+    group = SimilarRegionGroup(
+        regions=[make_region("a.py", 1), make_region("b.py", 10)], similarity=0.9, fingerprint="clone-1a2b3c4d"
+    )
+
+    suite = ET.fromstring(format_as_junit(SimilarityResult(similar_groups=[group]))).find("testsuite")
+
+    assert suite.get("tests") == "1"
+    assert suite.get("failures") == "1"
+    testcase = suite.find("testcase")
+    assert testcase.get("name") == "clone-1a2b3c4d (2 instances)"
+    assert testcase.get("file") == "a.py"
+    assert testcase.get("line") == "1"
+    assert "b.py:10-14 (5 lines) total" in testcase.find("failure").text
+
+
+def test_no_clones_is_a_passing_report():
+    suite = ET.fromstring(format_as_junit(SimilarityResult())).find("testsuite")
+
+    assert suite.get("failures") == "0"
+    assert suite.find("testcase").find("failure") is None
@@ -0,0 +1,51 @@
+from pathlib import Path
+
+import pytest
+
+from treepeat.formatters.markdown import MAX_ROWS, format_as_markdown, validate_link_template
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+
+
+def _group(lines: int, fingerprint: str = "clone-1a2b3c4d") -> SimilarRegionGroup:
+    regions = [
+        Region(
+            path=Path(name),
+            language="python",
+            region_type="function_definition",
+            region_name="total",
+            start_line=3,
+            end_line=2 + lines,
+        )
+        for name in ("src/a.py", "src/b|c.py")
+    ]
+    return SimilarRegionGroup(regions=regions, similarity=0.9, fingerprint=fingerprint)
+
+
+def test_summary_table_lists_largest_first():
+    result = SimilarityResult(similar_groups=[_group(5, "clone-small"), _group(20, "clone-large")])
+
+    report = format_as_markdown(result)
+
+    assert "2 clone class(es), 50 duplicated line(s)" in report
+    rows = [line for line in report.splitlines() if line.startswith("| clone-")]
+    assert rows[0].startswith("| clone-large | 90.0% | 40 |")
+    assert "`src/b\\|c.py:3-7`" in rows[1]
+
+
+def test_link_template():
+    template = "https://example.com/blob/main/{path}#L{start_line}-L{end_line}"
+
+    report = format_as_markdown(SimilarityResult(similar_groups=[_group(5)]), link_template=template)
+
+    assert "[`src/a.py:3-7`](https://example.com/blob/main/src/a.py#L3-L7)" in report
+
+
+def test_rows_are_capped():
+    report = format_as_markdown(SimilarityResult(similar_groups=[_group(5) for _ in range(MAX_ROWS + 2)]))
+
+    assert "...and 2 more clone class(es)." in report
+
+
+def test_invalid_link_template():
+    with pytest.raises(ValueError, match="supported fields"):
+        validate_link_template("https://example.com/{file}")
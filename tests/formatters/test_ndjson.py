@@ -0,0 +1,43 @@
+import io
+import json
+from pathlib import Path
+
+from treepeat.formatters.ndjson import NdjsonWriter
+from treepeat.models.similarity import Region, SimilarRegionGroup
+
+
+def _group(fingerprint: str) -> SimilarRegionGroup:
+    regions = [
+        Region(
+            path=Path(name),
+            language="python",
+            region_type="function_definition",
+            region_name="total",
+            start_line=1,
+            end_line=6,
+        )
+        for name in ("a.py", "b.py")
+    ]
+    return SimilarRegionGroup(regions=regions, similarity=1.0, fingerprint=fingerprint)
+
+
+def test_writer_emits_one_clone_class_per_line():
+    stream = io.StringIO()
+    writer = NdjsonWriter(stream)
+
+    writer(_group("clone-00000001"))
+    writer(_group("clone-00000002"))
+
+    lines = stream.getvalue().splitlines()
+    assert [json.loads(line)["fingerprint"] for line in lines] == ["clone-00000001", "clone-00000002"]
+    assert json.loads(lines[0])["instances"][1]["path"] == "b.py"
+
+
+def test_writer_skips_excluded_groups():
+    stream = io.StringIO()
+    writer = NdjsonWriter(stream, excluded_fingerprints=("00000001",))
+
+    writer(_group("clone-00000001"))
+    writer(_group("clone-00000002"))
+
+    assert [json.loads(line)["fingerprint"] for line in stream.getvalue().splitlines()] == ["clone-00000002"]
@@ -0,0 +1,96 @@
+import json
+from pathlib import Path
+
+from treepeat.formatters.sarif import format_as_sarif
+from treepeat.models.similarity import Ownership, Region, SimilarityResult, SimilarRegionGroup
+
+
+def _group(lines: int) -> SimilarRegionGroup:
+    regions = [
+        Region(
+            path=Path(name),
+            language="python",
+            region_type="function_definition",
+            region_name="f",
+            start_line=1,
+            end_line=lines,
+        )
+        for name in ("a.py", "b.py")
+    ]
+    return SimilarRegionGroup(regions=regions, similarity=1.0)
+
+
+def _run(result: SimilarityResult, size_buckets: bool) -> dict:
+    return json.loads(format_as_sarif(result, size_buckets=size_buckets))["runs"][0]
+
+
+def test_generic_rule_by_default():
+    run = _run(SimilarityResult(similar_groups=[_group(60), _group(5)]), size_buckets=False)
+
+    assert [rule["id"] for rule in run["tool"]["driver"]["rules"]] == ["similar-code"]
+    assert {r["ruleId"] for r in run["results"]} == {"similar-code"}
+
+
+def test_size_buckets_map_results_to_rules():
+    run = _run(SimilarityResult(similar_groups=[_group(60), _group(5)]), size_buckets=True)
+
+    assert [r["ruleId"] for r in run["results"]] == ["treepeat/clone-large", "treepeat/clone-small"]
+    rules = {rule["id"]: rule for rule in run["tool"]["driver"]["rules"]}
+    assert set(rules) == {"treepeat/clone-large", "treepeat/clone-small"}
+    assert "50 or more lines" in rules["treepeat/clone-large"]["fullDescription"]["text"]
+
+
+def test_size_bucket_rules_have_increasing_default_levels():
+    run = _run(SimilarityResult(similar_groups=[_group(60), _group(15), _group(5)]), size_buckets=True)
+
+    levels = {rule["id"]: rule["defaultConfiguration"]["level"] for rule in run["tool"]["driver"]["rules"]}
+    assert levels == {
+        "treepeat/clone-large": "error",
+        "treepeat/clone-medium": "warning",
+        "treepeat/clone-small": "note",
+    }
+
+
+def test_size_buckets_medium_boundary():
+    run = _run(SimilarityResult(similar_groups=[_group(15)]), size_buckets=True)
+
+    assert [r["ruleId"] for r in run["results"]] == ["treepeat/clone-medium"]
+
+
+def test_suppressed_groups_hidden_by_default():
+    run = _run(SimilarityResult(similar_groups=[_group(5)], suppressed_groups=[_group(60)]), size_buckets=False)
+
+    assert len(run["results"]) == 1
+    assert "suppressions" not in run["results"][0]
+
+
+def test_report_suppressed_marks_in_source_suppressions():
+    result = SimilarityResult(similar_groups=[_group(5)], suppressed_groups=[_group(60)])
+    run = json.loads(format_as_sarif(result, report_suppressed=True))["runs"][0]
+
+    assert "suppressions" not in run["results"][0]
+    assert [s["kind"] for s in run["results"][1]["suppressions"]] == ["inSource"]
+
+
+def test_owners_are_listed_per_instance_and_per_result():
+    group = _group(5)
+    owned = [
+        region.model_copy(update={"owner": Ownership(author="Ada", author_email="ada@example.com", owners=[team])})
+        for region, team in zip(group.regions, ("@org/api", "@org/web"), strict=True)
+    ]
+    run = _run(SimilarityResult(similar_groups=[group.model_copy(update={"regions": owned})]), size_buckets=False)
+
+    properties = run["results"][0]["properties"]
+    assert properties["owners"] == ["@org/api", "@org/web"]
+    assert properties["regions"][0]["owner"] == {
+        "author": "Ada",
+        "author_email": "ada@example.com",
+        "owners": ["@org/api"],
+    }
+
+
+def test_no_owners_without_ownership():
+    properties = _run(SimilarityResult(similar_groups=[_group(5)]), size_buckets=False)["results"][0]["properties"]
+
+    assert "owners" not in properties
+    assert "owner" not in properties["regions"][0]
@@ -0,0 +1,33 @@
+import json
+
+from tests.conftest import make_region
+from treepeat.formatters.sonarqube import RULE_ID, format_as_sonarqube
+from treepeat.models.similarity import SimilarityResult, SimilarRegionGroup
+
+
+def test_generic_issue_report():
+    group = SimilarRegionGroup(regions=[make_region("a.py", 1), make_region("b.py", 10)], similarity=0.97)
+
+    report = json.loads(format_as_sonarqube(SimilarityResult(similar_groups=[group])))
+
+    assert [rule["id"] for rule in report["rules"]] == [RULE_ID]
+    issue = report["issues"][0]
+    assert issue["ruleId"] == RULE_ID
+    assert issue["impacts"][0]["severity"] == "HIGH"
+    assert issue["primaryLocation"]["filePath"] == "a.py"
+    assert issue["primaryLocation"]["textRange"] == {"startLine": 1, "endLine": 5}
+    assert [loc["filePath"] for loc in issue["secondaryLocations"]] == ["b.py"]
+
+
+def test_empty_report():
+    assert json.loads(format_as_sonarqube(SimilarityResult())) == {"rules": [], "issues": []}
+
+
+def test_issue_message_carries_fingerprint():
+    group = SimilarRegionGroup(
+        regions=[make_region("a.py", 1), make_region("b.py", 10)], similarity=1.0, fingerprint="clone-1a2b3c4d"
+    )
+
+    report = json.loads(format_as_sonarqube(SimilarityResult(similar_groups=[group])))
+
+    assert report["issues"][0]["primaryLocation"]["message"].endswith("[clone-1a2b3c4d]")
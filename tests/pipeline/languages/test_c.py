@@ -0,0 +1,53 @@
+"""Tests for C language configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from tests.conftest import parse_fixture
+from treepeat.config import LSHSettings, PipelineSettings, RulesSettings, set_settings
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+# Fixture path
+fixture_comprehensive = Path(__file__).parent.parent.parent / "fixtures" / "c" / "comprehensive.c"
+
+
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_c_rules_extract(rules):
+    """Test that C files can be processed with different rule sets."""
+    parsed = parse_fixture(fixture_comprehensive, "c")
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    region_types = {r.region.region_type for r in regions}
+    assert {"function_definition", "struct"} <= region_types
+
+
+def _platform_variant(source: str) -> str:
+    """The same function guarded by another macro and with a different fallback branch."""
+    return source.replace("#ifdef _WIN32", "#if defined(USE_ARENA)").replace(
+        "realloc(buf->data, capacity)", "arena_realloc(arena_default(), buf->data, buf->length, capacity)"
+    )
+
+
+@pytest.mark.parametrize("flatten,expected_groups", [(False, 0), (True, 1)])
+def test_flatten_preprocessor_matches_platform_variants(tmp_path, flatten, expected_groups):
+    source = fixture_comprehensive.read_text()
+    (tmp_path / "buffer.c").write_text(source)
+    (tmp_path / "buffer_arena.c").write_text(_platform_variant(source))
+    set_settings(
+        PipelineSettings(
+            rules=RulesSettings(flatten_preprocessor=flatten),
+            lsh=LSHSettings(similarity_percent=1.0),
+        )
+    )
+
+    result = run_pipeline(tmp_path)
+
+    function_groups = [g for g in result.similar_groups if g.regions[0].region_type == "function_definition"]
+    assert len(function_groups) == expected_groups
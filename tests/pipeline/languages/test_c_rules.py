@@ -0,0 +1,40 @@
+from treepeat.pipeline.languages.c import CConfig
+
+
+def test_c_rules_detailed(rule_tester):
+    config = CConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore comments",
+                "source": "/* comment */\nint x;",
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Ignore includes",
+                "source": "#include <stdio.h>\nint x;",
+                "expected_symbol": None,
+                "unexpected_symbol": "preproc_include",
+            },
+            {
+                "rule_name": "Anonymize function names",
+                "source": "int add(int a) { return a; }",
+                "expected_symbol": "FUNC",
+                "unexpected_symbol": "add",
+            },
+            {
+                "rule_name": "Anonymize identifiers",
+                "source": "int counter = 1;",
+                "expected_symbol": "VAR_1",
+                "unexpected_symbol": "counter",
+            },
+            {
+                "rule_name": "Anonymize literals",
+                "source": 'char *name = "hello";',
+                "expected_symbol": "<LIT>",
+                "unexpected_symbol": "hello",
+            },
+        ],
+    )
@@ -0,0 +1,32 @@
+"""Tests for C++ language configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from tests.conftest import parse_fixture
+from treepeat.pipeline.parse import detect_language
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+# Fixture path
+fixture_comprehensive = Path(__file__).parent.parent.parent / "fixtures" / "cpp" / "comprehensive.cpp"
+
+
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_cpp_rules_extract(rules):
+    """Test that C++ files can be processed with different rule sets."""
+    parsed = parse_fixture(fixture_comprehensive, "cpp")
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    region_types = {r.region.region_type for r in regions}
+    assert {"function_definition", "class", "namespace_definition"} <= region_types
+
+
+@pytest.mark.parametrize("name", ["stock.cpp", "stock.cc", "stock.h", "stock.hpp"])
+def test_cpp_extensions(name):
+    assert detect_language(Path(name)) == "cpp"
@@ -0,0 +1,46 @@
+from treepeat.pipeline.languages.cpp import CppConfig
+
+
+def test_cpp_rules_detailed(rule_tester):
+    config = CppConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore comments",
+                "source": "// comment\nint x;",
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Ignore includes",
+                "source": "#include <vector>\nint x;",
+                "expected_symbol": None,
+                "unexpected_symbol": "preproc_include",
+            },
+            {
+                "rule_name": "Anonymize function names",
+                "source": "int add(int a) { return a; }",
+                "expected_symbol": "FUNC",
+                "unexpected_symbol": "add",
+            },
+            {
+                "rule_name": "Anonymize method names",
+                "source": "int Stock::reserve(int quantity) { return quantity; }",
+                "expected_symbol": "FUNC",
+                "unexpected_symbol": "reserve",
+            },
+            {
+                "rule_name": "Anonymize identifiers",
+                "source": "int counter = 1;",
+                "expected_symbol": "VAR_1",
+                "unexpected_symbol": "counter",
+            },
+            {
+                "rule_name": "Anonymize literals",
+                "source": "int limit = 42;",
+                "expected_symbol": "<LIT>",
+                "unexpected_symbol": "42",
+            },
+        ],
+    )
@@ -0,0 +1,70 @@
+"""Tests for C# language configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from tests.conftest import parse_fixture
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+# Fixture path
+fixture_comprehensive = Path(__file__).parent.parent.parent / "fixtures" / "csharp" / "comprehensive.cs"
+fixture_partial = Path(__file__).parent.parent.parent / "fixtures" / "csharp" / "partial"
+
+
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_csharp_rules_extract(rules):
+    """Test that C# files can be processed with different rule sets."""
+    parsed = parse_fixture(fixture_comprehensive, "csharp")
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    region_types = {r.region.region_type for r in regions}
+    assert {
+        "method_declaration",
+        "constructor_declaration",
+        "property_declaration",
+        "partial_class",
+        "struct_declaration",
+        "interface_declaration",
+        "record_declaration",
+        "linq",
+    } <= region_types
+    # Both parts of the partial class are regions of their own
+    assert sum(r.region.region_type == "partial_class" for r in regions) == 2
+    assert not any(r.region.region_type == "class_declaration" for r in regions)
+
+
+def test_partial_class_parts_extract_their_members():
+    engine = RuleEngine([rule for rule, _ in build_default_rules()])
+    parsed = [parse_fixture(path, "csharp") for path in sorted(fixture_partial.glob("*.cs"))]
+    regions = extract_all_regions(parsed, engine)
+
+    parts = [r.region for r in regions if r.region.region_type == "partial_class"]
+    assert [(part.path.name, part.region_name) for part in parts] == [
+        ("Order.Generated.cs", "Order"),
+        ("Order.cs", "Order"),
+    ]
+    methods = {
+        (r.region.path.name, r.region.region_name) for r in regions if r.region.region_type == "method_declaration"
+    }
+    assert methods == {("Order.cs", "Add"), ("Order.cs", "Total"), ("Order.Generated.cs", "Sum")}
+
+
+def test_code_duplicated_between_partial_class_parts_is_detected():
+    """The generated half of a partial class repeats a method of the hand-written half."""
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=0.9, min_lines=5)))
+    result = run_pipeline(fixture_partial)
+
+    methods = [
+        sorted((r.path.name, r.region_name) for r in group.regions)
+        for group in result.similar_groups
+        if group.regions[0].region_type == "method_declaration"
+    ]
+    assert methods == [[("Order.Generated.cs", "Sum"), ("Order.cs", "Total")]]
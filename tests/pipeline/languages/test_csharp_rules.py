@@ -0,0 +1,52 @@
+from treepeat.pipeline.languages.csharp import CSharpConfig
+
+
+def test_csharp_rules_detailed(rule_tester):
+    config = CSharpConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore using directives",
+                "source": "using System.Linq;",
+                "expected_symbol": None,
+                "unexpected_symbol": "using_directive",
+            },
+            {
+                "rule_name": "Ignore comments",
+                "source": "// line comment\n/* block */",
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Ignore attributes",
+                "source": "[Serializable]\nclass A {}",
+                "expected_symbol": None,
+                "unexpected_symbol": "Serializable",
+            },
+            {
+                "rule_name": "Anonymize method names",
+                "source": "class A { void MyMethod() {} }",
+                "expected_symbol": "FUNC",
+                "unexpected_symbol": "MyMethod",
+            },
+            {
+                "rule_name": "Anonymize type names",
+                "source": "class MyClass {}",
+                "expected_symbol": "TYPE",
+                "unexpected_symbol": "MyClass",
+            },
+            {
+                "rule_name": "Anonymize identifiers",
+                "source": "int myVar = 1;",
+                "expected_symbol": "VAR_1",
+                "unexpected_symbol": "myVar",
+            },
+            {
+                "rule_name": "Anonymize literals",
+                "source": 'class A { string s = "hello"; }',
+                "expected_symbol": "<LIT>",
+                "unexpected_symbol": "hello",
+            },
+        ],
+    )
@@ -0,0 +1,59 @@
+"""Tests for Dockerfile language configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from tests.conftest import parse_fixture
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.pipeline.parse import detect_language
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+# Fixture path
+fixture_comprehensive = Path(__file__).parent.parent.parent / "fixtures" / "dockerfile" / "Dockerfile"
+
+
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_dockerfile_rules_extract(rules):
+    """Test that Dockerfiles can be processed with different rule sets."""
+    parsed = parse_fixture(fixture_comprehensive, "dockerfile")
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    region_types = {r.region.region_type for r in regions}
+    assert {"dockerfile", "run"} <= region_types
+
+
+@pytest.mark.parametrize(
+    "name,expected",
+    [
+        ("Dockerfile", "dockerfile"),
+        ("Dockerfile.prod", "dockerfile"),
+        ("api.dockerfile", "dockerfile"),
+        ("Containerfile", "dockerfile"),
+        ("Dockerfile.yaml", "dockerfile"),
+        ("docker-compose.yml", "yaml"),
+    ],
+)
+def test_dockerfile_detected_by_name(name, expected):
+    assert detect_language(Path(name)) == expected
+
+
+def test_services_sharing_build_steps_are_detected(tmp_path):
+    """Dockerfiles that repeat the same steps on another base image tag are reported together."""
+    body = fixture_comprehensive.read_text()
+    (tmp_path / "api").mkdir()
+    (tmp_path / "api" / "Dockerfile").write_text(body)
+    (tmp_path / "worker").mkdir()
+    (tmp_path / "worker" / "Dockerfile.prod").write_text(body.replace("-slim", "-bookworm"))
+    set_settings(PipelineSettings(lsh=LSHSettings(min_lines=5)))
+
+    result = run_pipeline(tmp_path)
+
+    grouped = {r.path.name for group in result.similar_groups for r in group.regions}
+    assert grouped == {"Dockerfile", "Dockerfile.prod"}
@@ -0,0 +1,34 @@
+from treepeat.pipeline.languages.dockerfile import DockerfileConfig
+
+
+def test_dockerfile_rules_detailed(rule_tester):
+    config = DockerfileConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore comments",
+                "source": "# comment\nFROM alpine",
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Ignore image tags",
+                "source": "FROM python:3.11",
+                "expected_symbol": None,
+                "unexpected_symbol": "image_tag",
+            },
+            {
+                "rule_name": "Anonymize variables",
+                "source": "WORKDIR ${APP_HOME}",
+                "expected_symbol": "VAR_1",
+                "unexpected_symbol": "APP_HOME",
+            },
+            {
+                "rule_name": "Anonymize strings",
+                "source": 'ENV APP_HOME="/srv/app"',
+                "expected_symbol": "<STR>",
+                "unexpected_symbol": "/srv/app",
+            },
+        ],
+    )
@@ -21,3 +21,99 @@ def test_go_rules_extract(rules, expected_min_regions):
     regions = extract_all_regions([parsed], engine)
 
     assert len(regions) >= expected_min_regions
+
+
+def _write_sum(tmp_path: Path, name: str, return_type: str, values: str = "values") -> None:
+    (tmp_path / f"{name}.go").write_text(
+        "package main\n"
+        "\n"
+        f"func Sum({values} []int) {return_type} {{\n"
+        "\ttotal := 0\n"
+        f"\tfor _, value := range {values} {{\n"
+        "\t\ttotal += value\n"
+        "\t}\n"
+        "\treturn total\n"
+        "}\n"
+    )
+
+
+def _sum_similarities(
+    tmp_path: Path, normalize_signature_types: bool, similarity_percent: float, normalize: tuple[str, ...] = ()
+) -> list[float]:
+    from treepeat.config import LSHSettings, PipelineSettings, RulesSettings, set_settings
+    from treepeat.pipeline.pipeline import run_pipeline
+
+    set_settings(
+        PipelineSettings(
+            rules=RulesSettings(normalize_signature_types=normalize_signature_types, normalize=list(normalize)),
+            lsh=LSHSettings(similarity_percent=similarity_percent, min_lines=3),
+        )
+    )
+    result = run_pipeline(tmp_path)
+    return [group.similarity for group in result.similar_groups if len(group.regions) == 2]
+
+
+def test_normalize_signature_types_matches_different_return_types(tmp_path):
+    """Functions identical except for an int vs int64 return type group when signature types are normalized."""
+    from treepeat.pipeline.verification import SIGNATURE_TYPE_MATCH_SIMILARITY
+
+    _write_sum(tmp_path, "sum_int", "int")
+    _write_sum(tmp_path, "sum_int64", "int64")
+
+    assert not _sum_similarities(tmp_path, False, 1.0)
+    assert _sum_similarities(tmp_path, True, SIGNATURE_TYPE_MATCH_SIMILARITY) == [SIGNATURE_TYPE_MATCH_SIMILARITY]
+
+
+def test_signature_type_only_matches_are_not_exact_clones(tmp_path):
+    """A match only through normalized signature types is a near-match; identical signatures stay exact."""
+    _write_sum(tmp_path, "sum_int", "int")
+    _write_sum(tmp_path, "sum_int64", "int64")
+    assert not _sum_similarities(tmp_path, True, 1.0)
+
+    _write_sum(tmp_path, "sum_int64", "int")
+    assert _sum_similarities(tmp_path, True, 1.0) == [1.0]
+
+
+def test_parameter_names_are_not_signature_types(tmp_path):
+    """Copies whose signatures only differ by a parameter name stay exact clones when signature types are normalized."""
+    _write_sum(tmp_path, "sum_values", "int")
+    _write_sum(tmp_path, "sum_items", "int", values="items")
+
+    assert _sum_similarities(tmp_path, True, 1.0, normalize=("identifiers",)) == [1.0]
+
+
+def _write_scaled_sum(tmp_path: Path, name: str, acc: str, item: str, start: str) -> None:
+    (tmp_path / f"{name}.go").write_text(
+        "package main\n"
+        "\n"
+        f"func {name}(values []int) int {{\n"
+        f"\t{acc} := {start}\n"
+        f"\tfor _, {item} := range values {{\n"
+        f"\t\t{acc} += {item} * 2\n"
+        "\t}\n"
+        f"\treturn {acc}\n"
+        "}\n"
+    )
+
+
+def _scaled_sums_grouped(tmp_path: Path, normalize: list[str]) -> bool:
+    from treepeat.config import LSHSettings, PipelineSettings, RulesSettings, set_settings
+    from treepeat.pipeline.pipeline import run_pipeline
+
+    set_settings(
+        PipelineSettings(
+            rules=RulesSettings(normalize=normalize),
+            lsh=LSHSettings(similarity_percent=1.0, min_lines=3),
+        )
+    )
+    result = run_pipeline(tmp_path)
+    return any(len(group.regions) == 2 for group in result.similar_groups)
+
+
+def test_normalize_identifiers_and_literals_matches_renamed_copies(tmp_path):
+    """Copies that only renamed locals and changed a constant group once identifiers and literals are normalized."""
+    _write_scaled_sum(tmp_path, "SumScaled", "total", "value", "0")
+    _write_scaled_sum(tmp_path, "AccumulateDoubled", "acc", "item", "1")
+
+    assert not _scaled_sums_grouped(tmp_path, normalize=[])
+    assert _scaled_sums_grouped(tmp_path, normalize=["identifiers", "literals"])
@@ -0,0 +1,37 @@
+"""Tests for HCL (Terraform) language configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from tests.conftest import parse_fixture
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+# Fixture path
+fixture_comprehensive = Path(__file__).parent.parent.parent / "fixtures" / "hcl" / "comprehensive.tf"
+
+
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_hcl_rules_extract(rules):
+    """Test that Terraform files can be processed with different rule sets."""
+    parsed = parse_fixture(fixture_comprehensive, "hcl")
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    assert {r.region.region_type for r in regions} == {"block"}
+    assert len(regions) >= 4
+
+
+def test_renamed_environment_copies_are_detected():
+    """Resource blocks copied between environments only differ by their name."""
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=0.9, min_lines=5)))
+    result = run_pipeline(fixture_comprehensive)
+
+    starts = [sorted(r.start_line for r in group.regions) for group in result.similar_groups]
+    assert [7, 20] in starts
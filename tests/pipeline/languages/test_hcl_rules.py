@@ -0,0 +1,28 @@
+from treepeat.pipeline.languages.hcl import HCLConfig
+
+
+def test_hcl_rules_detailed(rule_tester):
+    config = HCLConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore comments",
+                "source": "# line comment\nx = 1",
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Anonymize block names",
+                "source": 'resource "aws_instance" "web_prod" {\n}',
+                "expected_symbol": "NAME",
+                "unexpected_symbol": "web_prod",
+            },
+            {
+                "rule_name": "Anonymize literals",
+                "source": 'x = "hello"',
+                "expected_symbol": "<LIT>",
+                "unexpected_symbol": "hello",
+            },
+        ],
+    )
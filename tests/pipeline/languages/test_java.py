@@ -76,3 +76,45 @@ def test_java_specific_rules():
     # Class and method should be there
     assert "class_declaration" in shingle_str
     assert "method_declaration" in shingle_str
+
+
+def _write_member(tmp_path: Path, class_name: str, modifier: str) -> Path:
+    path = tmp_path / f"{class_name}.java"
+    path.write_text(
+        f"class {class_name} {{\n"
+        f"    {modifier} int total(int[] values) {{\n"
+        "        int sum = 0;\n"
+        "        for (int value : values) {\n"
+        "            sum += value;\n"
+        "        }\n"
+        "        return sum;\n"
+        "    }\n"
+        "}\n"
+    )
+    return path
+
+
+def _methods_grouped(tmp_path: Path, ignore_qualifiers: bool) -> bool:
+    from treepeat.config import LSHSettings, PipelineSettings, RulesSettings, set_settings
+    from treepeat.pipeline.pipeline import run_pipeline
+
+    set_settings(
+        PipelineSettings(
+            rules=RulesSettings(ignore_qualifiers=ignore_qualifiers),
+            lsh=LSHSettings(similarity_percent=1.0, min_lines=3),
+        )
+    )
+    result = run_pipeline(tmp_path)
+    return any(
+        {r.region_type for r in group.regions} == {"method_declaration"} and len(group.regions) == 2
+        for group in result.similar_groups
+    )
+
+
+def test_ignore_qualifiers_matches_methods_with_different_modifiers(tmp_path):
+    """Methods differing only in access modifiers match when qualifiers are ignored."""
+    _write_member(tmp_path, "Public", "public")
+    _write_member(tmp_path, "Hidden", "private static")
+
+    assert not _methods_grouped(tmp_path, ignore_qualifiers=False)
+    assert _methods_grouped(tmp_path, ignore_qualifiers=True)
@@ -0,0 +1,44 @@
+"""Tests for JSON language configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from tests.conftest import parse_fixture
+from treepeat.config import LSHSettings, PipelineSettings, RulesSettings, ShingleSettings, set_settings
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+# Fixture path
+fixture_comprehensive = Path(__file__).parent.parent.parent / "fixtures" / "json" / "comprehensive.json"
+
+
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_json_rules_extract(rules):
+    """Test that JSON files can be processed with different rule sets."""
+    parsed = parse_fixture(fixture_comprehensive, "json")
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    region_types = {r.region.region_type for r in regions}
+    assert {"document", "pair", "array"} <= region_types
+
+
+def test_reordered_environments_are_detected():
+    """The production block lists the staging keys in another order; canonicalizing matches them."""
+    set_settings(
+        PipelineSettings(
+            rules=RulesSettings(ruleset="loose"),
+            shingle=ShingleSettings(canonicalize=True),
+            lsh=LSHSettings(similarity_percent=0.9, min_lines=5),
+        )
+    )
+    result = run_pipeline(fixture_comprehensive)
+
+    starts = [sorted(r.start_line for r in group.regions) for group in result.similar_groups]
+    assert [7, 16] in starts
+
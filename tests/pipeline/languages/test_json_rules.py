@@ -0,0 +1,28 @@
+from treepeat.pipeline.languages.json import JSONConfig
+
+
+def test_json_rules_detailed(rule_tester):
+    config = JSONConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore comments",
+                "source": '// comment\n{"a": 1}',
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Anonymize scalar values",
+                "source": '{"replicas": 3}',
+                "expected_symbol": "<LIT>",
+                "unexpected_symbol": "3",
+            },
+            {
+                "rule_name": "Anonymize string values",
+                "source": '{"image": "nginx"}',
+                "expected_symbol": "<LIT>",
+                "unexpected_symbol": "nginx",
+            },
+        ],
+    )
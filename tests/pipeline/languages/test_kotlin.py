@@ -26,6 +26,8 @@ def test_kotlin_rules_extract(rules):
 
     # Should find at least Comprehensive class and two function declarations
     assert len(regions) >= 3
+    region_types = {r.region.region_type for r in regions}
+    assert {"class_declaration", "function_declaration", "object_declaration", "when_expression"} <= region_types
 
 
 def test_kotlin_specific_rules():
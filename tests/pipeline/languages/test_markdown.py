@@ -5,8 +5,10 @@ from pathlib import Path
 import pytest
 
 from tests.conftest import parse_fixture
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
 from treepeat.pipeline.languages.markdown import MarkdownConfig
 from treepeat.pipeline.parse import parse_file
+from treepeat.pipeline.pipeline import run_pipeline
 from treepeat.pipeline.region_extraction import extract_all_regions
 from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
 from treepeat.pipeline.shingle import ASTShingler
@@ -215,8 +217,17 @@ def _resolve_fence_language(source: bytes) -> str:
     ("py", "python"),
     ("sh", "bash"),
     ("shell", "bash"),
+    ("zsh", "bash"),
+    ("c++", "cpp"),
+    ("golang", "go"),
+    ("jsonc", "json"),
     ("rs", "rust"),
+    ("rb", "ruby"),
     ("kt", "kotlin"),
+    ("docker", "dockerfile"),
+    ("protobuf", "proto"),
+    ("tf", "hcl"),
+    ("terraform", "hcl"),
     ("md", "markdown"),
     ("yml", "yaml"),
 ])
@@ -267,3 +278,20 @@ def test_unknown_language_skips_injection_and_warns(caplog):
     assert any("ruby" in msg for msg in caplog.messages), (
         "Expected a warning mentioning 'ruby' for the unsupported language"
     )
+
+
+def test_mdx_code_blocks_group_with_source(tmp_path):
+    """A snippet in an .mdx page that was copied from the implementation is reported with it."""
+    function = fixture_py_stats.read_text()
+    (tmp_path / "stats.py").write_text(function)
+    (tmp_path / "usage.mdx").write_text(
+        "import { Callout } from '../components/callout'\n\n# Usage\n\n<Callout>Stats helpers</Callout>\n\n"
+        f"```python\n{function}```\n"
+    )
+    # The injected block also carries its module wrapper, so it is not a 100% match
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=0.7, min_lines=3)))
+
+    result = run_pipeline(tmp_path)
+
+    paths = [{r.path.name for r in group.regions} for group in result.similar_groups]
+    assert {"stats.py", "usage.mdx"} in paths
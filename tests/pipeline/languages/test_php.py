@@ -0,0 +1,32 @@
+"""Tests for PHP language configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from tests.conftest import parse_fixture
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+# Fixture path (PHP followed by an HTML template section)
+fixture_comprehensive = Path(__file__).parent.parent.parent / "fixtures" / "php" / "comprehensive.php"
+
+
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_php_rules_extract(rules):
+    """Test that mixed PHP/HTML files can be processed with different rule sets."""
+    parsed = parse_fixture(fixture_comprehensive, "php")
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    region_types = {r.region.region_type for r in regions}
+    assert {
+        "function_definition",
+        "method_declaration",
+        "class_declaration",
+        "interface_declaration",
+        "trait_declaration",
+    } <= region_types
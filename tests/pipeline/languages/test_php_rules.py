@@ -0,0 +1,52 @@
+from treepeat.pipeline.languages.php import PHPConfig
+
+
+def test_php_rules_detailed(rule_tester):
+    config = PHPConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore namespace and use declarations",
+                "source": "<?php\nnamespace App;\nuse App\\Models\\Invoice;",
+                "expected_symbol": None,
+                "unexpected_symbol": "namespace_use_declaration",
+            },
+            {
+                "rule_name": "Ignore include and require expressions",
+                "source": "<?php\nrequire_once 'helpers.php';",
+                "expected_symbol": None,
+                "unexpected_symbol": "require_once_expression",
+            },
+            {
+                "rule_name": "Ignore comments",
+                "source": "<?php\n// line comment\n/* block */",
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Anonymize function names",
+                "source": "<?php\nfunction my_func() {}",
+                "expected_symbol": "FUNC",
+                "unexpected_symbol": "my_func",
+            },
+            {
+                "rule_name": "Anonymize class names",
+                "source": "<?php\nclass MyClass {}",
+                "expected_symbol": "CLASS",
+                "unexpected_symbol": "MyClass",
+            },
+            {
+                "rule_name": "Anonymize variables",
+                "source": "<?php\n$myVar = 1;",
+                "expected_symbol": "VAR_1",
+                "unexpected_symbol": "myVar",
+            },
+            {
+                "rule_name": "Anonymize literals",
+                "source": "<?php\n$x = 'hello';",
+                "expected_symbol": "<LIT>",
+                "unexpected_symbol": "hello",
+            },
+        ],
+    )
@@ -0,0 +1,122 @@
+"""Tests for grammar plugins."""
+
+from pathlib import Path
+
+import pytest
+from tree_sitter_language_pack import get_language
+
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.pipeline.languages import (
+    LANGUAGE_CONFIGS,
+    LANGUAGE_EXTENSIONS,
+    LANGUAGE_FILENAMES,
+    PLUGIN_GRAMMARS,
+    plugins,
+)
+from treepeat.pipeline.parse import detect_language
+from treepeat.pipeline.pipeline import run_pipeline
+
+load_grammar = plugins.load_grammar
+
+# A language the tests load from a plugin, parsed with the Python grammar
+MANIFEST = """\
+name = "snake"
+grammar = "snake.so"
+extensions = [".snake"]
+regions = ["function_definition"]
+
+[[rules]]
+name = "Ignore comments"
+query = "(comment) @comment"
+action = "remove"
+"""
+
+MODULE = """\
+def total(items):
+    result = 0
+    for item in items:
+        if item > 0:
+            result = result + item
+    return result
+"""
+
+
+@pytest.fixture(autouse=True)
+def plugin_registries(monkeypatch):
+    """Load plugin grammars from tree-sitter-language-pack, and forget the plugins after each test."""
+    monkeypatch.setattr(plugins, "load_grammar", lambda library, symbol: get_language("python"))
+    monkeypatch.setattr(plugins, "_registered", {})
+    registries = (LANGUAGE_CONFIGS, LANGUAGE_EXTENSIONS, LANGUAGE_FILENAMES, PLUGIN_GRAMMARS)
+    saved = [(registry, dict(registry)) for registry in registries]
+    yield
+    for registry, contents in saved:
+        registry.clear()
+        registry.update(contents)
+
+
+def _plugin(directory: Path, manifest: str = MANIFEST) -> Path:
+    (directory / "snake").mkdir(parents=True)
+    (directory / "snake" / plugins.MANIFEST_NAME).write_text(manifest)
+    return directory
+
+
+def test_plugin_adds_a_language(tmp_path):
+    assert plugins.load_plugins(_plugin(tmp_path / "plugins")) == ["snake"]
+    # Loading the same plugins again is a no-op
+    assert plugins.load_plugins(tmp_path / "plugins") == ["snake"]
+
+    assert detect_language(Path("lib.snake")) == "snake"
+    assert [rule.languages for rule in LANGUAGE_CONFIGS["snake"].get_default_rules()] == [["snake"]]
+
+
+def test_plugin_language_is_scanned(tmp_path):
+    plugins.load_plugins(_plugin(tmp_path / "plugins"))
+    source = tmp_path / "src"
+    source.mkdir()
+    for name in ("a.snake", "b.snake"):
+        (source / name).write_text(MODULE)
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=1.0, min_lines=3)))
+
+    result = run_pipeline(source)
+
+    assert [sorted(r.path.name for r in g.regions) for g in result.similar_groups] == [["a.snake", "b.snake"]]
+    assert {r.language for g in result.similar_groups for r in g.regions} == {"snake"}
+
+
+def test_missing_directory_loads_nothing(tmp_path):
+    assert plugins.load_plugins(tmp_path / "absent") == []
+
+
+@pytest.mark.parametrize(
+    "manifest, message",
+    [
+        (MANIFEST.replace('grammar = "snake.so"\n', ""), "missing 'grammar'"),
+        (MANIFEST.replace('name = "snake"', 'name = "python"'), "already defined"),
+        (MANIFEST.replace('action = "remove"', 'action = "explode"'), "Invalid action"),
+    ],
+)
+def test_broken_plugins(tmp_path, manifest, message):
+    with pytest.raises(plugins.PluginError, match=message):
+        plugins.load_plugins(_plugin(tmp_path, manifest))
+
+
+def test_broken_plugins_are_skipped_unless_strict(tmp_path, caplog):
+    _plugin(tmp_path, MANIFEST.replace('grammar = "snake.so"\n', ""))
+    (tmp_path / "worm").mkdir()
+    (tmp_path / "worm" / plugins.MANIFEST_NAME).write_text(MANIFEST.replace("snake", "worm"))
+
+    assert plugins.load_plugins(tmp_path, strict=False) == ["worm"]
+    assert "Skipping grammar plugin snake" in caplog.text
+    assert "snake" not in LANGUAGE_CONFIGS
+
+
+def test_wasm_grammars_are_refused(tmp_path):
+    with pytest.raises(plugins.PluginError, match="WASM"):
+        load_grammar(tmp_path / "snake.wasm", "tree_sitter_snake")
+
+
+def test_unloadable_grammar(tmp_path):
+    (tmp_path / "snake.so").write_text("not a library")
+
+    with pytest.raises(plugins.PluginError, match="cannot load tree_sitter_snake"):
+        load_grammar(tmp_path / "snake.so", "tree_sitter_snake")
@@ -0,0 +1,40 @@
+"""Tests for Protocol Buffers language configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from tests.conftest import parse_fixture
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+# Fixture path
+fixture_comprehensive = Path(__file__).parent.parent.parent / "fixtures" / "proto" / "comprehensive.proto"
+
+
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_proto_rules_extract(rules):
+    """Test that .proto files can be processed with different rule sets."""
+    parsed = parse_fixture(fixture_comprehensive, "proto")
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    region_types = {r.region.region_type for r in regions}
+    assert {"message", "enum", "service"} <= region_types
+
+
+def test_message_copied_to_another_package_is_detected(tmp_path):
+    source = fixture_comprehensive.read_text()
+    (tmp_path / "billing.proto").write_text(source)
+    (tmp_path / "shipping.proto").write_text(source.replace("package billing.v1;", "package shipping.v1;"))
+    set_settings(PipelineSettings(lsh=LSHSettings(min_lines=5)))
+
+    result = run_pipeline(tmp_path)
+
+    address_groups = [g for g in result.similar_groups if any(r.start_line == 14 for r in g.regions)]
+    assert {r.path.name for g in address_groups for r in g.regions} == {"billing.proto", "shipping.proto"}
@@ -0,0 +1,34 @@
+from treepeat.pipeline.languages.proto import ProtoConfig
+
+
+def test_proto_rules_detailed(rule_tester):
+    config = ProtoConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore comments",
+                "source": '// comment\nsyntax = "proto3";',
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Ignore package and imports",
+                "source": 'syntax = "proto3";\npackage billing;\nimport "other.proto";',
+                "expected_symbol": None,
+                "unexpected_symbol": "billing",
+            },
+            {
+                "rule_name": "Anonymize identifiers",
+                "source": 'syntax = "proto3";\nmessage User {\n  string name = 1;\n}',
+                "expected_symbol": "VAR_1",
+                "unexpected_symbol": "User",
+            },
+            {
+                "rule_name": "Anonymize literals",
+                "source": 'syntax = "proto3";\nmessage User {\n  string name = 42;\n}',
+                "expected_symbol": "<LIT>",
+                "unexpected_symbol": "42",
+            },
+        ],
+    )
@@ -0,0 +1,26 @@
+"""Tests for Ruby language configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from tests.conftest import parse_fixture
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+# Fixture path
+fixture_comprehensive = Path(__file__).parent.parent.parent / "fixtures" / "ruby" / "comprehensive.rb"
+
+
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_ruby_rules_extract(rules):
+    """Test that Ruby files can be processed with different rule sets."""
+    parsed = parse_fixture(fixture_comprehensive, "ruby")
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    region_types = {r.region.region_type for r in regions}
+    assert {"method", "singleton_method", "class", "module", "block"} <= region_types
@@ -0,0 +1,46 @@
+from treepeat.pipeline.languages.ruby import RubyConfig
+
+
+def test_ruby_rules_detailed(rule_tester):
+    config = RubyConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore comments",
+                "source": "# line comment\nx = 1",
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Anonymize method names",
+                "source": "def my_method\nend",
+                "expected_symbol": "FUNC",
+                "unexpected_symbol": "my_method",
+            },
+            {
+                "rule_name": "Anonymize method names",
+                "source": "def self.build\nend",
+                "expected_symbol": "FUNC",
+                "unexpected_symbol": "build",
+            },
+            {
+                "rule_name": "Anonymize class and module names",
+                "source": "module Billing\nend",
+                "expected_symbol": "CLASS",
+                "unexpected_symbol": "Billing",
+            },
+            {
+                "rule_name": "Anonymize identifiers",
+                "source": "my_var = 1",
+                "expected_symbol": "VAR_1",
+                "unexpected_symbol": "my_var",
+            },
+            {
+                "rule_name": "Anonymize literals",
+                "source": 'x = "hello"',
+                "expected_symbol": "<LIT>",
+                "unexpected_symbol": "hello",
+            },
+        ],
+    )
@@ -24,11 +24,11 @@ def test_rust_rules_extract(rules):
     engine = RuleEngine(rules)
     regions = extract_all_regions([parsed], engine)
 
-    # All six extraction types must be present
+    # All seven extraction types must be present
     region_types = {r.region.region_type for r in regions}
     assert region_types == {
         "function_item", "impl_item", "struct_item",
-        "enum_item", "trait_item", "macro_definition",
+        "enum_item", "trait_item", "macro_definition", "match_expression",
     }
 
 
@@ -25,3 +25,13 @@ def test_sql_rules_extract(rules):
 
     # SQL doesn't define region extraction rules, so we may get 0 regions
     assert len(regions) >= 0
+
+
+def test_sql_queries_views_and_migrations_extract():
+    """Queries, views and updates are compared as regions of their own."""
+    parsed = parse_fixture(fixture_comprehensive, "sql")
+    engine = RuleEngine([rule for rule, _ in build_default_rules()])
+    regions = extract_all_regions([parsed], engine)
+
+    region_types = {r.region.region_type for r in regions}
+    assert {"create_table", "create_view", "update"} <= region_types
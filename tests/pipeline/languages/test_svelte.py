@@ -0,0 +1,34 @@
+"""Tests for Svelte component configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from tests.conftest import parse_fixture
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+# Fixture path
+fixture_comprehensive = Path(__file__).parent.parent.parent / "fixtures" / "svelte" / "comprehensive.svelte"
+
+
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_svelte_rules_extract(rules):
+    """Test that Svelte files can be processed with different rule sets."""
+    parsed = parse_fixture(fixture_comprehensive, "svelte")
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    assert {"template", "script", "style"} <= {r.region.region_type for r in regions}
+
+
+def test_script_without_lang_is_javascript():
+    parsed = parse_fixture(fixture_comprehensive, "svelte")
+    regions = extract_all_regions([parsed], RuleEngine([rule for rule, _ in build_default_rules()]))
+
+    script = next(r for r in regions if r.region.region_type == "script")
+    assert script.injected_language == "javascript"
+    assert (script.region.start_line, script.region.end_line) == (1, 13)
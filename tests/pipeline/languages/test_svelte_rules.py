@@ -0,0 +1,28 @@
+from treepeat.pipeline.languages.svelte import SvelteConfig
+
+
+def test_svelte_rules_detailed(rule_tester):
+    config = SvelteConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore comments",
+                "source": "<!-- comment -->\n<p>hi</p>",
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Anonymize tag names",
+                "source": "<section>hi</section>",
+                "expected_symbol": "<TAG>",
+                "unexpected_symbol": "section",
+            },
+            {
+                "rule_name": "Anonymize attribute names",
+                "source": '<p class="x">hi</p>',
+                "expected_symbol": "<ATTR>",
+                "unexpected_symbol": "class",
+            },
+        ],
+    )
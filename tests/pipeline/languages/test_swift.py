@@ -0,0 +1,32 @@
+"""Tests for Swift language configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from tests.conftest import parse_fixture
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+# Fixture path
+fixture_comprehensive = Path(__file__).parent.parent.parent / "fixtures" / "swift" / "comprehensive.swift"
+
+
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_swift_rules_extract(rules):
+    """Test that Swift files can be processed with different rule sets."""
+    parsed = parse_fixture(fixture_comprehensive, "swift")
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    region_types = {r.region.region_type for r in regions}
+    assert {
+        "function_declaration",
+        "init_declaration",
+        "class_declaration",
+        "protocol_declaration",
+        "closure",
+    } <= region_types
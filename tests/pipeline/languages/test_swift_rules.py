@@ -0,0 +1,46 @@
+from treepeat.pipeline.languages.swift import SwiftConfig
+
+
+def test_swift_rules_detailed(rule_tester):
+    config = SwiftConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore import declarations",
+                "source": "import Foundation",
+                "expected_symbol": None,
+                "unexpected_symbol": "import_declaration",
+            },
+            {
+                "rule_name": "Ignore comments",
+                "source": "// line comment\n/* multi\nline */",
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Anonymize function names",
+                "source": "func myFunc() {}",
+                "expected_symbol": "FUNC",
+                "unexpected_symbol": "myFunc",
+            },
+            {
+                "rule_name": "Anonymize type names",
+                "source": "struct MyStruct {}",
+                "expected_symbol": "TYPE",
+                "unexpected_symbol": "MyStruct",
+            },
+            {
+                "rule_name": "Anonymize identifiers",
+                "source": "let myVar = 1",
+                "expected_symbol": "VAR_1",
+                "unexpected_symbol": "myVar",
+            },
+            {
+                "rule_name": "Anonymize literals",
+                "source": 'let x = "hello"',
+                "expected_symbol": "<LIT>",
+                "unexpected_symbol": "hello",
+            },
+        ],
+    )
@@ -0,0 +1,67 @@
+"""Tests for server-side template (ERB, Jinja2, Go html/template) configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.pipeline.languages import preprocess_source
+from treepeat.pipeline.parse import parse_file
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+FIXTURE_DIR = Path(__file__).parent.parent.parent / "fixtures" / "template"
+fixture_jinja = FIXTURE_DIR / "comprehensive.html.j2"
+fixture_erb = FIXTURE_DIR / "comprehensive.erb"
+
+
+@pytest.mark.parametrize("fixture", [fixture_jinja, fixture_erb])
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_template_rules_extract(fixture, rules):
+    """Test that templates can be processed with different rule sets."""
+    parsed = parse_file(fixture)
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    assert parsed.language == "template"
+    assert {r.region.region_type for r in regions} == {"template", "fragment"}
+
+
+@pytest.mark.parametrize(
+    "source,expected",
+    [
+        (b"{% for feature in product.features %}", b"<!--%for%-->"),
+        (b"{%- endif %}", b"<!--%endif%-->"),
+        (b"{{ product.title }}", b"<!--%=%-->"),
+        (b"{{ range .Features }}", b"<!--%range%-->"),
+        (b"{{ .Title }}", b"<!--%=%-->"),
+        (b"<% if product.on_sale %>", b"<!--%if%-->"),
+        (b"<%= product.title %>", b"<!--%=%-->"),
+        (b"{# note #}<p>", b"<p>"),
+        (b"<%# note %><p>", b"<p>"),
+    ],
+)
+def test_directives_become_keyword_comments(source, expected):
+    assert preprocess_source("template", source) == expected
+
+
+def test_rewriting_preserves_line_numbers():
+    source = fixture_jinja.read_bytes() + b"{% macro card(\n  product\n) %}\n"
+    assert preprocess_source("template", source).count(b"\n") == source.count(b"\n")
+
+
+def test_partial_copied_between_template_languages_is_detected(tmp_path):
+    """The same card partial written as a Jinja2 and an ERB template is reported as a clone."""
+    (tmp_path / "templates").mkdir()
+    (tmp_path / "templates" / "card.html.j2").write_text(fixture_jinja.read_text())
+    (tmp_path / "templates" / "card.erb").write_text(fixture_erb.read_text())
+    set_settings(PipelineSettings(lsh=LSHSettings(min_lines=5)))
+
+    result = run_pipeline(tmp_path)
+
+    grouped = {r.path.name for group in result.similar_groups for r in group.regions}
+    assert grouped == {"card.html.j2", "card.erb"}
@@ -0,0 +1,28 @@
+from treepeat.pipeline.languages.template import TemplateConfig
+
+
+def test_template_rules_detailed(rule_tester):
+    config = TemplateConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore comments",
+                "source": "<!-- note to self -->\n<p>hi</p>",
+                "expected_symbol": None,
+                "unexpected_symbol": "note",
+            },
+            {
+                "rule_name": "Anonymize attribute values",
+                "source": '<a href="/products">hi</a>',
+                "expected_symbol": "<VAL>",
+                "unexpected_symbol": "/products",
+            },
+            {
+                "rule_name": "Ignore text content",
+                "source": "<p>Hello there</p>",
+                "expected_symbol": None,
+                "unexpected_symbol": "Hello",
+            },
+        ],
+    )
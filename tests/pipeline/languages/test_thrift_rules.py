@@ -0,0 +1,34 @@
+from treepeat.pipeline.languages.thrift import ThriftConfig
+
+
+def test_thrift_rules_detailed(rule_tester):
+    config = ThriftConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore comments",
+                "source": "// comment\nstruct User {\n  1: string name,\n}",
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Ignore namespaces and includes",
+                "source": 'namespace py billing\ninclude "shared.thrift"',
+                "expected_symbol": None,
+                "unexpected_symbol": "billing",
+            },
+            {
+                "rule_name": "Anonymize identifiers",
+                "source": "struct User {\n  1: string name,\n}",
+                "expected_symbol": "VAR_1",
+                "unexpected_symbol": "User",
+            },
+            {
+                "rule_name": "Anonymize literals",
+                "source": "struct User {\n  42: string name,\n}",
+                "expected_symbol": "<LIT>",
+                "unexpected_symbol": "42",
+            },
+        ],
+    )
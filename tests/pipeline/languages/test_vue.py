@@ -0,0 +1,53 @@
+"""Tests for Vue single-file component configuration and rules."""
+
+from pathlib import Path
+
+import pytest
+
+from tests.conftest import parse_fixture
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine, build_default_rules, build_loose_rules
+
+# Fixture path
+fixture_comprehensive = Path(__file__).parent.parent.parent / "fixtures" / "vue" / "comprehensive.vue"
+
+
+@pytest.mark.parametrize(
+    "rules",
+    [[rule for rule, _ in build_default_rules()], [rule for rule, _ in build_loose_rules()]],
+)
+def test_vue_rules_extract(rules):
+    """Test that Vue files can be processed with different rule sets."""
+    parsed = parse_fixture(fixture_comprehensive, "vue")
+    engine = RuleEngine(rules)
+    regions = extract_all_regions([parsed], engine)
+
+    assert {"template", "script", "style"} <= {r.region.region_type for r in regions}
+
+
+def test_script_and_style_are_injected_with_line_numbers():
+    parsed = parse_fixture(fixture_comprehensive, "vue")
+    regions = extract_all_regions([parsed], RuleEngine([rule for rule, _ in build_default_rules()]))
+    by_type = {r.region.region_type: r for r in regions}
+
+    script, style = by_type["script"], by_type["style"]
+    assert script.injected_language == "typescript"
+    assert (script.region.start_line, script.region.end_line) == (12, 31)
+    assert style.injected_language == "css"
+    # Injected rows line up with the original file
+    assert script.injected_tree.root_node.children[0].start_point[0] + 1 == 13
+
+
+def test_components_sharing_script_are_detected(tmp_path):
+    source = fixture_comprehensive.read_text()
+    (tmp_path / "Cart.vue").write_text(source)
+    (tmp_path / "MiniCart.vue").write_text(source.replace('<section class="cart">', '<aside class="mini">'))
+    set_settings(PipelineSettings(lsh=LSHSettings(min_lines=5)))
+
+    result = run_pipeline(tmp_path)
+
+    script_groups = [g for g in result.similar_groups if g.regions[0].region_type == "script"]
+    assert len(script_groups) == 1
+    assert {r.path.name for r in script_groups[0].regions} == {"Cart.vue", "MiniCart.vue"}
@@ -0,0 +1,28 @@
+from treepeat.pipeline.languages.vue import VueConfig
+
+
+def test_vue_rules_detailed(rule_tester):
+    config = VueConfig()
+    rule_tester.verify_rules(
+        config,
+        [
+            {
+                "rule_name": "Ignore comments",
+                "source": "<!-- comment -->\n<template><p>hi</p></template>",
+                "expected_symbol": None,
+                "unexpected_symbol": "comment",
+            },
+            {
+                "rule_name": "Anonymize tag names",
+                "source": "<template><section>hi</section></template>",
+                "expected_symbol": "<TAG>",
+                "unexpected_symbol": "section",
+            },
+            {
+                "rule_name": "Anonymize attribute names",
+                "source": '<template><p class="x">hi</p></template>',
+                "expected_symbol": "<ATTR>",
+                "unexpected_symbol": "class",
+            },
+        ],
+    )
@@ -0,0 +1,98 @@
+from pathlib import Path
+
+from treepeat.config import LSHSettings, PipelineSettings, RulesSettings, set_settings
+from treepeat.models.shingle import Shingle, ShingledRegion, ShingleList
+from treepeat.models.similarity import Region
+from treepeat.pipeline.cache import FingerprintCache, config_key, shingle_with_cache
+from treepeat.pipeline.parse import parse_source_code
+from treepeat.pipeline.pipeline import run_pipeline
+
+MODULE = """\
+def total(items):
+    result = 0
+    for item in items:
+        if item > 0:
+            result = result + item
+    return result
+"""
+
+
+def _fake_shingle(calls):
+    """A shingler that records the files it is asked for and returns one region per file."""
+
+    def shingle(files):
+        calls.append([pf.path for pf in files])
+        return [
+            ShingledRegion(
+                region=Region(
+                    path=pf.path, language=pf.language, region_type="file", region_name="f", start_line=1, end_line=6
+                ),
+                shingles=ShingleList(shingles=[Shingle(content=pf.path.stem, start_line=1, end_line=6)]),
+            )
+            for pf in files
+        ]
+
+    return shingle
+
+
+def test_config_key_follows_rule_config():
+    assert config_key(PipelineSettings()) == config_key(PipelineSettings())
+    assert config_key(PipelineSettings()) != config_key(PipelineSettings(rules=RulesSettings(normalize=["literals"])))
+    assert config_key(PipelineSettings()) != config_key(PipelineSettings(lsh=LSHSettings(min_lines=2)))
+
+
+def test_only_missing_files_are_shingled(tmp_path):
+    cache = FingerprintCache(tmp_path / "cache", PipelineSettings())
+    first = parse_source_code(MODULE.encode(), "python", Path("a.py"))
+    second = parse_source_code(MODULE.replace("0", "1").encode(), "python", Path("b.py"))
+    calls: list[list[Path]] = []
+
+    shingle_with_cache([first], cache, _fake_shingle(calls))
+    regions = shingle_with_cache([first, second], cache, _fake_shingle(calls))
+
+    assert calls == [[Path("a.py")], [Path("b.py")]]
+    assert [r.region.path for r in regions] == [Path("a.py"), Path("b.py")]
+
+
+def test_moved_file_is_a_hit(tmp_path):
+    cache = FingerprintCache(tmp_path / "cache", PipelineSettings())
+    calls: list[list[Path]] = []
+    shingle_with_cache([parse_source_code(MODULE.encode(), "python", Path("a.py"))], cache, _fake_shingle(calls))
+
+    regions = shingle_with_cache(
+        [parse_source_code(MODULE.encode(), "python", Path("moved.py"))], cache, _fake_shingle(calls)
+    )
+
+    assert len(calls) == 1
+    assert [r.region.path for r in regions] == [Path("moved.py")]
+
+
+def test_cached_run_finds_the_same_clones(tmp_path):
+    source = tmp_path / "src"
+    source.mkdir()
+    (source / "a.py").write_text(MODULE)
+    (source / "b.py").write_text(MODULE.replace("total", "summed"))
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=1.0, min_lines=3), cache_dir=tmp_path / "cache"))
+
+    cold = run_pipeline(source)
+    warm = run_pipeline(source)
+
+    assert any((tmp_path / "cache").rglob("*.json"))
+    assert [[r.region_name for r in g.regions] for g in warm.similar_groups] == [
+        [r.region_name for r in g.regions] for g in cold.similar_groups
+    ]
+    assert cold.similar_groups
+
+
+def test_cached_run_counts_the_same_fragment_types(tmp_path):
+    source = tmp_path / "src"
+    source.mkdir()
+    (source / "a.py").write_text(MODULE)
+    (source / "b.py").write_text(MODULE.replace("total", "summed"))
+    set_settings(PipelineSettings(lsh=LSHSettings(min_lines=3), cache_dir=tmp_path / "cache"))
+
+    cold = run_pipeline(source)
+    warm = run_pipeline(source)
+
+    assert cold.fragment_counts[("python", "function_definition")] == 2
+    assert warm.fragment_counts == cold.fragment_counts
@@ -0,0 +1,108 @@
+from pathlib import Path
+
+from treepeat.pipeline.parse import parse_source_code
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.shingle import shingle_regions
+
+from ..conftest import default_rule_engine
+
+
+def _region_shingles(code: str, canonicalize: bool, language: str = "python") -> list[list[str]]:
+    parsed = parse_source_code(code.encode("utf-8"), language, Path(f"example.{language}"))
+    engine = default_rule_engine()
+    regions = shingle_regions(
+        extracted_regions=extract_all_regions([parsed], engine),
+        parsed_files=[parsed],
+        rule_engine=engine,
+        canonicalize=canonicalize,
+    )
+    return [region.shingles.get_contents() for region in regions]
+
+
+def _shingles(code: str, canonicalize: bool) -> list[str]:
+    return [content for region in _region_shingles(code, canonicalize) for content in region]
+
+
+def _equivalent(first: str, second: str, canonicalize: bool) -> bool:
+    return _shingles(first, canonicalize) == _shingles(second, canonicalize)
+
+
+def test_commutative_operands_are_ordered():
+    first = "def f(a, b):\n    return a + b\n"
+    second = "def f(a, b):\n    return b + a\n"
+
+    assert not _equivalent(first, second, canonicalize=False)
+    assert _equivalent(first, second, canonicalize=True)
+
+
+def test_non_commutative_operands_keep_their_order():
+    first = "def f(a, b):\n    return a - b\n"
+    second = "def f(a, b):\n    return b - a\n"
+
+    assert not _equivalent(first, second, canonicalize=True)
+
+
+def test_trivial_constants_are_folded():
+    first = "def f():\n    return 60 * 60\n"
+    second = "def f():\n    return 3600\n"
+
+    assert not _equivalent(first, second, canonicalize=False)
+    assert _equivalent(first, second, canonicalize=True)
+
+
+def test_loop_forms_share_a_name():
+    shingles = _shingles("def f(items):\n    for item in items:\n        pass\n", canonicalize=True)
+
+    assert any("<LOOP>" in shingle for shingle in shingles)
+    assert not any("for_statement" in shingle for shingle in shingles)
+
+
+def _javascript_equivalent(first: str, second: str, canonicalize: bool) -> bool:
+    return _region_shingles(first, canonicalize, "javascript") == _region_shingles(second, canonicalize, "javascript")
+
+
+C_STYLE_FOR = "function f(a) {\n  for (let i = 0; i < a.length; i++) {\n    total += a[i];\n  }\n}\n"
+
+
+def test_c_style_for_matches_the_equivalent_while():
+    equivalent_while = (
+        "function f(a) {\n  let i = 0;\n  while (i < a.length) {\n    total += a[i];\n    i++;\n  }\n}\n"
+    )
+
+    assert not _javascript_equivalent(C_STYLE_FOR, equivalent_while, canonicalize=False)
+    assert _javascript_equivalent(C_STYLE_FOR, equivalent_while, canonicalize=True)
+
+
+def test_loops_with_the_same_body_but_another_header_differ():
+    endless_while = "function f(a) {\n  while (i < a.length) {\n    total += a[i];\n  }\n}\n"
+    other_condition = C_STYLE_FOR.replace("i < a.length", "i < 10")
+
+    assert not _javascript_equivalent(C_STYLE_FOR, endless_while, canonicalize=True)
+    assert not _javascript_equivalent(C_STYLE_FOR, other_condition, canonicalize=True)
+
+
+def test_python_for_keeps_its_target_and_iterable():
+    first = "def f(items):\n    for item in items:\n        print(item)\n"
+    other_iterable = "def f(items):\n    for item in reversed(items):\n        print(item)\n"
+    while_loop = "def f(items):\n    while items:\n        print(item)\n"
+
+    assert not _equivalent(first, other_iterable, canonicalize=True)
+    assert not _equivalent(first, while_loop, canonicalize=True)
+
+
+def test_json_key_order_is_ignored():
+    first = '{\n  "name": "web",\n  "replicas": 3,\n  "ports": [80, 443]\n}\n'
+    second = '{\n  "ports": [80, 443],\n  "name": "web",\n  "replicas": 3\n}\n'
+
+    assert _region_shingles(first, False, "json") != _region_shingles(second, False, "json")
+    assert _region_shingles(first, True, "json") == _region_shingles(second, True, "json")
+
+
+def test_yaml_key_order_is_ignored():
+    first = "deployment:\n  name: web\n  replicas: 3\n  image: nginx\n"
+    second = "deployment:\n  image: nginx\n  name: web\n  replicas: 3\n"
+
+    def outer(code: str) -> list[str]:
+        return max(_region_shingles(code, True, "yaml"), key=len)
+
+    assert outer(first) == outer(second)
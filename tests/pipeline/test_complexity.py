@@ -0,0 +1,43 @@
+from pathlib import Path
+
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.pipeline.complexity import compute_complexity
+from treepeat.pipeline.parse import parse_source_code
+from treepeat.pipeline.pipeline import run_pipeline
+
+STRAIGHT_LINE = "def configure(settings):\n" + "".join(f"    settings.option_{i} = {i}\n" for i in range(19))
+
+BRANCHY = """def classify(values):
+    result = []
+    for value in values:
+        if value > 10:
+            result.append("big")
+        elif value > 0:
+            result.append("small")
+    return result
+"""
+
+
+def _complexity(source: str) -> int:
+    parsed = parse_source_code(source.encode(), "python", Path("example.py"))
+    return compute_complexity([parsed.root_node])
+
+
+def test_straight_line_code_has_base_complexity():
+    assert _complexity(STRAIGHT_LINE) == 1
+
+
+def test_branches_and_loops_add_complexity():
+    assert _complexity(BRANCHY) == 4
+
+
+def test_min_complexity_drops_trivial_clones(tmp_path):
+    for name in ("a", "b"):
+        (tmp_path / f"{name}.py").write_text(f"{STRAIGHT_LINE}\n\n{BRANCHY}")
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=1.0, min_lines=5, min_complexity=2)))
+
+    result = run_pipeline(tmp_path)
+
+    names = {r.region_name for group in result.similar_groups for r in group.regions}
+    assert "classify" in names
+    assert "configure" not in names
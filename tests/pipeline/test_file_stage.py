@@ -0,0 +1,52 @@
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.pipeline.file_stage import FILE_REGION_TYPE, shingle_file
+from treepeat.pipeline.pipeline import run_pipeline
+
+MODULE = """\
+def total(items):
+    result = 0
+    for item in items:
+        if item > 0:
+            result = result + item
+    return result
+
+
+def describe(user):
+    parts = [user.name]
+    if user.email:
+        parts.append(user.email)
+    if user.phone:
+        parts.append(user.phone)
+    return ", ".join(parts)
+"""
+
+
+def _run(tmp_path, file_similarity):
+    set_settings(
+        PipelineSettings(lsh=LSHSettings(similarity_percent=1.0, min_lines=3), file_similarity=file_similarity)
+    )
+    return run_pipeline(tmp_path)
+
+
+def test_shingle_file_skips_blank_lines(tmp_path):
+    path = tmp_path / "a.py"
+    path.write_text("x  =  1\n\ny = 2\n")
+
+    shingled = shingle_file(path, "python")
+
+    assert shingled.region.region_type == FILE_REGION_TYPE
+    assert (shingled.region.start_line, shingled.region.end_line) == (1, 3)
+    assert shingled.shingles.get_contents() == ["x = 1", "y = 2"]
+
+
+def test_copied_files_are_reported_once(tmp_path):
+    (tmp_path / "a.py").write_text(MODULE)
+    (tmp_path / "b.py").write_text(MODULE)
+
+    per_function = _run(tmp_path, file_similarity=None).similar_groups
+    assert len(per_function) == 2
+
+    groups = _run(tmp_path, file_similarity=1.0).similar_groups
+    assert len(groups) == 1
+    assert [r.region_type for r in groups[0].regions] == [FILE_REGION_TYPE, FILE_REGION_TYPE]
+    assert [r.path.name for r in groups[0].regions] == ["a.py", "b.py"]
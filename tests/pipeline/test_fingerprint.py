@@ -0,0 +1,66 @@
+from pathlib import Path
+
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.models.shingle import ShingledRegion, ShingleList
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+from treepeat.pipeline.fingerprint import exclude_groups, group_fingerprint, normalize_fingerprint
+from treepeat.pipeline.pipeline import run_pipeline
+
+FUNCTION = "def total(values):\n    result = 0\n    for value in values:\n        result += value\n    return result\n"
+
+
+def _shingled(path: str, start_line: int, shingles: list[str]) -> ShingledRegion:
+    region = Region(
+        path=Path(path),
+        language="python",
+        region_type="function_definition",
+        region_name="total",
+        start_line=start_line,
+        end_line=start_line + 4,
+    )
+    return ShingledRegion(region=region, shingles=ShingleList(shingles=shingles))
+
+
+def _group(fingerprint: str) -> SimilarRegionGroup:
+    regions = [_shingled(name, 1, []).region for name in ("a.py", "b.py")]
+    return SimilarRegionGroup(regions=regions, similarity=1.0, fingerprint=fingerprint)
+
+
+def test_group_fingerprint_ignores_location_and_order():
+    a = _shingled("a.py", 1, ["x→y", "y→z"])
+    b = _shingled("b.py", 40, ["x→y", "y→w"])
+    moved = _shingled("c.py", 99, ["x→y", "y→z"])
+
+    fingerprint = group_fingerprint([a, b])
+    assert fingerprint.startswith("clone-")
+    assert len(fingerprint) == len("clone-") + 8
+    assert group_fingerprint([b, a]) == fingerprint
+    assert group_fingerprint([moved, b]) == fingerprint
+
+
+def test_normalize_fingerprint_accepts_short_ids():
+    assert normalize_fingerprint("1A2B3C4D") == "clone-1a2b3c4d"
+    assert normalize_fingerprint(" clone-1a2b3c4d ") == "clone-1a2b3c4d"
+
+
+def test_exclude_groups_drops_matches_and_reports_unknown():
+    result = SimilarityResult(similar_groups=[_group("clone-aaaaaaaa"), _group("clone-bbbbbbbb")])
+
+    filtered, unknown = exclude_groups(result, ["aaaaaaaa", "clone-cccccccc"])
+
+    assert [g.fingerprint for g in filtered.similar_groups] == ["clone-bbbbbbbb"]
+    assert unknown == {"clone-cccccccc"}
+
+
+def test_pipeline_fingerprints_are_stable(tmp_path):
+    (tmp_path / "a.py").write_text(FUNCTION)
+    (tmp_path / "b.py").write_text(FUNCTION)
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=1.0, min_lines=3)))
+
+    first = run_pipeline(tmp_path)
+    (tmp_path / "b.py").write_text("\n\n" + FUNCTION)
+    second = run_pipeline(tmp_path)
+
+    assert len(first.similar_groups) == 1
+    assert first.similar_groups[0].fingerprint.startswith("clone-")
+    assert [g.fingerprint for g in second.similar_groups] == [g.fingerprint for g in first.similar_groups]
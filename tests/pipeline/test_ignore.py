@@ -394,3 +394,197 @@ class TestCollectSourceFilesWithIgnore:
         # build files should be ignored
         assert build_file not in files
         assert nested_build_file not in files
+
+
+class TestTreepeatIgnore:
+    """Tests for .treepeatignore and gitignore-style negations."""
+
+    def _tree(self, tmp_path):
+        (tmp_path / "src").mkdir()
+        (tmp_path / "src" / "main.py").write_text("print('main')")
+        (tmp_path / "fixtures").mkdir()
+        (tmp_path / "fixtures" / "sample.py").write_text("print('sample')")
+        (tmp_path / "fixtures" / "keep.py").write_text("print('keep')")
+
+    def test_treepeatignore_is_honored_without_ignore_file_patterns(self, tmp_path):
+        """.treepeatignore applies even when --ignore-files only names .gitignore."""
+        self._tree(tmp_path)
+        (tmp_path / ".treepeatignore").write_text("fixtures/\n")
+        set_settings(PipelineSettings(ignore_file_patterns=["**/.gitignore"]))
+
+        files = collect_source_files(tmp_path)
+
+        assert files == [tmp_path / "src" / "main.py"]
+
+    def test_negation_reincludes_file(self, tmp_path):
+        self._tree(tmp_path)
+        (tmp_path / ".treepeatignore").write_text("fixtures/*.py\n!fixtures/keep.py\n")
+        set_settings(PipelineSettings())
+
+        files = collect_source_files(tmp_path)
+
+        assert sorted(files) == [tmp_path / "fixtures" / "keep.py", tmp_path / "src" / "main.py"]
+
+    def test_treepeatignore_overrides_gitignore_in_same_directory(self, tmp_path):
+        self._tree(tmp_path)
+        (tmp_path / ".gitignore").write_text("fixtures/*.py\n")
+        (tmp_path / ".treepeatignore").write_text("!fixtures/sample.py\n")
+        set_settings(PipelineSettings())
+
+        files = collect_source_files(tmp_path)
+
+        assert tmp_path / "fixtures" / "sample.py" in files
+        assert tmp_path / "fixtures" / "keep.py" not in files
+
+    def test_nested_ignore_file_overrides_parent(self, tmp_path):
+        self._tree(tmp_path)
+        (tmp_path / ".treepeatignore").write_text("*.py\n")
+        (tmp_path / "src" / ".treepeatignore").write_text("!main.py\n")
+        set_settings(PipelineSettings())
+
+        files = collect_source_files(tmp_path)
+
+        assert files == [tmp_path / "src" / "main.py"]
+
+
+class TestNestedGitignore:
+    """Ignore files in subdirectories are anchored at their own directory, as in git."""
+
+    def _tree(self, tmp_path: Path) -> None:
+        for name in (
+            "packages/api/gen/models.py",
+            "packages/api/src/gen/handlers.py",
+            "packages/api/docs/conf.py",
+            "packages/api/docs/sub/conf.py",
+            "packages/web/build/bundle.js",
+            "packages/web/src/app.js",
+            "build/tool.py",
+        ):
+            (tmp_path / name).parent.mkdir(parents=True, exist_ok=True)
+            (tmp_path / name).write_text("x\n")
+
+    def _collect(self, tmp_path: Path) -> list[str]:
+        set_settings(PipelineSettings(ignore_file_patterns=["**/.gitignore"]))
+        return sorted(f.relative_to(tmp_path).as_posix() for f in collect_source_files(tmp_path))
+
+    def test_leading_slash_is_relative_to_the_ignore_file(self, tmp_path):
+        self._tree(tmp_path)
+        (tmp_path / "packages" / "api" / ".gitignore").write_text("/gen\n")
+
+        files = self._collect(tmp_path)
+
+        assert "packages/api/gen/models.py" not in files
+        assert "packages/api/src/gen/handlers.py" in files
+
+    def test_middle_slash_anchors_and_star_stops_at_slash(self, tmp_path):
+        self._tree(tmp_path)
+        (tmp_path / "packages" / "api" / ".gitignore").write_text("docs/*.py\n")
+
+        files = self._collect(tmp_path)
+
+        assert "packages/api/docs/conf.py" not in files
+        assert "packages/api/docs/sub/conf.py" in files
+
+    def test_directory_pattern_stays_within_its_package(self, tmp_path):
+        self._tree(tmp_path)
+        (tmp_path / "packages" / "web" / ".gitignore").write_text("build/\n")
+
+        files = self._collect(tmp_path)
+
+        assert "packages/web/build/bundle.js" not in files
+        assert "packages/web/src/app.js" in files
+        assert "build/tool.py" in files
+
+    def test_unanchored_directory_pattern_matches_at_any_depth(self, tmp_path):
+        self._tree(tmp_path)
+        (tmp_path / ".gitignore").write_text("gen/\n")
+
+        files = self._collect(tmp_path)
+
+        assert "packages/api/gen/models.py" not in files
+        assert "packages/api/src/gen/handlers.py" not in files
+
+
+class TestIncludeExclude:
+    """Tests for the --include/--exclude scope globs."""
+
+    def _tree(self, tmp_path: Path) -> None:
+        for name in ("src/main.go", "src/pkg/util.go", "src/pkg/testdata/case.go", "src/helper.py", "tool.go"):
+            (tmp_path / name).parent.mkdir(parents=True, exist_ok=True)
+            (tmp_path / name).write_text("x\n")
+
+    def test_include_limits_scan(self, tmp_path):
+        self._tree(tmp_path)
+        set_settings(PipelineSettings(include_patterns=["src/**/*.go"]))
+
+        files = collect_source_files(tmp_path)
+
+        assert sorted(f.relative_to(tmp_path).as_posix() for f in files) == [
+            "src/main.go",
+            "src/pkg/testdata/case.go",
+            "src/pkg/util.go",
+        ]
+
+    def test_exclude_wins_over_include(self, tmp_path):
+        self._tree(tmp_path)
+        set_settings(PipelineSettings(include_patterns=["src/**/*.go"], exclude_patterns=["**/testdata/**"]))
+
+        files = collect_source_files(tmp_path)
+
+        assert sorted(f.relative_to(tmp_path).as_posix() for f in files) == ["src/main.go", "src/pkg/util.go"]
+
+    def test_exclude_applies_after_ignore_files(self, tmp_path):
+        self._tree(tmp_path)
+        (tmp_path / ".gitignore").write_text("tool.go\n")
+        set_settings(PipelineSettings(exclude_patterns=["*.py"]))
+
+        files = collect_source_files(tmp_path)
+
+        assert tmp_path / "tool.go" not in files
+        assert tmp_path / "src" / "helper.py" not in files
+        assert tmp_path / "src" / "main.go" in files
+
+
+class TestGitExcludes:
+    """core.excludesFile and .git/info/exclude are honored inside a git worktree."""
+
+    def _repo(self, tmp_path: Path, monkeypatch) -> Path:
+        config_home = tmp_path / "config"
+        (config_home / "git").mkdir(parents=True)
+        (config_home / "git" / "ignore").write_text("scratch/\n")
+        monkeypatch.setenv("XDG_CONFIG_HOME", str(config_home))
+        monkeypatch.setenv("GIT_CONFIG_GLOBAL", str(tmp_path / "gitconfig"))
+        monkeypatch.setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+        repo = tmp_path / "repo"
+        (repo / ".git" / "info").mkdir(parents=True)
+        (repo / ".git" / "info" / "exclude").write_text("/local.py\n")
+        for name in ("src/app.py", "src/scratch/try.py", "local.py", "src/local.py"):
+            (repo / name).parent.mkdir(parents=True, exist_ok=True)
+            (repo / name).write_text("x\n")
+        return repo
+
+    def test_git_excludes_apply_to_the_worktree(self, tmp_path, monkeypatch):
+        repo = self._repo(tmp_path, monkeypatch)
+        set_settings(PipelineSettings())
+
+        files = sorted(f.relative_to(repo).as_posix() for f in collect_source_files(repo))
+
+        assert files == ["src/app.py", "src/local.py"]
+
+    def test_git_excludes_stay_anchored_when_scanning_a_subdirectory(self, tmp_path, monkeypatch):
+        repo = self._repo(tmp_path, monkeypatch)
+        set_settings(PipelineSettings())
+
+        files = collect_source_files(repo / "src")
+
+        assert sorted(f.name for f in files) == ["app.py", "local.py"]
+
+    def test_repository_ignore_file_overrides_git_excludes(self, tmp_path, monkeypatch):
+        repo = self._repo(tmp_path, monkeypatch)
+        (repo / ".gitignore").write_text("!scratch/\n")
+        set_settings(PipelineSettings())
+
+        files = collect_source_files(repo)
+
+        assert repo / "src" / "scratch" / "try.py" in files
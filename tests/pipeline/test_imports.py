@@ -0,0 +1,52 @@
+from treepeat.pipeline.imports import import_lines, is_mostly_imports, trim_imports
+
+GO = """\
+package main
+
+import (
+\t"fmt"
+\t"os"
+)
+
+func main() {
+\tfmt.Println(os.Args)
+}
+""".splitlines()
+
+
+def test_import_lines_across_languages():
+    lines = [
+        "import os",
+        "from pathlib import Path",
+        "#include <stdio.h>",
+        "using System.Linq;",
+        "use std::collections::HashMap;",
+        "require 'json'",
+        "const fs = require('fs');",
+        "export { a } from './a';",
+        "x = 1",
+        "using (var f = Open())",
+    ]
+
+    assert import_lines(lines) == set(range(1, 9))
+
+
+def test_import_lines_multi_line_blocks():
+    assert import_lines(GO) == {1, 3, 4, 5, 6}
+    assert import_lines(["import {", "  a,", "  b,", "} from './x';", "run(a, b);"]) == {1, 2, 3, 4}
+
+
+def test_is_mostly_imports():
+    imports = import_lines(GO)
+
+    assert is_mostly_imports(GO, 1, 6, imports)
+    assert not is_mostly_imports(GO, 8, 10, imports)
+    assert not is_mostly_imports(GO, 2, 2, imports)
+
+
+def test_trim_imports():
+    imports = import_lines(GO)
+
+    assert trim_imports(GO, 1, 10, imports) == (8, 10)
+    start, end = trim_imports(GO, 1, 6, imports)
+    assert start > end
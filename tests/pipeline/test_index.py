@@ -0,0 +1,64 @@
+from pathlib import Path
+
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.models.similarity import Region, SimilarRegionGroup
+from treepeat.pipeline.index import CloneIndex, merge_groups, plan_update
+from treepeat.pipeline.pipeline import run_pipeline
+
+MODULE = """\
+def {name}(items):
+    result = 0
+    for item in items:
+        if item > 0:
+            result = result + item
+    return result
+"""
+
+
+def _group(*paths: str) -> SimilarRegionGroup:
+    regions = [
+        Region(path=Path(p), language="python", region_type="function", region_name="f", start_line=1, end_line=6)
+        for p in paths
+    ]
+    return SimilarRegionGroup(regions=regions, similarity=1.0)
+
+
+def test_plan_update_searches_changed_files_and_their_old_partners():
+    previous = CloneIndex(
+        files={Path("a.py"): "1", Path("b.py"): "1", Path("c.py"): "1", Path("d.py"): "1", Path("gone.py"): "1"},
+        groups=[_group("a.py", "b.py", "c.py"), _group("c.py", "d.py"), _group("d.py", "gone.py")],
+    )
+    hashes = {Path("a.py"): "2", Path("b.py"): "1", Path("c.py"): "1", Path("d.py"): "1", Path("new.py"): "1"}
+
+    kept, focus = plan_update(previous, hashes)
+
+    assert kept == [_group("c.py", "d.py")]
+    assert focus == {Path("a.py"), Path("b.py"), Path("c.py"), Path("d.py"), Path("new.py")}
+
+
+def test_merge_groups_drops_kept_groups_found_again():
+    kept = [_group("b.py", "c.py"), _group("d.py", "e.py")]
+    found = [_group("a.py", "b.py", "c.py")]
+
+    assert merge_groups(kept, found) == [_group("a.py", "b.py", "c.py"), _group("d.py", "e.py")]
+
+
+def _names(result):
+    return sorted(sorted(str(r.path.name) for r in g.regions) for g in result.similar_groups)
+
+
+def test_update_matches_a_full_scan(tmp_path):
+    source = tmp_path / "src"
+    source.mkdir()
+    for name in ("a", "b", "c"):
+        (source / f"{name}.py").write_text(MODULE.format(name=name))
+    lsh = LSHSettings(similarity_percent=1.0, min_lines=3)
+    set_settings(PipelineSettings(lsh=lsh, cache_dir=tmp_path / "cache"))
+    run_pipeline(source)
+
+    (source / "b.py").write_text("def b():\n    return {'unrelated': True, 'values': [1, 2, 3]}\n")
+    (source / "d.py").write_text(MODULE.format(name="d"))
+    updated = run_pipeline(source)
+    set_settings(PipelineSettings(lsh=lsh))
+
+    assert _names(updated) == _names(run_pipeline(source)) == [["a.py", "c.py", "d.py"]]
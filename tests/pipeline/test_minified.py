@@ -0,0 +1,45 @@
+from pathlib import Path
+
+import pytest
+
+from treepeat.config import PipelineSettings, set_settings
+from treepeat.pipeline.minified import is_minified
+from treepeat.pipeline.parse import parse_path
+
+FORMATTED = b"""\
+function total(items) {
+  let result = 0;
+  for (const item of items) {
+    result += item;
+  }
+  return result;
+}
+"""
+
+MINIFIED = b"function t(n){let r=0;for(const o of n)r+=o;return r}" * 40 + b"\n"
+
+
+@pytest.mark.parametrize(
+    "name, source, expected",
+    [
+        ("app.js", FORMATTED, False),
+        ("app.min.js", FORMATTED, True),
+        ("vendor.bundle.js", FORMATTED, True),
+        ("app.js", MINIFIED, True),
+        ("app.js", FORMATTED + b"//# sourceMappingURL=app.js.map\n", True),
+        ("main.js", b"/******/ (() => { // webpackBootstrap\n" + FORMATTED, True),
+    ],
+)
+def test_is_minified(name, source, expected):
+    assert is_minified(Path(name), source) is expected
+
+
+@pytest.mark.parametrize("include_minified, expected", [(False, ["app.js"]), (True, ["app.js", "dist.js"])])
+def test_minified_files_are_skipped_unless_included(tmp_path, include_minified, expected):
+    (tmp_path / "app.js").write_bytes(FORMATTED)
+    (tmp_path / "dist.js").write_bytes(MINIFIED)
+    set_settings(PipelineSettings(include_minified=include_minified))
+
+    result = parse_path(tmp_path)
+
+    assert sorted(parsed.path.name for parsed in result.parsed_files) == expected
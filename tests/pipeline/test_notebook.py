@@ -0,0 +1,138 @@
+import json
+from pathlib import Path
+
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.models.similarity import Region
+from treepeat.pipeline.notebook import describe_notebook_location, load_notebook, read_notebook_lines
+from treepeat.pipeline.parse import collect_source_files, parse_notebook
+from treepeat.pipeline.pipeline import run_pipeline
+
+FIXTURE_DIR = Path(__file__).parent.parent / "fixtures" / "notebook"
+notebook_a = FIXTURE_DIR / "analysis_a.ipynb"
+notebook_b = FIXTURE_DIR / "analysis_b.ipynb"
+
+
+def _region(path: Path, start_line: int, end_line: int) -> Region:
+    return Region(
+        path=path,
+        language="python",
+        region_type="function_definition",
+        region_name="summarize",
+        start_line=start_line,
+        end_line=end_line,
+    )
+
+
+def test_load_notebook_uses_kernel_language():
+    notebook = load_notebook(notebook_a)
+    assert notebook.language == "python"
+    assert [cell.cell_type for cell in notebook.cells] == ["markdown", "code", "code", "code"]
+
+
+def test_code_source_blanks_markdown_cells():
+    notebook = load_notebook(notebook_a)
+    source = notebook.source_for({"code"}).decode("utf-8")
+    assert "Summary statistics" not in source
+    assert "def summarize(rows):" in source
+
+
+def test_read_notebook_lines_includes_every_cell():
+    lines = read_notebook_lines(notebook_a)
+    assert lines[0] == "# Summary statistics"
+    assert lines[10] == "def summarize(rows):"
+
+
+def test_describe_notebook_location_maps_to_cell():
+    assert describe_notebook_location(_region(notebook_a, 11, 19)) == f"{notebook_a}:cell[2]:line 1"
+    assert describe_notebook_location(_region(notebook_b, 12, 13)) == f"{notebook_b}:cell[3]:line 2"
+
+
+def test_describe_notebook_location_ignores_regular_files():
+    assert describe_notebook_location(_region(Path("module.py"), 1, 5)) is None
+
+
+def test_collect_source_files_finds_notebooks():
+    set_settings(PipelineSettings())
+    files = collect_source_files(FIXTURE_DIR)
+    assert set(files) == {notebook_a, notebook_b}
+
+
+def test_parse_notebook_skips_markdown_by_default():
+    set_settings(PipelineSettings())
+    parsed = parse_notebook(notebook_a)
+    assert [p.language for p in parsed] == ["python"]
+
+
+def test_parse_notebook_includes_markdown_when_detecting_comments():
+    set_settings(PipelineSettings(detect_comments=True))
+    parsed = parse_notebook(notebook_a)
+    assert [p.language for p in parsed] == ["python", "markdown"]
+
+
+def test_duplicate_cells_group_across_notebooks():
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=1.0)))
+    result = run_pipeline(FIXTURE_DIR)
+
+    assert len(result.similar_groups) == 1
+    group = result.similar_groups[0]
+    assert {r.path for r in group.regions} == {notebook_a, notebook_b}
+    assert {r.language for r in group.regions} == {"python"}
+
+
+def test_markdown_cells_group_when_detecting_comments():
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=0.9), detect_comments=True))
+    result = run_pipeline(FIXTURE_DIR)
+
+    languages = {r.language for group in result.similar_groups for r in group.regions}
+    assert "markdown" in languages
+
+
+def _write_notebook(path: Path, cells: list[str]) -> None:
+    path.write_text(
+        json.dumps(
+            {
+                "metadata": {"kernelspec": {"language": "python"}},
+                "cells": [{"cell_type": "code", "source": source} for source in cells],
+            }
+        )
+    )
+
+
+def test_code_source_blanks_ipython_magics(tmp_path):
+    notebook_path = tmp_path / "magics.ipynb"
+    _write_notebook(
+        notebook_path, ["%matplotlib inline\n!pip install pandas\nx = 1", "%%bash\necho hi", "%%time\ny = 2"]
+    )
+    notebook = load_notebook(notebook_path)
+
+    assert notebook.source_for({"code"}).decode("utf-8").splitlines() == ["", "", "x = 1", "", "", "", "", "", "y = 2"]
+    assert read_notebook_lines(notebook_path)[0] == "%matplotlib inline"
+
+
+def test_read_notebook_lines_reloads_an_edited_notebook(tmp_path):
+    notebook_path = tmp_path / "edited.ipynb"
+    _write_notebook(notebook_path, ["x = 1"])
+    assert read_notebook_lines(notebook_path) == ["x = 1"]
+
+    _write_notebook(notebook_path, ["x = 1", "total = x + 1"])
+
+    assert read_notebook_lines(notebook_path) == ["x = 1", "", "total = x + 1"]
+
+
+def test_notebook_cell_groups_with_python_module(tmp_path):
+    function = (
+        "def normalize(rows):\n"
+        "    total = sum(r['value'] for r in rows)\n"
+        "    if total == 0:\n"
+        "        return rows\n"
+        "    return [dict(r, value=r['value'] / total) for r in rows]\n"
+    )
+    _write_notebook(tmp_path / "explore.ipynb", ["%load_ext autoreload\n" + function])
+    (tmp_path / "features.py").write_text(function)
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=1.0)))
+
+    result = run_pipeline(tmp_path)
+
+    assert len(result.similar_groups) == 1
+    locations = {describe_notebook_location(r) or str(r.path) for r in result.similar_groups[0].regions}
+    assert locations == {f"{tmp_path / 'explore.ipynb'}:cell[0]:line 2", str(tmp_path / "features.py")}
@@ -0,0 +1,182 @@
+from pathlib import Path
+
+import pytest
+
+from treepeat.config import PipelineSettings, set_settings
+from treepeat.models import ParseResult
+from treepeat.pipeline.parse import (
+    ParseTimeoutError,
+    collect_fallback_files,
+    collect_source_files,
+    detect_language,
+    parse_files,
+    parse_path,
+    parse_source_code,
+    walk_files,
+)
+
+
+def _slow_source() -> bytes:
+    """A large, deeply nested file that takes tree-sitter a while to parse."""
+    nested = "[" * 200 + "1" + "]" * 200
+    return "\n".join(f"value_{i} = {nested}" for i in range(2000)).encode()
+
+
+def test_parse_source_code_times_out():
+    set_settings(PipelineSettings(parse_timeout=1e-6))
+    with pytest.raises(ParseTimeoutError):
+        parse_source_code(_slow_source(), "python", Path("slow.py"))
+
+
+def test_parse_source_code_without_timeout():
+    set_settings(PipelineSettings())
+    parsed = parse_source_code(b"x = 1\n", "python", Path("fast.py"))
+    assert parsed.root_node.type == "module"
+
+
+def test_parse_files_skips_timed_out_file(tmp_path, caplog):
+    slow = tmp_path / "slow.py"
+    slow.write_bytes(_slow_source())
+    fast = tmp_path / "fast.py"
+    fast.write_text("def f():\n    return 1\n")
+    set_settings(PipelineSettings(parse_timeout=1e-6))
+
+    result = ParseResult()
+    parse_files([slow, fast], result)
+
+    assert slow not in {p.path for p in result.parsed_files}
+    assert f"Skipping {slow}" in caplog.text
+
+
+def test_parse_files_in_parallel_keeps_file_order(tmp_path, caplog):
+    files = [tmp_path / f"module_{i}.py" for i in range(8)]
+    for path in files:
+        path.write_text("def f():\n    return 1\n")
+    broken = tmp_path / "broken.unknown"
+    broken.write_text("?")
+    set_settings(PipelineSettings())
+
+    result = ParseResult()
+    parse_files([files[0], broken, *files[1:]], result, jobs=4)
+
+    assert [p.path for p in result.parsed_files] == files
+    assert f"Failed to parse {broken}" in caplog.text
+
+
+@pytest.mark.parametrize(
+    "shebang,expected",
+    [
+        ("#!/bin/sh", "bash"),
+        ("#!/usr/bin/env bash", "bash"),
+        ("#!/usr/bin/env -S python3 -u", "python"),
+        ("#!/usr/local/bin/python3.11", "python"),
+        ("#!/usr/bin/env node", "javascript"),
+        ("#!/usr/bin/awk -f", None),
+        ("echo no shebang", None),
+    ],
+)
+def test_detect_language_from_shebang(tmp_path, shebang, expected):
+    script = tmp_path / "deploy"
+    script.write_text(f"{shebang}\necho hi\n")
+    assert detect_language(script) == expected
+
+
+def test_extension_wins_over_shebang(tmp_path):
+    script = tmp_path / "tool.py"
+    script.write_text("#!/bin/sh\nprint(1)\n")
+    assert detect_language(script) == "python"
+
+
+def test_extensionless_scripts_are_collected(tmp_path):
+    (tmp_path / "bin").mkdir()
+    (tmp_path / "bin" / "deploy").write_text("#!/usr/bin/env bash\necho deploy\n")
+    (tmp_path / "scripts").mkdir()
+    (tmp_path / "scripts" / "release").write_text("#!/bin/sh\necho release\n")
+    (tmp_path / "LICENSE").write_text("MIT\n")
+    set_settings(PipelineSettings())
+
+    collected = collect_source_files(tmp_path)
+
+    assert sorted(collected) == [tmp_path / "bin" / "deploy", tmp_path / "scripts" / "release"]
+    assert collect_fallback_files(tmp_path) == [tmp_path / "LICENSE"]
+
+
+def _symlinked_tree(tmp_path: Path) -> Path:
+    root, shared = tmp_path / "repo", tmp_path / "shared"
+    (root / "src").mkdir(parents=True)
+    shared.mkdir()
+    (root / "src" / "app.py").write_text("x = 1\n")
+    (shared / "lib.py").write_text("y = 2\n")
+    (root / "libs").symlink_to(shared)
+    (root / "alias").symlink_to(root / "src")
+    (root / "src" / "loop").symlink_to(root)
+    (shared / "self").symlink_to(shared)
+    return root
+
+
+def test_symlinks_are_skipped_by_default(tmp_path):
+    root = _symlinked_tree(tmp_path)
+    set_settings(PipelineSettings())
+
+    assert walk_files(root) == [root / "src" / "app.py"]
+
+
+def test_follow_symlinks_visits_each_target_once(tmp_path):
+    root = _symlinked_tree(tmp_path)
+    set_settings(PipelineSettings(follow_symlinks=True))
+
+    # alias/ and src/loop point back into the root and shared/self is a cycle: none are walked
+    assert walk_files(root) == [root / "libs" / "lib.py", root / "src" / "app.py"]
+
+
+def test_files_over_max_file_size_are_skipped(tmp_path, caplog):
+    (tmp_path / "small.py").write_text("x = 1\n")
+    (tmp_path / "huge.go").write_text("package main\n" + "var x = 1\n" * 200)
+    (tmp_path / "huge.txt").write_text("token " * 500)
+    set_settings(PipelineSettings(max_file_size=1024))
+
+    result = parse_path(tmp_path)
+
+    assert [parsed.path.name for parsed in result.parsed_files] == ["small.py"]
+    assert collect_fallback_files(tmp_path) == []
+    assert "huge.go" in caplog.text
+
+
+@pytest.mark.parametrize(
+    "header",
+    [
+        "// Code generated by protoc-gen-go. DO NOT EDIT.\n",
+        "# Generated by the protocol buffer compiler.  DO NOT EDIT!\n",
+        "/* @generated by codegen */\n",
+        "// This file was automatically generated.\n",
+    ],
+)
+def test_generated_files_are_skipped(tmp_path, header):
+    (tmp_path / "gen.py").write_text(header.replace("//", "#") + "x = 1\n")
+    (tmp_path / "app.py").write_text("x = 1\n")
+    set_settings(PipelineSettings())
+
+    assert [parsed.path.name for parsed in parse_path(tmp_path).parsed_files] == ["app.py"]
+
+
+def test_include_generated_and_custom_markers(tmp_path):
+    (tmp_path / "gen.py").write_text("# Code generated by stringer. DO NOT EDIT.\nx = 1\n")
+    (tmp_path / "api.py").write_text("# autogen: openapi\nx = 1\n")
+    set_settings(PipelineSettings(generated_markers=[r"autogen: \w+"]))
+    assert [parsed.path.name for parsed in parse_path(tmp_path).parsed_files] == ["gen.py"]
+
+    set_settings(PipelineSettings(include_generated=True))
+    assert sorted(parsed.path.name for parsed in parse_path(tmp_path).parsed_files) == ["api.py", "gen.py"]
+
+
+@pytest.mark.parametrize("include_vendored", [False, True])
+def test_vendored_directories_are_skipped_by_default(tmp_path, include_vendored):
+    for name in ("src/app.py", "vendor/lib/util.py", "node_modules/pkg/index.js", ".venv/lib/site.py", "target/gen.rs"):
+        (tmp_path / name).parent.mkdir(parents=True, exist_ok=True)
+        (tmp_path / name).write_text("x\n")
+    set_settings(PipelineSettings(include_vendored=include_vendored))
+
+    files = collect_source_files(tmp_path)
+
+    assert (len(files) == 5) == include_vendored
+    assert tmp_path / "src" / "app.py" in files
@@ -3,6 +3,7 @@ from pathlib import Path
 import pytest
 
 from treepeat.config import (
+    LanguageThresholds,
     LSHSettings,
     MinHashSettings,
     PipelineSettings,
@@ -281,3 +282,133 @@ def test_match_counts(ruleset, path, similarity_threshold, similar_groups, expec
     for region1, region2 in expected_regions:
         group = assert_regions_in_same_group(result, region1, region2)
         assert group.similarity > similarity_threshold
+
+
+def test_on_group_receives_each_reported_group():
+    set_settings(
+        PipelineSettings(
+            rules=RulesSettings(ruleset="default"),
+            shingle=ShingleSettings(),
+            minhash=MinHashSettings(),
+            lsh=LSHSettings(similarity_percent=0.9),
+        )
+    )
+    streamed = []
+    result = run_pipeline(fixture_class_with_methods, on_group=streamed.append)
+
+    assert result.similar_groups
+    assert streamed == result.similar_groups
+
+
+PYTHON_TOTAL = """\
+def total(items):
+    result = 0
+    for item in items:
+        if item > 0:
+            result = result + item
+    return result
+"""
+
+GO_TOTAL = """\
+package main
+
+func total(items []int) int {
+	result := 0
+	for _, item := range items {
+		if item > 0 {
+			result = result + item
+		}
+	}
+	return result
+}
+"""
+
+
+@pytest.mark.parametrize("cross_language", [False, True])
+def test_cross_language_groups_ported_logic(tmp_path, cross_language):
+    (tmp_path / "total.py").write_text(PYTHON_TOTAL)
+    (tmp_path / "total.go").write_text(GO_TOTAL)
+    set_settings(
+        PipelineSettings(
+            shingle=ShingleSettings(cross_language=cross_language),
+            lsh=LSHSettings(similarity_percent=0.8, min_lines=3),
+        )
+    )
+    result = run_pipeline(tmp_path)
+
+    languages = [{r.language for r in group.regions} for group in result.similar_groups]
+    assert ({"python", "go"} in languages) == cross_language
+
+
+def test_copies_form_one_clone_class(tmp_path):
+    for i in range(12):
+        (tmp_path / f"copy{i:02d}.py").write_text(PYTHON_TOTAL)
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=1.0, min_lines=3)))
+    result = run_pipeline(tmp_path)
+
+    assert len(result.similar_groups) == 1
+    group = result.similar_groups[0]
+    assert group.size == 12
+    assert group.representative.path.name == "copy00.py"
+
+
+@pytest.mark.parametrize("num_perm", [16, 256])
+def test_num_perm_still_finds_exact_copies(tmp_path, num_perm):
+    for i in range(3):
+        (tmp_path / f"copy{i}.py").write_text(PYTHON_TOTAL)
+    set_settings(
+        PipelineSettings(
+            minhash=MinHashSettings(num_perm=num_perm),
+            lsh=LSHSettings(similarity_percent=1.0, min_lines=3),
+        )
+    )
+    result = run_pipeline(tmp_path)
+
+    assert [group.size for group in result.similar_groups] == [3]
+
+
+PYTHON_LONGEST = """\
+def longest(words):
+    best = ""
+    while words:
+        word = words.pop()
+        if len(word) > len(best):
+            best = word
+    return best
+"""
+
+
+def test_changed_files_limit_reported_clones(tmp_path):
+    (tmp_path / "changed.py").write_text(PYTHON_TOTAL)
+    (tmp_path / "original.py").write_text(PYTHON_TOTAL)
+    (tmp_path / "old1.py").write_text(PYTHON_LONGEST)
+    (tmp_path / "old2.py").write_text(PYTHON_LONGEST)
+    set_settings(
+        PipelineSettings(
+            lsh=LSHSettings(similarity_percent=1.0, min_lines=3),
+            changed_files={(tmp_path / "changed.py").resolve()},
+        )
+    )
+    result = run_pipeline(tmp_path)
+
+    assert [sorted(r.path.name for r in group.regions) for group in result.similar_groups] == [
+        ["changed.py", "original.py"]
+    ]
+
+
+@pytest.mark.parametrize(("global_min_lines", "python_min_lines", "found"), [(10, 3, True), (3, 10, False)])
+def test_language_thresholds_override_global_ones(tmp_path, global_min_lines, python_min_lines, found):
+    for i in range(2):
+        (tmp_path / f"copy{i}.py").write_text(PYTHON_TOTAL)
+    set_settings(
+        PipelineSettings(
+            lsh=LSHSettings(
+                similarity_percent=1.0,
+                min_lines=global_min_lines,
+                language_thresholds={"python": LanguageThresholds(min_lines=python_min_lines)},
+            )
+        )
+    )
+    result = run_pipeline(tmp_path)
+
+    assert bool(result.similar_groups) == found
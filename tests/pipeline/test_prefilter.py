@@ -0,0 +1,79 @@
+from pathlib import Path
+
+from datasketch import MinHash
+
+from treepeat.models.similarity import Region, RegionSignature
+from treepeat.pipeline.prefilter import Prefilter, bloom_size, estimated_jaccard, shingle_bloom, sizes_compatible
+
+
+def _signature(name: str, shingles: set[str]) -> RegionSignature:
+    return RegionSignature(
+        region=Region(
+            path=Path(f"{name}.py"),
+            language="python",
+            region_type="function",
+            region_name=name,
+            start_line=1,
+            end_line=9,
+        ),
+        minhash=MinHash(),
+        shingle_count=len(shingles),
+        distinct_shingles=len(shingles),
+        shingle_bloom=shingle_bloom(shingles, bloom_size(len(shingles))),
+        bloom_bits=bloom_size(len(shingles)),
+    )
+
+
+def _overlapping(shared: int, total: int) -> tuple[RegionSignature, RegionSignature]:
+    """Two regions of ``total`` distinct shingles, ``shared`` of which they have in common."""
+    common = {f"s{n}" for n in range(shared)}
+    first = common | {f"a{n}" for n in range(total - shared)}
+    second = common | {f"b{n}" for n in range(total - shared)}
+    return _signature("first", first), _signature("second", second)
+
+
+def test_bloom_filters_grow_with_the_region():
+    assert bloom_size(1) == 256
+    assert bloom_size(1000) == 16384
+    assert bloom_size(1025) == 32768
+
+
+def test_large_regions_do_not_saturate_the_estimate():
+    assert estimated_jaccard(*_overlapping(900, 1000)) > 0.75
+    assert estimated_jaccard(*_overlapping(100, 1000)) < 0.1
+
+
+def test_sizes_bound_the_similarity():
+    assert sizes_compatible(80, 100, 0.8)
+    assert not sizes_compatible(10, 100, 0.8)
+    # Unknown sizes are never rejected
+    assert sizes_compatible(0, 100, 0.8)
+
+
+def test_prefilter_counts_rejected_pairs():
+    prefilter = Prefilter(0.5)
+    small = _signature("small", {"x"})
+    large = _signature("large", {f"s{n}" for n in range(10)} | {"x"})
+    similar = _signature("similar", {f"s{n}" for n in range(9)} | {"x"})
+
+    assert not prefilter.admits(small, large)
+    assert prefilter.admits(large, similar)
+    assert (prefilter.checked, prefilter.rejected) == (2, 1)
+
+
+def test_prefilter_rejects_same_size_pairs_sharing_few_shingles():
+    prefilter = Prefilter(0.5)
+    unrelated = [_overlapping(shared, 300) for shared in range(0, 60, 6)]
+    related = [_overlapping(shared, 300) for shared in range(270, 300, 6)]
+
+    assert not any(prefilter.admits(*pair) for pair in unrelated)
+    assert all(prefilter.admits(*pair) for pair in related)
+    assert (prefilter.checked, prefilter.rejected) == (15, 10)
+
+
+def test_prefilter_keeps_pairs_at_the_threshold():
+    prefilter = Prefilter(0.5)
+    # Sharing two thirds of their shingles makes the Jaccard similarity exactly 0.5
+    pairs = [_overlapping(2 * total // 3, total) for total in range(3, 600, 3)]
+
+    assert all(prefilter.admits(*pair) for pair in pairs)
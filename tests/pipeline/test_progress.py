@@ -0,0 +1,39 @@
+import io
+
+from treepeat.pipeline.progress import iter_progress_lines, progress_line
+
+
+def test_progress_line_reports_remaining_and_eta():
+    line = progress_line("Parsing", "file", 25, 100, 5.0)
+
+    assert line == (
+        "treepeat: progress phase=Parsing unit=file done=25 total=100 remaining=75 elapsed=5.0s eta=15.0s"
+    )
+
+
+def test_progress_line_without_total_has_no_eta():
+    line = progress_line("Verifying", "group", 3, None, 1.0)
+
+    assert "total=" not in line
+    assert line.endswith("eta=?")
+
+
+def test_iter_progress_lines_yields_every_item_and_reports_start_and_end():
+    stream = io.StringIO()
+
+    items = list(iter_progress_lines(["a", "b", "c"], "Parsing", "file", 3, stream, interval=3600))
+
+    assert items == ["a", "b", "c"]
+    lines = stream.getvalue().splitlines()
+    assert len(lines) == 2
+    assert "done=0 total=3 remaining=3" in lines[0]
+    assert "done=3 total=3 remaining=0" in lines[1]
+
+
+def test_iter_progress_lines_reports_periodically():
+    stream = io.StringIO()
+
+    list(iter_progress_lines(range(4), "MinHash", "region", 4, stream, interval=0))
+
+    dones = [line.split("done=")[1].split()[0] for line in stream.getvalue().splitlines()]
+    assert dones == ["0", "1", "2", "3", "4", "4"]
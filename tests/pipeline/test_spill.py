@@ -0,0 +1,40 @@
+from treepeat.pipeline.lsh_stage import detect_similarity
+from treepeat.pipeline.minhash_stage import compute_region_signatures, create_minhash_signature
+from treepeat.pipeline.region_extraction import extract_all_regions
+from treepeat.pipeline.rules.engine import RuleEngine
+from treepeat.pipeline.shingle import shingle_regions
+from treepeat.pipeline.spill import SpilledLSH, index_memory
+
+from ..conftest import default_rule_engine, fixture_path2, parsed_fixture
+
+
+def test_spilled_index_answers_like_the_in_memory_one():
+    shingles = {name: {f"{name}-{i}" for i in range(40)} | {f"shared-{i}" for i in range(60)} for name in "abc"}
+    shingles["other"] = {f"other-{i}" for i in range(100)}
+    minhashes = {name: create_minhash_signature(values) for name, values in shingles.items()}
+
+    with SpilledLSH(0.5, 128) as lsh:
+        lsh.insert_all(minhashes.items())
+
+        assert sorted(lsh.query(minhashes["a"])) == ["a", "b", "c"]
+        assert lsh.query(minhashes["other"]) == ["other"]
+
+
+def test_index_memory_grows_with_signatures():
+    assert index_memory(2000, 0.5, 128) == 2 * index_memory(1000, 0.5, 128) > 0
+
+
+def test_detection_over_memory_budget_finds_the_same_groups():
+    parsed = parsed_fixture(fixture_path2)
+    shingled_regions = shingle_regions(
+        extracted_regions=extract_all_regions([parsed], default_rule_engine()),
+        parsed_files=[parsed],
+        rule_engine=RuleEngine([]),
+    )
+    signatures = compute_region_signatures(shingled_regions)
+
+    in_memory = detect_similarity(signatures, 0.5, shingled_regions)
+    spilled = detect_similarity(signatures, 0.5, shingled_regions, max_index_memory=1)
+
+    assert spilled.similar_groups == in_memory.similar_groups
+    assert len(spilled.similar_groups) == 1
@@ -0,0 +1,105 @@
+from pathlib import Path
+
+import pytest
+
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.models.similarity import Region, SimilarRegionGroup
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.suppress import SuppressionIndex, find_directives
+
+PYTHON_TOTAL = """\
+def total(items):
+    result = 0
+    for item in items:
+        if item > 0:
+            result = result + item
+    return result
+"""
+
+PYTHON_NAMES = """\
+def names(people):
+    found = []
+    while people:
+        person = people.pop()
+        found.append(person.name)
+    return found
+"""
+
+
+def _region(path: Path, start: int, end: int) -> Region:
+    return Region(
+        path=path, language="python", region_type="function_definition", region_name="f", start_line=start, end_line=end
+    )
+
+
+def _group(*regions: Region) -> SimilarRegionGroup:
+    return SimilarRegionGroup(regions=list(regions), similarity=1.0)
+
+
+@pytest.mark.parametrize(
+    "line, expected",
+    [
+        ("x = 1  # treepeat:ignore", [(1, False)]),
+        ("// treepeat:ignore-next-block", [(1, True)]),
+        ("<!-- treepeat:ignore -->", [(1, False)]),
+        ("-- treepeat:ignore-next-block", [(1, True)]),
+        ("# treepeat: clone of a.py:1-5", []),
+        ("message = 'treepeat:ignore'", []),
+    ],
+)
+def test_find_directives(line, expected):
+    assert find_directives([line]) == expected
+
+
+def test_ignore_suppresses_overlapping_group(tmp_path):
+    path = tmp_path / "a.py"
+    path.write_text("def f():\n    return 1  # treepeat:ignore\n\n\ndef g():\n    return 2\n")
+    index = SuppressionIndex()
+
+    assert index.suppresses(_group(_region(path, 1, 2), _region(tmp_path / "b.py", 1, 2)))
+    assert not index.suppresses(_group(_region(path, 5, 6), _region(tmp_path / "b.py", 1, 2)))
+
+
+def test_ignore_next_block_covers_the_whole_region(tmp_path):
+    path = tmp_path / "a.py"
+    path.write_text(
+        "# treepeat:ignore-next-block\n\nclass A:\n    def f(self):\n        return 1\n\n\ndef g():\n    pass\n"
+    )
+    index = SuppressionIndex()
+    index.add_regions([_region(path, 3, 5), _region(path, 4, 5)])
+
+    assert index.spans(path) == [(3, 5)]
+    assert index.suppresses(_group(_region(path, 4, 5), _region(tmp_path / "b.py", 1, 2)))
+    assert not index.suppresses(_group(_region(path, 8, 9), _region(tmp_path / "b.py", 1, 2)))
+
+
+def test_ignore_next_block_without_regions_covers_the_next_line(tmp_path):
+    path = tmp_path / "a.py"
+    path.write_text("# treepeat:ignore-next-block\ndef f():\n    pass\n")
+
+    assert SuppressionIndex().spans(path) == [(2, 2)]
+
+
+def test_split_partitions_groups(tmp_path):
+    path = tmp_path / "a.py"
+    path.write_text("def f():  # treepeat:ignore\n    pass\n\ndef g():\n    pass\n")
+    suppressed = _group(_region(path, 1, 2), _region(tmp_path / "b.py", 1, 2))
+    reported = _group(_region(path, 4, 5), _region(tmp_path / "b.py", 1, 2))
+
+    assert SuppressionIndex().split([suppressed, reported]) == ([reported], [suppressed])
+
+
+def test_pipeline_reports_suppressed_groups_separately(tmp_path):
+    for name in ("a.py", "b.py"):
+        (tmp_path / name).write_text(PYTHON_TOTAL + "\n\n" + PYTHON_NAMES)
+    (tmp_path / "c.py").write_text("# treepeat:ignore-next-block\n" + PYTHON_TOTAL)
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=1.0, min_lines=3)))
+    streamed: list[SimilarRegionGroup] = []
+
+    result = run_pipeline(tmp_path, on_group=streamed.append)
+
+    assert len(result.suppressed_groups) == 1
+    assert len(result.similar_groups) == 1
+    assert {r.path.name for r in result.suppressed_groups[0].regions} >= {"c.py"}
+    assert all("c.py" not in {r.path.name for r in g.regions} for g in result.similar_groups)
+    assert all(group in result.similar_groups for group in streamed)
@@ -0,0 +1,61 @@
+from pathlib import Path
+
+import pytest
+
+from treepeat.models.similarity import Region, SimilarRegionGroup
+from treepeat.pipeline.testcode import is_test_path, split_test_groups, tests_callback
+
+ROOT = Path("repo")
+
+
+def _group(*paths: str) -> SimilarRegionGroup:
+    regions = [
+        Region(path=ROOT / path, language="python", region_type="function", region_name="f", start_line=1, end_line=5)
+        for path in paths
+    ]
+    return SimilarRegionGroup(regions=regions, similarity=1.0)
+
+
+@pytest.mark.parametrize(
+    "path",
+    [
+        "pkg/server_test.go",
+        "pkg/test_models.py",
+        "src/app.spec.ts",
+        "web/__tests__/button.jsx",
+        "tests/helpers.py",
+        "spec/models/user.rb",
+        "src/main/java/FooTest.java",
+    ],
+)
+def test_conventional_test_paths_are_recognized(path):
+    assert is_test_path(ROOT / path, ROOT)
+
+
+@pytest.mark.parametrize("path", ["pkg/server.go", "src/testing_utils.py", "src/contest.ts", "tests"])
+def test_production_paths_are_not_test_code(path):
+    assert not is_test_path(ROOT / path, ROOT)
+
+
+def test_test_directories_above_the_scanned_path_do_not_count():
+    root = Path("tests/fixtures/project")
+
+    assert not is_test_path(root / "app.py", root)
+
+
+def test_groups_only_in_test_code_are_split_off():
+    mixed = _group("app.py", "tests/test_app.py")
+    tests_only = _group("tests/test_a.py", "tests/test_b.py")
+
+    assert split_test_groups([mixed, tests_only], ROOT) == ([mixed], [tests_only])
+
+
+def test_tests_callback_skips_groups_only_in_test_code():
+    seen: list[SimilarRegionGroup] = []
+    callback = tests_callback(seen.append, ROOT)
+    assert callback is not None
+
+    callback(_group("a.py", "b.py"))
+    callback(_group("tests/a.py", "tests/b.py"))
+
+    assert len(seen) == 1
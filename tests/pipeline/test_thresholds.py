@@ -0,0 +1,64 @@
+from pathlib import Path
+
+from treepeat.config import LanguageThresholds, LSHSettings
+from treepeat.models.similarity import Region, SimilarRegionGroup
+from treepeat.pipeline.thresholds import (
+    effective_thresholds,
+    group_meets_thresholds,
+    loosest_min_lines,
+    loosest_similarity,
+)
+
+
+def _group(language: str, lines: int, similarity: float = 1.0) -> SimilarRegionGroup:
+    regions = [
+        Region(
+            path=Path(f"{name}.{language}"),
+            language=language,
+            region_type="function",
+            region_name="f",
+            start_line=1,
+            end_line=lines,
+        )
+        for name in ("a", "b")
+    ]
+    return SimilarRegionGroup(regions=regions, similarity=similarity)
+
+
+LSH = LSHSettings(
+    min_lines=10,
+    similarity_percent=0.9,
+    language_thresholds={
+        "go": LanguageThresholds(min_lines=3),
+        "html": LanguageThresholds(min_lines=30, min_similarity=0.98),
+    },
+)
+
+
+def test_loosest_thresholds_cover_every_language():
+    assert loosest_min_lines(LSH) == 3
+    assert loosest_similarity(LSH) == 0.9
+    assert loosest_min_lines(LSHSettings(min_lines=7)) == 7
+
+
+def test_language_overrides_replace_the_global_thresholds():
+    assert group_meets_thresholds(_group("go", 4), LSH, {})
+    assert not group_meets_thresholds(_group("python", 4), LSH, {})
+    assert group_meets_thresholds(_group("python", 10), LSH, {})
+    assert not group_meets_thresholds(_group("html", 20), LSH, {})
+    assert not group_meets_thresholds(_group("html", 40, similarity=0.95), LSH, {})
+    assert group_meets_thresholds(_group("html", 40, similarity=0.99), LSH, {})
+
+
+def test_min_tokens_uses_the_region_token_counts():
+    lsh = LSHSettings(min_lines=1, language_thresholds={"go": LanguageThresholds(min_tokens=50)})
+    group = _group("go", 5)
+    tokens = {(r.path, r.start_line, r.end_line, r.region_name): 40 for r in group.regions}
+
+    assert not group_meets_thresholds(group, lsh, tokens)
+    assert group_meets_thresholds(group, lsh, {key: 60 for key in tokens})
+
+
+def test_effective_thresholds_fall_back_to_the_global_settings():
+    assert effective_thresholds("html", LSH) == LanguageThresholds(min_lines=30, min_tokens=0, min_similarity=0.98)
+    assert effective_thresholds("python", LSH) == LanguageThresholds(min_lines=10, min_tokens=0, min_similarity=0.9)
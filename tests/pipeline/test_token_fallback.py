@@ -0,0 +1,65 @@
+from pathlib import Path
+
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.token_fallback import FALLBACK_LANGUAGE, extract_token_regions, is_binary, tokenize
+
+RULE = (
+    "rule deploy_{name} {{\n"
+    "  when branch == \"{branch}\" and approvals >= {count}\n"
+    "  then notify(\"ops\")\n"
+    "  then release(target: \"{branch}\")\n"
+    "}}\n"
+)
+
+
+def test_tokenize_with_normalization():
+    assert tokenize(['x = "a" + 12'], []) == [("x", 1), ("=", 1), ('"a"', 1), ("+", 1), ("12", 1)]
+    assert [t for t, _ in tokenize(['x = "a" + 12'], ["literals"])] == ["x", "=", "<STR>", "+", "<NUM>"]
+    assert [t for t, _ in tokenize(["x = y"], ["identifiers"])] == ["<ID>", "=", "<ID>"]
+
+
+def test_blocks_become_regions(tmp_path):
+    path = tmp_path / "policy.dsl"
+    path.write_text(RULE.format(name="a", branch="main", count=2) + "\n" + "short\n")
+
+    regions = extract_token_regions(path, k=3, min_lines=3, normalize=[])
+
+    assert [(r.region.start_line, r.region.end_line) for r in regions] == [(1, 5)]
+    assert regions[0].region.language == FALLBACK_LANGUAGE
+    assert regions[0].shingles.shingles[0].content == "rule deploy_a {"
+
+
+def test_is_binary(tmp_path):
+    (tmp_path / "blob.bin").write_bytes(b"abc\0def")
+    (tmp_path / "text.dsl").write_text("abc")
+
+    assert is_binary(tmp_path / "blob.bin")
+    assert not is_binary(tmp_path / "text.dsl")
+
+
+def _run(tmp_path: Path, fallback: str | None):
+    set_settings(PipelineSettings(lsh=LSHSettings(similarity_percent=1.0, min_lines=3), fallback=fallback))
+    return run_pipeline(tmp_path)
+
+
+def test_fallback_detects_clones_in_files_without_a_grammar(tmp_path):
+    (tmp_path / "one.dsl").write_text(RULE.format(name="a", branch="main", count=2))
+    (tmp_path / "two.dsl").write_text(RULE.format(name="a", branch="main", count=2))
+    (tmp_path / ".hidden").mkdir()
+    (tmp_path / ".hidden" / "three.dsl").write_text(RULE.format(name="a", branch="main", count=2))
+
+    assert not _run(tmp_path, fallback=None).similar_groups
+    groups = _run(tmp_path, fallback="token").similar_groups
+    assert len(groups) == 1
+    assert sorted(r.path.name for r in groups[0].regions) == ["one.dsl", "two.dsl"]
+
+
+def test_import_blocks_are_not_regions(tmp_path):
+    path = tmp_path / "policy.dsl"
+    imports = "import rules.base\nimport rules.deploy\nimport rules.notify\n\n"
+    path.write_text(imports + RULE.format(name="a", branch="main", count=2))
+
+    regions = extract_token_regions(path, k=3, min_lines=3, normalize=[])
+
+    assert [(r.region.start_line, r.region.end_line) for r in regions] == [(5, 9)]
@@ -0,0 +1,52 @@
+from pathlib import Path
+
+from treepeat.config import PipelineSettings, set_settings
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.verbose_metrics import (
+    get_verbose_metrics,
+    record_cache_lookups,
+    record_fragment_type,
+    reset_verbose_metrics,
+)
+
+python_fixtures = Path(__file__).parent.parent / "fixtures" / "python"
+
+
+def test_record_fragment_type_counts_by_language_and_type():
+    reset_verbose_metrics()
+    record_fragment_type(Path("a.py"), "python", "function_definition")
+    record_fragment_type(Path("b.py"), "python", "function_definition")
+    record_fragment_type(Path("c.go"), "go", "function_declaration")
+
+    assert get_verbose_metrics().fragment_counts == {
+        ("python", "function_definition"): 2,
+        ("go", "function_declaration"): 1,
+    }
+    assert get_verbose_metrics().file_fragment_counts[Path("a.py")] == {("python", "function_definition"): 1}
+
+
+def test_pipeline_records_extracted_fragment_types():
+    set_settings(PipelineSettings())
+    reset_verbose_metrics()
+    run_pipeline(python_fixtures / "class_with_methods.py")
+
+    counts = get_verbose_metrics().fragment_counts
+    assert counts[("python", "class_definition")] == 3
+    assert counts[("python", "function_definition")] == 8
+
+
+def test_pipeline_records_parsed_lines():
+    set_settings(PipelineSettings())
+    reset_verbose_metrics()
+    path = python_fixtures / "class_with_methods.py"
+    run_pipeline(path)
+
+    assert get_verbose_metrics().parsed_lines == len(path.read_text().splitlines())
+
+
+def test_cache_lookups_add_up():
+    reset_verbose_metrics()
+    record_cache_lookups(2, 1)
+    record_cache_lookups(1, 0)
+
+    assert (get_verbose_metrics().cache_hits, get_verbose_metrics().cache_misses) == (3, 1)
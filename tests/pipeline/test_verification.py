@@ -46,3 +46,80 @@ def test_renamed_clone_penalized_when_names_not_anonymized():
     # Under 'none' the signature check fires: differing names -> not a match.
     result = _run_with_ruleset("none", similarity_percent=1.0)
     assert len(result.similar_groups) == 0
+
+
+PYTHON_FIXTURES = Path(__file__).parent.parent / "fixtures" / "python"
+
+
+def _group_summary(jobs: int):
+    set_settings(
+        PipelineSettings(
+            lsh=LSHSettings(similarity_percent=0.8, min_lines=3),
+            jobs=jobs,
+        )
+    )
+    result = run_pipeline(PYTHON_FIXTURES)
+    return [
+        (group.fingerprint, group.similarity, [(str(r.path), r.start_line, r.end_line) for r in group.regions])
+        for group in result.similar_groups
+    ]
+
+
+def test_parallel_verification_is_deterministic():
+    """Verifying with several worker processes yields exactly the single-process groups, in order."""
+    sequential = _group_summary(jobs=1)
+
+    assert sequential
+    assert _group_summary(jobs=8) == sequential
+
+
+def _write_loader(path: Path, extra: str) -> None:
+    path.write_text(
+        "def load(rows):\n"
+        "    result = []\n"
+        "    for row in rows:\n"
+        "        if row:\n"
+        "            result.append(row)\n"
+        f"{extra}"
+        "    total = len(result)\n"
+        "    print(total)\n"
+        "    return result\n"
+    )
+
+
+def _loaders_grouped(tmp_path: Path, max_gap_lines: int | str | None) -> bool:
+    set_settings(
+        PipelineSettings(
+            rules=RulesSettings(ruleset="none"),
+            lsh=LSHSettings(similarity_percent=0.5, min_lines=3, max_gap_lines=max_gap_lines),
+        )
+    )
+    return bool(run_pipeline(tmp_path).similar_groups)
+
+
+def test_max_gap_lines_tolerates_small_insertions(tmp_path):
+    _write_loader(tmp_path / "a.py", "")
+    _write_loader(tmp_path / "b.py", "    result.sort()\n")
+
+    assert _loaders_grouped(tmp_path, max_gap_lines=None)
+    assert _loaders_grouped(tmp_path, max_gap_lines=2)
+
+
+def test_max_gap_lines_rejects_wide_rewrites(tmp_path):
+    _write_loader(tmp_path / "a.py", "")
+    _write_loader(
+        tmp_path / "b.py",
+        "    result.sort()\n    result.reverse()\n    result = result[:10]\n    result = [r for r in result if r]\n",
+    )
+
+    assert _loaders_grouped(tmp_path, max_gap_lines=None)
+    assert not _loaders_grouped(tmp_path, max_gap_lines=1)
+
+
+def test_max_gap_lines_as_a_percentage_of_the_region(tmp_path):
+    _write_loader(tmp_path / "a.py", "")
+    _write_loader(tmp_path / "b.py", "    result.sort()\n    result.reverse()\n    result = result[:10]\n")
+
+    # b.py is 11 lines long: 10% of it tolerates a 1-line gap, 50% a 5-line one
+    assert not _loaders_grouped(tmp_path, max_gap_lines="10%")
+    assert _loaders_grouped(tmp_path, max_gap_lines="50%")
@@ -0,0 +1,67 @@
+from pathlib import Path
+
+from treepeat.config import LSHSettings, PipelineSettings, ShingleSettings, set_settings
+from treepeat.models.shingle import Shingle, ShingledRegion, ShingleList
+from treepeat.models.similarity import Region
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.verification import _ordered_match
+from treepeat.pipeline.winnow import select_fingerprints, winnow_region
+
+SOURCE = """\
+def total(items):
+    result = 0
+    for item in items:
+        if item > 0:
+            result = result + item
+    return result
+"""
+
+
+def _shingled(contents: list[str]) -> ShingledRegion:
+    region = Region(
+        path=Path("a.py"),
+        language="python",
+        region_type="function_definition",
+        region_name="f",
+        start_line=1,
+        end_line=len(contents),
+    )
+    shingles = [Shingle(content=c, start_line=i, end_line=i) for i, c in enumerate(contents, start=1)]
+    return ShingledRegion(region=region, shingles=ShingleList(shingles=shingles))
+
+
+def test_every_window_keeps_a_fingerprint():
+    shingles = [f"node{i}" for i in range(40)]
+    kept = {str(s) for s in select_fingerprints(shingles, window=4)}
+
+    assert kept <= set(shingles)
+    assert all(kept & set(shingles[i : i + 4]) for i in range(len(shingles) - 3))
+    assert select_fingerprints([], window=4) == []
+
+
+def test_winnowed_regions_tolerate_reordering():
+    first = [f"first{i}" for i in range(30)]
+    second = [f"second{i}" for i in range(30)]
+    original, reordered = _shingled(first + second), _shingled(second + first)
+
+    ordered, _ = _ordered_match(original.shingles.get_contents(), reordered.shingles.get_contents())
+    winnowed, _ = _ordered_match(
+        winnow_region(original, 4).shingles.get_contents(),
+        winnow_region(reordered, 4).shingles.get_contents(),
+    )
+
+    assert ordered == 0.5
+    assert winnowed > 0.75
+
+
+def test_winnow_strategy_finds_copies(tmp_path):
+    (tmp_path / "a.py").write_text(SOURCE)
+    (tmp_path / "b.py").write_text(SOURCE)
+    set_settings(
+        PipelineSettings(
+            shingle=ShingleSettings(strategy="winnow"),
+            lsh=LSHSettings(similarity_percent=1.0, min_lines=3),
+        )
+    )
+
+    assert [group.size for group in run_pipeline(tmp_path).similar_groups] == [2]
@@ -0,0 +1,75 @@
+import pytest
+
+from treepeat.publish import Finding, PublishError, finding_message
+from treepeat.publish.bitbucket import MAX_ANNOTATIONS, BitbucketCloudPublisher, BitbucketServerPublisher
+
+
+class FakeClient:
+    """Records the Bitbucket API calls."""
+
+    def __init__(self):
+        self.calls = []
+
+    def call(self, method, path, body=None, params=None):
+        self.calls.append((method, path, body))
+        return None
+
+
+def _finding(line: int) -> Finding:
+    return Finding(f"clone-{line}", "src/a.py", 1, 10, line, 1.0, ("b.py:1-10",))
+
+
+def test_cloud_report_is_replaced_and_annotated_in_batches():
+    client = FakeClient()
+    findings = [_finding(line) for line in range(1, 151)]
+
+    counts = BitbucketCloudPublisher(client, "acme/shop", "abc123", {}).publish(findings)
+
+    report = "/repositories/acme/shop/commit/abc123/reports/treepeat"
+    assert counts == {"created": 150, "omitted": 0}
+    assert [(method, path) for method, path, body in client.calls] == [
+        ("PUT", report),
+        ("POST", f"{report}/annotations"),
+        ("POST", f"{report}/annotations"),
+    ]
+    assert client.calls[0][2]["result"] == "FAILED"
+    assert [len(body) for method, path, body in client.calls[1:]] == [100, 50]
+    assert client.calls[1][2][0] == {
+        "external_id": findings[0].key,
+        "annotation_type": "CODE_SMELL",
+        "severity": "MEDIUM",
+        "path": "src/a.py",
+        "line": 1,
+        "summary": finding_message(findings[0]),
+    }
+
+
+def test_server_annotations_of_earlier_runs_are_dropped():
+    client = FakeClient()
+    findings = [_finding(line) for line in range(1, MAX_ANNOTATIONS + 3)]
+
+    counts = BitbucketServerPublisher(client, "SHOP/web", "abc123", {}).publish(findings)
+
+    report = "/insights/1.0/projects/SHOP/repos/web/commits/abc123/reports/treepeat"
+    assert counts == {"created": MAX_ANNOTATIONS, "omitted": 2}
+    assert [(method, path) for method, path, body in client.calls] == [
+        ("PUT", report),
+        ("DELETE", f"{report}/annotations"),
+        ("POST", f"{report}/annotations"),
+    ]
+    assert client.calls[0][2]["result"] == "FAIL"
+    assert len(client.calls[2][2]["annotations"]) == MAX_ANNOTATIONS
+
+
+def test_server_report_without_findings_passes():
+    client = FakeClient()
+
+    BitbucketServerPublisher(client, "SHOP/web", "abc123", {}).publish([])
+
+    assert [method for method, path, body in client.calls] == ["PUT", "DELETE"]
+    assert client.calls[0][2]["result"] == "PASS"
+
+
+def test_server_repository_needs_a_project():
+    with pytest.raises(PublishError):
+        BitbucketServerPublisher(FakeClient(), "web", "abc123", {})
@@ -0,0 +1,70 @@
+from treepeat.publish import Finding, finding_message
+from treepeat.publish.gerrit import GerritPublisher, diff_added_lines
+
+REVISION = "/changes/42/revisions/current"
+
+
+class FakeClient:
+    """Answers Gerrit API calls from canned data and records the writes."""
+
+    def __init__(self, responses):
+        self.responses = responses
+        self.writes = []
+
+    def call(self, method, path, body=None, params=None):
+        if method == "GET":
+            return self.responses[path]
+        self.writes.append((method, path, body))
+        return {}
+
+
+def _finding(fingerprint: str, line: int) -> Finding:
+    return Finding(fingerprint, "src/a.py", 1, 10, line, 1.0, ("b.py:1-10",))
+
+
+def test_diff_added_lines_count_common_and_skipped_lines():
+    diff = {"content": [{"ab": ["x = 0"]}, {"a": ["y = 0"], "b": ["y = 1", "z = 2"]}, {"skip": 10}, {"b": ["w = 3"]}]}
+
+    assert diff_added_lines(diff) == {2, 3, 14}
+
+
+def test_changed_lines_skip_the_commit_message_and_deleted_files():
+    client = FakeClient(
+        {
+            f"{REVISION}/files": {"/COMMIT_MSG": {}, "src/a.py": {"status": "M"}, "old.py": {"status": "D"}},
+            f"{REVISION}/files/src%2Fa.py/diff": {"content": [{"b": ["x = 1"]}]},
+        }
+    )
+
+    assert GerritPublisher(client, "42", "current").changed_lines() == {"src/a.py": {1}}
+
+
+def test_findings_commented_on_before_are_not_repeated():
+    old, new = _finding("clone-old", 3), _finding("clone-new", 5)
+    posted = {"robot_id": "treepeat", "properties": {"key": old.key}}
+    other_robot = {"robot_id": "linter", "properties": {"key": new.key}}
+    client = FakeClient({f"{REVISION}/robotcomments": {"src/a.py": [posted, other_robot]}})
+
+    counts = GerritPublisher(client, "42", "current").publish([old, new])
+
+    assert counts == {"created": 1, "unchanged": 1}
+    [(method, path, review)] = client.writes
+    assert (method, path, review["tag"]) == ("POST", f"{REVISION}/review", "autogenerated:treepeat")
+    assert review["robot_comments"] == {
+        "src/a.py": [
+            {
+                "robot_id": "treepeat",
+                "robot_run_id": "current",
+                "line": 5,
+                "message": finding_message(new),
+                "properties": {"fingerprint": "clone-new", "key": new.key},
+            }
+        ]
+    }
+
+
+def test_nothing_new_nothing_posted():
+    client = FakeClient({f"{REVISION}/robotcomments": {}})
+
+    assert GerritPublisher(client, "42", "current").publish([]) == {"created": 0, "unchanged": 0}
+    assert client.writes == []
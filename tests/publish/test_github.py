@@ -0,0 +1,66 @@
+from treepeat.publish import Finding, comment_body
+from treepeat.publish.github import GitHubPublisher
+
+
+class FakeClient:
+    """Answers GitHub API calls from canned data and records the writes."""
+
+    def __init__(self, files, comments):
+        self.files = files
+        self.comments = comments
+        self.writes = []
+
+    def paginate(self, path, params=None):
+        return self.files if path.endswith("/files") else self.comments
+
+    def call(self, method, path, body=None, params=None):
+        if method == "GET":
+            return {"head": {"sha": "abc123"}}
+        self.writes.append((method, path, body))
+        return {}
+
+
+def _finding(fingerprint: str, line: int) -> Finding:
+    return Finding(fingerprint, "src/a.py", 1, 10, line, 1.0, ("b.py:1-10",))
+
+
+def test_shifted_clone_updates_its_comment():
+    earlier = _finding("clone-ab", 3)
+    shifted = Finding("clone-ab", "src/a.py", 2, 11, 4, 1.0, ("b.py:1-10",))
+    client = FakeClient([], [{"id": 1, "body": comment_body(earlier)}])
+
+    counts = GitHubPublisher(client, "acme/shop", 7).publish([shifted])
+
+    assert counts == {"created": 0, "updated": 1, "unchanged": 0}
+    assert client.writes == [("PATCH", "/repos/acme/shop/pulls/comments/1", {"body": comment_body(shifted)})]
+
+
+def test_changed_lines_skip_files_without_patch():
+    client = FakeClient(
+        [{"filename": "src/a.py", "patch": "@@ -1 +1 @@\n-x = 0\n+x = 1"}, {"filename": "logo.png"}], []
+    )
+
+    assert GitHubPublisher(client, "acme/shop", 7).changed_lines() == {"src/a.py": {1}}
+
+
+def test_comments_of_earlier_runs_are_updated_not_repeated():
+    kept, moved, new = _finding("clone-kept", 3), _finding("clone-moved", 4), _finding("clone-new", 5)
+    stale = Finding("clone-moved", "src/a.py", 1, 10, 4, 0.9, ("old.py:1-10",))
+    comments = [
+        {"id": 1, "body": comment_body(kept)},
+        {"id": 2, "body": comment_body(stale)},
+        {"id": 3, "body": "Please rename this"},
+    ]
+    client = FakeClient([], comments)
+
+    counts = GitHubPublisher(client, "acme/shop", 7).publish([kept, moved, new])
+
+    assert counts == {"created": 1, "updated": 1, "unchanged": 1}
+    assert client.writes == [
+        ("PATCH", "/repos/acme/shop/pulls/comments/2", {"body": comment_body(moved)}),
+        (
+            "POST",
+            "/repos/acme/shop/pulls/7/comments",
+            {"body": comment_body(new), "commit_id": "abc123", "path": "src/a.py", "line": 5, "side": "RIGHT"},
+        ),
+    ]
@@ -0,0 +1,77 @@
+from treepeat.publish import Finding, comment_body
+from treepeat.publish.gitlab import GitLabPublisher
+
+MERGE_REQUEST = "/projects/acme%2Fshop/merge_requests/7"
+REFS = {"base_sha": "base", "start_sha": "start", "head_sha": "head"}
+
+
+class FakeClient:
+    """Answers GitLab API calls from canned data and records the writes."""
+
+    def __init__(self, diffs, discussions):
+        self.diffs = diffs
+        self.discussions = discussions
+        self.writes = []
+
+    def paginate(self, path, params=None):
+        return self.diffs if path.endswith("/diffs") else self.discussions
+
+    def call(self, method, path, body=None, params=None):
+        if method == "GET":
+            return {"diff_refs": REFS}
+        self.writes.append((method, path, body, params))
+        return {}
+
+
+def _finding(fingerprint: str, line: int) -> Finding:
+    return Finding(fingerprint, "src/a.py", 1, 10, line, 1.0, ("b.py:1-10",))
+
+
+def _discussion(discussion_id: str, body: str, resolved: bool = False) -> dict:
+    return {"id": discussion_id, "notes": [{"id": 1, "body": body, "resolved": resolved}]}
+
+
+def test_moved_clone_updates_its_discussion():
+    earlier = _finding("clone-ab", 3)
+    moved = Finding("clone-ab", "src/a.py", 6, 15, 7, 1.0, ("b.py:1-10",))
+    client = FakeClient([], [_discussion("d1", comment_body(earlier))])
+
+    counts = GitLabPublisher(client, "acme/shop", 7).publish([moved])
+
+    assert counts == {"created": 0, "updated": 1, "reopened": 0, "unchanged": 0, "resolved": 0}
+    assert client.writes == [("PUT", f"{MERGE_REQUEST}/discussions/d1/notes/1", {"body": comment_body(moved)}, None)]
+
+
+def test_changed_lines_skip_deleted_files():
+    diffs = [
+        {"new_path": "src/a.py", "diff": "@@ -1 +1,2 @@\n-x = 0\n+x = 1\n+y = 2"},
+        {"new_path": "old.py", "diff": "@@ -1 +0,0 @@\n-x = 0", "deleted_file": True},
+    ]
+
+    assert GitLabPublisher(FakeClient(diffs, []), "acme/shop", 7).changed_lines() == {"src/a.py": {1, 2}}
+
+
+def test_discussions_follow_the_clones():
+    kept, moved = _finding("clone-kept", 4), _finding("clone-moved", 1)
+    back, new = _finding("clone-back", 2), _finding("clone-new", 3)
+    stale = Finding("clone-moved", "src/a.py", 1, 10, 1, 0.9, ("old.py:1-10",))
+    gone = _finding("clone-gone", 9)
+    discussions = [
+        _discussion("d1", comment_body(kept)),
+        _discussion("d2", comment_body(stale)),
+        _discussion("d3", comment_body(back), resolved=True),
+        _discussion("d4", comment_body(gone)),
+        _discussion("d5", "Please rename this"),
+    ]
+    client = FakeClient([], discussions)
+
+    counts = GitLabPublisher(client, "acme/shop", 7).publish([kept, moved, back, new])
+
+    assert counts == {"created": 1, "updated": 1, "reopened": 1, "unchanged": 1, "resolved": 1}
+    position = {"position_type": "text", **REFS, "old_path": "src/a.py", "new_path": "src/a.py", "new_line": 3}
+    assert client.writes == [
+        ("PUT", f"{MERGE_REQUEST}/discussions/d2/notes/1", {"body": comment_body(moved)}, None),
+        ("PUT", f"{MERGE_REQUEST}/discussions/d3", None, {"resolved": "false"}),
+        ("POST", f"{MERGE_REQUEST}/discussions", {"body": comment_body(new), "position": position}, None),
+        ("PUT", f"{MERGE_REQUEST}/discussions/d4", None, {"resolved": "true"}),
+    ]
@@ -0,0 +1,67 @@
+import json
+import threading
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+
+import pytest
+
+from treepeat.publish import PublishError
+from treepeat.publish.http import ApiClient
+
+
+class _Api(BaseHTTPRequestHandler):
+    """Two pages of items, a first request answered 429, a Gerrit style endpoint and a 404 endpoint."""
+
+    throttled = False
+
+    def _send(self, status, payload, headers=(), prefix=b""):
+        body = prefix + json.dumps(payload).encode("utf-8")
+        self.send_response(status)
+        for name, value in headers:
+            self.send_header(name, value)
+        self.send_header("Content-Length", str(len(body)))
+        self.end_headers()
+        self.wfile.write(body)
+
+    def do_GET(self):
+        if not _Api.throttled:
+            _Api.throttled = True
+            self._send(429, {"message": "slow down"}, [("Retry-After", "0")])
+        elif self.path.startswith("/items?page=2"):
+            self._send(200, [3])
+        elif self.path == "/gerrit":
+            self._send(200, {"ok": True}, prefix=b")]}'\n")
+        elif self.path.startswith("/items"):
+            link = f'<http://127.0.0.1:{self.server.server_port}/items?page=2>; rel="next"'
+            self._send(200, [1, 2], [("Link", link)])
+        else:
+            self._send(404, {"message": "Not Found"})
+
+    def log_message(self, format, *args):
+        pass
+
+
+@pytest.fixture
+def api():
+    _Api.throttled = False
+    server = ThreadingHTTPServer(("127.0.0.1", 0), _Api)
+    threading.Thread(target=server.serve_forever, daemon=True).start()
+    yield ApiClient(f"http://127.0.0.1:{server.server_port}", {"Authorization": "Bearer t"})
+    server.shutdown()
+    server.server_close()
+
+
+def test_paginate_follows_links_after_waiting_out_the_rate_limit(api):
+    assert api.paginate("/items", {"per_page": 2}) == [1, 2, 3]
+
+
+def test_errors_name_the_status(api):
+    api.paginate("/items")
+
+    with pytest.raises(PublishError, match="404"):
+        api.call("GET", "/missing")
+
+
+def test_gerrit_xssi_prefix_is_dropped(api):
+    api.paginate("/items")
+
+    assert api.call("GET", "/gerrit") == {"ok": True}
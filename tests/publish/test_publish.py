@@ -0,0 +1,89 @@
+from treepeat.publish import added_lines, changed_findings, comment_body, comment_key, diff_changed_lines
+
+PATCH = """\
+@@ -1,4 +1,5 @@
+ def total(items):
+-    result = 0
++    result = 1
++    count = 0
+     for item in items:
+@@ -20,2 +21,3 @@ def other():
+     pass
++    return None
+\\ No newline at end of file
+"""
+
+DIFF = """\
+diff --git a/src/a.py b/src/a.py
+index 1111111..2222222 100644
+--- a/src/a.py
++++ b/src/a.py
+@@ -2 +2,2 @@ def total(items):
+-    result = 0
++    result = 1
++    count = 0
+diff --git a/old.py b/old.py
+deleted file mode 100644
+--- a/old.py
++++ /dev/null
+@@ -1 +0,0 @@
+-x = 0
+diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+"""
+
+
+def _report(root) -> dict:
+    def copy(name, start, end):
+        return {"path": str(root / name), "start_line": start, "end_line": end}
+
+    return {
+        "clone_classes": [
+            {"fingerprint": "clone-ab", "similarity": 0.9, "instances": [copy("src/a.py", 1, 10), copy("b.py", 1, 9)]},
+            {"fingerprint": "clone-cd", "similarity": 1.0, "instances": [copy("src/a.py", 40, 50), copy("c.py", 1, 9)]},
+        ]
+    }
+
+
+def test_added_lines_follow_hunk_headers():
+    assert added_lines(PATCH) == {2, 3, 22}
+
+
+def test_diff_changed_lines_leave_out_deleted_files():
+    assert diff_changed_lines(DIFF) == {"src/a.py": {2, 3}}
+
+
+def test_findings_are_the_copies_touching_changed_lines(tmp_path):
+    root = tmp_path.resolve()
+
+    [finding] = changed_findings(_report(root), {"src/a.py": {3, 30}}, root)
+
+    assert (finding.fingerprint, finding.path, finding.line) == ("clone-ab", "src/a.py", 3)
+    assert finding.others == ("b.py:1-9",)
+
+
+def test_comment_markers_identify_the_finding(tmp_path):
+    root = tmp_path.resolve()
+    [finding] = changed_findings(_report(root), {"c.py": {5}}, root)
+
+    body = comment_body(finding)
+
+    assert "`src/a.py:40-50`" in body
+    assert comment_key(body) == finding.key
+    assert comment_key("LGTM") is None
+
+
+def test_keys_survive_shifted_lines_and_tell_copies_in_a_file_apart(tmp_path):
+    root = tmp_path.resolve()
+    report = _report(root)
+    first = changed_findings(report, {"src/a.py": {3}}, root)[0].key
+    for instance in report["clone_classes"][0]["instances"]:
+        instance["start_line"] += 1
+        instance["end_line"] += 1
+    report["clone_classes"][0]["instances"].append({"path": str(root / "src/a.py"), "start_line": 20, "end_line": 29})
+
+    shifted, second_copy = changed_findings(report, {"src/a.py": {3, 25}}, root)
+
+    assert shifted.key == first == "clone-ab:src/a.py"
+    assert second_copy.key == "clone-ab:src/a.py:1"
@@ -0,0 +1,81 @@
+from pathlib import Path
+
+from treepeat.annotate import annotate_sources, is_annotation, remove_annotations
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+
+GO_SOURCE = "package main\n\nfunc Sum(values []int) int {\n\treturn 0\n}\n"
+PY_SOURCE = "class Totals:\n    def total(self):\n        return 0\n"
+
+
+def _region(path: Path, language: str, start_line: int, end_line: int) -> Region:
+    return Region(
+        path=path,
+        language=language,
+        region_type="function",
+        region_name="total",
+        start_line=start_line,
+        end_line=end_line,
+    )
+
+
+def _result(go_path: Path, py_path: Path, go_line: int, py_line: int) -> SimilarityResult:
+    group = SimilarRegionGroup(
+        regions=[_region(go_path, "go", go_line, go_line + 2), _region(py_path, "python", py_line, py_line + 1)],
+        similarity=1.0,
+        fingerprint="clone-1a2b3c4d",
+    )
+    return SimilarityResult(similar_groups=[group])
+
+
+def _write_sources(tmp_path: Path) -> tuple[Path, Path]:
+    go_path = tmp_path / "sum.go"
+    py_path = tmp_path / "totals.py"
+    go_path.write_text(GO_SOURCE)
+    py_path.write_text(PY_SOURCE)
+    return go_path, py_path
+
+
+def test_annotate_inserts_language_comments(tmp_path):
+    go_path, py_path = _write_sources(tmp_path)
+
+    annotate_sources(_result(go_path, py_path, 3, 2))
+
+    go_lines = go_path.read_text().splitlines()
+    py_lines = py_path.read_text().splitlines()
+    assert go_lines[2] == f"// treepeat: clone of clone-1a2b3c4d (also in {py_path}:3)"
+    assert py_lines[1] == f"    # treepeat: clone of clone-1a2b3c4d (also in {go_path}:4)"
+
+
+def test_annotate_is_idempotent(tmp_path):
+    go_path, py_path = _write_sources(tmp_path)
+    annotate_sources(_result(go_path, py_path, 3, 2))
+    annotated = go_path.read_text(), py_path.read_text()
+
+    # A second run reports the regions at their shifted lines
+    assert annotate_sources(_result(go_path, py_path, 4, 3)) == []
+    assert (go_path.read_text(), py_path.read_text()) == annotated
+
+
+def test_annotate_dry_run_leaves_files_untouched(tmp_path):
+    go_path, py_path = _write_sources(tmp_path)
+
+    diffs = annotate_sources(_result(go_path, py_path, 3, 2), dry_run=True)
+
+    assert len(diffs) == 2
+    assert "+// treepeat: clone of clone-1a2b3c4d" in diffs[0]
+    assert go_path.read_text() == GO_SOURCE
+
+
+def test_remove_annotations_restores_sources(tmp_path):
+    go_path, py_path = _write_sources(tmp_path)
+    annotate_sources(_result(go_path, py_path, 3, 2))
+
+    remove_annotations([go_path, py_path])
+
+    assert go_path.read_text() == GO_SOURCE
+    assert py_path.read_text() == PY_SOURCE
+
+
+def test_is_annotation_only_matches_markers():
+    assert is_annotation("  -- treepeat: clone of clone-1a2b3c4d (also in a.sql:1)")
+    assert not is_annotation("# treepeat is great")
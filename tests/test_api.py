@@ -0,0 +1,72 @@
+from pathlib import Path
+
+import pytest
+
+import treepeat
+from treepeat.config import LSHSettings, get_settings
+from treepeat.models.similarity import SimilarityResult
+
+MODULE = """\
+def total(items):
+    result = 0
+    for item in items:
+        if item > 0:
+            result = result + item
+    return result
+"""
+
+
+@pytest.fixture
+def source(tmp_path):
+    for name in ("a.py", "b.py", "c.py"):
+        (tmp_path / name).write_text(MODULE)
+    return tmp_path
+
+
+def _settings():
+    return treepeat.PipelineSettings(lsh=LSHSettings(similarity_percent=1.0, min_lines=3))
+
+
+def test_scan_finds_clones(source):
+    streamed = []
+
+    result = treepeat.scan(source, _settings(), on_group=streamed.append)
+
+    assert [sorted(r.path.name for r in g.regions) for g in result.similar_groups] == [["a.py", "b.py", "c.py"]]
+    assert streamed == result.similar_groups
+
+
+def test_scan_restores_the_current_settings(source):
+    current = get_settings()
+
+    treepeat.scan(source, _settings())
+
+    assert get_settings() is current
+
+
+def test_custom_walker_chooses_the_files(source):
+    class OnlyTwo:
+        def walk(self, root: Path) -> list[Path]:
+            return [root / "a.py", root / "b.py"]
+
+    result = treepeat.scan(source, _settings(), walker=OnlyTwo())
+
+    assert [sorted(r.path.name for r in g.regions) for g in result.similar_groups] == [["a.py", "b.py"]]
+
+
+def test_custom_detector_and_reporter(source):
+    class Nothing:
+        def detect(self, root, files, on_group):
+            return SimilarityResult()
+
+    result = treepeat.scan(source, _settings(), detector=Nothing())
+
+    assert result.similar_groups == []
+    assert '"clone_classes": []' in treepeat.FormatReporter("json").report(result)
+
+
+def test_scan_errors():
+    with pytest.raises(treepeat.ScanError):
+        treepeat.scan("/does/not/exist")
+    with pytest.raises(ValueError):
+        treepeat.FormatReporter("console")
@@ -0,0 +1,66 @@
+import json
+from pathlib import Path
+
+import pytest
+
+from treepeat.baseline import load_baseline, record_accepted
+from treepeat.formatters.json import format_as_json
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+from treepeat.pipeline.fingerprint import exclude_groups
+
+
+def _group(fingerprint: str) -> SimilarRegionGroup:
+    regions = [
+        Region(path=Path(name), language="python", region_type="function", region_name="f", start_line=1, end_line=5)
+        for name in ("a.py", "b.py")
+    ]
+    return SimilarRegionGroup(regions=regions, similarity=1.0, fingerprint=fingerprint)
+
+
+def test_baseline_round_trips_a_json_report(tmp_path):
+    baseline = tmp_path / ".treepeat-baseline.json"
+    baseline.write_text(format_as_json(SimilarityResult(similar_groups=[_group("clone-aaaa0001")])))
+
+    assert load_baseline(baseline) == {"clone-aaaa0001"}
+
+
+def test_baseline_hides_known_clones_only(tmp_path):
+    baseline = tmp_path / ".treepeat-baseline.json"
+    baseline.write_text(format_as_json(SimilarityResult(similar_groups=[_group("clone-aaaa0001")])))
+    result = SimilarityResult(similar_groups=[_group("clone-aaaa0001"), _group("clone-bbbb0002")])
+
+    new, _ = exclude_groups(result, load_baseline(baseline))
+
+    assert [group.fingerprint for group in new.similar_groups] == ["clone-bbbb0002"]
+
+
+@pytest.mark.parametrize("content", ["not json", "{}", '{"clone_classes": [{"path": "a.py"}]}'])
+def test_invalid_baseline_is_rejected(tmp_path, content):
+    baseline = tmp_path / "baseline.json"
+    baseline.write_text(content)
+
+    with pytest.raises(ValueError, match="not a treepeat json report"):
+        load_baseline(baseline)
+
+
+def _clone_classes(*fingerprints: str) -> list[dict]:
+    result = SimilarityResult(similar_groups=[_group(fingerprint) for fingerprint in fingerprints])
+    return json.loads(format_as_json(result))["clone_classes"]
+
+
+def test_record_accepted_creates_a_baseline(tmp_path):
+    baseline = tmp_path / ".treepeat-baseline.json"
+
+    count = record_accepted(baseline, _clone_classes("clone-aaaa0001", "clone-bbbb0002"), {"clone-bbbb0002"})
+
+    assert count == 1
+    assert load_baseline(baseline) == {"clone-bbbb0002"}
+
+
+def test_record_accepted_keeps_entries_that_were_not_reviewed(tmp_path):
+    baseline = tmp_path / ".treepeat-baseline.json"
+    record_accepted(baseline, _clone_classes("clone-aaaa0001", "clone-bbbb0002"), {"clone-aaaa0001", "clone-bbbb0002"})
+
+    record_accepted(baseline, _clone_classes("clone-bbbb0002", "clone-cccc0003"), {"clone-cccc0003"})
+
+    assert load_baseline(baseline) == {"clone-aaaa0001", "clone-cccc0003"}
@@ -0,0 +1,42 @@
+from treepeat.bench import BenchRun, _median_run, bench_once, language_patterns, scanned_languages
+from treepeat.config import PipelineSettings
+
+MODULE = """\
+def total(items):
+    result = 0
+    for item in items:
+        if item > 0:
+            result = result + item
+    return result
+"""
+
+
+def _run(parse_seconds: float, peak_rss: int | None) -> BenchRun:
+    return BenchRun("python", "shingle", 4, 2**20, parse_seconds, 1.0, 2.0, peak_rss)
+
+
+def test_language_patterns():
+    assert "*.py" in language_patterns("python")
+    assert "Dockerfile" in language_patterns("dockerfile")
+
+
+def test_median_run():
+    median = _median_run([_run(1.0, 10), _run(3.0, None), _run(2.0, 30)])
+
+    assert median.parse_seconds == 2.0
+    assert median.peak_rss == 30
+    assert median.files_per_second == 2.0
+    assert median.mb_per_second == 0.5
+
+
+def test_bench_once_measures_one_language(tmp_path):
+    (tmp_path / "a.py").write_text(MODULE)
+    (tmp_path / "b.py").write_text(MODULE)
+    (tmp_path / "main.go").write_text("package main\n\nfunc main() {}\n")
+
+    assert scanned_languages(tmp_path, PipelineSettings()) == {"python": 2, "go": 1}
+    run = bench_once(tmp_path, PipelineSettings(), "python")
+
+    assert (run.language, run.strategy, run.files) == ("python", "shingle", 2)
+    assert run.source_bytes == 2 * len(MODULE)
+    assert run.parse_seconds >= 0
@@ -0,0 +1,48 @@
+import pytest
+
+from treepeat.config_file import ConfigFileError, find_config_file, load_config_file
+
+
+def test_nearest_config_file_is_found_up_to_the_repository_root(tmp_path):
+    repo = tmp_path / "repo"
+    nested = repo / "src" / "pkg"
+    nested.mkdir(parents=True)
+    (repo / ".git").mkdir()
+    (repo / ".treepeat.toml").write_text("[detect]\nmin-lines = 10\n")
+    (tmp_path / ".treepeat.toml").write_text("[detect]\nmin-lines = 3\n")
+
+    assert find_config_file(nested) == repo / ".treepeat.toml"
+
+
+def test_search_stops_at_the_repository_root(tmp_path):
+    repo = tmp_path / "repo"
+    (repo / ".git").mkdir(parents=True)
+    (tmp_path / ".treepeat.toml").write_text("[detect]\nmin-lines = 3\n")
+
+    assert find_config_file(repo) is None
+
+
+def test_pyproject_needs_a_tool_treepeat_table(tmp_path):
+    (tmp_path / ".git").mkdir()
+    (tmp_path / "pyproject.toml").write_text('[project]\nname = "demo"\n')
+    assert find_config_file(tmp_path) is None
+
+    (tmp_path / "pyproject.toml").write_text('[project]\nname = "demo"\n\n[tool.treepeat.detect]\nmin-lines = 8\n')
+    assert find_config_file(tmp_path) == tmp_path / "pyproject.toml"
+    assert load_config_file(tmp_path / "pyproject.toml") == {"detect": {"min-lines": 8}}
+
+
+def test_dedicated_config_file_wins_over_pyproject(tmp_path):
+    (tmp_path / "pyproject.toml").write_text("[tool.treepeat]\nruleset = 'loose'\n")
+    (tmp_path / "treepeat.toml").write_text("ruleset = 'none'\n")
+
+    assert find_config_file(tmp_path) == tmp_path / "treepeat.toml"
+    assert load_config_file(tmp_path / "treepeat.toml") == {"ruleset": "none"}
+
+
+def test_invalid_toml_is_rejected(tmp_path):
+    config = tmp_path / ".treepeat.toml"
+    config.write_text("[detect\n")
+
+    with pytest.raises(ConfigFileError):
+        load_config_file(config)
@@ -66,3 +66,11 @@ class TestDisplayGroupSpecialCharPaths:
         output = _capture_display_group(group, monkeypatch)
 
         assert path in output
+
+
+def test_display_group_shows_fingerprint(monkeypatch):
+    group = SimilarRegionGroup(
+        regions=[_make_region("a.ts"), _make_region("b.ts")], similarity=1.0, fingerprint="clone-1a2b3c4d"
+    )
+
+    assert "clone-1a2b3c4d" in _capture_display_group(group, monkeypatch)
@@ -0,0 +1,61 @@
+import pytest
+
+from treepeat.config import LanguageThresholds, LSHSettings, PipelineSettings, ShingleSettings
+from treepeat.explain import CloneNotFoundError, differing_tokens, find_clone_class, match_criteria
+
+
+def _clone(fingerprint: str, *languages: str) -> dict:
+    return {
+        "fingerprint": fingerprint,
+        "similarity": 0.93,
+        "instances": [
+            {"path": f"a.{language}", "language": language, "region_type": "function", "start_line": 1, "end_line": 9}
+            for language in languages
+        ],
+    }
+
+
+CLONES = [_clone("3f9a2c1b", "python"), _clone("3f11d0e4", "python"), _clone("", "python")]
+
+
+def test_find_clone_class_by_fingerprint_or_unique_prefix():
+    assert find_clone_class(CLONES, "3f9a2c1b") is CLONES[0]
+    assert find_clone_class(CLONES, "3f1") is CLONES[1]
+
+
+def test_find_clone_class_by_csv_group_number():
+    assert find_clone_class(CLONES, "group-3") is CLONES[2]
+
+
+@pytest.mark.parametrize("clone_id", ["3f", "ffff", "group-4"])
+def test_find_clone_class_rejects_ambiguous_or_unknown_ids(clone_id):
+    with pytest.raises(CloneNotFoundError):
+        find_clone_class(CLONES, clone_id)
+
+
+def test_differing_tokens_lists_only_the_changed_runs():
+    left = ["def total(items):", "    return sum(items) + 1"]
+    right = ["def count(values):", "    return sum(values) + 1"]
+
+    assert differing_tokens(left, right) == [("total", "count"), ("items", "values"), ("items", "values")]
+
+
+def test_differing_tokens_of_identical_copies_is_empty():
+    lines = ["x = 1"]
+
+    assert differing_tokens(lines, lines) == []
+
+
+def test_match_criteria_reports_the_thresholds_of_each_language():
+    settings = PipelineSettings(
+        shingle=ShingleSettings(strategy="winnow", k=3, winnow_window=4),
+        lsh=LSHSettings(min_lines=5, language_thresholds={"go": LanguageThresholds(min_similarity=0.95)}),
+    )
+
+    criteria = dict(match_criteria(_clone("3f9a2c1b", "python", "go"), settings))
+
+    assert criteria["Matched regions"] == "function"
+    assert criteria["Strategy"] == "winnow (k=3, window 4)"
+    assert criteria["Thresholds (go)"] == "similarity >= 95%, at least 5 line(s) and 0 token(s) per copy"
+    assert criteria["Thresholds (python)"].startswith("similarity >= 80%")
+    assert criteria["Similarity"] == "93.0%"
@@ -0,0 +1,62 @@
+import pytest
+
+from treepeat.grpc_service import PROTO_PATH, SERVICE_NAME, start_grpc_server
+from treepeat.server import CloneIndex
+
+# The gRPC API is an optional extra
+grpc = pytest.importorskip("grpc")
+pytest.importorskip("grpc_tools")
+
+
+def _report(*paths: str) -> dict:
+    instance = {"language": "python", "region_type": "function", "region_name": "total", "start_line": 1, "end_line": 9}
+    instances = [{"path": path, **instance} for path in paths]
+    return {"clone_classes": [{"fingerprint": "clone-ab", "similarity": 1.0, "instances": instances}]}
+
+
+@pytest.fixture
+def served(tmp_path):
+    root = tmp_path.resolve()
+    a, b = str(root / "a.py"), str(root / "b.py")
+    index = CloneIndex([root], lambda scanned, changed: _report(a, b))
+    index.rescan(None, None)
+    server, port = start_grpc_server(index, "127.0.0.1", 0)
+    channel = grpc.insecure_channel(f"127.0.0.1:{port}")
+    yield root, channel
+    channel.close()
+    server.stop(None)
+
+
+def _call(channel, method, request_type, response_type, streaming):
+    messages = grpc.protos(PROTO_PATH)
+    path = f"/{SERVICE_NAME}/{method}"
+    call = channel.unary_stream if streaming else channel.unary_unary
+    return call(
+        path,
+        request_serializer=getattr(messages, request_type).SerializeToString,
+        response_deserializer=getattr(messages, response_type).FromString,
+    ), messages
+
+
+def test_scan_streams_findings_then_progress(served):
+    root, channel = served
+    scan, messages = _call(channel, "Scan", "ScanRequest", "ScanEvent", streaming=True)
+
+    events = list(scan(messages.ScanRequest()))
+
+    assert [event.WhichOneof("event") for event in events] == ["clone_class", "progress"]
+    assert events[0].clone_class.fingerprint == "clone-ab"
+    assert (events[1].progress.root, events[1].progress.roots_done, events[1].progress.roots_total) == (str(root), 1, 1)
+
+
+def test_clones_of_answers_from_the_index(served):
+    root, channel = served
+    clones_of, messages = _call(channel, "ClonesOf", "ClonesOfRequest", "CloneClasses", streaming=False)
+
+    found = clones_of(messages.ClonesOfRequest(file=str(root / "a.py"), line=3))
+
+    assert [clone.fingerprint for clone in found.clone_classes] == ["clone-ab"]
+    assert [instance.path for instance in found.clone_classes[0].instances] == [str(root / "a.py"), str(root / "b.py")]
+    with pytest.raises(grpc.RpcError) as error:
+        clones_of(messages.ClonesOfRequest(file="/elsewhere/x.py"))
+    assert error.value.code() == grpc.StatusCode.NOT_FOUND
@@ -0,0 +1,72 @@
+from datetime import datetime, timezone
+
+import pytest
+
+from treepeat.history import HistoryError, record_run, recorded_runs
+
+
+def _report(root, lines: int, duplicated: int, clone_classes: int) -> dict:
+    """A json report of ``root`` whose duplication is all in its api directory."""
+    return {
+        "summary": {"clone_classes": clone_classes, "clone_instances": 2 * clone_classes},
+        "duplication": {
+            "lines": lines,
+            "duplicated_lines": duplicated,
+            "percent": 100.0 * duplicated / lines,
+            "by_directory": [
+                {"path": str(root / "api"), "lines": lines // 2, "duplicated_lines": duplicated},
+                {"path": str(root / "web"), "lines": lines // 2, "duplicated_lines": 0},
+            ],
+        },
+    }
+
+
+def _at(month: int) -> datetime:
+    return datetime(2026, month, 1, tzinfo=timezone.utc)
+
+
+def test_recorded_runs_follow_the_whole_path_oldest_first(tmp_path):
+    history = tmp_path / "history.sqlite"
+    record_run(history, _report(tmp_path, 1000, 100, 4), tmp_path, "c0ffee", _at(4))
+    record_run(history, _report(tmp_path, 1000, 200, 7), tmp_path, "beef", _at(1))
+
+    runs = recorded_runs(history)
+
+    assert [run.commit for run in runs] == ["beef", "c0ffee"]
+    assert [run.percent for run in runs] == [20.0, 10.0]
+    assert runs[0].clone_classes == 7
+    assert runs[0].recorded_at == _at(1)
+
+
+def test_recorded_runs_follow_a_directory_relative_to_the_scanned_path(tmp_path):
+    history = tmp_path / "history.sqlite"
+    record_run(history, _report(tmp_path, 1000, 100, 4), tmp_path, None, _at(1))
+
+    (api,) = recorded_runs(history, "api/")
+    (web,) = recorded_runs(history, "./web")
+
+    assert (api.lines, api.duplicated_lines, api.percent) == (500, 100, 20.0)
+    assert web.percent == 0.0
+    assert recorded_runs(history, "docs") == []
+
+
+def test_record_run_returns_increasing_ids(tmp_path):
+    history = tmp_path / "history.sqlite"
+
+    first = record_run(history, _report(tmp_path, 10, 1, 1), tmp_path, None, _at(1))
+    second = record_run(history, _report(tmp_path, 10, 1, 1), tmp_path, None, _at(2))
+
+    assert second > first
+
+
+def test_recorded_runs_of_a_missing_history(tmp_path):
+    with pytest.raises(HistoryError, match="does not exist"):
+        recorded_runs(tmp_path / "missing.sqlite")
+
+
+def test_history_that_is_not_a_database(tmp_path):
+    history = tmp_path / "history.sqlite"
+    history.write_text("not sqlite " * 100)
+
+    with pytest.raises(HistoryError, match="not a treepeat history"):
+        record_run(history, _report(tmp_path, 10, 1, 1), tmp_path, None, _at(1))
@@ -0,0 +1,60 @@
+from pathlib import Path
+
+from treepeat.hotspots import hotspot_to_dict, rank_hotspots
+
+
+def _instance(path: Path, lines: int) -> dict:
+    return {"path": str(path), "start_line": 1, "end_line": lines, "lines": lines}
+
+
+def _clone(fingerprint: str, *instances: dict) -> dict:
+    return {"fingerprint": fingerprint, "similarity": 1.0, "instances": list(instances)}
+
+
+def test_rank_hotspots_ranks_by_lines_times_commits(tmp_path):
+    busy, quiet = tmp_path / "busy.py", tmp_path / "quiet.py"
+    churn = {busy: 10, quiet: 1}
+    small_busy = _clone("clone-small", _instance(busy, 5), _instance(busy, 5))
+    large_quiet = _clone("clone-large", _instance(quiet, 30), _instance(quiet, 30))
+
+    ranked = rank_hotspots([large_quiet, small_busy], churn)
+
+    assert [hotspot.clone["fingerprint"] for hotspot in ranked] == ["clone-small", "clone-large"]
+    assert ranked[0].score == 100
+    assert ranked[1].score == 60
+
+
+def test_rank_hotspots_puts_the_most_changed_copy_first(tmp_path):
+    a, b = tmp_path / "a.py", tmp_path / "b.py"
+    clone = _clone("clone-1", _instance(a, 5), _instance(b, 5))
+
+    (hotspot,) = rank_hotspots([clone], {a: 1, b: 4})
+
+    assert [copy.instance["path"] for copy in hotspot.copies] == [str(b), str(a)]
+    assert hotspot.commits == 5
+
+
+def test_rank_hotspots_counts_the_commits_of_a_file_once(tmp_path):
+    a = tmp_path / "a.py"
+    clone = _clone("clone-1", _instance(a, 5), _instance(a, 8))
+
+    (hotspot,) = rank_hotspots([clone], {a: 3})
+
+    assert hotspot.commits == 3
+    assert hotspot.score == 39
+
+
+def test_rank_hotspots_leaves_out_clones_in_unchanged_files(tmp_path):
+    clone = _clone("clone-1", _instance(tmp_path / "a.py", 5), _instance(tmp_path / "b.py", 5))
+
+    assert rank_hotspots([clone], {}) == []
+
+
+def test_hotspot_to_dict_adds_commits_and_scores(tmp_path):
+    a = tmp_path / "a.py"
+    (hotspot,) = rank_hotspots([_clone("clone-1", _instance(a, 5), _instance(a, 5))], {a: 2})
+
+    document = hotspot_to_dict(hotspot)
+
+    assert (document["fingerprint"], document["commits"], document["score"]) == ("clone-1", 2, 20)
+    assert document["instances"][0] == {**_instance(a, 5), "commits": 2, "score": 10}
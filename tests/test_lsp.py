@@ -0,0 +1,119 @@
+import io
+
+from treepeat.lsp import SHOW_COPY_COMMAND, code_actions, read_message, report_diagnostics, serve, write_message
+
+
+def _report(tmp_path, *paths: str) -> dict:
+    return {
+        "clone_classes": [
+            {
+                "fingerprint": "clone-ab000001",
+                "similarity": 0.95,
+                "instances": [
+                    {"path": str(tmp_path / path), "region_name": "total", "start_line": 3, "end_line": 9}
+                    for path in paths
+                ],
+            }
+        ]
+    }
+
+
+def _frame(*messages: dict) -> io.BytesIO:
+    stream = io.BytesIO()
+    for message in messages:
+        write_message(stream, message)
+    stream.seek(0)
+    return stream
+
+
+def _read_all(stream: io.BytesIO) -> list[dict]:
+    stream.seek(0)
+    messages = []
+    while (message := read_message(stream)) is not None:
+        messages.append(message)
+    return messages
+
+
+def test_messages_round_trip_through_content_length_framing():
+    message = {"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"name": "café"}}
+
+    assert _read_all(_frame(message, message)) == [message, message]
+
+
+def test_each_copy_gets_a_diagnostic_related_to_the_others(tmp_path):
+    diagnostics = report_diagnostics(_report(tmp_path, "a.py", "b.py", "c.py"))
+
+    assert sorted(diagnostics) == sorted((tmp_path / name).as_uri() for name in ("a.py", "b.py", "c.py"))
+    [diagnostic] = diagnostics[(tmp_path / "a.py").as_uri()]
+    assert diagnostic["range"] == {"start": {"line": 2, "character": 0}, "end": {"line": 9, "character": 0}}
+    assert diagnostic["code"] == "clone-ab000001"
+    assert [r["location"]["uri"] for r in diagnostic["relatedInformation"]] == [
+        (tmp_path / "b.py").as_uri(),
+        (tmp_path / "c.py").as_uri(),
+    ]
+
+
+def test_code_actions_jump_to_each_other_copy(tmp_path):
+    [diagnostic] = report_diagnostics(_report(tmp_path, "a.py", "b.py"))[(tmp_path / "a.py").as_uri()]
+    foreign = {"source": "pyright", "message": "unused", "relatedInformation": diagnostic["relatedInformation"]}
+
+    [action] = code_actions([diagnostic, foreign])
+
+    assert action["title"] == "Go to copy b.py:3-9 (total)"
+    assert action["command"]["command"] == SHOW_COPY_COMMAND
+    assert action["command"]["arguments"][0]["uri"] == (tmp_path / "b.py").as_uri()
+
+
+def test_session_publishes_rescans_and_clears_fixed_files(tmp_path):
+    reports = [_report(tmp_path, "a.py", "b.py"), _report(tmp_path, "a.py", "c.py")]
+    scanned = []
+
+    def scan(root):
+        scanned.append(root)
+        return reports[len(scanned) - 1]
+
+    output = io.BytesIO()
+    serve(
+        _frame(
+            {"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"rootUri": tmp_path.as_uri()}},
+            {"jsonrpc": "2.0", "method": "initialized", "params": {}},
+            {"jsonrpc": "2.0", "method": "textDocument/didSave", "params": {}},
+            {"jsonrpc": "2.0", "id": 2, "method": "textDocument/hover", "params": {}},
+            {"jsonrpc": "2.0", "id": 3, "method": "shutdown"},
+            {"jsonrpc": "2.0", "method": "exit"},
+        ),
+        output,
+        scan,
+    )
+    messages = _read_all(output)
+
+    assert scanned == [tmp_path, tmp_path]
+    assert messages[0]["id"] == 1 and messages[0]["result"]["capabilities"]["codeActionProvider"]
+    published = [(m["params"]["uri"], len(m["params"]["diagnostics"])) for m in messages if "params" in m]
+    b, c = (tmp_path / "b.py").as_uri(), (tmp_path / "c.py").as_uri()
+    assert published[2:] == [(b, 0), ((tmp_path / "a.py").as_uri(), 1), (c, 1)]
+    assert messages[-2]["error"]["code"] == -32601
+    assert messages[-1] == {"jsonrpc": "2.0", "id": 3, "result": None}
+
+
+def test_show_copy_command_asks_the_editor_to_open_the_copy(tmp_path):
+    location = {"uri": (tmp_path / "b.py").as_uri(), "range": {"start": {"line": 2, "character": 0}}}
+    output = io.BytesIO()
+
+    serve(
+        _frame(
+            {
+                "jsonrpc": "2.0",
+                "id": 1,
+                "method": "workspace/executeCommand",
+                "params": {"command": SHOW_COPY_COMMAND, "arguments": [location]},
+            }
+        ),
+        output,
+        lambda root: {"clone_classes": []},
+    )
+    show, response = _read_all(output)
+
+    assert show["method"] == "window/showDocument"
+    assert show["params"] == {"uri": location["uri"], "takeFocus": True, "selection": location["range"]}
+    assert response == {"jsonrpc": "2.0", "id": 1, "result": None}
@@ -0,0 +1,127 @@
+import io
+import json
+
+import pytest
+
+from treepeat.mcp import TOOLS, ToolError, clones_of, consolidation, serve, summarize
+
+
+@pytest.fixture
+def project(tmp_path):
+    root = tmp_path.resolve()
+    for name in ("a.py", "b.py", "c.py"):
+        (root / name).write_text("x = 1\n" * 40)
+    return root
+
+
+def _report(root) -> dict:
+    def copy(name, start, end):
+        return {"path": str(root / name), "region_name": "total", "start_line": start, "end_line": end}
+
+    return {
+        "summary": {"files": 3, "clone_classes": 2},
+        "clone_classes": [
+            {"fingerprint": "clone-ab", "similarity": 1.0, "instances": [copy("a.py", 1, 10), copy("b.py", 1, 10)]},
+            {"fingerprint": "clone-ac", "similarity": 0.9, "instances": [copy("a.py", 20, 35), copy("c.py", 5, 20)]},
+        ],
+    }
+
+
+def _session(root, scan, *requests: dict) -> list[dict]:
+    output = io.StringIO()
+    serve(root, io.StringIO("".join(json.dumps(request) + "\n" for request in requests)), output, scan)
+    return [json.loads(line) for line in output.getvalue().splitlines()]
+
+
+def _call(tool: str, arguments: dict, request_id: int) -> dict:
+    params = {"name": tool, "arguments": arguments}
+    return {"jsonrpc": "2.0", "id": request_id, "method": "tools/call", "params": params}
+
+
+def test_clones_of_a_line_range_point_elsewhere(project):
+    found = clones_of(_report(project), project, project / "a.py", 25, 30)
+
+    assert [clone["fingerprint"] for clone in found] == ["clone-ac"]
+    assert found[0]["copy"]["path"] == "a.py"
+    assert found[0]["elsewhere"] == [{"path": "c.py", "region_name": "total", "start_line": 5, "end_line": 20}]
+
+
+def test_summary_lists_the_largest_clone_classes_first(project):
+    summary = summarize(_report(project), project)
+
+    assert summary["files"] == 3
+    assert summary["duplicated_lines"] == 20 + 32
+    assert [clone["fingerprint"] for clone in summary["largest_clone_classes"]] == ["clone-ac", "clone-ab"]
+
+
+def test_session_lists_and_calls_tools(project):
+    scanned = []
+
+    def scan(path, changed):
+        scanned.append((path, changed))
+        return _report(project)
+
+    initialize, listed, whole_file, summary = _session(
+        project,
+        scan,
+        {"jsonrpc": "2.0", "id": 0, "method": "initialize", "params": {}},
+        {"jsonrpc": "2.0", "method": "notifications/initialized"},
+        {"jsonrpc": "2.0", "id": 1, "method": "tools/list"},
+        _call("find_clones_of", {"file": "a.py"}, request_id=2),
+        _call("duplication_summary", {}, request_id=3),
+    )
+
+    assert initialize["result"]["capabilities"] == {"tools": {}}
+    assert [tool["name"] for tool in listed["result"]["tools"]] == [tool["name"] for tool in TOOLS]
+    assert not whole_file["result"]["isError"]
+    clones = json.loads(whole_file["result"]["content"][0]["text"])["clones"]
+    assert [clone["fingerprint"] for clone in clones] == ["clone-ab", "clone-ac"]
+    assert json.loads(summary["result"]["content"][0]["text"])["files"] == 3
+    assert scanned == [(project, {project / "a.py"}), (project, None)]
+
+
+def test_tool_errors_are_reported_to_the_assistant(project):
+    outside, missing, bad_line, unknown = _session(
+        project,
+        lambda path, changed: _report(project),
+        _call("find_clones_of", {"file": "../elsewhere.py"}, request_id=1),
+        _call("find_clones_of", {"file": "missing.py"}, request_id=2),
+        _call("find_clones_of", {"file": "a.py", "start_line": "ten"}, request_id=3),
+        _call("rewrite_everything", {}, request_id=4),
+    )
+
+    assert outside["result"]["isError"] and "outside the project" in outside["result"]["content"][0]["text"]
+    assert missing["result"]["isError"]
+    assert bad_line["result"]["isError"]
+    assert unknown["error"]["code"] == -32602
+
+
+def _instance(path, language="python") -> dict:
+    return {
+        "path": str(path),
+        "language": language,
+        "region_type": "function_definition",
+        "region_name": "load",
+        "start_line": 1,
+        "end_line": 2,
+    }
+
+
+def test_consolidation_suggests_a_function_for_the_copies(project):
+    (project / "d.py").write_text("def load():\n    return read('a.csv')\n")
+    (project / "e.py").write_text("def load():\n    return read('b.csv')\n")
+    clone = {"fingerprint": "clone-de", "instances": [_instance(project / "d.py"), _instance(project / "e.py")]}
+
+    suggestion = consolidation(clone, project)
+
+    assert suggestion["skeleton"] == "def load():\n    return read(__param1__)"
+    assert suggestion["parameters"] == [{"name": "__param1__", "kind": "string", "values": ["'a.csv'", "'b.csv'"]}]
+    assert suggestion["target"] == "."
+    assert [copy["path"] for copy in suggestion["copies"]] == ["d.py", "e.py"]
+
+
+def test_no_consolidation_across_languages(project):
+    clone = {"fingerprint": "clone-ab", "instances": [_instance(project / "a.py"), _instance(project / "b.py", "ruby")]}
+
+    with pytest.raises(ToolError, match="spans languages"):
+        consolidation(clone, project)
@@ -0,0 +1,38 @@
+from pathlib import Path
+
+from treepeat.metrics import ServerMetrics
+from treepeat.pipeline.verbose_metrics import VerboseMetrics
+
+
+def _samples(text: str) -> dict[str, str]:
+    return dict(line.rsplit(" ", 1) for line in text.splitlines() if not line.startswith("#"))
+
+
+def test_scans_add_up_and_the_latest_sets_the_gauges():
+    root = Path("/src/api")
+    metrics = ServerMetrics()
+    metrics.observe(root, 2.0, VerboseMetrics(stage_counts={"parse": 10}, parsed_lines=400, cache_misses=10))
+    rescan = VerboseMetrics(stage_counts={"parse": 11}, parsed_lines=500, cache_hits=9, cache_misses=2)
+    metrics.observe(root, 0.5, rescan)
+
+    samples = _samples(metrics.render({root: (3, 50)}))
+
+    label = '{root="/src/api"}'
+    assert samples[f"treepeat_scans_total{label}"] == "2"
+    assert samples[f"treepeat_scan_duration_seconds_total{label}"] == "2.5"
+    assert samples[f"treepeat_last_scan_duration_seconds{label}"] == "0.5"
+    assert samples[f"treepeat_files_scanned{label}"] == "11"
+    assert samples[f"treepeat_clone_classes{label}"] == "3"
+    assert samples[f"treepeat_duplication_percent{label}"] == "10.0"
+    assert samples[f"treepeat_cache_hits_total{label}"] == "9"
+    assert samples[f"treepeat_cache_misses_total{label}"] == "12"
+
+
+def test_every_metric_is_described_and_labels_are_escaped():
+    metrics = ServerMetrics()
+    metrics.observe(Path('/src/"odd"'), 1.0, VerboseMetrics())
+
+    text = metrics.render({})
+
+    assert "# TYPE treepeat_duplication_percent gauge" in text
+    assert 'treepeat_duplication_percent{root="/src/\\"odd\\""} 0.0' in text.splitlines()
@@ -0,0 +1,78 @@
+import json
+import threading
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from pathlib import Path
+
+import pytest
+
+from treepeat.notify import DEFAULT_TEMPLATE, NotifyError, load_template, notification_variables, render, send
+
+
+class _Webhook(BaseHTTPRequestHandler):
+    """Records the payloads posted to it; posts to /gone are refused."""
+
+    received: list[dict] = []
+
+    def do_POST(self):
+        payload = json.loads(self.rfile.read(int(self.headers["Content-Length"])))
+        status = 410 if self.path == "/gone" else 200
+        if status == 200:
+            _Webhook.received.append(payload)
+        self.send_response(status)
+        self.send_header("Content-Length", "0")
+        self.end_headers()
+
+    def log_message(self, format, *args):
+        pass
+
+
+@pytest.fixture
+def webhook():
+    _Webhook.received = []
+    server = ThreadingHTTPServer(("127.0.0.1", 0), _Webhook)
+    threading.Thread(target=server.serve_forever, daemon=True).start()
+    yield f"http://127.0.0.1:{server.server_port}"
+    server.shutdown()
+    server.server_close()
+
+
+def _variables() -> dict:
+    return notification_variables(Path("src"), ['2 new clone class(es) found', 'quotes " survive'], 5, 2)
+
+
+def test_default_payload_is_a_slack_message():
+    payload = json.loads(render(DEFAULT_TEMPLATE, _variables()))
+
+    assert payload == {
+        "text": 'treepeat: duplication regression in src\n- 2 new clone class(es) found\n- quotes " survive'
+    }
+
+
+def test_template_variables_expand_to_json_values(tmp_path):
+    template_path = tmp_path / "payload.json"
+    template_path.write_text('{"content": $text, "new": $new_clone_classes, "why": $violations, "cost": "$$0"}')
+
+    payload = json.loads(render(load_template(template_path), _variables()))
+
+    assert payload["new"] == 2
+    assert payload["why"] == ["2 new clone class(es) found", 'quotes " survive']
+    assert payload["cost"] == "$0"
+
+
+def test_unknown_template_variables_are_rejected(tmp_path):
+    template_path = tmp_path / "payload.json"
+    template_path.write_text('{"text": $txt}')
+
+    with pytest.raises(ValueError, match="txt"):
+        load_template(template_path)
+
+
+def test_send_posts_the_payload(webhook):
+    send(webhook, '{"text": "hi"}')
+
+    assert _Webhook.received == [{"text": "hi"}]
+
+
+def test_refused_delivery_is_an_error(webhook):
+    with pytest.raises(NotifyError, match="410"):
+        send(f"{webhook}/gone", '{"text": "hi"}')
@@ -0,0 +1,108 @@
+import subprocess
+from pathlib import Path
+
+import pytest
+
+from treepeat.models.similarity import Ownership, Region, SimilarityResult, SimilarRegionGroup
+from treepeat.ownership import annotate_ownership, code_owners, load_codeowners, owners_of, parse_codeowners
+from treepeat.revisions import RevisionError
+
+CODEOWNERS = """
+# Default owners
+*       @org/core
+
+[Docs]
+docs/   @org/docs docs@example.com
+*.py    @org/python  # inline comment
+/generated/*.py
+"""
+
+
+def _region(path: Path, start_line: int = 1, end_line: int = 2) -> Region:
+    return Region(
+        path=path,
+        language="python",
+        region_type="function_definition",
+        region_name="f",
+        start_line=start_line,
+        end_line=end_line,
+    )
+
+
+@pytest.fixture
+def repo(tmp_path):
+    repo = tmp_path / "repo"
+    (repo / ".github").mkdir(parents=True)
+    (repo / ".github" / "CODEOWNERS").write_text("*.py @org/python\n")
+    (repo / "a.py").write_text("def f():\n    return 1\n")
+    for args in (["init", "-q"], ["add", "-A"], ["commit", "-qm", "first"]):
+        subprocess.run(
+            ["git", "-c", "user.name=Ada", "-c", "user.email=ada@example.com", *args],
+            cwd=repo,
+            check=True,
+            capture_output=True,
+        )
+    return repo
+
+
+def test_parse_codeowners_skips_comments_blank_lines_and_sections():
+    rules = parse_codeowners(CODEOWNERS)
+
+    assert [(rule.pattern, rule.owners) for rule in rules] == [
+        ("*", ("@org/core",)),
+        ("docs/", ("@org/docs", "docs@example.com")),
+        ("*.py", ("@org/python",)),
+        ("/generated/*.py", ()),
+    ]
+
+
+def test_code_owners_are_those_of_the_last_matching_rule(tmp_path):
+    rules = parse_codeowners(CODEOWNERS)
+
+    assert code_owners(tmp_path / "src" / "app.py", rules, tmp_path) == ["@org/python"]
+    assert code_owners(tmp_path / "docs" / "guide.md", rules, tmp_path) == ["@org/docs", "docs@example.com"]
+    assert code_owners(tmp_path / "main.go", rules, tmp_path) == ["@org/core"]
+    assert code_owners(tmp_path / "generated" / "api.py", rules, tmp_path) == []
+
+
+def test_load_codeowners_looks_in_github_root_and_docs(tmp_path):
+    assert load_codeowners(tmp_path) == []
+
+    (tmp_path / "docs").mkdir()
+    (tmp_path / "docs" / "CODEOWNERS").write_text("* @docs\n")
+    (tmp_path / "CODEOWNERS").write_text("* @root\n")
+
+    assert load_codeowners(tmp_path)[0].owners == ("@root",)
+
+
+def test_annotate_ownership_adds_the_author_and_code_owners(repo):
+    group = SimilarRegionGroup(regions=[_region(repo / "a.py"), _region(repo / "a.py")], similarity=1.0)
+
+    result = annotate_ownership(SimilarityResult(similar_groups=[group]), repo)
+
+    owner = result.similar_groups[0].regions[0].owner
+    assert owner == Ownership(author="Ada", author_email="ada@example.com", owners=["@org/python"])
+
+
+def test_annotate_ownership_leaves_unowned_uncommitted_code_without_owner(repo):
+    (repo / "new.txt").write_text("a\nb\n")
+    group = SimilarRegionGroup(regions=[_region(repo / "new.txt")], similarity=1.0)
+
+    result = annotate_ownership(SimilarityResult(test_groups=[group]), repo)
+
+    assert result.test_groups[0].regions[0].owner is None
+
+
+def test_annotate_ownership_outside_a_repository_is_rejected(tmp_path):
+    with pytest.raises(RevisionError):
+        annotate_ownership(SimilarityResult(), tmp_path)
+
+
+def test_owners_of_lists_each_owner_once_most_frequent_first(tmp_path):
+    regions = [
+        _region(tmp_path / "a.py").model_copy(update={"owner": Ownership(owners=["@b"])}),
+        _region(tmp_path / "b.py").model_copy(update={"owner": Ownership(owners=["@a", "@b"])}),
+        _region(tmp_path / "c.py"),
+    ]
+
+    assert owners_of(regions) == ["@b", "@a"]
@@ -0,0 +1,71 @@
+from pathlib import Path
+
+from datasketch import MinHash
+
+from treepeat.models.similarity import Region, RegionSignature, SimilarityResult, SimilarRegionGroup
+from treepeat.policy import FailPolicy, duplicated_lines, duplication_percent, policy_violations
+
+
+def _group(paths: tuple[Path, ...], start_line: int = 1, end_line: int = 5) -> SimilarRegionGroup:
+    regions = [
+        Region(
+            path=path,
+            language="python",
+            region_type="function",
+            region_name="f",
+            start_line=start_line,
+            end_line=end_line,
+        )
+        for path in paths
+    ]
+    return SimilarRegionGroup(regions=regions, similarity=1.0)
+
+
+def test_duplicated_lines_counts_each_line_once():
+    a, b = Path("a.py"), Path("b.py")
+
+    assert duplicated_lines([_group((a, b)), _group((a, b), start_line=3, end_line=8)]) == 16
+
+
+def test_duplication_percent_is_relative_to_the_compared_files(tmp_path):
+    a, b = tmp_path / "a.py", tmp_path / "b.py"
+    for path in (a, b):
+        path.write_text("x = 1\n" * 10)
+    group = _group((a, b))
+    signatures = [RegionSignature(region=r, minhash=MinHash(), shingle_count=5) for r in group.regions]
+
+    assert duplication_percent(SimilarityResult(signatures=signatures, similar_groups=[group])) == 50.0
+
+
+def test_fail_on_modes():
+    known, new = _group((Path("a.py"), Path("b.py"))), _group((Path("c.py"), Path("d.py")))
+    found = SimilarityResult(similar_groups=[known])
+
+    assert policy_violations(found, [], FailPolicy(fail_on="any")) == ["1 clone class(es) found"]
+    assert policy_violations(found, [], FailPolicy(fail_on="new-clones")) == []
+    assert policy_violations(found, [new], FailPolicy(fail_on="new-clones")) == ["1 new clone class(es) found"]
+    assert policy_violations(found, [new], FailPolicy(fail_on="threshold", max_new_duplicated_lines=10)) == []
+
+
+def test_new_duplicated_lines_budget():
+    new = _group((Path("c.py"), Path("d.py")))
+
+    violations = policy_violations(SimilarityResult(), [new], FailPolicy(max_new_duplicated_lines=9))
+
+    assert violations == ["new clones cover 10 lines, above --max-new-duplicated-lines 9"]
+
+
+def test_duplication_increase_budget_counts_only_new_clones(tmp_path):
+    paths = tuple(tmp_path / name for name in ("a.py", "b.py", "c.py", "d.py"))
+    for path in paths:
+        path.write_text("x = 1\n" * 25)
+    known, new = _group(paths[:2]), _group(paths[2:])
+    signatures = [RegionSignature(region=r, minhash=MinHash(), shingle_count=5) for r in known.regions + new.regions]
+    found = SimilarityResult(signatures=signatures, similar_groups=[known, new])
+
+    violations = policy_violations(found, [new], FailPolicy(max_duplication_increase_pct=5))
+
+    assert violations == [
+        "new clones add 10.0% of the lines to the duplication, above --max-duplication-increase-pct 5"
+    ]
+    assert policy_violations(found, [new], FailPolicy(max_duplication_increase_pct=10)) == []
@@ -0,0 +1,36 @@
+import json
+import pstats
+import tracemalloc
+
+from treepeat.pipeline.verbose_metrics import record_stage_timing, reset_verbose_metrics
+from treepeat.profiling import profiled, trace_events
+
+
+def test_trace_events():
+    trace = trace_events([("parse", 10.5, 0.25), ("lsh", 11.0, 1.0)], origin=10.0)
+
+    assert [(e["name"], e["ts"], e["dur"], e["ph"]) for e in trace["traceEvents"]] == [
+        ("parse", 500000, 250000, "X"),
+        ("lsh", 1000000, 1000000, "X"),
+    ]
+
+
+def test_profiled_writes_each_requested_profile(tmp_path):
+    reset_verbose_metrics()
+    cpu, memory, trace = tmp_path / "cpu.prof", tmp_path / "mem.snapshot", tmp_path / "trace.json"
+
+    with profiled(cpu, memory, trace):
+        sorted(str(i) for i in range(1000))
+        record_stage_timing("parse", 0.0)
+
+    assert pstats.Stats(str(cpu)).total_calls > 0
+    assert tracemalloc.Snapshot.load(str(memory)).traces is not None
+    assert [e["name"] for e in json.loads(trace.read_text())["traceEvents"]] == ["parse"]
+    assert not tracemalloc.is_tracing()
+
+
+def test_profiled_without_profiles_writes_nothing(tmp_path):
+    with profiled(None, None, None):
+        pass
+
+    assert not any(tmp_path.iterdir())
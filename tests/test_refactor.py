@@ -0,0 +1,89 @@
+from treepeat.refactor import suggest_extraction, suggestion_to_dict
+
+
+def _clone(*copies: tuple[str, str], language: str = "python") -> dict:
+    instances = [
+        {"path": path, "language": language, "region_type": "function", "region_name": name, "start_line": 1}
+        for path, name in copies
+    ]
+    return {"fingerprint": "clone-1a2b3c4d", "similarity": 0.9, "instances": instances}
+
+
+def test_differing_tokens_become_parameters_with_each_copy_value():
+    first = ["def total(xs):", "    s = sum(xs)", "    return s * 10"]
+    second = ["def total(xs):", "    s = max(xs)", "    return s * 20"]
+
+    suggestion = suggest_extraction(_clone(("src/a.py", "total"), ("src/b.py", "total")), [first, second])
+
+    assert suggestion is not None
+    assert suggestion.skeleton == "def total(xs):\n    s = __param1__(xs)\n    return s * __param2__"
+    assert [(p.name, p.kind, p.values) for p in suggestion.parameters] == [
+        ("__param1__", "identifier", ("sum", "max")),
+        ("__param2__", "number", ("10", "20")),
+    ]
+
+
+def test_a_run_differing_the_same_way_everywhere_is_one_parameter():
+    first = ["def f(a):", "    total = a + 1", "    return total"]
+    second = ["def f(a):", "    count = a + 1", "    return count"]
+
+    suggestion = suggest_extraction(_clone(("a.py", "f"), ("b.py", "f")), [first, second])
+
+    assert suggestion is not None
+    assert suggestion.skeleton.count("__param1__") == 2
+    assert len(suggestion.parameters) == 1
+
+
+def test_code_only_some_copies_have_is_an_expression_parameter():
+    first = ['log("start")', "run()"]
+    second = ['log("start")', "check()", "run()"]
+
+    suggestion = suggest_extraction(_clone(("a.py", "f"), ("b.py", "g")), [first, second])
+
+    assert suggestion is not None
+    (parameter,) = suggestion.parameters
+    assert parameter.kind == "expression"
+    assert parameter.values == ("run", "check()\nrun")
+
+
+def test_identical_copies_need_no_parameters():
+    lines = ["    x = load()", "    return x"]
+
+    suggestion = suggest_extraction(_clone(("a.py", "f"), ("a.py", "f")), [lines, lines])
+
+    assert suggestion is not None
+    assert suggestion.parameters == []
+    assert suggestion.skeleton == "x = load()\nreturn x"
+
+
+def test_target_is_the_shared_file_else_the_closest_common_directory():
+    lines = ["x = 1"]
+
+    same_file = suggest_extraction(_clone(("src/a.py", "f"), ("src/a.py", "g")), [lines, lines])
+    spread = suggest_extraction(_clone(("src/api/a.py", "f"), ("src/web/b.py", "f")), [lines, lines])
+
+    assert same_file is not None and same_file.target == "src/a.py"
+    assert same_file.name == "f"
+    assert spread is not None and spread.target == "src"
+
+
+def test_no_suggestion_across_languages_or_for_unreadable_copies():
+    lines = ["x = 1"]
+    mixed = _clone(("a.py", "f"), ("b.js", "f"))
+    mixed["instances"][1]["language"] = "javascript"
+
+    assert suggest_extraction(mixed, [lines, lines]) is None
+    assert suggest_extraction(_clone(("a.py", "f"), ("b.py", "f")), [lines, []]) is None
+
+
+def test_suggestion_to_dict():
+    suggestion = suggest_extraction(_clone(("a.py", "f"), ("b.py", "f")), [["x = 1"], ["x = 2"]])
+
+    assert suggestion is not None
+    assert suggestion_to_dict(suggestion) == {
+        "name": "f",
+        "language": "python",
+        "target": ".",
+        "skeleton": "x = __param1__",
+        "parameters": [{"name": "__param1__", "kind": "number", "values": ["1", "2"]}],
+    }
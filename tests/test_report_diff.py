@@ -0,0 +1,77 @@
+import json
+from pathlib import Path
+
+from treepeat.formatters.json import format_as_json
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+from treepeat.report_diff import compare_reports, format_changes_as_json, summarize_report
+
+
+def _group(fingerprint: str, root: Path, names: tuple[str, ...], end_line: int = 5) -> SimilarRegionGroup:
+    regions = [
+        Region(
+            path=root / name,
+            language="python",
+            region_type="function",
+            region_name="f",
+            start_line=1,
+            end_line=end_line,
+        )
+        for name in names
+    ]
+    return SimilarRegionGroup(regions=regions, similarity=1.0, fingerprint=fingerprint)
+
+
+def _summary(root: Path, *groups: SimilarRegionGroup) -> dict:
+    report = json.loads(format_as_json(SimilarityResult(similar_groups=list(groups))))
+    return summarize_report(report, root)
+
+
+def test_summary_paths_are_relative_to_the_scanned_tree(tmp_path):
+    summary = _summary(tmp_path, _group("clone-aaaa0001", tmp_path, ("a.py", "pkg/b.py")))
+
+    assert summary["clone-aaaa0001"]["locations"] == ["a.py:1-5", "pkg/b.py:1-5"]
+    assert summary["clone-aaaa0001"]["instances"] == 2
+    assert summary["clone-aaaa0001"]["lines"] == 10
+
+
+def test_compare_reports_classifies_changes(tmp_path):
+    before = _summary(
+        tmp_path,
+        _group("clone-kept0001", tmp_path, ("a.py", "b.py")),
+        _group("clone-gone0002", tmp_path, ("c.py", "d.py")),
+        _group("clone-grow0003", tmp_path, ("e.py", "f.py")),
+    )
+    after = _summary(
+        tmp_path,
+        _group("clone-kept0001", tmp_path, ("a.py", "b.py")),
+        _group("clone-grow0003", tmp_path, ("e.py", "f.py", "g.py")),
+        _group("clone-new00004", tmp_path, ("h.py", "i.py")),
+    )
+
+    changes = compare_reports(before, after)
+
+    assert [c["fingerprint"] for c in changes["introduced"]] == ["clone-new00004"]
+    assert [c["fingerprint"] for c in changes["removed"]] == ["clone-gone0002"]
+    assert [c["fingerprint"] for c in changes["grown"]] == ["clone-grow0003"]
+    assert changes["grown"][0]["before"]["instances"] == 2
+    assert changes["grown"][0]["after"]["instances"] == 3
+
+
+def test_longer_copies_count_as_grown(tmp_path):
+    before = _summary(tmp_path, _group("clone-aaaa0001", tmp_path, ("a.py", "b.py")))
+    after = _summary(tmp_path, _group("clone-aaaa0001", tmp_path, ("a.py", "b.py"), end_line=9))
+
+    assert [c["fingerprint"] for c in compare_reports(before, after)["grown"]] == ["clone-aaaa0001"]
+    assert compare_reports(after, before)["grown"] == []
+
+
+def test_changes_json_names_both_states(tmp_path):
+    changes = compare_reports({}, _summary(tmp_path, _group("clone-aaaa0001", tmp_path, ("a.py", "b.py"))))
+
+    document = json.loads(format_changes_as_json(changes, "v1", "HEAD"))
+
+    assert document["before"] == "v1"
+    assert document["after"] == "HEAD"
+    assert [c["fingerprint"] for c in document["introduced"]] == ["clone-aaaa0001"]
+    assert document["removed"] == []
+    assert document["grown"] == []
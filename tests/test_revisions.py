@@ -0,0 +1,139 @@
+import subprocess
+
+import pytest
+
+from treepeat.revisions import (
+    RevisionError,
+    blame_authors,
+    changed_files,
+    diff_since,
+    export_revision,
+    file_churn,
+    head_commit,
+    staged_files,
+)
+
+
+def _git(repo, *args):
+    subprocess.run(
+        ["git", "-c", "user.name=test", "-c", "user.email=test@example.com", *args],
+        cwd=repo,
+        check=True,
+        capture_output=True,
+    )
+
+
+@pytest.fixture
+def repo(tmp_path):
+    repo = tmp_path / "repo"
+    (repo / "src").mkdir(parents=True)
+    _git(repo, "init", "-q")
+    (repo / "src" / "a.py").write_text("old = 1\n")
+    _git(repo, "add", "-A")
+    _git(repo, "commit", "-qm", "first")
+    _git(repo, "tag", "v1")
+    (repo / "src" / "a.py").write_text("new = 2\n")
+    (repo / "b.py").write_text("b = 3\n")
+    _git(repo, "add", "-A")
+    _git(repo, "commit", "-qm", "second")
+    return repo
+
+
+def test_export_revision_writes_the_tree_of_that_revision(repo, tmp_path):
+    exported = export_revision("v1", tmp_path / "v1", repo)
+
+    assert (exported / "src" / "a.py").read_text() == "old = 1\n"
+    assert not (exported / "b.py").exists()
+
+
+def test_export_revision_accepts_symbolic_refs(repo, tmp_path):
+    exported = export_revision("HEAD", tmp_path / "head", repo)
+
+    assert (exported / "src" / "a.py").read_text() == "new = 2\n"
+    assert (exported / "b.py").exists()
+
+
+def test_unknown_revision_is_rejected(repo, tmp_path):
+    with pytest.raises(RevisionError):
+        export_revision("no-such-ref", tmp_path / "missing", repo)
+
+
+def test_changed_files_lists_committed_uncommitted_and_untracked_changes(repo):
+    (repo / "src" / "a.py").write_text("newer = 3\n")
+    (repo / "c.py").write_text("c = 4\n")
+    (repo / "ignored.py").write_text("i = 5\n")
+    (repo / ".gitignore").write_text("ignored.py\n")
+
+    changed = changed_files("v1", repo / "src")
+
+    root = repo.resolve()
+    assert changed == {root / "src" / "a.py", root / "b.py", root / "c.py", root / ".gitignore"}
+
+
+def test_changed_files_outside_a_repository_is_rejected(tmp_path):
+    with pytest.raises(RevisionError):
+        changed_files("HEAD", tmp_path)
+
+
+def test_staged_files_lists_the_index_only(repo):
+    (repo / "src" / "a.py").write_text("staged = 1\n")
+    (repo / "b.py").unlink()
+    (repo / "c.py").write_text("unstaged = 2\n")
+    _git(repo, "add", "src/a.py", "b.py")
+
+    assert staged_files(repo) == {repo.resolve() / "src" / "a.py"}
+
+
+def test_diff_since_covers_the_commits_since_the_branch_point(repo):
+    _git(repo, "checkout", "-qb", "feature")
+    (repo / "c.py").write_text("c = 4\n")
+    _git(repo, "add", "-A")
+    _git(repo, "commit", "-qm", "third")
+    (repo / "uncommitted.py").write_text("u = 5\n")
+
+    diff = diff_since("v1", repo)
+
+    assert "+++ b/src/a.py" in diff and "+++ b/b.py" in diff and "+++ b/c.py" in diff
+    assert "uncommitted.py" not in diff
+
+
+def test_head_commit_is_the_checked_out_commit(repo):
+    commit = subprocess.run(["git", "rev-parse", "HEAD"], cwd=repo, check=True, capture_output=True, text=True)
+
+    assert head_commit(repo / "src") == commit.stdout.strip()
+
+
+def test_file_churn_counts_the_commits_touching_each_file(repo):
+    churn = file_churn(repo)
+
+    root = repo.resolve()
+    assert churn == {root / "src" / "a.py": 2, root / "b.py": 1}
+
+
+def test_file_churn_is_limited_to_the_path_and_period(repo):
+    root = repo.resolve()
+
+    assert file_churn(repo / "src") == {root / "src" / "a.py": 2}
+    assert file_churn(repo, since="2099-01-01") == {}
+
+
+def test_blame_authors_counts_the_committed_lines_of_each_author(repo):
+    (repo / "b.py").write_text("b = 3\nc = 4\nd = 5\n")
+    subprocess.run(
+        ["git", "-c", "user.name=other", "-c", "user.email=other@example.com", "commit", "-qam", "third"],
+        cwd=repo,
+        check=True,
+        capture_output=True,
+    )
+    (repo / "b.py").write_text("b = 3\nc = 4\nd = 5\ne = 6\n")
+
+    authors = blame_authors(repo / "b.py", 1, 4, repo)
+
+    assert authors == {("test", "test@example.com"): 1, ("other", "other@example.com"): 2}
+
+
+def test_blame_authors_of_an_untracked_file_is_rejected(repo):
+    (repo / "new.py").write_text("n = 1\n")
+
+    with pytest.raises(RevisionError):
+        blame_authors(repo / "new.py", 1, 1, repo)
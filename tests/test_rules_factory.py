@@ -1,6 +1,29 @@
+import pytest
+
 from treepeat.config import PipelineSettings
+from treepeat.pipeline.rules.models import RuleAction
+from treepeat.pipeline.rules.parser import RuleParseError
 from treepeat.pipeline.rules_factory import build_rule_engine, get_ruleset_with_descriptions
 
+GO_BODIES = {
+    "go-bodies": {
+        "extends": "default",
+        "regions": {"go": ["function_declaration", "method_declaration"]},
+        "ignore": {"go": ["import_declaration"]},
+    },
+    "go-strict": {
+        "extends": "go-bodies",
+        "rules": [
+            {
+                "name": "Anonymize receivers",
+                "languages": "go",
+                "query": "(method_declaration receiver: (parameter_list) @receiver)",
+                "action": "anonymize",
+            }
+        ],
+    },
+}
+
 
 def _javascript_rule_names(ruleset: str) -> list[str]:
     return [
@@ -57,3 +80,105 @@ def test_javascript_identifier_anonymization_is_only_in_loose_ruleset() -> None:
 
     assert "Anonymize identifiers" not in default_rule_names
     assert "Anonymize identifiers" in loose_rule_names
+
+
+def test_ignore_qualifiers_adds_modifier_rules() -> None:
+    default_names = {rule.name for rule in build_rule_engine(PipelineSettings()).rules}
+
+    settings = PipelineSettings()
+    settings.rules.ignore_qualifiers = True
+    engine = build_rule_engine(settings)
+    java_rule_names = {rule.name for rule in engine.rules if rule.matches_language("java")}
+
+    assert "Ignore access and storage modifiers" not in default_names
+    assert "Ignore access and storage modifiers" in java_rule_names
+
+
+def test_normalize_signature_types_adds_type_rules() -> None:
+    settings = PipelineSettings()
+    settings.rules.normalize_signature_types = True
+    engine = build_rule_engine(settings)
+    go_rule_names = {rule.name for rule in engine.rules if rule.matches_language("go")}
+
+    assert "Normalize signature types" in go_rule_names
+
+
+def test_normalize_adds_identifier_and_literal_rules() -> None:
+    settings = PipelineSettings()
+    settings.rules.normalize = ["identifiers", "literals"]
+    engine = build_rule_engine(settings)
+    python_rule_names = [rule.name for rule in engine.rules if rule.matches_language("python")]
+
+    assert "Anonymize identifiers" in python_rule_names
+    assert "Anonymize literals" in python_rule_names
+    # Ruleset rules come later so they keep precedence (e.g. FUNC for function names)
+    assert python_rule_names.index("Anonymize identifiers") < python_rule_names.index("Anonymize function names")
+    assert "Anonymize operators" not in python_rule_names
+
+
+def test_normalize_does_not_repeat_loose_rules() -> None:
+    settings = PipelineSettings()
+    settings.rules.ruleset = "loose"
+    settings.rules.normalize = ["identifiers"]
+    engine = build_rule_engine(settings)
+    python_rule_names = [rule.name for rule in engine.rules if rule.matches_language("python")]
+
+    assert python_rule_names.count("Anonymize identifiers") == 1
+
+
+def test_user_ruleset_replaces_the_regions_of_its_languages() -> None:
+    settings = PipelineSettings()
+    settings.rules.ruleset = "go-bodies"
+    settings.rules.custom_rulesets = GO_BODIES
+
+    engine = build_rule_engine(settings)
+
+    go_regions = {region_type for _, region_type in engine.get_region_extraction_rules("go")}
+    python_regions = {region_type for _, region_type in engine.get_region_extraction_rules("python")}
+    assert go_regions == {"function_declaration", "method_declaration"}
+    assert "function_definition" in python_regions
+
+
+def test_user_ruleset_ignores_node_kinds_and_inherits_rules() -> None:
+    rules = [rule for rule, _ in get_ruleset_with_descriptions("go-strict", custom_rulesets=GO_BODIES)]
+
+    assert any(rule.query == "(import_declaration) @ignore" and rule.action == RuleAction.REMOVE for rule in rules)
+    assert rules[-1].name == "Anonymize receivers"
+    assert rules[-1].languages == ["go"]
+    assert "Anonymize function names" in [rule.name for rule in rules]
+
+
+@pytest.mark.parametrize(
+    "rulesets",
+    [
+        {"mine": {"extends": "strict"}},
+        {"mine": {"extends": "mine"}},
+        {"mine": {"regions": {"go": "function_declaration"}}},
+        {"mine": {"rules": [{"name": "x", "languages": ["go"], "query": "(x) @x", "action": "explode"}]}},
+    ],
+)
+def test_invalid_user_rulesets_are_rejected(rulesets: dict) -> None:
+    with pytest.raises(RuleParseError):
+        get_ruleset_with_descriptions("mine", custom_rulesets=rulesets)
+
+
+def test_normalize_comments_strips_comments_and_docstrings_without_a_ruleset() -> None:
+    settings = PipelineSettings()
+    settings.rules.ruleset = "none"
+    settings.rules.normalize = ["comments"]
+    engine = build_rule_engine(settings)
+    python_rule_names = [rule.name for rule in engine.rules if rule.matches_language("python")]
+    java_rule_names = [rule.name for rule in engine.rules if rule.matches_language("java")]
+
+    assert {"Ignore comments", "Ignore docstrings"}.issubset(python_rule_names)
+    assert "Anonymize function names" not in python_rule_names
+    assert "Ignore comments" in java_rule_names
+
+
+def test_normalize_comments_does_not_repeat_default_rules() -> None:
+    settings = PipelineSettings()
+    settings.rules.normalize = ["comments"]
+    engine = build_rule_engine(settings)
+    python_rule_names = [rule.name for rule in engine.rules if rule.matches_language("python")]
+
+    assert python_rule_names.count("Ignore comments") == 1
@@ -0,0 +1,124 @@
+import json
+import threading
+import urllib.error
+import urllib.request
+from http.server import ThreadingHTTPServer
+
+import pytest
+
+from treepeat.metrics import ServerMetrics
+from treepeat.pipeline.verbose_metrics import VerboseMetrics
+from treepeat.server import CloneIndex, ServerError, make_handler
+
+
+def _clone(fingerprint: str, *copies: tuple[str, int, int]) -> dict:
+    return {
+        "fingerprint": fingerprint,
+        "similarity": 1.0,
+        "instances": [{"path": path, "start_line": start, "end_line": end} for path, start, end in copies],
+    }
+
+
+class FakeScan:
+    """Answers scans with queued reports and records what was scanned."""
+
+    def __init__(self, *reports: dict):
+        self.reports = list(reports)
+        self.calls: list[tuple] = []
+
+    def __call__(self, root, changed):
+        self.calls.append((root, changed))
+        return self.reports.pop(0)
+
+
+@pytest.fixture
+def tree(tmp_path):
+    root = tmp_path.resolve()
+    for name in ("a.py", "b.py", "c.py"):
+        (root / name).write_text("x = 1\n")
+    return root
+
+
+def test_queries_are_answered_from_the_index(tree):
+    a, b, c = (str(tree / name) for name in ("a.py", "b.py", "c.py"))
+    scan = FakeScan(
+        {"clone_classes": [_clone("clone-ab", (a, 1, 10), (b, 1, 10)), _clone("clone-bc", (b, 20, 30), (c, 5, 15))]}
+    )
+    index = CloneIndex([tree], scan)
+    index.rescan(None, None)
+
+    assert [clone["fingerprint"] for clone in index.clones_of(b, None)] == ["clone-ab", "clone-bc"]
+    assert [clone["fingerprint"] for clone in index.clones_of(b, 25)] == ["clone-bc"]
+    assert index.clones_of(a, 15) == []
+    assert len(index.report(str(tree))["clone_classes"]) == 2
+    assert scan.calls == [(tree, None)]
+
+
+def test_rescan_of_some_files_replaces_only_their_clones(tree):
+    a, b, c = (str(tree / name) for name in ("a.py", "b.py", "c.py"))
+    scan = FakeScan(
+        {"clone_classes": [_clone("clone-ab", (a, 1, 10), (b, 1, 10)), _clone("clone-bc", (b, 20, 30), (c, 5, 15))]},
+        {"clone_classes": []},
+    )
+    index = CloneIndex([tree], scan)
+    index.rescan(None, None)
+
+    [summary] = index.rescan(str(tree), [a])
+
+    assert summary["clone_classes"] == 1
+    assert scan.calls[-1] == (tree, {tree / "a.py"})
+    assert [clone["fingerprint"] for clone in index.clones_of(b, None)] == ["clone-bc"]
+
+
+def test_paths_outside_the_served_roots_are_not_found(tree, tmp_path_factory):
+    index = CloneIndex([tree], FakeScan({"clone_classes": []}))
+
+    with pytest.raises(ServerError, match="not under a served root"):
+        index.clones_of(str(tmp_path_factory.mktemp("elsewhere") / "x.py"), None)
+
+
+def test_http_api(tree):
+    a, b = str(tree / "a.py"), str(tree / "b.py")
+    index = CloneIndex([tree], FakeScan({"clone_classes": [_clone("clone-ab", (a, 1, 10), (b, 1, 10))]}))
+    index.rescan(None, None)
+    server = ThreadingHTTPServer(("127.0.0.1", 0), make_handler(index))
+    threading.Thread(target=server.serve_forever, daemon=True).start()
+    base = f"http://127.0.0.1:{server.server_port}"
+
+    def get(path):
+        with urllib.request.urlopen(base + path) as response:
+            return json.loads(response.read())
+
+    try:
+        assert get("/roots")[0]["clone_classes"] == 1
+        assert [clone["fingerprint"] for clone in get(f"/clones?file={a}&line=5")] == ["clone-ab"]
+        with pytest.raises(urllib.error.HTTPError) as error:
+            get("/clones")
+        assert error.value.code == 400
+        with pytest.raises(urllib.error.HTTPError) as error:
+            get("/nowhere")
+        assert error.value.code == 404
+    finally:
+        server.shutdown()
+        server.server_close()
+
+
+def test_metrics_endpoint(tree):
+    a, b = str(tree / "a.py"), str(tree / "b.py")
+    index = CloneIndex([tree], FakeScan({"clone_classes": [_clone("clone-ab", (a, 1, 10), (b, 1, 10))]}))
+    index.rescan(None, None)
+    metrics = ServerMetrics()
+    metrics.observe(tree, 2.5, VerboseMetrics(stage_counts={"parse": 3}, parsed_lines=80))
+    server = ThreadingHTTPServer(("127.0.0.1", 0), make_handler(index, metrics))
+    threading.Thread(target=server.serve_forever, daemon=True).start()
+
+    try:
+        with urllib.request.urlopen(f"http://127.0.0.1:{server.server_port}/metrics") as response:
+            content_type, text = response.headers["Content-Type"], response.read().decode()
+    finally:
+        server.shutdown()
+        server.server_close()
+
+    assert content_type.startswith("text/plain")
+    assert f'treepeat_clone_classes{{root="{tree}"}} 1' in text.splitlines()
+    assert f'treepeat_duplication_percent{{root="{tree}"}} 25.0' in text.splitlines()
@@ -0,0 +1,102 @@
+from pathlib import Path
+
+import pytest
+
+from treepeat.config import LSHSettings, PipelineSettings, set_settings
+from treepeat.pipeline.parse import collect_source_files, in_shard
+from treepeat.pipeline.pipeline import match_shingled_regions, run_pipeline, shingle_shard
+from treepeat.shard import ShardError, load_shards, parse_shard, write_shard
+
+MODULE = """\
+def total(items):
+    result = 0
+    for item in items:
+        if item > 0:
+            result = result + item
+    return result
+"""
+
+
+def _settings(**overrides):
+    return PipelineSettings(lsh=LSHSettings(similarity_percent=1.0, min_lines=3), **overrides)
+
+
+def _write_shards(root: Path, out: Path, count: int, **overrides) -> list[Path]:
+    paths = []
+    for index in range(1, count + 1):
+        settings = _settings(shard=(index, count), **overrides)
+        set_settings(settings)
+        path = out / f"shard-{index}.json"
+        write_shard(path, root, settings, shingle_shard(root))
+        paths.append(path)
+    return paths
+
+
+@pytest.fixture
+def source(tmp_path):
+    root = tmp_path / "src"
+    root.mkdir()
+    for name in ("alpha", "beta", "gamma", "delta", "epsilon"):
+        (root / f"{name}.py").write_text(MODULE.replace("total", name))
+    return root
+
+
+def test_parse_shard():
+    assert parse_shard("3/8") == (3, 8)
+    assert parse_shard(" 1 / 1 ") == (1, 1)
+    for spec in ("0/8", "9/8", "3", "a/b"):
+        with pytest.raises(ShardError):
+            parse_shard(spec)
+
+
+def test_every_file_is_in_exactly_one_shard(tmp_path):
+    files = [tmp_path / f"dir{i % 3}" / f"file{i}.py" for i in range(50)]
+
+    for file in files:
+        assert sum(in_shard(file, tmp_path, (index, 4)) for index in range(1, 5)) == 1
+    # The slice depends on the path below the scanned directory only
+    assert [in_shard(f, tmp_path, (1, 4)) for f in files] == [
+        in_shard(Path("/elsewhere") / f.relative_to(tmp_path), Path("/elsewhere"), (1, 4)) for f in files
+    ]
+
+
+def test_shards_split_the_collected_files(source):
+    set_settings(_settings())
+    every = set(collect_source_files(source))
+    sliced = []
+    for index in (1, 2, 3):
+        set_settings(_settings(shard=(index, 3)))
+        sliced.extend(collect_source_files(source))
+
+    assert sorted(sliced) == sorted(every)
+
+
+def test_merge_finds_the_clones_of_an_unsharded_run(source, tmp_path):
+    set_settings(_settings())
+    whole = run_pipeline(source)
+
+    settings, root, regions = load_shards(_write_shards(source, tmp_path, 3))
+    set_settings(settings)
+    merged = match_shingled_regions(regions, root)
+
+    assert merged.similar_groups
+    assert sorted(sorted(r.region_name for r in g.regions) for g in merged.similar_groups) == sorted(
+        sorted(r.region_name for r in g.regions) for g in whole.similar_groups
+    )
+
+
+def test_merge_needs_every_shard(source, tmp_path):
+    paths = _write_shards(source, tmp_path, 3)
+
+    with pytest.raises(ShardError, match="missing shard"):
+        load_shards(paths[:2])
+
+
+def test_merge_rejects_shards_scanned_differently(source, tmp_path):
+    (tmp_path / "a").mkdir()
+    (tmp_path / "b").mkdir()
+    first = _write_shards(source, tmp_path / "a", 2)
+    other = _write_shards(source, tmp_path / "b", 2, ignore_patterns=["*.txt"])
+
+    with pytest.raises(ShardError, match="other detection settings"):
+        load_shards([first[0], other[1]])
@@ -0,0 +1,107 @@
+from pathlib import Path
+
+from datasketch import MinHash
+
+from treepeat.models.similarity import Region, RegionSignature, SimilarityResult, SimilarRegionGroup
+from treepeat.stats import (
+    clone_fragment_counts,
+    duplication_stats,
+    fragment_types_to_list,
+    most_duplicated,
+    stats_to_dict,
+)
+
+
+def _region(path: Path, start_line: int, end_line: int, language: str = "python") -> Region:
+    return Region(
+        path=path,
+        language=language,
+        region_type="function",
+        region_name="f",
+        start_line=start_line,
+        end_line=end_line,
+    )
+
+
+def _result(tmp_path: Path) -> SimilarityResult:
+    """api/a.py and api/v2/b.py share 5 of their 10 lines; web/c.js (20 lines) has no clone."""
+    files = {"api/a.py": 10, "api/v2/b.py": 10, "web/c.js": 20}
+    for name, lines in files.items():
+        (tmp_path / name).parent.mkdir(parents=True, exist_ok=True)
+        (tmp_path / name).write_text("x = 1\n" * lines)
+    a, b, c = (_region(tmp_path / name, 1, 5) for name in files)
+    signatures = [RegionSignature(region=r, minhash=MinHash(), shingle_count=5) for r in (a, b, c)]
+    return SimilarityResult(signatures=signatures, similar_groups=[SimilarRegionGroup(regions=[a, b], similarity=1.0)])
+
+
+def test_duplication_stats_totals_the_compared_files(tmp_path):
+    stats = duplication_stats(_result(tmp_path))
+
+    assert (stats.total.lines, stats.total.duplicated_lines) == (40, 10)
+    assert stats.total.percent == 25.0
+
+
+def test_duplication_stats_by_file_and_language(tmp_path):
+    stats = duplication_stats(_result(tmp_path))
+
+    assert stats.by_file[str(tmp_path / "api/a.py")].percent == 50.0
+    assert stats.by_file[str(tmp_path / "web/c.js")].duplicated_lines == 0
+    assert stats.by_language["python"].percent == 50.0
+    assert stats.by_language["javascript"].lines == 20
+
+
+def test_duplication_stats_directories_count_the_files_below_them(tmp_path):
+    stats = duplication_stats(_result(tmp_path))
+
+    assert stats.by_directory[str(tmp_path / "api")].lines == 20
+    assert stats.by_directory[str(tmp_path / "api/v2")].lines == 10
+    assert stats.by_directory[str(tmp_path)].lines == 40
+    assert str(tmp_path.parent) not in stats.by_directory
+
+
+def test_duplication_stats_counts_overlapping_clones_once(tmp_path):
+    path = tmp_path / "a.py"
+    path.write_text("x = 1\n" * 10)
+    regions = [_region(path, 1, 6), _region(path, 4, 8)]
+    signatures = [RegionSignature(region=r, minhash=MinHash(), shingle_count=5) for r in regions]
+    result = SimilarityResult(signatures=signatures, similar_groups=[SimilarRegionGroup(regions=regions, similarity=1)])
+
+    assert duplication_stats(result).total.duplicated_lines == 8
+
+
+def test_stats_to_dict_lists_the_most_duplicated_first(tmp_path):
+    stats = duplication_stats(_result(tmp_path))
+
+    document = stats_to_dict(stats)
+
+    assert (document["lines"], document["duplicated_lines"], document["percent"]) == (40, 10, 25.0)
+    assert [row["language"] for row in document["by_language"]] == ["python", "javascript"]
+    last = {"path": str(tmp_path / "web/c.js"), "lines": 20, "duplicated_lines": 0, "percent": 0}
+    assert document["by_file"][-1] == last
+    assert [name for name, _ in most_duplicated(stats.by_language)] == ["python", "javascript"]
+
+
+def test_duplication_stats_without_compared_files():
+    stats = duplication_stats(SimilarityResult())
+
+    assert stats.total.percent == 0.0
+    assert stats_to_dict(stats)["by_file"] == []
+
+
+def test_clone_fragment_counts_groups_by_language_and_type(tmp_path):
+    group = SimilarRegionGroup(
+        regions=[_region(tmp_path / "a.ts", 1, 10, "typescript"), _region(tmp_path / "c.ts", 20, 30, "typescript")],
+        similarity=1.0,
+    )
+
+    assert clone_fragment_counts(SimilarityResult(similar_groups=[group])) == {("typescript", "function"): 2}
+
+
+def test_fragment_types_to_list_counts_extracted_regions_and_those_in_clones(tmp_path):
+    result = _result(tmp_path)
+    result.fragment_counts = {("python", "function"): 12, ("javascript", "function"): 4}
+
+    assert fragment_types_to_list(result) == [
+        {"language": "javascript", "region_type": "function", "extracted": 4, "in_clones": 0},
+        {"language": "python", "region_type": "function", "extracted": 12, "in_clones": 2},
+    ]
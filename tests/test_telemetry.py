@@ -0,0 +1,77 @@
+import time
+
+import pytest
+
+from treepeat import telemetry
+from treepeat.pipeline.verbose_metrics import record_stage_count, record_stage_timing, reset_verbose_metrics
+from treepeat.telemetry import epoch_ns, export_run_trace, nest_spans, otlp_configured
+
+
+@pytest.fixture(autouse=True)
+def _no_otlp_endpoint(monkeypatch):
+    for variable in telemetry.ENDPOINT_VARIABLES:
+        monkeypatch.delenv(variable, raising=False)
+    reset_verbose_metrics()
+
+
+def test_nest_spans_nests_stages_running_within_another():
+    spans = [("parse", 0.0, 5.0), ("shingle", 6.0, 1.0), ("walk", 0.0, 1.0), ("lsh", 7.5, 0.5)]
+
+    nested = nest_spans(spans)
+
+    assert nested == [
+        (("parse", 0.0, 5.0), None),
+        (("walk", 0.0, 1.0), 0),
+        (("shingle", 6.0, 1.0), None),
+        (("lsh", 7.5, 0.5), None),
+    ]
+
+
+def test_nest_spans_nests_deeper_than_one_level():
+    nested = nest_spans([("run", 0.0, 10.0), ("parse", 1.0, 5.0), ("walk", 1.5, 1.0), ("report", 8.0, 1.0)])
+
+    assert [parent for _, parent in nested] == [None, 0, 1, 0]
+
+
+def test_otlp_configured_by_either_endpoint_variable(monkeypatch):
+    assert not otlp_configured()
+    monkeypatch.setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://collector:4318/v1/traces")
+    assert otlp_configured()
+
+
+def test_epoch_ns_converts_monotonic_readings_to_wall_clock():
+    assert abs(epoch_ns(time.monotonic()) - time.time_ns()) < 1_000_000_000
+
+
+def test_export_run_trace_does_nothing_without_an_endpoint(monkeypatch):
+    record_stage_timing("parse", 1.0)
+    monkeypatch.setattr(telemetry, "_tracer_provider", pytest.fail)
+
+    export_run_trace({"treepeat.path": "src"})
+
+
+def test_export_run_trace_exports_a_span_per_stage_under_the_run(monkeypatch):
+    sdk_trace = pytest.importorskip("opentelemetry.sdk.trace")
+    export = pytest.importorskip("opentelemetry.sdk.trace.export")
+    in_memory = pytest.importorskip("opentelemetry.sdk.trace.export.in_memory_span_exporter")
+    exporter = in_memory.InMemorySpanExporter()
+
+    def provider():
+        tracer_provider = sdk_trace.TracerProvider()
+        tracer_provider.add_span_processor(export.SimpleSpanProcessor(exporter))
+        return tracer_provider
+
+    monkeypatch.setattr(telemetry, "_tracer_provider", provider)
+    monkeypatch.setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+    record_stage_timing("walk", 0.1)
+    record_stage_timing("parse", 0.2)
+    record_stage_count("parse", 3)
+
+    export_run_trace({"treepeat.path": "src", "treepeat.clone_classes": 2})
+
+    spans = {span.name: span for span in exporter.get_finished_spans()}
+    assert set(spans) == {"treepeat.detect", "treepeat.walk", "treepeat.parse"}
+    run = spans["treepeat.detect"]
+    assert run.attributes["treepeat.clone_classes"] == 2
+    assert spans["treepeat.parse"].parent.span_id == run.context.span_id
+    assert spans["treepeat.parse"].attributes["treepeat.items"] == 3
@@ -0,0 +1,59 @@
+from pathlib import Path
+
+from treepeat.watch import CloneState, changed_paths, take_snapshot
+
+
+def _report(*clones: tuple[str, tuple[str, ...]]) -> dict:
+    return {
+        "clone_classes": [
+            {
+                "fingerprint": fingerprint,
+                "similarity": 1.0,
+                "instances": [{"path": path, "start_line": 1, "end_line": 5, "lines": 5} for path in paths],
+            }
+            for fingerprint, paths in clones
+        ]
+    }
+
+
+def test_changed_paths_reports_created_modified_and_deleted_files(tmp_path):
+    kept, edited, deleted = (tmp_path / name for name in ("kept.py", "edited.py", "deleted.py"))
+    for path in (kept, edited, deleted):
+        path.write_text("x = 1\n")
+    before = take_snapshot([kept, edited, deleted])
+
+    edited.write_text("x = 22\n")
+    deleted.unlink()
+    created = tmp_path / "created.py"
+    created.write_text("y = 1\n")
+
+    after = take_snapshot([kept, edited, deleted, created])
+
+    assert changed_paths(before, after) == {edited.resolve(), deleted.resolve(), created.resolve()}
+
+
+def test_clone_state_tracks_changes_of_the_rescanned_files(tmp_path):
+    a, b, c, d = (str(tmp_path / name) for name in ("a.py", "b.py", "c.py", "d.py"))
+    state = CloneState()
+    initial = state.update(_report(("clone-ab000001", (a, b)), ("clone-cd000002", (c, d))), tmp_path, None)
+    assert len(state) == 2
+    assert len(initial["introduced"]) == 2
+
+    # c.py was edited so that it no longer matches d.py, and now copies a.py
+    changes = state.update(_report(("clone-ab000001", (a, b, c))), tmp_path, {Path(c)})
+
+    assert [clone["fingerprint"] for clone in changes["introduced"]] == []
+    assert [clone["fingerprint"] for clone in changes["removed"]] == ["clone-cd000002"]
+    assert [change["fingerprint"] for change in changes["grown"]] == ["clone-ab000001"]
+    assert len(state) == 1
+
+
+def test_clone_state_keeps_clones_of_untouched_files(tmp_path):
+    a, b, c = (str(tmp_path / name) for name in ("a.py", "b.py", "c.py"))
+    state = CloneState()
+    state.update(_report(("clone-ab000001", (a, b))), tmp_path, None)
+
+    changes = state.update(_report(), tmp_path, {Path(c)})
+
+    assert changes == {"introduced": [], "removed": [], "grown": []}
+    assert len(state) == 1
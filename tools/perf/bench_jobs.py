@@ -0,0 +1,65 @@
+# bench_jobs.py — benchmark group verification across --jobs values on synthetic fragments
+#
+# Run:
+#   uv run python tools/perf/bench_jobs.py --groups 400 --group-size 6 --jobs 1 --jobs 4 --jobs 8
+
+import random
+import time
+from pathlib import Path
+
+import click
+
+from treepeat.models.shingle import ShingledRegion, ShingleList
+from treepeat.models.similarity import Region, SimilarRegionGroup
+from treepeat.pipeline.verification import verify_similar_groups
+
+_VOCABULARY = [f"node_{i}→child_{i % 7}→leaf_{i % 13}" for i in range(200)]
+
+
+def _synthetic_groups(
+    groups: int, group_size: int, shingles: int, seed: int
+) -> tuple[list[SimilarRegionGroup], list[ShingledRegion]]:
+    """Build candidate groups of near-identical fragments sharing one LSH bucket each."""
+    rng = random.Random(seed)
+    candidates: list[SimilarRegionGroup] = []
+    shingled: list[ShingledRegion] = []
+    for g in range(groups):
+        base = [rng.choice(_VOCABULARY) for _ in range(shingles)]
+        regions = []
+        for m in range(group_size):
+            variant = [s if rng.random() > 0.05 else rng.choice(_VOCABULARY) for s in base]
+            region = Region(
+                path=Path(f"group_{g}/member_{m}.py"),
+                language="python",
+                region_type="function_definition",
+                region_name=f"f_{g}_{m}",
+                start_line=1,
+                end_line=shingles // 4 + 1,
+            )
+            regions.append(region)
+            shingled.append(ShingledRegion(region=region, shingles=ShingleList(shingles=variant)))
+        candidates.append(SimilarRegionGroup(regions=regions, similarity=1.0))
+    return candidates, shingled
+
+
+@click.command()
+@click.option("--groups", default=400, show_default=True, help="Number of candidate groups")
+@click.option("--group-size", default=6, show_default=True, help="Fragments per group")
+@click.option("--shingles", default=400, show_default=True, help="Shingles per fragment")
+@click.option("--jobs", "jobs_values", multiple=True, type=int, default=(1, 4), help="Job counts to compare")
+@click.option("--seed", default=0, show_default=True, help="Random seed")
+def main(groups: int, group_size: int, shingles: int, jobs_values: tuple[int, ...], seed: int) -> None:
+    candidates, shingled = _synthetic_groups(groups, group_size, shingles, seed)
+    baseline = None
+    for jobs in jobs_values:
+        start = time.perf_counter()
+        verified = verify_similar_groups(candidates, shingled, rules=[], jobs=jobs)
+        elapsed = time.perf_counter() - start
+        summary = [(g.fingerprint, g.similarity) for g in verified]
+        baseline = baseline or summary
+        identical = "identical" if summary == baseline else "DIFFERENT"
+        click.echo(f"jobs={jobs:<3} {elapsed:8.2f}s  ({identical} to jobs={jobs_values[0]})")
+
+
+if __name__ == "__main__":
+    main()
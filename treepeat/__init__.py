@@ -0,0 +1,31 @@
+"""treepeat: find similar code with tree-sitter; ``scan`` embeds clone detection in other tools."""
+
+from treepeat.api import (
+    REPORT_FORMATS,
+    Detector,
+    FormatReporter,
+    PipelineDetector,
+    Reporter,
+    ScanError,
+    SourceWalker,
+    Walker,
+    scan,
+)
+from treepeat.config import PipelineSettings
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+
+__all__ = [
+    "REPORT_FORMATS",
+    "Detector",
+    "FormatReporter",
+    "PipelineDetector",
+    "PipelineSettings",
+    "Region",
+    "Reporter",
+    "ScanError",
+    "SimilarRegionGroup",
+    "SimilarityResult",
+    "SourceWalker",
+    "Walker",
+    "scan",
+]
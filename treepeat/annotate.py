@@ -0,0 +1,178 @@
+import difflib
+import logging
+import re
+from collections import defaultdict
+from pathlib import Path
+
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+from treepeat.pipeline.notebook import is_notebook
+
+logger = logging.getLogger(__name__)
+
+MARKER = "treepeat: clone of"
+
+# Line comment delimiters (prefix, suffix) per language
+COMMENT_SYNTAX: dict[str, tuple[str, str]] = {
+    "bash": ("# ", ""),
+    "c": ("// ", ""),
+    "cpp": ("// ", ""),
+    "csharp": ("// ", ""),
+    "css": ("/* ", " */"),
+    "dockerfile": ("# ", ""),
+    "go": ("// ", ""),
+    "hcl": ("# ", ""),
+    "html": ("<!-- ", " -->"),
+    "java": ("// ", ""),
+    "javascript": ("// ", ""),
+    "jsx": ("// ", ""),
+    "kotlin": ("// ", ""),
+    "markdown": ("<!-- ", " -->"),
+    "php": ("// ", ""),
+    "proto": ("// ", ""),
+    "python": ("# ", ""),
+    "ruby": ("# ", ""),
+    "rust": ("// ", ""),
+    "sql": ("-- ", ""),
+    "svelte": ("<!-- ", " -->"),
+    "swift": ("// ", ""),
+    "template": ("<!-- ", " -->"),
+    "thrift": ("// ", ""),
+    "tsx": ("// ", ""),
+    "typescript": ("// ", ""),
+    "vue": ("<!-- ", " -->"),
+    "yaml": ("# ", ""),
+}
+
+_MARKER_LINE = re.compile(r"^\s*(?:#|//|/\*|<!--|--)\s*" + re.escape(MARKER) + r" ")
+
+
+def is_annotation(line: str) -> bool:
+    """True if a source line is a clone marker written by treepeat."""
+    return _MARKER_LINE.match(line) is not None
+
+
+def _annotatable(region: Region) -> bool:
+    """True if a region lives in a file we know how to comment."""
+    return region.language in COMMENT_SYNTAX and not is_notebook(region.path)
+
+
+def _read_lines(path: Path) -> list[str]:
+    """Read a file's lines, keeping line endings."""
+    return path.read_text(encoding="utf-8").splitlines(keepends=True)
+
+
+_Placements = dict[Path, dict[int, list[tuple[SimilarRegionGroup, Region]]]]
+
+
+def _collect_placements(result: SimilarityResult) -> _Placements:
+    """Group clone instances by file and start line."""
+    placements: _Placements = defaultdict(lambda: defaultdict(list))
+    for group in result.similar_groups:
+        for region in group.regions:
+            if _annotatable(region):
+                placements[region.path][region.start_line].append((group, region))
+            else:
+                logger.debug("Cannot annotate %s (%s)", region.path, region.language)
+    return placements
+
+
+def _final_line_numbers(lines: list[str], inserted: dict[int, int]) -> dict[int, int]:
+    """Map original line numbers to their numbers once old markers are dropped and new ones inserted."""
+    mapping: dict[int, int] = {}
+    offset = 0
+    for number, line in enumerate(lines, start=1):
+        if is_annotation(line):
+            offset -= 1
+            continue
+        offset += inserted.get(number, 0)
+        mapping[number] = number + offset
+    return mapping
+
+
+def _marker_text(group: SimilarRegionGroup, region: Region, line_maps: dict[Path, dict[int, int]]) -> str:
+    """Describe a region's clone group and where its other copies live (after annotation)."""
+    others = ", ".join(
+        f"{r.path}:{line_maps.get(r.path, {}).get(r.start_line, r.start_line)}"
+        for r in group.regions
+        if r is not region
+    )
+    return f"{MARKER} {group.fingerprint or 'group'} (also in {others})"
+
+
+def _marker(group: SimilarRegionGroup, region: Region, line_maps: dict[Path, dict[int, int]]) -> str:
+    """Build the full comment line (without indentation) for one clone instance."""
+    prefix, suffix = COMMENT_SYNTAX[region.language]
+    return f"{prefix}{_marker_text(group, region, line_maps)}{suffix}"
+
+
+def collect_annotations(result: SimilarityResult) -> dict[Path, dict[int, list[str]]]:
+    """Map each annotatable file to the markers to insert above each region start line.
+
+    Line numbers quoted in markers are those the regions will have once every
+    file is annotated, so re-running on annotated sources changes nothing.
+    """
+    placements = _collect_placements(result)
+    line_maps = {
+        path: _final_line_numbers(_read_lines(path), {line: len(items) for line, items in by_line.items()})
+        for path, by_line in placements.items()
+    }
+    return {
+        path: {
+            line: [_marker(group, region, line_maps) for group, region in items]
+            for line, items in by_line.items()
+        }
+        for path, by_line in placements.items()
+    }
+
+
+def _indentation(line: str) -> str:
+    """Return the leading whitespace of a line."""
+    return line[: len(line) - len(line.lstrip())]
+
+
+def annotate_lines(lines: list[str], markers: dict[int, list[str]]) -> list[str]:
+    """Insert markers above their (1-indexed) lines, replacing any previous markers."""
+    annotated: list[str] = []
+    for number, line in enumerate(lines, start=1):
+        if is_annotation(line):
+            continue
+        newline = "\r\n" if line.endswith("\r\n") else "\n"
+        annotated.extend(f"{_indentation(line)}{marker}{newline}" for marker in markers.get(number, []))
+        annotated.append(line)
+    return annotated
+
+
+def remove_annotation_lines(lines: list[str]) -> list[str]:
+    """Drop every treepeat clone marker."""
+    return [line for line in lines if not is_annotation(line)]
+
+
+def _rewrite(path: Path, original: list[str], updated: list[str], dry_run: bool) -> str | None:
+    """Write the updated lines unless dry_run; return a unified diff of the change, or None."""
+    if updated == original:
+        return None
+    if not dry_run:
+        path.write_text("".join(updated), encoding="utf-8")
+    return "".join(difflib.unified_diff(original, updated, fromfile=str(path), tofile=str(path)))
+
+
+def annotate_sources(result: SimilarityResult, dry_run: bool = False) -> list[str]:
+    """Write clone markers above every clone instance; return a diff per changed file."""
+    diffs = []
+    for path, markers in sorted(collect_annotations(result).items()):
+        original = _read_lines(path)
+        diff = _rewrite(path, original, annotate_lines(original, markers), dry_run)
+        if diff is not None:
+            diffs.append(diff)
+    return diffs
+
+
+def remove_annotations(files: list[Path], dry_run: bool = False) -> list[str]:
+    """Strip clone markers from files; return a diff per changed file."""
+    diffs = []
+    for path in files:
+        original = _read_lines(path)
+        diff = _rewrite(path, original, remove_annotation_lines(original), dry_run)
+        if diff is not None:
+            diffs.append(diff)
+    return diffs
@@ -0,0 +1,106 @@
+"""Library API: embed clone detection in other tools instead of running the CLI and parsing its output.
+
+    import treepeat
+
+    result = treepeat.scan("src", treepeat.PipelineSettings())
+    for group in result.similar_groups:
+        print(group.fingerprint, [str(region.path) for region in group.regions])
+    print(treepeat.FormatReporter("sarif").report(result))
+
+Each stage can be swapped: a ``Walker`` chooses the files, a ``Detector``
+finds the clone groups among them and a ``Reporter`` renders the result.
+"""
+
+from collections.abc import Callable
+from pathlib import Path
+from typing import Protocol
+
+from treepeat.config import PipelineSettings, get_settings, set_settings
+from treepeat.formatters import FILE_FORMATTERS
+from treepeat.formatters.markdown import format_as_markdown
+from treepeat.formatters.sarif import format_as_sarif
+from treepeat.models.similarity import GroupCallback, SimilarityResult
+from treepeat.pipeline.parse import collect_source_files
+from treepeat.pipeline.pipeline import run_pipeline
+
+# Formats a FormatReporter renders
+REPORT_FORMATS: dict[str, Callable[[SimilarityResult], str]] = {
+    **FILE_FORMATTERS,
+    "markdown": format_as_markdown,
+    "sarif": format_as_sarif,
+}
+
+
+class ScanError(Exception):
+    """A scan that could not be run."""
+
+
+class Walker(Protocol):
+    """Chooses the source files of a scan."""
+
+    def walk(self, root: Path) -> list[Path]: ...
+
+
+class Detector(Protocol):
+    """Finds the clone groups among the files of a scan, calling ``on_group`` with each as it is found."""
+
+    def detect(self, root: Path, files: list[Path], on_group: GroupCallback | None) -> SimilarityResult: ...
+
+
+class Reporter(Protocol):
+    """Renders the result of a scan as text."""
+
+    def report(self, result: SimilarityResult) -> str: ...
+
+
+class SourceWalker:
+    """The files the CLI scans: source files with a grammar, honoring ignore files and the scope settings."""
+
+    def walk(self, root: Path) -> list[Path]:
+        return collect_source_files(root)
+
+
+class PipelineDetector:
+    """The detection pipeline of the CLI (the token fallback, when enabled, still walks the root itself)."""
+
+    def detect(self, root: Path, files: list[Path], on_group: GroupCallback | None) -> SimilarityResult:
+        return run_pipeline(root, on_group=on_group, files=files)
+
+
+class FormatReporter:
+    """Renders a result in one of the CLI's output formats (see REPORT_FORMATS)."""
+
+    def __init__(self, output_format: str = "json"):
+        if output_format not in REPORT_FORMATS:
+            raise ValueError(f"Unknown format {output_format!r}, expected one of {', '.join(sorted(REPORT_FORMATS))}")
+        self.output_format = output_format
+
+    def report(self, result: SimilarityResult) -> str:
+        return REPORT_FORMATS[self.output_format](result)
+
+
+def scan(
+    path: str | Path,
+    settings: PipelineSettings | None = None,
+    *,
+    walker: Walker | None = None,
+    detector: Detector | None = None,
+    on_group: GroupCallback | None = None,
+) -> SimilarityResult:
+    """Find the clones under ``path`` with ``settings`` (the default settings when None).
+
+    The settings are made current for the duration of the scan, so run one
+    scan at a time per process. Raises ScanError if ``path`` does not exist.
+    """
+    root = Path(path)
+    if not root.exists():
+        raise ScanError(f"{root} does not exist")
+    settings = settings or PipelineSettings()
+    previous = get_settings()
+    set_settings(settings)
+    try:
+        files = (walker or SourceWalker()).walk(root)
+        result = (detector or PipelineDetector()).detect(root, files, on_group)
+    finally:
+        set_settings(previous)
+    return result
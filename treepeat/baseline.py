@@ -0,0 +1,59 @@
+import json
+from pathlib import Path
+from typing import Any
+
+from treepeat.formatters.json import SCHEMA_VERSION
+from treepeat.pipeline.fingerprint import normalize_fingerprint
+
+# Where 'treepeat baseline' writes, and where 'detect --baseline' is usually pointed
+DEFAULT_BASELINE_PATH = Path(".treepeat-baseline.json")
+
+
+def load_baseline(path: Path) -> set[str]:
+    """Read the clone fingerprints recorded in a baseline (any treepeat json report).
+
+    Raises ValueError if the file is not a treepeat json report.
+    """
+    try:
+        document = json.loads(path.read_text(encoding="utf-8"))
+        clone_classes = document["clone_classes"]
+        return {normalize_fingerprint(clone["fingerprint"]) for clone in clone_classes if clone["fingerprint"]}
+    except (OSError, ValueError, KeyError, TypeError, AttributeError) as e:
+        raise ValueError(f"{path} is not a treepeat json report: {e}") from e
+
+
+def _recorded_clone_classes(path: Path) -> list[dict[str, Any]]:
+    """The clone classes of an existing baseline ([] if there is none yet)."""
+    if not path.exists():
+        return []
+    load_baseline(path)
+    return list(json.loads(path.read_text(encoding="utf-8"))["clone_classes"])
+
+
+def _baseline_document(clone_classes: list[dict[str, Any]]) -> dict[str, Any]:
+    """A json report holding only the given clone classes."""
+    instances = [instance for clone in clone_classes for instance in clone["instances"]]
+    return {
+        "schema_version": SCHEMA_VERSION,
+        "tool": "treepeat",
+        "summary": {
+            "files": len({instance["path"] for instance in instances}),
+            "regions": len(instances),
+            "clone_classes": len(clone_classes),
+            "clone_instances": len(instances),
+        },
+        "clone_classes": clone_classes,
+    }
+
+
+def record_accepted(path: Path, clone_classes: list[dict[str, Any]], accepted: set[str]) -> int:
+    """Rewrite a baseline so that, of the reviewed ``clone_classes``, exactly the ``accepted`` ones are in it.
+
+    Entries for clone classes that were not reviewed are kept. Returns the number of entries written.
+    Raises ValueError if an existing file is not a treepeat json report.
+    """
+    reviewed = {clone["fingerprint"] for clone in clone_classes}
+    kept = [clone for clone in _recorded_clone_classes(path) if clone["fingerprint"] not in reviewed]
+    entries = kept + [clone for clone in clone_classes if clone["fingerprint"] in accepted]
+    path.write_text(json.dumps(_baseline_document(entries), indent=2) + "\n", encoding="utf-8")
+    return len(entries)
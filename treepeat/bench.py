@@ -0,0 +1,120 @@
+"""Benchmark the detection pipeline: throughput, stage times and peak memory per language."""
+
+import multiprocessing
+import statistics
+import sys
+from collections import Counter
+from concurrent.futures import ProcessPoolExecutor
+from dataclasses import dataclass
+from pathlib import Path
+
+from treepeat.config import PipelineSettings, set_settings
+from treepeat.pipeline.languages import LANGUAGE_EXTENSIONS, LANGUAGE_FILENAMES
+from treepeat.pipeline.parse import collect_source_files, detect_language
+from treepeat.pipeline.pipeline import run_pipeline
+from treepeat.pipeline.verbose_metrics import get_verbose_metrics, reset_verbose_metrics
+
+# Pipeline stages timed as building the index (regions to signatures), and as matching (candidates to clones)
+INDEX_STAGES = ("extract", "shingle", "minhash")
+MATCH_STAGES = ("lsh",)
+
+
+@dataclass
+class BenchRun:
+    """Measurements of one scan of the files of one language."""
+
+    language: str
+    strategy: str
+    files: int
+    source_bytes: int
+    parse_seconds: float
+    index_seconds: float
+    match_seconds: float
+    peak_rss: int | None
+
+    @property
+    def files_per_second(self) -> float:
+        return self.files / self.parse_seconds if self.parse_seconds else 0.0
+
+    @property
+    def mb_per_second(self) -> float:
+        return self.source_bytes / 2**20 / self.parse_seconds if self.parse_seconds else 0.0
+
+
+def scanned_languages(path: Path, settings: PipelineSettings) -> Counter[str]:
+    """Number of files of each language a scan of ``path`` would parse."""
+    set_settings(settings)
+    return Counter(language for language in map(detect_language, collect_source_files(path)) if language)
+
+
+def language_patterns(language: str) -> list[str]:
+    """--include globs selecting the files of one language (by extension or file name)."""
+    extensions = [f"*{extension}" for extension in LANGUAGE_EXTENSIONS.get(language, [])]
+    return extensions + LANGUAGE_FILENAMES.get(language, [])
+
+
+def _peak_rss() -> int | None:
+    """Peak resident set size of this process in bytes, where the platform reports it."""
+    if sys.platform == "win32":
+        return None
+    import resource
+
+    peak = resource.getrusage(resource.RUSAGE_SELF).ru_maxrss
+    # Linux reports KiB, macOS bytes
+    return int(peak) if sys.platform == "darwin" else int(peak) * 1024
+
+
+def _stage_seconds(stages: tuple[str, ...]) -> float:
+    timings = get_verbose_metrics().stage_timings
+    return sum(timings.get(stage, 0.0) for stage in stages)
+
+
+def bench_once(path: Path, settings: PipelineSettings, language: str) -> BenchRun:
+    """Scan the files of one language and measure it (run in a fresh worker process, so peak RSS is its own)."""
+    scoped = settings.model_copy(update={"include_patterns": language_patterns(language), "cache_dir": None})
+    set_settings(scoped)
+    files = collect_source_files(path)
+    reset_verbose_metrics()
+    run_pipeline(path)
+    return BenchRun(
+        language=language,
+        strategy=settings.shingle.strategy,
+        files=get_verbose_metrics().stage_counts.get("parse", 0),
+        source_bytes=sum(f.stat().st_size for f in files),
+        parse_seconds=get_verbose_metrics().stage_timings.get("parse", 0.0),
+        index_seconds=_stage_seconds(INDEX_STAGES),
+        match_seconds=_stage_seconds(MATCH_STAGES),
+        peak_rss=_peak_rss(),
+    )
+
+
+def _median_run(runs: list[BenchRun]) -> BenchRun:
+    """One run summarizing repeated ones: the median of each time, and the largest peak RSS."""
+    rss = [run.peak_rss for run in runs if run.peak_rss is not None]
+    return BenchRun(
+        language=runs[0].language,
+        strategy=runs[0].strategy,
+        files=runs[0].files,
+        source_bytes=runs[0].source_bytes,
+        parse_seconds=statistics.median(run.parse_seconds for run in runs),
+        index_seconds=statistics.median(run.index_seconds for run in runs),
+        match_seconds=statistics.median(run.match_seconds for run in runs),
+        peak_rss=max(rss) if rss else None,
+    )
+
+
+def run_bench(
+    path: Path, settings: PipelineSettings, languages: list[str], strategies: list[str], repeat: int
+) -> list[BenchRun]:
+    """Scan the files of each language ``repeat`` times with each strategy, each scan in a fresh process."""
+    configurations = [
+        settings.model_copy(update={"shingle": settings.shingle.model_copy(update={"strategy": strategy})})
+        for strategy in strategies
+    ]
+    context = multiprocessing.get_context("spawn")
+    with ProcessPoolExecutor(max_workers=1, mp_context=context, max_tasks_per_child=1) as executor:
+        return [
+            _median_run([executor.submit(bench_once, path, configuration, language).result() for _ in range(repeat)])
+            for configuration in configurations
+            for language in languages
+        ]
@@ -2,12 +2,39 @@
 
 import logging
 from importlib.metadata import PackageNotFoundError, version
+from pathlib import Path
+from typing import Any, cast
 
 import click
 from rich.console import Console
 from rich.logging import RichHandler
 
-from treepeat.cli.commands import detect, list_ruleset, treesitter
+from treepeat.cli.commands import (
+    baseline,
+    bench,
+    browse,
+    detect,
+    diff,
+    explain,
+    hook,
+    hotspots,
+    list_ruleset,
+    lsp,
+    mcp,
+    merge,
+    publish,
+    record,
+    remove_annotations,
+    serve,
+    stats,
+    treesitter,
+    trend,
+    watch,
+)
+from treepeat.config_file import DETECTING_COMMANDS, ConfigFileError, find_config_file, load_config_file
+from treepeat.pipeline.languages.plugins import PluginError, default_plugins_dir, load_plugins
+from treepeat.pipeline.rules.parser import RuleParseError
+from treepeat.pipeline.rules_factory import BUILTIN_RULESETS, get_ruleset_with_descriptions
 
 console = Console()
 
@@ -28,6 +55,102 @@ def setup_logging(log_level: str) -> None:
     )
 
 
+def _option_names(command: click.Command) -> dict[str, str]:
+    """Map the keys an option may have in a config file (its long flag without dashes, or its name) to its name."""
+    names: dict[str, str] = {}
+    for param in command.params:
+        if param.name:
+            names[param.name] = param.name
+            names.update({opt.lstrip("-"): param.name for opt in param.opts if opt.startswith("--")})
+    return names
+
+
+def _command_defaults(values: dict[str, Any], command: click.Command, where: str) -> dict[str, Any]:
+    """Translate config file keys into default values of a command's parameters."""
+    names = _option_names(command)
+    unknown = sorted(set(values) - set(names))
+    if unknown:
+        raise click.UsageError(f"Unknown option(s) in {where}: {', '.join(unknown)}")
+    return {names[key]: value for key, value in values.items()}
+
+
+def _subcommand_defaults(group: click.Group, name: str, values: Any, source: Path) -> dict[str, Any]:
+    """Translate the table of one subcommand."""
+    command = group.commands.get(name)
+    if command is None or not isinstance(values, dict):
+        raise click.UsageError(f"Unknown command table [{name}] in {source}")
+    return _command_defaults(values, command, f"[{name}] of {source}")
+
+
+def _is_table(key: str, value: Any, group: click.Group) -> bool:
+    """True if a top-level config key holds the options of a subcommand."""
+    return isinstance(value, dict) or key in group.commands
+
+
+def _share_detect_table(defaults: dict[str, Any]) -> None:
+    """Apply the [detect] options to the other commands that run detect, under their own tables."""
+    for name in DETECTING_COMMANDS:
+        defaults[name] = {**defaults.get("detect", {}), **defaults.get(name, {})}
+
+
+def _default_map(config: dict[str, Any], group: click.Group, source: Path) -> dict[str, Any]:
+    """Build click defaults from a config file: top-level keys set global options, tables set subcommand options.
+
+    The [detect] table also applies to the other commands that run detect; their own tables win.
+    """
+    options = {key: value for key, value in config.items() if not _is_table(key, value, group)}
+    defaults = _command_defaults(options, group, str(source))
+    for name in config.keys() - options.keys():
+        defaults[name] = _subcommand_defaults(group, name, config[name], source)
+    _share_detect_table(defaults)
+    return defaults
+
+
+def _load_config(ctx: click.Context, param: click.Parameter, value: Path | None) -> None:
+    """Use the settings of --config (or of the nearest config file) as defaults; flags override them."""
+    path = value or find_config_file(Path.cwd())
+    if path is None:
+        return
+    try:
+        config = load_config_file(path)
+    except ConfigFileError as e:
+        raise click.BadParameter(str(e), ctx=ctx, param=param) from e
+    rulesets = config.pop("rulesets", {})
+    if not isinstance(rulesets, dict):
+        raise click.BadParameter(f"[rulesets] of {path} is not a table", ctx=ctx, param=param)
+    ctx.ensure_object(dict)["rulesets"] = rulesets
+    ctx.default_map = _default_map(config, cast(click.Group, ctx.command), path)
+
+
+def _check_ruleset(ctx: click.Context, param: click.Parameter, value: str) -> str:
+    """Accept a built-in ruleset (in any case) or one defined in the [rulesets] of the config file."""
+    if value.lower() in BUILTIN_RULESETS:
+        return value.lower()
+    defined = ctx.ensure_object(dict).get("rulesets", {})
+    if value not in defined:
+        choices = ", ".join([*BUILTIN_RULESETS, *sorted(defined)])
+        raise click.BadParameter(f"'{value}' is not a built-in or configured ruleset (choose from {choices})")
+    try:
+        get_ruleset_with_descriptions(value, custom_rulesets=defined)
+    except RuleParseError as e:
+        raise click.BadParameter(str(e)) from e
+    return value
+
+
+def _load_plugins(directory: Path | None) -> None:
+    """Register the grammar plugins of --plugins-dir, or of the default plugins directory when it exists.
+
+    A broken plugin of --plugins-dir is an error; one of the default directory is skipped with a warning.
+    """
+    if directory is None:
+        load_plugins(default_plugins_dir(), strict=False)
+        return
+    try:
+        load_plugins(directory)
+    except PluginError as e:
+        raise click.ClickException(str(e)) from e
+
+
 @click.group()
 @click.pass_context
 @click.version_option(version=get_version(), prog_name="treepeat")
@@ -41,17 +164,42 @@ def setup_logging(log_level: str) -> None:
 @click.option(
     "--ruleset",
     "-r",
-    type=click.Choice(["none", "default", "loose"], case_sensitive=False),
     default="default",
-    help="Built-in ruleset profile to use (default: default)",
+    callback=_check_ruleset,
+    help=(
+        "Ruleset profile to use: none, default, loose, or one defined under [rulesets] in the config file "
+        "(default: default)"
+    ),
+)
+@click.option(
+    "--config",
+    type=click.Path(exists=True, dir_okay=False, path_type=Path),
+    is_eager=True,
+    expose_value=False,
+    callback=_load_config,
+    help=(
+        "Read default options from this TOML file (default: the nearest .treepeat.toml, treepeat.toml "
+        "or pyproject.toml with a [tool.treepeat] table, up to the repository root)"
+    ),
+)
+@click.option(
+    "--plugins-dir",
+    type=click.Path(exists=True, file_okay=False, path_type=Path),
+    default=None,
+    help=(
+        "Directory of grammar plugins: subdirectories with a plugin.toml naming a compiled tree-sitter grammar, "
+        "its file extensions, regions and default rules (default: $XDG_CONFIG_HOME/treepeat/plugins)"
+    ),
 )
 def main(
     ctx: click.Context,
     log_level: str,
     ruleset: str,
+    plugins_dir: Path | None,
 ) -> None:
     """Tree-sitter based similarity detector."""
     setup_logging(log_level.upper())
+    _load_plugins(plugins_dir)
 
     # Store common options in context for subcommands
     ctx.ensure_object(dict)
@@ -61,8 +209,25 @@ def main(
 
 # Register subcommands
 main.add_command(detect)
+main.add_command(baseline)
+main.add_command(bench)
+main.add_command(browse)
+main.add_command(diff)
+main.add_command(explain)
+main.add_command(hook)
+main.add_command(hotspots)
 main.add_command(treesitter)
+main.add_command(trend)
 main.add_command(list_ruleset)
+main.add_command(lsp)
+main.add_command(mcp)
+main.add_command(merge)
+main.add_command(publish)
+main.add_command(record)
+main.add_command(remove_annotations)
+main.add_command(serve)
+main.add_command(stats)
+main.add_command(watch)
 
 
 if __name__ == "__main__":
@@ -1,7 +1,45 @@
 """CLI subcommands."""
 
+from .baseline import baseline
+from .bench import bench
+from .browse import browse
 from .detect import detect
+from .diff import diff
+from .explain import explain
+from .hook import hook
+from .hotspots import hotspots
 from .list_ruleset import list_ruleset
+from .lsp import lsp
+from .mcp import mcp
+from .merge import merge
+from .publish import publish
+from .record import record
+from .remove_annotations import remove_annotations
+from .serve import serve
+from .stats import stats
 from .treesitter import treesitter
+from .trend import trend
+from .watch import watch
 
-__all__ = ["detect", "list_ruleset", "treesitter"]
+__all__ = [
+    "baseline",
+    "bench",
+    "browse",
+    "detect",
+    "diff",
+    "explain",
+    "hook",
+    "hotspots",
+    "list_ruleset",
+    "lsp",
+    "mcp",
+    "merge",
+    "publish",
+    "record",
+    "remove_annotations",
+    "serve",
+    "stats",
+    "treesitter",
+    "trend",
+    "watch",
+]
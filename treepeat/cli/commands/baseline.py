@@ -0,0 +1,38 @@
+from pathlib import Path
+from typing import Any
+
+import click
+from rich.console import Console
+
+from treepeat.baseline import DEFAULT_BASELINE_PATH, load_baseline
+from treepeat.cli.commands.detect import detect, detection_params
+
+console = Console(stderr=True)
+
+
+@click.pass_context
+def _baseline(ctx: click.Context, baseline_output: Path, **detect_options: Any) -> None:
+    """Snapshot the current clones into a baseline file for 'detect --baseline'."""
+    ctx.invoke(detect, output_format="json", output=baseline_output, **detect_options)
+    count = len(load_baseline(baseline_output))
+    console.print(f"Recorded {count} clone class(es) in {baseline_output}")
+
+
+baseline = click.Command(
+    name="baseline",
+    callback=_baseline,
+    params=[
+        *detection_params(),
+        click.Option(
+            ["--output", "-o", "baseline_output"],
+            type=click.Path(dir_okay=False, path_type=Path),
+            default=DEFAULT_BASELINE_PATH,
+            show_default=True,
+            help="Baseline file to write",
+        ),
+    ],
+    help=(
+        "Snapshot the clones found in PATH into a baseline file. Run 'detect --baseline FILE' with the same "
+        "options to report only clones that are not in the baseline."
+    ),
+)
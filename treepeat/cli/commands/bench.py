@@ -0,0 +1,101 @@
+import json
+from dataclasses import asdict
+from pathlib import Path
+
+import click
+from rich.console import Console
+from rich.table import Table
+
+from treepeat.bench import BenchRun, run_bench, scanned_languages
+from treepeat.config import LSHSettings, PipelineSettings, RulesSettings
+from treepeat.pipeline.winnow import STRATEGIES
+
+console = Console()
+
+
+def _megabytes(size: int | None) -> str:
+    return "-" if size is None else f"{size / 2**20:.1f}"
+
+
+def _print_table(runs: list[BenchRun], repeat: int) -> None:
+    """One row per language and strategy, with the median of the repeated scans."""
+    table = Table(title=f"Median of {repeat} scan(s) per row")
+    for column in ("Language", "Strategy", "Files", "MB", "Files/s", "MB/s", "Parse (s)", "Index (s)", "Match (s)"):
+        table.add_column(column, justify="left" if column in ("Language", "Strategy") else "right")
+    table.add_column("Peak RSS (MB)", justify="right")
+    for run in runs:
+        table.add_row(
+            run.language,
+            run.strategy,
+            str(run.files),
+            _megabytes(run.source_bytes),
+            f"{run.files_per_second:.1f}",
+            f"{run.mb_per_second:.2f}",
+            f"{run.parse_seconds:.3f}",
+            f"{run.index_seconds:.3f}",
+            f"{run.match_seconds:.3f}",
+            _megabytes(run.peak_rss),
+        )
+    console.print(table)
+
+
+def _languages(path: Path, settings: PipelineSettings, selected: tuple[str, ...]) -> list[str]:
+    """The languages to benchmark: those found under PATH, narrowed to --language when given."""
+    found = scanned_languages(path, settings)
+    missing = sorted(set(selected) - found.keys())
+    if missing:
+        raise click.ClickException(f"no {', '.join(missing)} files under {path}")
+    return sorted(selected or found)
+
+
+@click.command()
+@click.pass_context
+@click.argument("path", type=click.Path(exists=True, path_type=Path))
+@click.option("--repeat", type=click.IntRange(min=1), default=3, help="Scans per language and strategy (default: 3)")
+@click.option(
+    "--strategy",
+    "strategies",
+    type=click.Choice(STRATEGIES, case_sensitive=False),
+    multiple=True,
+    help="Strategy to benchmark (repeatable; default: every strategy)",
+)
+@click.option("--language", "languages", multiple=True, help="Only benchmark this language (repeatable)")
+@click.option("--min-lines", type=int, default=5, help="Minimum number of lines for a match (default: 5)")
+@click.option(
+    "--similarity", type=click.FloatRange(1, 100), default=100.0, help="Percent similarity from 1-100 (default: 100)"
+)
+@click.option("--jobs", "-j", type=click.IntRange(min=1), default=1, help="Number of workers (default: 1)")
+@click.option(
+    "--format",
+    "output_format",
+    type=click.Choice(["table", "json"], case_sensitive=False),
+    default="table",
+    help="table for people, json to keep and compare between versions",
+)
+def bench(
+    ctx: click.Context,
+    path: Path,
+    repeat: int,
+    strategies: tuple[str, ...],
+    languages: tuple[str, ...],
+    min_lines: int,
+    similarity: float,
+    jobs: int,
+    output_format: str,
+) -> None:
+    """Benchmark scanning PATH: parse throughput, index build and match time, and peak RSS per language.
+
+    Every scan runs in a fresh process without the fingerprint cache, so the
+    numbers of each row are its own.
+    """
+    settings = PipelineSettings(
+        rules=RulesSettings(ruleset=ctx.obj["ruleset"], custom_rulesets=ctx.obj.get("rulesets") or {}),
+        lsh=LSHSettings(similarity_percent=similarity / 100.0, min_lines=min_lines),
+        jobs=jobs,
+    )
+    selected = _languages(path, settings, languages)
+    runs = run_bench(path, settings, selected, [s.lower() for s in strategies] or list(STRATEGIES), repeat)
+    if output_format.lower() == "json":
+        click.echo(json.dumps([asdict(run) for run in runs], indent=2))
+    else:
+        _print_table(runs, repeat)
@@ -0,0 +1,136 @@
+import json
+from pathlib import Path
+from typing import Any
+
+import click
+from rich.columns import Columns
+from rich.console import Console
+from rich.markup import escape
+from rich.panel import Panel
+from rich.syntax import Syntax
+
+from treepeat.baseline import DEFAULT_BASELINE_PATH, load_baseline, record_accepted
+from treepeat.formatters.json import region_from_dict
+from treepeat.formatters.snippets import describe_region, read_region_lines
+
+console = Console()
+
+_KEYS = "[n]ext  [p]revious  [a]ccept/unaccept  [1-9..] jump  [w]rite  [q]uit"
+
+
+def _instance_panel(instance: dict[str, Any]) -> Panel:
+    """Show one clone instance with syntax highlighting."""
+    region = region_from_dict(instance)
+    code = "\n".join(read_region_lines(region))
+    lexer = Syntax.guess_lexer(str(region.path), code)
+    syntax = Syntax(code, lexer, line_numbers=True, start_line=region.start_line, word_wrap=True)
+    return Panel(syntax, title=escape(describe_region(region)), title_align="left")
+
+
+class _Browser:
+    """Navigation and acceptance state of a browsing session."""
+
+    def __init__(self, clone_classes: list[dict[str, Any]], accepted: set[str], accepted_path: Path) -> None:
+        self.clone_classes = clone_classes
+        self.accepted = accepted
+        self.accepted_path = accepted_path
+        self.index = 0
+        self.unsaved = False
+
+    @property
+    def current(self) -> dict[str, Any]:
+        """The clone class on screen."""
+        return self.clone_classes[self.index]
+
+    def move(self, step: int) -> None:
+        """Go to the next (or previous) clone class, wrapping around."""
+        self.index = (self.index + step) % len(self.clone_classes)
+
+    def jump(self, number: int) -> None:
+        """Go to a clone class by its 1-based number."""
+        self.index = min(max(number, 1), len(self.clone_classes)) - 1
+
+    def toggle(self) -> None:
+        """Accept the current clone class, or take back its acceptance."""
+        self.accepted ^= {self.current["fingerprint"]}
+        self.unsaved = True
+
+    def write(self) -> None:
+        """Record the acceptances in the baseline file."""
+        count = record_accepted(self.accepted_path, self.clone_classes, self.accepted)
+        self.unsaved = False
+        console.print(f"Wrote {count} accepted clone class(es) to {escape(str(self.accepted_path))}")
+
+    def handle(self, command: str) -> None:
+        """Apply one command typed at the prompt."""
+        if command.isdigit():
+            self.jump(int(command))
+            return
+        actions = {"n": lambda: self.move(1), "p": lambda: self.move(-1), "a": self.toggle, "w": self.write}
+        actions.get(command, lambda: None)()
+
+    def render(self) -> None:
+        """Show the current clone class, its copies side by side."""
+        clone = self.current
+        status = "[green]accepted[/green]" if clone["fingerprint"] in self.accepted else "[yellow]open[/yellow]"
+        console.clear()
+        console.print(
+            f"[bold]Clone {self.index + 1}/{len(self.clone_classes)}[/bold]  {clone['fingerprint']}  "
+            f"{clone['similarity']:.1%} similar  {len(clone['instances'])} copies  {status}"
+        )
+        console.print(Columns([_instance_panel(i) for i in clone["instances"]], equal=True, expand=True))
+        console.print(f"[dim]{_KEYS}[/dim]")
+
+
+def _load_report(report: Path) -> list[dict[str, Any]]:
+    """The clone classes of a json report."""
+    try:
+        return list(json.loads(report.read_text(encoding="utf-8"))["clone_classes"])
+    except (ValueError, KeyError, TypeError) as e:
+        raise click.BadParameter(f"{report} is not a treepeat json report: {e}", param_hint="'REPORT'") from e
+
+
+def _accepted(accepted_path: Path, clone_classes: list[dict[str, Any]]) -> set[str]:
+    """The clone classes of the report already recorded in the acceptance file."""
+    if not accepted_path.exists():
+        return set()
+    try:
+        recorded = load_baseline(accepted_path)
+    except ValueError as e:
+        raise click.BadParameter(str(e), param_hint="'--accepted'") from e
+    return {clone["fingerprint"] for clone in clone_classes if clone["fingerprint"] in recorded}
+
+
+def _run(browser: _Browser) -> None:
+    """Show clone classes and apply the commands typed until the user quits."""
+    while True:
+        browser.render()
+        command = click.prompt(">", default="n", show_default=False).strip().lower()
+        if command == "q":
+            return
+        browser.handle(command)
+
+
+@click.command()
+@click.argument("report", type=click.Path(exists=True, dir_okay=False, path_type=Path))
+@click.option(
+    "--accepted",
+    "accepted_path",
+    type=click.Path(dir_okay=False, path_type=Path),
+    default=DEFAULT_BASELINE_PATH,
+    show_default=True,
+    help="Baseline file the accepted clone classes are written to (read by 'detect --baseline')",
+)
+def browse(report: Path, accepted_path: Path) -> None:
+    """Browse the clone classes of a json REPORT (detect --format json) and accept findings.
+
+    Accepted clone classes are written to a baseline file, so 'detect --baseline' stops reporting them.
+    """
+    clone_classes = _load_report(report)
+    if not clone_classes:
+        console.print("No clone classes in this report")
+        return
+    browser = _Browser(clone_classes, _accepted(accepted_path, clone_classes), accepted_path)
+    _run(browser)
+    if browser.unsaved and click.confirm(f"Write acceptances to {accepted_path}?", default=True):
+        browser.write()
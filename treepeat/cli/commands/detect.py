@@ -1,16 +1,28 @@
 """Detect command - find similar code regions."""
 
+import json
+import os
+import re
 import sys
 import time
+from collections.abc import Iterator
+from contextlib import contextmanager
 from pathlib import Path
+from string import Template
+from typing import Any
 
 import click
 from rich.console import Console
 from rich.markup import escape
 from rich.table import Table
 
+from treepeat.annotate import annotate_sources
+from treepeat.baseline import load_baseline
 from treepeat.config import (
+    DEFAULT_GENERATED_MARKERS,
+    LanguageThresholds,
     LSHSettings,
+    MaxGap,
     MinHashSettings,
     PipelineSettings,
     RulesSettings,
@@ -18,23 +30,204 @@ from treepeat.config import (
     get_settings,
     set_settings,
 )
+from treepeat.formatters import FILE_FORMATTERS
+from treepeat.formatters.markdown import format_as_markdown, validate_link_template
+from treepeat.formatters.ndjson import NdjsonWriter
 from treepeat.formatters.sarif import format_as_sarif
-from treepeat.models.similarity import Region, RegionSignature, SimilarityResult, SimilarRegionGroup
-from treepeat.pipeline.pipeline import run_pipeline
-from treepeat.pipeline.verbose_metrics import get_verbose_metrics, reset_verbose_metrics
+from treepeat.models.similarity import GroupCallback, Region, RegionSignature, SimilarityResult, SimilarRegionGroup
+from treepeat.notify import DEFAULT_TEMPLATE, NotifyError, load_template, notification_variables, render, send
+from treepeat.ownership import annotate_ownership
+from treepeat.pipeline.cache import default_cache_dir
+from treepeat.pipeline.fingerprint import exclude_groups
+from treepeat.pipeline.notebook import describe_notebook_location
+from treepeat.pipeline.pipeline import run_pipeline, shingle_shard
+from treepeat.pipeline.rules_factory import NORMALIZATION_BUILDERS
+from treepeat.pipeline.testcode import TESTS_MODES
+from treepeat.pipeline.verbose_metrics import get_verbose_metrics, record_stage_timing, reset_verbose_metrics
+from treepeat.pipeline.winnow import STRATEGIES
+from treepeat.policy import FAIL_ON_MODES, FailPolicy, policy_violations
+from treepeat.profiling import profiled
+from treepeat.revisions import RevisionError, changed_files
+from treepeat.shard import ShardError, default_shard_path, parse_shard, write_shard
+from treepeat.stats import fragment_types_to_list
+from treepeat.telemetry import export_run_trace
 
 console = Console()
 
+# Output formats written incrementally while the pipeline runs
+STREAMING_FORMATS = ["ndjson"]
+
 
 def _parse_patterns(pattern_string: str) -> list[str]:
     """Parse comma-separated pattern string into list."""
     return [p.strip() for p in pattern_string.split(",") if p.strip()]
 
 
+_DURATION_UNITS = {"ms": 0.001, "s": 1.0, "m": 60.0}
+_SIZE_UNITS = {"": 1, "b": 1, "k": 1024, "kb": 1024, "m": 1024**2, "mb": 1024**2, "g": 1024**3, "gb": 1024**3}
+
+
+def _parse_duration(ctx: click.Context, param: click.Parameter, value: str | None) -> float | None:
+    """Parse a duration such as '500ms', '2s', '1m' or '1.5' (seconds) into seconds."""
+    if value is None:
+        return None
+
+    match = re.fullmatch(r"\s*(\d+(?:\.\d+)?)\s*(ms|s|m)?\s*", value)
+    if not match or float(match.group(1)) <= 0:
+        raise click.BadParameter(f"Invalid duration '{value}'. Use e.g. '500ms', '2s' or '1m'")
+    return float(match.group(1)) * _DURATION_UNITS[match.group(2) or "s"]
+
+
+def _parse_size(ctx: click.Context, param: click.Parameter, value: str | None) -> int | None:
+    """Parse a size such as '500k', '2MB' or '1048576' (bytes) into bytes."""
+    if value is None:
+        return None
+
+    match = re.fullmatch(r"\s*(\d+(?:\.\d+)?)\s*([a-zA-Z]*)\s*", value)
+    if not match or float(match.group(1)) <= 0 or match.group(2).lower() not in _SIZE_UNITS:
+        raise click.BadParameter(f"Invalid size '{value}'. Use e.g. '500k', '2MB' or a number of bytes")
+    return int(float(match.group(1)) * _SIZE_UNITS[match.group(2).lower()])
+
+
+def _parse_max_gap(ctx: click.Context, param: click.Parameter, value: str | None) -> int | str | None:
+    """Parse a number of lines such as '3', or a percentage of a region's lines such as '10%'."""
+    if value is None:
+        return None
+
+    match = re.fullmatch(r"\s*(?:(\d+)|(\d+(?:\.\d+)?)\s*%)\s*", value)
+    if not match:
+        raise click.BadParameter(f"Invalid gap '{value}'. Use a number of lines such as '3' or a percentage like '10%'")
+    lines, percent = match.groups()
+    return int(lines) if lines is not None else f"{percent}%"
+
+
+def _parse_shard(ctx: click.Context, param: click.Parameter, value: str | None) -> tuple[int, int] | None:
+    """Parse 'K/N' (shard K of N)."""
+    if value is None:
+        return None
+    try:
+        return parse_shard(value)
+    except ShardError as e:
+        raise click.BadParameter(str(e)) from e
+
+
+def _parse_regexes(ctx: click.Context, param: click.Parameter, value: tuple[str, ...]) -> tuple[str, ...]:
+    """Reject invalid regular expressions up front."""
+    for pattern in value:
+        try:
+            re.compile(pattern)
+        except re.error as e:
+            raise click.BadParameter(f"Invalid regex '{pattern}': {e}") from e
+    return value
+
+
+# '<key>=<value>' of a --language-threshold: a line or token count, or a similarity fraction
+_LANGUAGE_THRESHOLD = re.compile(
+    r"\s*(?:(?P<key>min-lines|min-tokens)\s*=\s*(?P<count>\d+)"
+    r"|min-similarity\s*=\s*(?P<fraction>0(?:\.\d+)?|1(?:\.0*)?))\s*"
+)
+
+
+def _threshold_value(match: re.Match[str]) -> tuple[str, float]:
+    """The LanguageThresholds field and value of one '<key>=<value>'."""
+    if match["fraction"] is not None:
+        return "min_similarity", float(match["fraction"])
+    return match["key"].replace("-", "_"), int(match["count"])
+
+
+def _parse_language_threshold(spec: str) -> tuple[str, dict[str, float]]:
+    """Parse '<language>:min-lines=N,min-tokens=N,min-similarity=F' (any subset of the keys)."""
+    language, _, assignments = spec.partition(":")
+    matches = [_LANGUAGE_THRESHOLD.fullmatch(assignment) for assignment in assignments.split(",")]
+    if not language.strip() or not all(matches):
+        raise click.BadParameter(
+            f"Invalid value '{spec}'. Expected e.g. 'go:min-lines=3' or 'html:min-lines=30,min-similarity=0.95'"
+        )
+    return language.strip().lower(), dict(_threshold_value(match) for match in matches if match)
+
+
+def _parse_language_thresholds(
+    ctx: click.Context, param: click.Parameter, value: tuple[str, ...]
+) -> dict[str, dict[str, float]]:
+    """Collect repeated --language-threshold values by language."""
+    thresholds: dict[str, dict[str, float]] = {}
+    for spec in value:
+        language, values = _parse_language_threshold(spec)
+        thresholds.setdefault(language, {}).update(values)
+    return thresholds
+
+
+def _parse_normalize(ctx: click.Context, param: click.Parameter, value: str) -> list[str]:
+    """Parse a comma-separated list of normalizations such as 'identifiers,literals'."""
+    kinds = [kind.lower() for kind in _parse_patterns(value)]
+    unknown = sorted(set(kinds) - set(NORMALIZATION_BUILDERS))
+    if unknown:
+        choices = ", ".join(NORMALIZATION_BUILDERS)
+        raise click.BadParameter(f"Unknown normalization '{unknown[0]}'. Choose from: {choices}")
+    return list(dict.fromkeys(kinds))
+
+
+def _parse_link_template(ctx: click.Context, param: click.Parameter, value: str | None) -> str | None:
+    """Reject link templates with unknown placeholders up front."""
+    if value is not None:
+        try:
+            validate_link_template(value)
+        except ValueError as e:
+            raise click.BadParameter(str(e)) from e
+    return value
+
+
+def _parse_notify_template(ctx: click.Context, param: click.Parameter, value: Path | None) -> Template:
+    """Load a --notify-template file, falling back to the built-in message."""
+    if value is None:
+        return DEFAULT_TEMPLATE
+    try:
+        return load_template(value)
+    except ValueError as e:
+        raise click.BadParameter(str(e)) from e
+
+
+def _parse_baseline(ctx: click.Context, param: click.Parameter, value: Path | None) -> tuple[str, ...]:
+    """Load the fingerprints of a baseline file (see 'treepeat baseline')."""
+    if value is None:
+        return ()
+    try:
+        return tuple(sorted(load_baseline(value)))
+    except ValueError as e:
+        raise click.BadParameter(str(e)) from e
+
+
+def _changed_since(ref: str | None, path: Path) -> set[Path]:
+    """Resolve --changed-since into the files changed since that git revision."""
+    if ref is None:
+        return set()
+    try:
+        return changed_files(ref, path.resolve())
+    except RevisionError as e:
+        raise click.BadParameter(str(e), param_hint="'--changed-since'") from e
+
+
+def _changed_files(ref: str | None, files: tuple[Path, ...], path: Path) -> set[Path] | None:
+    """The files whose clones are reported: --changed-file plus those changed since --changed-since (None: all)."""
+    if ref is None and not files:
+        return None
+    return {file.resolve() for file in files} | _changed_since(ref, path)
+
+
+def _fraction(percent: int | None) -> float | None:
+    """Convert an optional percent option into a fraction."""
+    return None if percent is None else percent / 100.0
+
+
+def _similarity_percent(similarity: float, min_similarity: float | None) -> float:
+    """Resolve the threshold percent; --min-similarity (a fraction) wins over --similarity."""
+    if min_similarity is None:
+        return similarity
+    return min_similarity * 100.0
+
+
 def _parse_add_region_arg(region_spec: str) -> tuple[str, set[str]]:
     """Parse '<language>:node1,node2,...' for additional regions."""
-    import re
-
     m = re.match(r"^\s*([\w+\-]+)\s*:(.+)$", region_spec)
     if not m:
         raise click.ClickException(
@@ -53,8 +246,6 @@ def _parse_add_region_arg(region_spec: str) -> tuple[str, set[str]]:
 
 def _parse_exclude_region_arg(region_spec: str) -> tuple[str, set[str]]:
     """Parse '<language>:label1,label2,...' for excluding regions."""
-    import re
-
     m = re.match(r"^\s*([\w+\-]+)\s*:(.+)$", region_spec)
     if not m:
         raise click.ClickException(
@@ -106,53 +297,165 @@ def _merge_region_mappings(
     return merged
 
 
-def _create_rules_settings(ruleset: str) -> RulesSettings:
+def _create_rules_settings(
+    ruleset: str,
+    ignore_qualifiers: bool = False,
+    normalize_signature_types: bool = False,
+    normalize: list[str] | None = None,
+    flatten_preprocessor: bool = False,
+    custom_rulesets: dict[str, Any] | None = None,
+) -> RulesSettings:
     """Create RulesSettings."""
-    return RulesSettings(ruleset=ruleset)
+    return RulesSettings(
+        ruleset=ruleset,
+        custom_rulesets=custom_rulesets or {},
+        ignore_qualifiers=ignore_qualifiers,
+        normalize_signature_types=normalize_signature_types,
+        normalize=normalize or [],
+        flatten_preprocessor=flatten_preprocessor,
+    )
+
+
+def _worker_count(jobs: int | None) -> int:
+    """The --jobs value, defaulting to the number of CPUs."""
+    return jobs or os.cpu_count() or 1
+
 
+def _cache_dir(cache_dir: Path | None, no_cache: bool) -> Path | None:
+    """The fingerprint cache directory, or None with --no-cache."""
+    if no_cache:
+        return None
+    return cache_dir or default_cache_dir()
 
-def _configure_settings(
+
+def _rules_settings(
     ruleset: str,
-    similarity_percent: float,
-    min_lines: int,
-    ignore: str,
-    ignore_files: str,
-    ignore_node_types: str,
+    custom_rulesets: dict[str, Any] | None,
+    *,
+    ignore_qualifiers: bool,
+    normalize_signature_types: bool,
+    normalize: list[str] | None,
+    flatten_preprocessor: bool,
     add_regions: tuple[str, ...],
     exclude_regions: tuple[str, ...],
-) -> None:
-    lsh_settings = LSHSettings(
-        similarity_percent=similarity_percent / 100.0,
+    **_: Any,
+) -> RulesSettings:
+    """Rules settings: the ruleset, its normalizations and the regions added to or excluded from it."""
+    rules = _create_rules_settings(
+        ruleset, ignore_qualifiers, normalize_signature_types, normalize, flatten_preprocessor, custom_rulesets
+    )
+    additional_regions = _build_additional_region_rules(add_regions)
+    if additional_regions:
+        rules.additional_regions = _merge_region_mappings(rules.additional_regions, additional_regions)
+    excluded_regions = _build_excluded_region_rules(exclude_regions)
+    if excluded_regions:
+        rules.excluded_regions = _merge_region_mappings(rules.excluded_regions, excluded_regions)
+    return rules
+
+
+def _lsh_settings(
+    *,
+    similarity: float,
+    min_similarity: float | None,
+    min_lines: int,
+    min_complexity: int,
+    max_gap_lines: MaxGap | None,
+    ignore_node_types: str,
+    language_thresholds: dict[str, dict[str, float]] | None,
+    **_: Any,
+) -> LSHSettings:
+    """LSH and verification settings: which regions are compared and how similar they must be."""
+    return LSHSettings(
+        similarity_percent=_similarity_percent(similarity, min_similarity) / 100.0,
         min_lines=min_lines,
+        min_complexity=min_complexity,
+        max_gap_lines=max_gap_lines,
         ignore_node_types=_parse_patterns(ignore_node_types),
+        language_thresholds={
+            language: LanguageThresholds.model_validate(values)
+            for language, values in (language_thresholds or {}).items()
+        },
     )
 
-    settings = PipelineSettings(
-        rules=_create_rules_settings(ruleset),
-        shingle=ShingleSettings(),  # Uses default k=3
-        minhash=MinHashSettings(),  # Uses default num_perm=128
-        lsh=lsh_settings,
-        ignore_patterns=_parse_patterns(ignore),
-        ignore_file_patterns=_parse_patterns(ignore_files),
-    )
 
-    set_settings(settings)
-    settings = get_settings()
-    additional_regions = _build_additional_region_rules(add_regions)
-    if additional_regions:
-        settings.rules.additional_regions = _merge_region_mappings(
-            getattr(settings.rules, "additional_regions", {}),
-            additional_regions,
-        )
+def _shingle_settings(*, cross_language: bool, strategy: str, canonicalize: bool, **_: Any) -> ShingleSettings:
+    """Shingling settings (default k=3)."""
+    return ShingleSettings(cross_language=cross_language, strategy=strategy.lower(), canonicalize=canonicalize)
 
-    excluded_regions = _build_excluded_region_rules(exclude_regions)
-    if excluded_regions:
-        settings.rules.excluded_regions = _merge_region_mappings(
-            getattr(settings.rules, "excluded_regions", {}),
-            excluded_regions,
-        )
 
-    set_settings(settings)
+def _scan_settings(
+    path: Path,
+    *,
+    ignore: str,
+    ignore_files: str,
+    include: tuple[str, ...],
+    exclude: tuple[str, ...],
+    follow_symlinks: bool,
+    include_vendored: bool,
+    tests: str,
+    changed_since: str | None,
+    changed_file: tuple[Path, ...],
+    shard: tuple[int, int] | None,
+    detect_comments: bool,
+    parse_timeout: float | None,
+    max_file_size: int | None,
+    include_minified: bool,
+    include_generated: bool,
+    generated_markers: tuple[str, ...],
+    **_: Any,
+) -> dict[str, Any]:
+    """Parse and filter settings: which files under PATH are read, and how."""
+    return {
+        "ignore_patterns": _parse_patterns(ignore),
+        "ignore_file_patterns": _parse_patterns(ignore_files),
+        "include_patterns": list(include),
+        "exclude_patterns": list(exclude),
+        "follow_symlinks": follow_symlinks,
+        "include_vendored": include_vendored,
+        "tests": tests.lower(),
+        "changed_files": _changed_files(changed_since, changed_file, path),
+        "shard": shard,
+        "detect_comments": detect_comments,
+        "parse_timeout": parse_timeout,
+        "max_file_size": max_file_size,
+        "include_minified": include_minified,
+        "include_generated": include_generated,
+        "generated_markers": [*DEFAULT_GENERATED_MARKERS, *generated_markers],
+    }
+
+
+def _run_settings(
+    *,
+    jobs: int | None,
+    cache_dir: Path | None,
+    no_cache: bool,
+    max_index_memory: int | None,
+    fallback: str | None,
+    file_similarity: int | None,
+    **_: Any,
+) -> dict[str, Any]:
+    """Execution settings: workers, cache, memory budget, the fallback for unsupported files and file mode."""
+    return {
+        "jobs": _worker_count(jobs),
+        "cache_dir": _cache_dir(cache_dir, no_cache),
+        "max_index_memory": max_index_memory,
+        "fallback": fallback.lower() if fallback else None,
+        "file_similarity": _fraction(file_similarity),
+    }
+
+
+def _configure_settings(ruleset: str, custom_rulesets: dict[str, Any] | None, path: Path, **options: Any) -> None:
+    """Build the pipeline settings of a detect run from its options, per area, and make them current."""
+    set_settings(
+        PipelineSettings(
+            rules=_rules_settings(ruleset, custom_rulesets, **options),
+            shingle=_shingle_settings(**options),
+            minhash=MinHashSettings(num_perm=options["num_perm"]),
+            lsh=_lsh_settings(**options),
+            **_scan_settings(path, **options),
+            **_run_settings(**options),
+        )
+    )
 
 
 def _write_output(text: str, output_path: Path | None) -> None:
@@ -163,10 +466,38 @@ def _write_output(text: str, output_path: Path | None) -> None:
         print(text)
 
 
-def _run_pipeline_with_ui(path: Path, output_format: str, progress: bool = False) -> SimilarityResult:
+@contextmanager
+def result_stream(
+    output_format: str, output_path: Path | None, excluded_fingerprints: tuple[str, ...]
+) -> Iterator[GroupCallback | None]:
+    """Yield a callback that streams each clone group to the output, or None for buffered formats."""
+    if output_format.lower() not in STREAMING_FORMATS:
+        yield None
+        return
+    if output_path is None:
+        yield NdjsonWriter(sys.stdout, excluded_fingerprints)
+        return
+    with output_path.open("w", encoding="utf-8") as stream:
+        yield NdjsonWriter(stream, excluded_fingerprints)
+
+
+def _write_shard(path: Path, shard: tuple[int, int], output: Path | None, progress: bool) -> None:
+    """Shingle this run's --shard slice and write it as a partial index for 'treepeat merge'."""
+    settings = get_settings()
+    if settings.file_similarity is not None:
+        raise click.UsageError("--file-similarity compares whole files across shards; it cannot be used with --shard")
+    output_path = output or default_shard_path(shard)
+    regions = shingle_shard(path, progress=progress)
+    write_shard(output_path, path, settings, regions)
+    click.echo(f"Wrote {len(regions)} region(s) of shard {shard[0]}/{shard[1]} to {output_path}", err=True)
+
+
+def _run_pipeline_with_ui(
+    path: Path, output_format: str, progress: bool = False, on_group: GroupCallback | None = None
+) -> SimilarityResult:
     """Run the pipeline with appropriate UI feedback based on output format."""
     if output_format.lower() != "console":
-        return run_pipeline(path, progress=progress)
+        return run_pipeline(path, progress=progress, on_group=on_group)
 
     from treepeat.config import get_settings
     settings = get_settings()
@@ -191,12 +522,23 @@ def _format_region_name(region: Region) -> str:
     return f"{region.region_name}({region.region_type})"
 
 
+def _format_region_location(region: Region) -> str:
+    """Format a region's location, mapping notebook lines back to their cell."""
+    notebook_location = describe_notebook_location(region)
+    if notebook_location is not None:
+        return notebook_location
+    return f"{region.path} [{region.start_line}:{region.end_line}]"
+
+
 def _display_group(group: SimilarRegionGroup, show_diff: bool = False) -> None:
     """Display a single similarity group with optional diff."""
     from treepeat.diff import display_diff
 
     # Display similarity group header
-    console.print(f"Similar group found ([bold]{group.similarity:.1%}[/bold] similar, {group.size} regions):")
+    fingerprint = f" [dim]{group.fingerprint}[/dim]" if group.fingerprint else ""
+    console.print(
+        f"Similar group found ([bold]{group.similarity:.1%}[/bold] similar, {group.size} regions){fingerprint}:"
+    )
 
     # Display all regions in the group
     for i, region in enumerate(group.regions):
@@ -204,7 +546,7 @@ def _display_group(group: SimilarRegionGroup, show_diff: bool = False) -> None:
         prefix = "  - " if i == 0 else "    "
         region_display = _format_region_name(region)
         console.print(
-            f"{prefix}{escape(str(region.path))} [{region.start_line}:{region.end_line}] "
+            f"{prefix}{escape(_format_region_location(region))} "
             f"({lines} lines) {region_display}"
         )
 
@@ -229,6 +571,16 @@ def display_similar_groups(result: SimilarityResult, show_diff: bool = False) ->
         _display_group(group, show_diff=show_diff)
 
 
+def display_test_groups(result: SimilarityResult, show_diff: bool = False) -> None:
+    """Display the groups found only in test code, kept apart with --tests separate."""
+    if not result.test_groups:
+        return
+
+    console.print("\n[bold cyan]Similar Regions in Tests:[/bold cyan]")
+    for group in sorted(result.test_groups, key=_get_group_sort_key):
+        _display_group(group, show_diff=show_diff)
+
+
 def _init_language_stats(
     stats_by_format: dict[str, dict[str, int | set[Path]]], language: str
 ) -> None:
@@ -344,23 +696,182 @@ def display_summary_table(result: SimilarityResult) -> None:
     console.print(table)
 
 
-def _handle_output(
+def handle_output(
     result: SimilarityResult,
     output_format: str,
     output_path: Path | None,
     log_level: str,
     show_diff: bool = False,
+    sarif_size_buckets: bool = False,
+    link_template: str | None = None,
+    report_suppressed: bool = False,
 ) -> None:
     """Handle formatting and outputting results."""
     if output_format.lower() == "sarif":
-        output_text = format_as_sarif(result, pretty=True)
+        output_text = format_as_sarif(
+            result, pretty=True, size_buckets=sarif_size_buckets, report_suppressed=report_suppressed
+        )
         _write_output(output_text, output_path)
+    elif output_format.lower() == "markdown":
+        _write_output(format_as_markdown(result, link_template=link_template), output_path)
+    elif output_format.lower() in FILE_FORMATTERS:
+        _write_output(FILE_FORMATTERS[output_format.lower()](result), output_path)
+    elif output_format.lower() in STREAMING_FORMATS:
+        return  # already written while the pipeline ran
     else:  # console
         display_similar_groups(result, show_diff=show_diff)
+        display_test_groups(result, show_diff=show_diff)
         display_summary_table(result)
         console.print()
 
 
+def _apply_group_exclusions(
+    result: SimilarityResult, excluded_fingerprints: tuple[str, ...], strict: bool
+) -> SimilarityResult:
+    """Drop groups excluded by fingerprint, warning about unknown fingerprints in strict mode."""
+    result, unknown = exclude_groups(result, excluded_fingerprints)
+    if strict:
+        for fingerprint in sorted(unknown):
+            click.echo(f"Warning: --exclude-group {fingerprint} did not match any clone group", err=True)
+    return result
+
+
+def _handle_annotations(result: SimilarityResult, annotate: bool, dry_run: bool) -> None:
+    """Write clone markers into the sources, or print the would-be changes for a dry run."""
+    if not (annotate or dry_run):
+        return
+    diffs = annotate_sources(result, dry_run=dry_run)
+    if dry_run:
+        click.echo("".join(diffs), nl=False)
+    else:
+        click.echo(f"Annotated {len(diffs)} file(s) with clone markers", err=True)
+
+
+def _with_owners(result: SimilarityResult, path: Path) -> SimilarityResult:
+    """Annotate the reported clone instances with their owners (left as they are outside a git repository)."""
+    try:
+        return annotate_ownership(result, path)
+    except RevisionError as e:
+        click.echo(f"treepeat: --owners needs a git repository: {e}", err=True)
+        return result
+
+
+def _find_clones(
+    path: Path,
+    *,
+    output_format: str,
+    output: Path | None,
+    progress: bool,
+    exclude_group: tuple[str, ...],
+    baseline: tuple[str, ...],
+    strict: bool,
+    cpuprofile: Path | None,
+    memprofile: Path | None,
+    trace: Path | None,
+    **_: Any,
+) -> tuple[SimilarityResult, SimilarityResult]:
+    """Run the pipeline on PATH: (every clone found, those left to report once excluded and baselined ones go)."""
+    with (
+        profiled(cpuprofile, memprofile, trace),
+        result_stream(output_format, output, exclude_group + baseline) as on_group,
+    ):
+        result = _run_pipeline_with_ui(path, output_format, progress=progress, on_group=on_group)
+
+    _check_result_errors(result, output_format)
+    found = _apply_group_exclusions(result, exclude_group, strict)
+    result, _ = exclude_groups(found, baseline)
+    return found, result
+
+
+def _report(
+    result: SimilarityResult,
+    path: Path,
+    log_level: str,
+    *,
+    owners: bool,
+    output_format: str,
+    output: Path | None,
+    diff: bool,
+    sarif_size_buckets: bool,
+    link_template: str | None,
+    report_suppressed: bool,
+    annotate: bool,
+    annotate_dry_run: bool,
+    **_: Any,
+) -> SimilarityResult:
+    """Report the clones (with their owners under --owners) and annotate the sources; returns what was reported."""
+    result = _with_owners(result, path) if owners else result
+    report_started = time.monotonic()
+    handle_output(
+        result, output_format, output, log_level, diff, sarif_size_buckets, link_template, report_suppressed
+    )
+    record_stage_timing("report", time.monotonic() - report_started)
+    export_run_trace({"treepeat.path": str(path), "treepeat.clone_classes": len(result.similar_groups)})
+    _handle_annotations(result, annotate, annotate_dry_run)
+    return result
+
+
+def _fail_policy(
+    *,
+    fail: bool,
+    fail_on: str | None,
+    max_duplication_pct: float | None,
+    max_new_duplicated_lines: int | None,
+    max_duplication_increase_pct: float | None,
+    **_: Any,
+) -> FailPolicy:
+    """Combine --fail (short for --fail-on new-clones), --fail-on and the duplication budgets."""
+    policy = FailPolicy(
+        fail_on or ("new-clones" if fail else None),
+        max_duplication_pct,
+        max_new_duplicated_lines,
+        max_duplication_increase_pct,
+    )
+    if policy.fail_on == "threshold" and not policy.has_budget:
+        raise click.UsageError(
+            "--fail-on threshold needs --max-duplication-pct, --max-new-duplicated-lines "
+            "or --max-duplication-increase-pct"
+        )
+    return policy
+
+
+def _check_notify_url(notify_url: str | None, policy: FailPolicy) -> None:
+    if notify_url and not policy.is_set:
+        raise click.UsageError("--notify-url needs --fail, --fail-on or a duplication budget to notify about")
+
+
+def _notify(
+    url: str, template: Template, path: Path, found: SimilarityResult, new_clones: int, violations: list[str]
+) -> None:
+    """Post the policy violations of a run to a webhook; a failed delivery only warns."""
+    variables = notification_variables(path, violations, len(found.similar_groups), new_clones)
+    try:
+        send(url, render(template, variables))
+    except NotifyError as e:
+        click.echo(f"treepeat: notification not sent: {e}", err=True)
+
+
+def _enforce_policy(
+    found: SimilarityResult,
+    result: SimilarityResult,
+    policy: FailPolicy,
+    path: Path,
+    notify_url: str | None,
+    notify_template: Template,
+) -> None:
+    """Exit with status 1, saying why (and notifying --notify-url), if the run breaks the policy.
+
+    ``result`` holds the new clones.
+    """
+    violations = policy_violations(found, result.similar_groups, policy)
+    for violation in violations:
+        click.echo(f"treepeat: {violation}", err=True)
+    if violations and notify_url:
+        _notify(notify_url, notify_template, path, found, len(result.similar_groups), violations)
+    if violations:
+        sys.exit(1)
+
+
 def _check_result_errors(result: SimilarityResult, output_format: str) -> None:
     """Check for errors in the result and exit if necessary."""
     if result.success_count != 0:
@@ -442,9 +953,31 @@ def _display_verbose_timing_metrics(elapsed_time: float) -> None:
     console.print(_build_stage_timings_table(elapsed_time))
 
 
-def _display_verbose_metrics(elapsed_time: float) -> None:
+def _build_fragment_types_table(result: SimilarityResult) -> Table:
+    table = Table(title="Fragment types")
+    table.add_column("Language")
+    table.add_column("Region type")
+    table.add_column("Extracted", justify="right")
+    table.add_column("In clones", justify="right")
+
+    for row in fragment_types_to_list(result):
+        table.add_row(row["language"], row["region_type"], f"{row['extracted']:,}", f"{row['in_clones']:,}")
+
+    return table
+
+
+def _display_verbose_fragment_metrics(result: SimilarityResult) -> None:
+    if not result.fragment_counts:
+        return
+
+    console.print()
+    console.print(_build_fragment_types_table(result))
+
+
+def _display_verbose_metrics(elapsed_time: float, result: SimilarityResult) -> None:
     """Display verbose metrics about the pipeline run."""
     _display_verbose_node_metrics()
+    _display_verbose_fragment_metrics(result)
     _display_verbose_timing_metrics(elapsed_time)
 
     console.print()
@@ -460,6 +993,12 @@ def _display_verbose_metrics(elapsed_time: float) -> None:
     default=100,
     help="Percent similarity threshold (default: 100)",
 )
+@click.option(
+    "--min-similarity",
+    type=click.FloatRange(0.05, 1.0),
+    default=None,
+    help="Similarity threshold as a fraction, e.g. 0.85 (overrides --similarity)",
+)
 @click.option(
     "--min-lines",
     "-ml",
@@ -467,11 +1006,21 @@ def _display_verbose_metrics(elapsed_time: float) -> None:
     default=5,
     help="Minimum number of lines to be considered similar (default: 5)",
 )
+@click.option(
+    "--language-threshold",
+    "language_thresholds",
+    multiple=True,
+    callback=_parse_language_thresholds,
+    help=(
+        "Override thresholds for one language as '<language>:min-lines=N,min-tokens=N,min-similarity=F' "
+        "(any subset), e.g. 'go:min-lines=3' or 'html:min-lines=30'; repeatable"
+    ),
+)
 @click.option(
     "--format",
     "-f",
     "output_format",
-    type=click.Choice(["console", "sarif"], case_sensitive=False),
+    type=click.Choice(["console", "sarif", "markdown", *FILE_FORMATTERS, *STREAMING_FORMATS], case_sensitive=False),
     default="console",
     help="Output format (default: console)",
 )
@@ -518,6 +1067,55 @@ def _display_verbose_metrics(elapsed_time: float) -> None:
     default="**/.*ignore",
     help="Comma-separated list of glob patterns to find ignore files (default: '**/.*ignore')",
 )
+@click.option(
+    "--include",
+    multiple=True,
+    default=(),
+    help="Only scan files matching this glob, relative to PATH (e.g., 'src/**/*.go'); repeatable",
+)
+@click.option(
+    "--exclude",
+    multiple=True,
+    default=(),
+    help="Skip files matching this glob, relative to PATH (e.g., '**/testdata/**'); repeatable",
+)
+@click.option(
+    "--changed-since",
+    metavar="REF",
+    default=None,
+    help=(
+        "Only report clones involving files changed since this git revision (committed, uncommitted or "
+        "untracked); the rest of PATH is still indexed so copies of unchanged code are found"
+    ),
+)
+@click.option(
+    "--changed-file",
+    multiple=True,
+    type=click.Path(dir_okay=False, path_type=Path),
+    help="Only report clones involving this file, like --changed-since does for a revision; repeatable",
+)
+@click.option(
+    "--include-vendored",
+    is_flag=True,
+    default=False,
+    help="Also scan dependency and build-output directories (vendor/, node_modules/, .venv/, target/, ...)",
+)
+@click.option(
+    "--tests",
+    type=click.Choice(TESTS_MODES, case_sensitive=False),
+    default="include",
+    show_default=True,
+    help=(
+        "Test code (test_*.py, *_test.go, *.spec.ts, tests/, __tests__/, spec/, ...): 'include' it, report clones "
+        "found only in tests in a 'separate' section (console and json), or 'exclude' test files from the scan"
+    ),
+)
+@click.option(
+    "--follow-symlinks",
+    is_flag=True,
+    default=False,
+    help="Follow symlinked files and directories (each target once; cycles and links back into PATH are skipped)",
+)
 @click.option(
     "--diff",
     "-d",
@@ -529,7 +1127,54 @@ def _display_verbose_metrics(elapsed_time: float) -> None:
     "--fail",
     is_flag=True,
     default=False,
-    help="Exit with error code 1 if any similar blocks are detected",
+    help="Exit with error code 1 if any similar blocks are detected (not counting --baseline ones)",
+)
+@click.option(
+    "--fail-on",
+    type=click.Choice(FAIL_ON_MODES, case_sensitive=False),
+    default=None,
+    help=(
+        "Exit with error code 1 on: 'new-clones' (clones not in --baseline, like --fail), 'any' (baseline "
+        "clones included) or 'threshold' (only when a --max-duplication-pct/--max-new-duplicated-lines budget "
+        "is exceeded; budgets apply in every mode)"
+    ),
+)
+@click.option(
+    "--max-duplication-pct",
+    type=click.FloatRange(min=0, max=100),
+    default=None,
+    help="Exit with error code 1 if duplicated lines exceed this percentage of the scanned lines",
+)
+@click.option(
+    "--max-new-duplicated-lines",
+    type=click.IntRange(min=0),
+    default=None,
+    help="Exit with error code 1 if clones not in --baseline cover more than this many lines",
+)
+@click.option(
+    "--max-duplication-increase-pct",
+    type=click.FloatRange(min=0, max=100),
+    default=None,
+    help="Exit with error code 1 if clones not in --baseline cover more than this percentage of the scanned lines",
+)
+@click.option(
+    "--notify-url",
+    envvar="TREEPEAT_NOTIFY_URL",
+    default=None,
+    help=(
+        "Webhook (e.g. a Slack incoming webhook) to POST to when the run breaks the --fail/--fail-on policy or a "
+        "budget (default: $TREEPEAT_NOTIFY_URL)"
+    ),
+)
+@click.option(
+    "--notify-template",
+    type=click.Path(exists=True, dir_okay=False, path_type=Path),
+    default=None,
+    callback=_parse_notify_template,
+    help=(
+        "JSON payload template for --notify-url, with $text, $path, $violations, $clone_classes and "
+        "$new_clone_classes expanding to JSON values (default: {\"text\": $text})"
+    ),
 )
 @click.option(
     "--ignore-node-types",
@@ -543,61 +1188,342 @@ def _display_verbose_metrics(elapsed_time: float) -> None:
     "-v",
     is_flag=True,
     default=False,
-    help="Show verbose output including timing, ignored nodes, and used node types per language",
+    help="Show verbose output including timing, fragment types, and used node types per language",
+)
+@click.option(
+    "--cpuprofile",
+    type=click.Path(dir_okay=False, path_type=Path),
+    default=None,
+    help="Write a CPU profile of the run (cProfile/pstats format, e.g. for snakeviz) to this file",
+)
+@click.option(
+    "--memprofile",
+    type=click.Path(dir_okay=False, path_type=Path),
+    default=None,
+    help="Write a memory profile of the run (a tracemalloc snapshot) to this file",
+)
+@click.option(
+    "--trace",
+    type=click.Path(dir_okay=False, path_type=Path),
+    default=None,
+    help="Write a timeline of the pipeline stages (Chrome trace format, for chrome://tracing or Perfetto) to this file",
 )
 @click.option(
     "--progress",
     "-p",
     is_flag=True,
     default=False,
-    help="Show progress bars for long-running pipeline stages",
+    help="Show progress for long-running pipeline stages (a bar on a terminal, periodic lines otherwise)",
 )
-def detect(
-    ctx: click.Context,
-    path: Path,
-    similarity: float,
-    min_lines: int,
-    output_format: str,
-    output: Path | None,
-    ignore: str,
-    ignore_files: str,
-    diff: bool,
-    fail: bool,
-    ignore_node_types: str,
-    verbose: bool,
-    progress: bool,
-    add_regions: tuple[str, ...],
-    exclude_regions: tuple[str, ...],
-) -> None:
-    log_level = ctx.obj["log_level"]
-    ruleset = ctx.obj["ruleset"]
-
-    _configure_settings(
-        ruleset,
-        similarity,
-        min_lines,
-        ignore,
-        ignore_files,
-        ignore_node_types,
-        add_regions,
-        exclude_regions,
-    )
+@click.option(
+    "--detect-comments",
+    is_flag=True,
+    default=False,
+    help="Also detect clones in prose such as Jupyter notebook markdown cells",
+)
+@click.option(
+    "--ignore-qualifiers",
+    is_flag=True,
+    default=False,
+    help="Ignore access and storage modifiers (public, private, static, ...) when comparing regions",
+)
+@click.option(
+    "--parse-timeout",
+    type=str,
+    default=None,
+    callback=_parse_duration,
+    help="Skip any file whose parse takes longer than this duration (e.g., '500ms', '2s', '1m')",
+)
+@click.option(
+    "--max-file-size",
+    type=str,
+    default=None,
+    callback=_parse_size,
+    help="Skip any file larger than this size (e.g., '500k', '2MB') instead of parsing it",
+)
+@click.option(
+    "--include-minified",
+    is_flag=True,
+    default=False,
+    help="Also scan minified and bundled JavaScript (*.min.js, webpack bundles, ...), skipped by default",
+)
+@click.option(
+    "--include-generated",
+    is_flag=True,
+    default=False,
+    help="Also scan generated code ('Code generated ... DO NOT EDIT', '@generated', ...), skipped by default",
+)
+@click.option(
+    "--generated-marker",
+    "generated_markers",
+    multiple=True,
+    default=(),
+    callback=_parse_regexes,
+    help="Regex that marks a file as generated when found in its first 10 lines, added to the defaults; repeatable",
+)
+@click.option(
+    "--sarif-size-buckets",
+    is_flag=True,
+    default=False,
+    help="Report SARIF results under per-size rules (treepeat/clone-small|medium|large) (sarif format only)",
+)
+@click.option(
+    "--report-suppressed",
+    is_flag=True,
+    default=False,
+    help="Also list clone groups hidden by treepeat:ignore comments, marked as suppressed (sarif format only)",
+)
+@click.option(
+    "--normalize-signature-types",
+    is_flag=True,
+    default=False,
+    help=(
+        "Normalize parameter and return types in signatures so functions with identical bodies match "
+        "(as near-matches, not exact clones)"
+    ),
+)
+@click.option(
+    "--exclude-group",
+    "exclude_group",
+    multiple=True,
+    default=(),
+    help="Hide the clone group with this fingerprint (e.g., 'clone-1a2b3c4d') for this run; repeatable",
+)
+@click.option(
+    "--baseline",
+    type=click.Path(exists=True, dir_okay=False, path_type=Path),
+    default=None,
+    callback=_parse_baseline,
+    help="Report only clone groups that are not in this baseline file (written by 'treepeat baseline')",
+)
+@click.option(
+    "--strict",
+    is_flag=True,
+    default=False,
+    help="Warn about --exclude-group fingerprints that match no clone group",
+)
+@click.option(
+    "--jobs",
+    "-j",
+    type=click.IntRange(min=1),
+    default=None,
+    help="Number of workers used to parse files, fingerprint regions and compare candidates (default: number of CPUs)",
+)
+@click.option(
+    "--max-index-memory",
+    type=str,
+    default=None,
+    callback=_parse_size,
+    help=(
+        "Memory budget of the LSH candidate index only (e.g., '4GB'); a larger index is spilled to disk "
+        "and matched there, trading speed for memory. Region signatures are not counted"
+    ),
+)
+@click.option(
+    "--cache-dir",
+    type=click.Path(file_okay=False, path_type=Path),
+    default=None,
+    help=(
+        "Directory of the fingerprint cache, so repeat runs only re-analyze changed files "
+        "(default: $XDG_CACHE_HOME/treepeat or ~/.cache/treepeat)"
+    ),
+)
+@click.option(
+    "--no-cache",
+    is_flag=True,
+    default=False,
+    help="Analyze every file from scratch, neither reading nor writing the fingerprint cache",
+)
+@click.option(
+    "--shard",
+    type=str,
+    default=None,
+    callback=_parse_shard,
+    help=(
+        "Only analyze shard K of N (e.g. '3/8') of the files and write its partial index to --output "
+        "(default: treepeat-shard-K-of-N.json); combine the N partial indexes with 'treepeat merge'"
+    ),
+)
+@click.option(
+    "--annotate",
+    is_flag=True,
+    default=False,
+    help="Insert a comment marker above each clone instance, modifying files in place",
+)
+@click.option(
+    "--annotate-dry-run",
+    is_flag=True,
+    default=False,
+    help="Print the changes --annotate would make without modifying any file",
+)
+@click.option(
+    "--owners",
+    is_flag=True,
+    default=False,
+    help=(
+        "Annotate each clone instance in json, html and sarif output with its owner: the author of most of "
+        "its lines (git blame) and the code owners of its file (CODEOWNERS)"
+    ),
+)
+@click.option(
+    "--min-complexity",
+    type=click.IntRange(min=1),
+    default=1,
+    help="Ignore regions whose complexity (1 + control-flow branches) is below this value (default: 1)",
+)
+@click.option(
+    "--link-template",
+    type=str,
+    default=None,
+    callback=_parse_link_template,
+    help=(
+        "Link clone instances in markdown output, using {path}, {start_line} and {end_line} "
+        "(e.g., 'https://github.com/org/repo/blob/main/{path}#L{start_line}-L{end_line}')"
+    ),
+)
+@click.option(
+    "--normalize",
+    type=str,
+    default="",
+    callback=_parse_normalize,
+    help=(
+        "Comma-separated normalizations applied on top of the ruleset: 'identifiers' (variable/function names), "
+        "'literals' (string/number values) and/or 'comments' (comments and docstrings), e.g. 'identifiers,literals'"
+    ),
+)
+@click.option(
+    "--max-gap-lines",
+    default=None,
+    callback=_parse_max_gap,
+    help=(
+        "Largest inserted, deleted or modified stretch tolerated inside a near-miss clone, in lines (e.g. '3') "
+        "or as a percentage of the region's lines (e.g. '10%'); combine with --similarity below 100"
+    ),
+)
+@click.option(
+    "--fallback",
+    type=click.Choice(["token"], case_sensitive=False),
+    default=None,
+    help="Also scan files without a tree-sitter grammar, comparing blank-line separated blocks of lexical tokens",
+)
+@click.option(
+    "--flatten-preprocessor",
+    is_flag=True,
+    default=False,
+    help="C/C++: compare only the first branch of every #if/#ifdef, ignoring its condition",
+)
+@click.option(
+    "--file-similarity",
+    type=click.IntRange(1, 100),
+    default=None,
+    help=(
+        "First report whole files that are identical (100) or at least this percent similar, "
+        "comparing them line by line; only one copy of each goes on to fragment analysis"
+    ),
+)
+@click.option(
+    "--canonicalize",
+    is_flag=True,
+    default=False,
+    help=(
+        "Canonicalize code before hashing: order operands of commutative operators (a + b == b + a), "
+        "fold trivial constant expressions (60 * 60 == 3600), read a C-style for as the equivalent while "
+        "and ignore the key order of YAML/JSON mappings"
+    ),
+)
+@click.option(
+    "--strategy",
+    type=click.Choice(STRATEGIES, case_sensitive=False),
+    default="shingle",
+    help=(
+        "How regions are fingerprinted: 'shingle' compares every AST k-gram in order (default); "
+        "'winnow' keeps winnowed k-grams and ignores their order, tolerating reordered and lightly edited code"
+    ),
+)
+@click.option(
+    "--num-perm",
+    type=click.IntRange(16, 1024),
+    default=128,
+    help=(
+        "MinHash permutations per region used by the LSH candidate index (default: 128); "
+        "lower is faster and lighter on very large repositories, higher finds more borderline near-misses"
+    ),
+)
+@click.option(
+    "--cross-language",
+    is_flag=True,
+    default=False,
+    help=(
+        "Compare a language-neutral shape of the code (control flow, calls, assignments) "
+        "so logic ported between languages (e.g. Python and Go) is detected"
+    ),
+)
+def detect(ctx: click.Context, path: Path, **options: Any) -> None:
+    policy = _fail_policy(**options)
+    _check_notify_url(options["notify_url"], policy)
+    _configure_settings(ctx.obj["ruleset"], ctx.obj.get("rulesets"), path, **options)
+    if options["shard"] is not None:
+        _write_shard(path, options["shard"], options["output"], options["progress"])
+        return
 
     # Reset and track timing for verbose output
     reset_verbose_metrics()
     start_time = time.time()
-
-    result = _run_pipeline_with_ui(path, output_format, progress=progress)
-
+    found, result = _find_clones(path, **options)
     elapsed_time = time.time() - start_time
 
-    _check_result_errors(result, output_format)
-    _handle_output(result, output_format, output, log_level, diff)
+    result = _report(result, path, ctx.obj["log_level"], **options)
 
     # Display verbose metrics if requested
-    if verbose and output_format.lower() == "console":
-        _display_verbose_metrics(elapsed_time)
+    if options["verbose"] and options["output_format"].lower() == "console":
+        _display_verbose_metrics(elapsed_time, result)
 
-    # Exit with error code 1 in strict mode if any similar blocks are detected
-    if fail and result.similar_groups:
-        sys.exit(1)
+    # Exit with error code 1 if the clones found break the --fail/--fail-on policy
+    _enforce_policy(found, result, policy, path, options["notify_url"], options["notify_template"])
+
+
+# detect options that choose what is reported and how, rather than which clones are found
+REPORT_OPTIONS = {
+    "output_format",
+    "output",
+    "baseline",
+    "diff",
+    "fail",
+    "fail_on",
+    "max_duplication_pct",
+    "max_new_duplicated_lines",
+    "max_duplication_increase_pct",
+    "notify_url",
+    "notify_template",
+    "verbose",
+    "annotate",
+    "annotate_dry_run",
+    "owners",
+    "link_template",
+    "sarif_size_buckets",
+    "report_suppressed",
+    "exclude_group",
+    "strict",
+    "cpuprofile",
+    "memprofile",
+    "trace",
+    "shard",
+}
+
+
+def detection_params() -> list[click.Parameter]:
+    """The detect parameters that decide which clones are found (PATH included), for commands that run detect."""
+    return [param for param in detect.params if param.name not in REPORT_OPTIONS]
+
+
+def json_report(ctx: click.Context, report_path: Path, path: Path, **detect_options: Any) -> dict[str, Any]:
+    """Run detect on PATH into a json report file and load it (no clone classes if no file could be parsed)."""
+    report_path.unlink(missing_ok=True)
+    try:
+        ctx.invoke(detect, path=path, output_format="json", output=report_path, **detect_options)
+    except SystemExit:
+        pass  # exits before writing the report when no file could be parsed
+    if not report_path.exists():
+        return {"clone_classes": []}
+    report: dict[str, Any] = json.loads(report_path.read_text(encoding="utf-8"))
+    return report
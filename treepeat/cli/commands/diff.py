@@ -0,0 +1,117 @@
+import tempfile
+from pathlib import Path
+from typing import Any
+
+import click
+from rich.console import Console
+from rich.table import Table
+
+from treepeat.cli.commands.detect import detection_params, json_report
+from treepeat.report_diff import (
+    CHANGE_KINDS,
+    CloneSummary,
+    compare_reports,
+    format_changes_as_json,
+    summarize_report,
+)
+from treepeat.revisions import RevisionError, export_revision
+
+console = Console()
+
+_TITLES = {
+    "introduced": "Introduced clones",
+    "removed": "Removed clones",
+    "grown": "Grown clones",
+}
+
+
+def _materialize(state: str, workdir: Path) -> Path:
+    """Return the directory to scan for a state: the directory itself, or a git revision exported to workdir."""
+    if Path(state).is_dir():
+        return Path(state)
+    try:
+        return export_revision(state, workdir, repo=Path.cwd())
+    except RevisionError as e:
+        raise click.BadParameter(f"'{state}' is neither a directory nor a git revision ({e})") from e
+
+
+def _scan(ctx: click.Context, state: str, workdir: Path, detect_options: dict[str, Any]) -> dict[str, CloneSummary]:
+    """Run detect on one state and summarize its clone classes by fingerprint (none if no file could be parsed)."""
+    root = _materialize(state, workdir / "tree")
+    return summarize_report(json_report(ctx, workdir / "report.json", root, **detect_options), root)
+
+
+def _row(kind: str, change: dict[str, Any]) -> tuple[str, str, str, str]:
+    """Fingerprint, copies, duplicated lines and locations of one change."""
+    if kind != "grown":
+        return change["fingerprint"], str(change["instances"]), str(change["lines"]), "\n".join(change["locations"])
+    before, after = change["before"], change["after"]
+    return (
+        change["fingerprint"],
+        f"{before['instances']} → {after['instances']}",
+        f"{before['lines']} → {after['lines']}",
+        "\n".join(after["locations"]),
+    )
+
+
+def _display(changes: dict[str, list[Any]]) -> None:
+    """Print one table per kind of change."""
+    for kind in CHANGE_KINDS:
+        table = Table(title=f"{_TITLES[kind]} ({len(changes[kind])})", title_justify="left")
+        for column in ("Fingerprint", "Copies", "Lines", "Locations"):
+            table.add_column(column)
+        for change in changes[kind]:
+            table.add_row(*_row(kind, change))
+        console.print(table)
+
+
+@click.pass_context
+def _diff(
+    ctx: click.Context,
+    before: str,
+    after: str,
+    changes_format: str,
+    changes_output: Path | None,
+    **detect_options: Any,
+) -> None:
+    """Report clones introduced, removed and grown between two revisions or directories."""
+    with tempfile.TemporaryDirectory(prefix="treepeat-diff-") as tmp:
+        changes = compare_reports(
+            _scan(ctx, before, Path(tmp) / "before", detect_options),
+            _scan(ctx, after, Path(tmp) / "after", detect_options),
+        )
+    if changes_format == "json":
+        text = format_changes_as_json(changes, before, after)
+        if changes_output:
+            changes_output.write_text(text + "\n")
+        else:
+            click.echo(text)
+        return
+    _display(changes)
+
+
+diff = click.Command(
+    name="diff",
+    callback=_diff,
+    params=[
+        click.Argument(["before"]),
+        click.Argument(["after"]),
+        *[param for param in detection_params() if param.name != "path"],
+        click.Option(
+            ["--format", "-f", "changes_format"],
+            type=click.Choice(["console", "json"], case_sensitive=False),
+            default="console",
+            help="Output format (default: console)",
+        ),
+        click.Option(
+            ["--output", "-o", "changes_output"],
+            type=click.Path(dir_okay=False, path_type=Path),
+            default=None,
+            help="Write the json output to this file (default: stdout)",
+        ),
+    ],
+    help=(
+        "Compare the clones of two states, each a git revision (of the repository in the current directory) "
+        "or a directory, and report the clone classes introduced, removed and grown from BEFORE to AFTER."
+    ),
+)
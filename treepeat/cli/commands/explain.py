@@ -0,0 +1,102 @@
+import json
+import tempfile
+from pathlib import Path
+from typing import Any
+
+import click
+from rich.console import Console
+from rich.markup import escape
+from rich.syntax import Syntax
+from rich.table import Table
+
+from treepeat.cli.commands.detect import detect, detection_params
+from treepeat.config import get_settings
+from treepeat.diff import display_diff
+from treepeat.explain import CloneNotFoundError, differing_tokens, find_clone_class, match_criteria
+from treepeat.formatters.json import region_from_dict
+from treepeat.formatters.snippets import describe_region, read_region_lines
+from treepeat.models.similarity import Region
+from treepeat.refactor import suggest_extraction
+
+console = Console()
+
+
+def _print_criteria(clone: dict[str, Any]) -> None:
+    """Print the settings that made the clone class count as a clone."""
+    table = Table(show_header=False, box=None)
+    table.add_column(style="bold")
+    table.add_column()
+    for label, value in match_criteria(clone, get_settings()):
+        table.add_row(label, escape(value))
+    console.print(table)
+    console.print()
+
+
+def _print_token_changes(first: Region, other: Region) -> None:
+    """List the tokens that differ between two copies, as the lexer sees them."""
+    changes = differing_tokens(read_region_lines(first), read_region_lines(other))
+    if not changes:
+        console.print("[green]Token for token identical[/green]\n")
+        return
+    console.print(f"[bold]{len(changes)} differing token run(s):[/bold]")
+    for left, right in changes:
+        console.print(f"  [red]{escape(left) or '∅'}[/red] → [green]{escape(right) or '∅'}[/green]")
+    console.print()
+
+
+def _print_suggestion(clone: dict[str, Any], regions: list[Region]) -> None:
+    """Print how the copies could be consolidated into one function, if they are in one language."""
+    suggestion = suggest_extraction(clone, [read_region_lines(region) for region in regions])
+    if suggestion is None:
+        return
+    console.print(
+        f"[bold]Suggested consolidation[/bold]: extract [bold]{escape(suggestion.name)}[/bold] "
+        f"into {escape(suggestion.target)}, with {len(suggestion.parameters)} parameter(s)"
+    )
+    lexer = Syntax.guess_lexer(str(regions[0].path), suggestion.skeleton)
+    console.print(Syntax(suggestion.skeleton, lexer, word_wrap=True))
+    for parameter in suggestion.parameters:
+        values = ", ".join(f"{number}: {value!r}" for number, value in enumerate(parameter.values, start=1))
+        console.print(f"  {escape(parameter.name)} ({parameter.kind}) — {escape(values)}")
+    console.print()
+
+
+def _explain_clone(clone: dict[str, Any]) -> None:
+    """Print every instance of a clone class, then how each one differs from the first."""
+    regions = [region_from_dict(instance) for instance in clone["instances"]]
+    console.print(f"[bold]Clone class {escape(clone['fingerprint'])}[/bold]: {len(regions)} instances")
+    for number, region in enumerate(regions, start=1):
+        console.print(f"  {number}. {escape(describe_region(region))}")
+    console.print()
+    _print_criteria(clone)
+    for other in regions[1:]:
+        display_diff(regions[0], other)
+        _print_token_changes(regions[0], other)
+    _print_suggestion(clone, regions)
+
+
+@click.pass_context
+def _explain(ctx: click.Context, clone_id: str, **detect_options: Any) -> None:
+    """Show why a clone class was reported."""
+    with tempfile.TemporaryDirectory(prefix="treepeat-explain-") as tmp:
+        report_path = Path(tmp) / "report.json"
+        ctx.invoke(detect, output_format="json", output=report_path, **detect_options)
+        clone_classes = json.loads(report_path.read_text(encoding="utf-8"))["clone_classes"]
+    try:
+        clone = find_clone_class(clone_classes, clone_id)
+    except CloneNotFoundError as e:
+        raise click.ClickException(str(e)) from e
+    _explain_clone(clone)
+
+
+explain = click.Command(
+    name="explain",
+    callback=_explain,
+    params=[click.Argument(["clone_id"]), *detection_params()],
+    help=(
+        "Explain a clone class: scan PATH with the given detection options, then print every instance of the "
+        "clone class CLONE_ID (a fingerprint, a unique prefix of one, or a csv group-N id), the ruleset and "
+        "thresholds it matched under, an aligned diff of each copy against the first with the differing "
+        "tokens listed, and a suggested function the copies could be consolidated into."
+    ),
+)
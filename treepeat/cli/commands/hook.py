@@ -0,0 +1,65 @@
+import json
+import os
+import sys
+import tempfile
+from pathlib import Path
+from typing import Any
+
+import click
+
+from treepeat.cli.commands.detect import detect, detection_params
+from treepeat.revisions import RevisionError, repository_root, staged_files
+
+
+def _relative(path: str) -> str:
+    """Show a reported path relative to the current directory when it is below it."""
+    return os.path.relpath(path) if Path(path).is_absolute() else path
+
+
+def _print_findings(clone_classes: list[dict[str, Any]]) -> None:
+    """Print one short block per clone class: its fingerprint, then the location of every copy."""
+    for clone in clone_classes:
+        instances = clone["instances"]
+        click.echo(f"{clone['fingerprint']}: {len(instances)} copies, {clone['similarity']:.0%} similar")
+        for instance in instances:
+            click.echo(f"  {_relative(instance['path'])}:{instance['start_line']}-{instance['end_line']}")
+    click.echo(f"treepeat: {len(clone_classes)} clone class(es) involve the files being committed", err=True)
+
+
+def _files_to_check(files: tuple[Path, ...], root: Path) -> tuple[Path, ...]:
+    """The files named on the command line (as pre-commit passes them), or else those staged in git."""
+    return files or tuple(sorted(staged_files(root)))
+
+
+@click.pass_context
+def _hook(ctx: click.Context, files: tuple[Path, ...], **detect_options: Any) -> None:
+    """Check the files about to be committed for duplication against the rest of the repository."""
+    try:
+        root = repository_root(Path.cwd())
+        changed = _files_to_check(files, root)
+    except RevisionError as e:
+        raise click.ClickException(str(e)) from e
+    if not changed:
+        return
+    with tempfile.TemporaryDirectory(prefix="treepeat-hook-") as tmp:
+        report_path = Path(tmp) / "report.json"
+        ctx.invoke(detect, path=root, changed_file=changed, output_format="json", output=report_path, **detect_options)
+        clone_classes = json.loads(report_path.read_text(encoding="utf-8"))["clone_classes"]
+    if clone_classes:
+        _print_findings(clone_classes)
+        sys.exit(1)
+
+
+hook = click.Command(
+    name="hook",
+    callback=_hook,
+    params=[
+        click.Argument(["files"], nargs=-1, type=click.Path(dir_okay=False, path_type=Path)),
+        *[param for param in detection_params() if param.name not in ("path", "changed_file")],
+        *[param for param in detect.params if param.name == "baseline"],
+    ],
+    help=(
+        "Pre-commit check: report clones involving FILES (default: the files staged in git), matched against "
+        "the whole repository, and exit with status 1 if there are any. Use --baseline to tolerate known clones."
+    ),
+)
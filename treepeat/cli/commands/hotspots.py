@@ -0,0 +1,88 @@
+import json
+import tempfile
+from pathlib import Path
+from typing import Any
+
+import click
+from rich.console import Console
+from rich.markup import escape
+from rich.table import Table
+
+from treepeat.cli.commands.detect import detection_params, json_report
+from treepeat.hotspots import Hotspot, hotspot_to_dict, rank_hotspots
+from treepeat.revisions import RevisionError, file_churn
+
+console = Console()
+
+
+def _describe(copy: dict[str, Any]) -> str:
+    return f"{copy['path']}:{copy['start_line']}-{copy['end_line']}"
+
+
+def _print_table(hotspots: list[Hotspot], since: str | None) -> None:
+    """One row per clone class, the hottest first, with its most changed copy."""
+    table = Table(title=f"Clone classes by churn of their copies{f' since {since}' if since else ''}")
+    for column in ("#", "Clone class", "Copies", "Commits", "Score"):
+        table.add_column(column, justify="left" if column == "Clone class" else "right")
+    table.add_column("Most changed copy", justify="left")
+    for rank, hotspot in enumerate(hotspots, start=1):
+        hottest = hotspot.copies[0]
+        table.add_row(
+            str(rank),
+            hotspot.clone["fingerprint"],
+            str(len(hotspot.copies)),
+            str(hotspot.commits),
+            str(hotspot.score),
+            escape(f"{_describe(hottest.instance)} ({hottest.commits} commits)"),
+        )
+    console.print(table)
+
+
+@click.pass_context
+def _hotspots(
+    ctx: click.Context, path: Path, since: str | None, top: int, hotspots_format: str, **detect_options: Any
+) -> None:
+    """Rank the clone classes of PATH by how often their copies change."""
+    try:
+        churn = file_churn(path, since)
+    except RevisionError as e:
+        raise click.ClickException(str(e)) from e
+    with tempfile.TemporaryDirectory(prefix="treepeat-hotspots-") as tmp:
+        report = json_report(ctx, Path(tmp) / "report.json", path, **detect_options)
+    hotspots = rank_hotspots(report["clone_classes"], churn)
+    hotspots = hotspots[:top] if top else hotspots
+    if hotspots_format.lower() == "json":
+        click.echo(json.dumps([hotspot_to_dict(hotspot) for hotspot in hotspots], indent=2))
+    else:
+        _print_table(hotspots, since)
+
+
+hotspots = click.Command(
+    name="hotspots",
+    callback=_hotspots,
+    params=[
+        *detection_params(),
+        click.Option(
+            ["--since"],
+            help="Only count the commits since this date, in any form git log understands "
+            "(e.g. 2026-01-01, '6 months ago'; default: the whole history)",
+        ),
+        click.Option(
+            ["--top"],
+            type=click.IntRange(min=0),
+            default=20,
+            help="Show only the hottest clone classes (default: 20, 0 for all)",
+        ),
+        click.Option(
+            ["--format", "hotspots_format"],
+            type=click.Choice(["table", "json"], case_sensitive=False),
+            default="table",
+            help="table for people, json (clone classes with the commits and score of each copy) for scripts",
+        ),
+    ],
+    help=(
+        "Rank the clone classes of PATH by churn: each copy scores its lines times the commits (git log) that "
+        "touched its file, and a clone class the sum of its copies. Duplicated code that keeps changing is the "
+        "riskiest to leave duplicated; consolidate the top of the list first."
+    ),
+)
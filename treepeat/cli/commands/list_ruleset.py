@@ -38,11 +38,13 @@ def _filter_rules_by_language(
     return [(rule, desc) for rule, desc in rules if language_filter in rule.languages]
 
 
-def _print_rulesets(ruleset_name: str, language_filter: str | None = None) -> None:
+def _print_rulesets(
+    ruleset_name: str, language_filter: str | None = None, custom_rulesets: dict[str, Any] | None = None
+) -> None:
     """Print rules in the specified ruleset, optionally filtered by language."""
     from treepeat.pipeline.rules_factory import get_ruleset_with_descriptions
 
-    rules_with_descriptions = get_ruleset_with_descriptions(ruleset_name)
+    rules_with_descriptions = get_ruleset_with_descriptions(ruleset_name, custom_rulesets=custom_rulesets)
     rules_with_descriptions = _filter_rules_by_language(rules_with_descriptions, language_filter)
 
     header = _build_ruleset_header(ruleset_name, language_filter)
@@ -59,10 +61,8 @@ def _print_rulesets(ruleset_name: str, language_filter: str | None = None) -> No
 
 
 @click.command(name="list-ruleset")
-@click.argument(
-    "ruleset",
-    type=click.Choice(["none", "default", "loose"], case_sensitive=False),
-)
+@click.pass_context
+@click.argument("ruleset")
 @click.option(
     "--language",
     "-l",
@@ -70,10 +70,16 @@ def _print_rulesets(ruleset_name: str, language_filter: str | None = None) -> No
     default=None,
     help="Filter rules by language (e.g., python, java, javascript)",
 )
-def list_ruleset(ruleset: str, language: str | None) -> None:
+def list_ruleset(ctx: click.Context, ruleset: str, language: str | None) -> None:
     """List rules in the specified ruleset.
 
-    Display all rules in a given ruleset (none/default/loose), optionally
-    filtered by a specific programming language.
+    Display all rules in a given ruleset (none/default/loose, or one defined
+    under [rulesets] in the config file), optionally filtered by a specific
+    programming language.
     """
-    _print_rulesets(ruleset, language)
+    from treepeat.pipeline.rules.parser import RuleParseError
+
+    try:
+        _print_rulesets(ruleset, language, ctx.obj.get("rulesets"))
+    except RuleParseError as e:
+        raise click.ClickException(str(e)) from e
@@ -0,0 +1,39 @@
+import contextlib
+import sys
+import tempfile
+from pathlib import Path
+from typing import Any
+
+import click
+
+from treepeat.cli.commands.detect import detection_params, json_report
+from treepeat.lsp import serve
+
+# detect parameters lsp sets itself: the workspace comes from the editor, and stdout carries the protocol
+_OWN_PARAMS = ("path", "changed_file", "changed_since", "progress")
+
+
+@click.pass_context
+def _lsp(ctx: click.Context, **detect_options: Any) -> None:
+    """Serve the clones of the editor's workspace as diagnostics, over stdin and stdout."""
+    with tempfile.TemporaryDirectory(prefix="treepeat-lsp-") as tmp:
+        report_path = Path(tmp) / "report.json"
+
+        def scan(root: Path) -> dict[str, Any]:
+            return json_report(ctx, report_path, root, progress=False, **detect_options)
+
+        protocol = sys.stdout.buffer
+        # Anything else printed (logs, warnings) goes to stderr, where editors show it as the server's log
+        with contextlib.redirect_stdout(sys.stderr):
+            serve(sys.stdin.buffer, protocol, scan)
+
+
+lsp = click.Command(
+    name="lsp",
+    callback=_lsp,
+    params=[param for param in detection_params() if param.name not in _OWN_PARAMS],
+    help=(
+        "Run a language server on stdin/stdout: the clones of the editor's workspace are published as diagnostics "
+        "pointing at the other copies, with a code action to jump to each. The workspace is rescanned on save."
+    ),
+)
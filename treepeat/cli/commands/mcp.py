@@ -0,0 +1,46 @@
+import contextlib
+import sys
+import tempfile
+from pathlib import Path
+from typing import Any
+
+import click
+
+from treepeat.cli.commands.detect import detection_params, json_report
+from treepeat.mcp import serve
+
+# detect parameters mcp sets itself: the assistant chooses what to scan, and stdout carries the protocol
+_OWN_PARAMS = ("path", "changed_file", "changed_since", "progress")
+
+
+@click.pass_context
+def _mcp(ctx: click.Context, root: Path, **detect_options: Any) -> None:
+    """Answer the clone lookups of an AI coding assistant about the project under ROOT, over stdin and stdout."""
+    with tempfile.TemporaryDirectory(prefix="treepeat-mcp-") as tmp:
+        report_path = Path(tmp) / "report.json"
+
+        def scan(path: Path, changed: set[Path] | None) -> dict[str, Any]:
+            changed_file = tuple(sorted(changed or ()))
+            return json_report(ctx, report_path, path, changed_file=changed_file, progress=False, **detect_options)
+
+        protocol = sys.stdout
+        # Anything else printed (logs, warnings) goes to stderr, which MCP clients keep as the server's log
+        with contextlib.redirect_stdout(sys.stderr):
+            serve(root, sys.stdin, protocol, scan)
+
+
+mcp = click.Command(
+    name="mcp",
+    callback=_mcp,
+    params=[
+        click.Argument(
+            ["root"], required=False, default=Path("."), type=click.Path(exists=True, file_okay=False, path_type=Path)
+        ),
+        *[param for param in detection_params() if param.name not in _OWN_PARAMS],
+    ],
+    help=(
+        "Run a Model Context Protocol server on stdin/stdout for AI coding assistants, with the tools "
+        "find_clones_of (the copies elsewhere in ROOT of a file or some of its lines) and duplication_summary "
+        "(the duplication of a directory and its largest clone classes)."
+    ),
+)
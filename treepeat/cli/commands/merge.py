@@ -0,0 +1,44 @@
+from pathlib import Path
+
+import click
+
+from treepeat.cli.commands.detect import detect, handle_output, result_stream
+from treepeat.config import set_settings
+from treepeat.pipeline.pipeline import match_shingled_regions
+from treepeat.shard import ShardError, load_shards
+
+# detect options merge reuses: the clones were chosen by the shards, only the report is left to pick
+_OUTPUT_OPTIONS = ("output_format", "output", "diff", "link_template")
+
+
+@click.pass_context
+def _merge(
+    ctx: click.Context,
+    shard_files: tuple[Path, ...],
+    output_format: str,
+    output: Path | None,
+    diff: bool,
+    link_template: str | None,
+) -> None:
+    try:
+        settings, root, regions = load_shards(list(shard_files))
+    except ShardError as e:
+        raise click.ClickException(str(e)) from e
+    set_settings(settings)
+    with result_stream(output_format, output, ()) as on_group:
+        result = match_shingled_regions(regions, root, on_group=on_group)
+    handle_output(result, output_format, output, ctx.obj["log_level"], diff, link_template=link_template)
+
+
+merge = click.Command(
+    name="merge",
+    callback=_merge,
+    params=[
+        click.Argument(["shard_files"], nargs=-1, required=True, type=click.Path(exists=True, path_type=Path)),
+        *[param for param in detect.params if param.name in _OUTPUT_OPTIONS],
+    ],
+    help=(
+        "Combine the partial indexes written by 'detect --shard K/N' into one clone report, finding the clones "
+        "across shards. Give every shard of the split; run it from the directory the shards were scanned from."
+    ),
+)
@@ -0,0 +1,227 @@
+import tempfile
+from pathlib import Path
+from typing import Any
+
+import click
+
+from treepeat.cli.commands.detect import detect, detection_params, json_report
+from treepeat.publish import Finding, Publisher, PublishError, changed_findings, diff_changed_lines
+from treepeat.publish.bitbucket import bitbucket_publisher
+from treepeat.publish.gerrit import GerritPublisher, gerrit_client
+from treepeat.publish.github import GITHUB_API_URL, GitHubPublisher, github_client
+from treepeat.publish.gitlab import GITLAB_API_URL, GitLabPublisher, gitlab_client
+from treepeat.revisions import RevisionError, diff_since, head_commit, repository_root
+
+# Detection options of the publish commands: they scan the files the pull request changed, minus accepted clones
+_DETECTION_PARAMS = [
+    *[param for param in detection_params() if param.name not in ("path", "changed_file", "changed_since", "progress")],
+    *[param for param in detect.params if param.name == "baseline"],
+]
+
+
+def _root() -> Path:
+    try:
+        return repository_root(Path.cwd())
+    except RevisionError as e:
+        raise click.ClickException(str(e)) from e
+
+
+def _findings(
+    ctx: click.Context, root: Path, changed_lines: dict[str, set[int]], **detect_options: Any
+) -> list[Finding]:
+    """The clones touching the changed lines, found by scanning the changed files against the whole repository."""
+    changed = tuple(sorted(root / path for path in changed_lines if (root / path).is_file()))
+    if not changed:
+        return []
+    with tempfile.TemporaryDirectory(prefix="treepeat-publish-") as tmp:
+        report_path = Path(tmp) / "report.json"
+        report = json_report(ctx, report_path, root, changed_file=changed, progress=False, **detect_options)
+    return changed_findings(report, changed_lines, root)
+
+
+def _publish(ctx: click.Context, publisher: Publisher, where: str, **detect_options: Any) -> None:
+    """Publish the findings of the changed lines of a pull request and say what became of its comments."""
+    root = _root()
+    try:
+        counts = publisher.publish(_findings(ctx, root, publisher.changed_lines(), **detect_options))
+    except PublishError as e:
+        raise click.ClickException(str(e)) from e
+    summary = ", ".join(f"{count} {outcome}" for outcome, count in counts.items())
+    click.echo(f"treepeat: {summary} comment(s) on {where}", err=True)
+
+
+@click.group()
+def publish() -> None:
+    """Publish the clones a pull request introduces as review comments (or annotations) on its changed lines."""
+
+
+@click.pass_context
+def _github(ctx: click.Context, pr: int, repo: str, token: str, api_url: str, **detect_options: Any) -> None:
+    _publish(ctx, GitHubPublisher(github_client(token, api_url), repo, pr), f"{repo}#{pr}", **detect_options)
+
+
+github = click.Command(
+    name="github",
+    callback=_github,
+    params=[
+        click.Option(["--pr"], type=click.IntRange(min=1), required=True, help="Pull request number"),
+        click.Option(
+            ["--repo"], envvar="GITHUB_REPOSITORY", required=True, help="owner/name (default: $GITHUB_REPOSITORY)"
+        ),
+        click.Option(
+            ["--token"],
+            envvar="GITHUB_TOKEN",
+            required=True,
+            help="Token allowed to write pull request comments (default: $GITHUB_TOKEN)",
+        ),
+        click.Option(
+            ["--api-url"],
+            envvar="GITHUB_API_URL",
+            default=GITHUB_API_URL,
+            show_default=True,
+            help="REST API root, for GitHub Enterprise Server (default: $GITHUB_API_URL)",
+        ),
+        *_DETECTION_PARAMS,
+    ],
+    help=(
+        "Comment on the lines of a GitHub pull request that duplicate code elsewhere in the repository. "
+        "Run it from a checkout of the pull request; comments of earlier runs are updated rather than repeated."
+    ),
+)
+
+publish.add_command(github)
+
+
+@click.pass_context
+def _gitlab(ctx: click.Context, mr: int, project: str, token: str, api_url: str, **detect_options: Any) -> None:
+    _publish(ctx, GitLabPublisher(gitlab_client(token, api_url), project, mr), f"{project}!{mr}", **detect_options)
+
+
+gitlab = click.Command(
+    name="gitlab",
+    callback=_gitlab,
+    params=[
+        click.Option(
+            ["--mr"],
+            type=click.IntRange(min=1),
+            envvar="CI_MERGE_REQUEST_IID",
+            required=True,
+            help="Merge request IID (default: $CI_MERGE_REQUEST_IID)",
+        ),
+        click.Option(
+            ["--project"],
+            envvar="CI_PROJECT_ID",
+            required=True,
+            help="Project ID or path, e.g. group/name (default: $CI_PROJECT_ID)",
+        ),
+        click.Option(
+            ["--token"],
+            envvar="GITLAB_TOKEN",
+            required=True,
+            help="Access token with the api scope (default: $GITLAB_TOKEN)",
+        ),
+        click.Option(
+            ["--api-url"],
+            envvar="CI_API_V4_URL",
+            default=GITLAB_API_URL,
+            show_default=True,
+            help="REST API root, for self-managed GitLab (default: $CI_API_V4_URL)",
+        ),
+        *_DETECTION_PARAMS,
+    ],
+    help=(
+        "Open a discussion on the lines of a GitLab merge request that duplicate code elsewhere in the repository. "
+        "Run it from a checkout of the merge request; discussions of earlier runs are updated rather than repeated, "
+        "and resolved once their clone is gone."
+    ),
+)
+
+publish.add_command(gitlab)
+
+
+@click.pass_context
+def _bitbucket(
+    ctx: click.Context, repo: str, since: str, token: str, server_url: str | None, **detect_options: Any
+) -> None:
+    root = _root()
+    try:
+        commit, changed = head_commit(root), diff_changed_lines(diff_since(since, root))
+        publisher = bitbucket_publisher(token, repo, commit, changed, server_url)
+    except (RevisionError, PublishError) as e:
+        raise click.ClickException(str(e)) from e
+    _publish(ctx, publisher, f"{repo}@{commit[:12]}", **detect_options)
+
+
+bitbucket = click.Command(
+    name="bitbucket",
+    callback=_bitbucket,
+    params=[
+        click.Option(
+            ["--repo"],
+            envvar="BITBUCKET_REPO_FULL_NAME",
+            required=True,
+            help="workspace/slug, or PROJECT/slug on Bitbucket Server (default: $BITBUCKET_REPO_FULL_NAME)",
+        ),
+        click.Option(["--since"], required=True, help="Branch the changes are compared to, e.g. origin/main"),
+        click.Option(
+            ["--token"],
+            envvar="BITBUCKET_TOKEN",
+            required=True,
+            help="Access token allowed to write the repository (default: $BITBUCKET_TOKEN)",
+        ),
+        click.Option(
+            ["--server-url"],
+            envvar="BITBUCKET_SERVER_URL",
+            help="Base URL of Bitbucket Server or Data Center (default: Bitbucket Cloud)",
+        ),
+        *_DETECTION_PARAMS,
+    ],
+    help=(
+        "Publish a Code Insights report on the checked out commit, annotating the lines changed since a branch "
+        "that duplicate code elsewhere in the repository. Pull requests of the commit show the annotations in "
+        "their diff; publishing again replaces the report of the earlier run."
+    ),
+)
+
+publish.add_command(bitbucket)
+
+
+@click.pass_context
+def _gerrit(
+    ctx: click.Context, change: str, revision: str, url: str, user: str, password: str, **detect_options: Any
+) -> None:
+    publisher = GerritPublisher(gerrit_client(url, user, password), change, revision)
+    _publish(ctx, publisher, f"change {change} ({revision})", **detect_options)
+
+
+gerrit = click.Command(
+    name="gerrit",
+    callback=_gerrit,
+    params=[
+        click.Option(
+            ["--change"], envvar="GERRIT_CHANGE_NUMBER", required=True, help="Change (default: $GERRIT_CHANGE_NUMBER)"
+        ),
+        click.Option(
+            ["--revision"],
+            envvar="GERRIT_PATCHSET_REVISION",
+            default="current",
+            show_default=True,
+            help="Patch set commit or number (default: $GERRIT_PATCHSET_REVISION)",
+        ),
+        click.Option(["--url"], envvar="GERRIT_URL", required=True, help="Gerrit base URL (default: $GERRIT_URL)"),
+        click.Option(["--user"], envvar="GERRIT_USER", required=True, help="User (default: $GERRIT_USER)"),
+        click.Option(
+            ["--password"],
+            envvar="GERRIT_HTTP_PASSWORD",
+            required=True,
+            help="HTTP password of the user (default: $GERRIT_HTTP_PASSWORD)",
+        ),
+        *_DETECTION_PARAMS,
+    ],
+    help=(
+        "Post robot comments on the lines of a Gerrit change that duplicate code elsewhere in the repository. "
+        "Run it from a checkout of the patch set; findings commented on by earlier runs are not repeated."
+    ),
+)
+
+publish.add_command(gerrit)
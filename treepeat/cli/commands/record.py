@@ -0,0 +1,72 @@
+import tempfile
+from datetime import datetime, timezone
+from pathlib import Path
+from typing import Any
+
+import click
+
+from treepeat.cli.commands.detect import detection_params, json_report
+from treepeat.history import DEFAULT_HISTORY_PATH, HistoryError, record_run
+from treepeat.revisions import RevisionError, head_commit
+
+
+def _commit(path: Path, commit: str | None) -> str | None:
+    """The commit the run is recorded for: --commit, else the one checked out (None outside a git repository)."""
+    if commit is not None:
+        return commit
+    try:
+        return head_commit(path)
+    except RevisionError:
+        return None
+
+
+@click.pass_context
+def _record(
+    ctx: click.Context, path: Path, history: Path, commit: str | None, at: datetime | None, **detect_options: Any
+) -> None:
+    """Scan PATH and append the duplication it measures to the history database."""
+    with tempfile.TemporaryDirectory(prefix="treepeat-record-") as tmp:
+        report = json_report(ctx, Path(tmp) / "report.json", path, **detect_options)
+    if "duplication" not in report:
+        raise click.ClickException("no file could be parsed")
+    recorded_at = (at or datetime.now()).astimezone(timezone.utc)
+    try:
+        run_id = record_run(history, report, path, _commit(path, commit), recorded_at)
+    except HistoryError as e:
+        raise click.ClickException(str(e)) from e
+    duplication = report["duplication"]
+    click.echo(
+        f"treepeat: recorded run {run_id} in {history}: {duplication['duplicated_lines']} of "
+        f"{duplication['lines']} lines duplicated ({duplication['percent']:.1f}%), "
+        f"{report['summary']['clone_classes']} clone class(es)",
+        err=True,
+    )
+
+
+record = click.Command(
+    name="record",
+    callback=_record,
+    params=[
+        *detection_params(),
+        click.Option(
+            ["--history"],
+            type=click.Path(dir_okay=False, path_type=Path),
+            default=DEFAULT_HISTORY_PATH,
+            show_default=True,
+            help="SQLite database the run is appended to (created if missing)",
+        ),
+        click.Option(
+            ["--commit"],
+            help="Commit the run is recorded for (default: the one checked out in PATH's repository)",
+        ),
+        click.Option(
+            ["--at"],
+            type=click.DateTime(),
+            help="When the run is recorded as made (default: now), to backfill the history from older commits",
+        ),
+    ],
+    help=(
+        "Scan PATH like detect and append a summary of the run to a history database: the duplicated lines of "
+        "PATH and of each of its directories, the clone counts, the commit and the time. See trend."
+    ),
+)
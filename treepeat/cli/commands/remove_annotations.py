@@ -0,0 +1,26 @@
+from pathlib import Path
+
+import click
+from rich.console import Console
+
+from treepeat.annotate import remove_annotations as strip_annotations
+from treepeat.pipeline.parse import collect_source_files
+
+console = Console()
+
+
+@click.command(name="remove-annotations")
+@click.argument("path", type=click.Path(exists=True, path_type=Path))
+@click.option(
+    "--dry-run",
+    is_flag=True,
+    default=False,
+    help="Show the markers that would be removed without modifying any file",
+)
+def remove_annotations(path: Path, dry_run: bool) -> None:
+    """Strip clone markers written by 'detect --annotate'."""
+    diffs = strip_annotations(collect_source_files(path), dry_run=dry_run)
+    if dry_run:
+        click.echo("".join(diffs), nl=False)
+        return
+    console.print(f"Removed clone markers from {len(diffs)} file(s)")
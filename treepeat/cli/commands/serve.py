@@ -0,0 +1,82 @@
+import tempfile
+import time
+from pathlib import Path
+from typing import Any
+
+import click
+
+from treepeat.cli.commands.detect import detection_params, json_report
+from treepeat.grpc_service import GrpcUnavailableError, start_grpc_server
+from treepeat.metrics import ServerMetrics
+from treepeat.pipeline.verbose_metrics import get_verbose_metrics
+from treepeat.server import CloneIndex, run_server
+
+# detect parameters serve sets itself: the roots are its arguments, the files to rescan come with each request
+_OWN_PARAMS = ("path", "changed_file", "changed_since", "progress")
+
+
+def _start_grpc(index: CloneIndex, host: str, grpc_port: int | None) -> Any:
+    """The running gRPC server, or None without --grpc-port."""
+    if grpc_port is None:
+        return None
+    try:
+        server, _ = start_grpc_server(index, host, grpc_port)
+    except GrpcUnavailableError as e:
+        raise click.ClickException(str(e)) from e
+    return server
+
+
+@click.pass_context
+def _serve(
+    ctx: click.Context, roots: tuple[Path, ...], host: str, port: int, grpc_port: int | None, **detect_options: Any
+) -> None:
+    """Keep the clones of ROOTS in memory and answer queries about them over HTTP (and gRPC)."""
+    metrics = ServerMetrics()
+    with tempfile.TemporaryDirectory(prefix="treepeat-serve-") as tmp:
+        report_path = Path(tmp) / "report.json"
+
+        def scan(root: Path, changed: set[Path] | None) -> dict[str, Any]:
+            changed_file = tuple(sorted(changed or ()))
+            started = time.monotonic()
+            report = json_report(ctx, report_path, root, changed_file=changed_file, progress=False, **detect_options)
+            metrics.observe(root, time.monotonic() - started, get_verbose_metrics())
+            return report
+
+        index = CloneIndex(list(roots), scan)
+        index.rescan(None, None)
+        grpc_server = _start_grpc(index, host, grpc_port)
+        try:
+            run_server(index, host, port, metrics)
+        except KeyboardInterrupt:
+            pass
+        finally:
+            if grpc_server is not None:
+                grpc_server.stop(None)
+
+
+serve = click.Command(
+    name="serve",
+    callback=_serve,
+    params=[
+        click.Argument(
+            ["roots"], nargs=-1, required=True, type=click.Path(exists=True, file_okay=False, path_type=Path)
+        ),
+        *[param for param in detection_params() if param.name not in _OWN_PARAMS],
+        click.Option(["--host"], default="127.0.0.1", show_default=True, help="Address to listen on"),
+        click.Option(
+            ["--port"], type=click.IntRange(0, 65535), default=8765, show_default=True, help="Port to listen on"
+        ),
+        click.Option(
+            ["--grpc-port"],
+            type=click.IntRange(0, 65535),
+            default=None,
+            help="Also serve the gRPC API of treepeat/proto/treepeat.proto on this port (needs treepeat[grpc])",
+        ),
+    ],
+    help=(
+        "Scan ROOTS once, keep their clones in memory and answer HTTP queries from there: GET /roots, "
+        "GET /clones?file=PATH[&line=N], GET /report?root=PATH, GET /metrics (Prometheus), and POST /scan to rescan "
+        "a root or some of its files. With --grpc-port, the same is served over gRPC, scans streaming their "
+        "findings and progress."
+    ),
+)
@@ -0,0 +1,76 @@
+import json
+import tempfile
+from pathlib import Path
+from typing import Any
+
+import click
+from rich.console import Console
+from rich.table import Table
+
+from treepeat.cli.commands.detect import detection_params, json_report
+from treepeat.stats import BREAKDOWNS
+
+console = Console()
+
+
+def _print_table(duplication: dict[str, Any], by: str, top: int) -> None:
+    """One row per language, directory or file, the most duplicated first, under the overall figures."""
+    rows = duplication[f"by_{by}"]
+    shown = rows[:top] if top else rows
+    title = (
+        f"{duplication['duplicated_lines']} of {duplication['lines']} lines duplicated "
+        f"({duplication['percent']:.1f}%)"
+    )
+    table = Table(title=title, caption=f"{len(shown)} of {len(rows)} {by} row(s)" if len(shown) < len(rows) else None)
+    table.add_column(by.capitalize(), justify="left")
+    for column in ("Duplicated lines", "Lines", "%"):
+        table.add_column(column, justify="right")
+    for row in shown:
+        table.add_row(row[BREAKDOWNS[by]], str(row["duplicated_lines"]), str(row["lines"]), f"{row['percent']:.1f}")
+    console.print(table)
+
+
+@click.pass_context
+def _stats(ctx: click.Context, path: Path, by: str, top: int, stats_format: str, **detect_options: Any) -> None:
+    """Print how duplicated PATH is, overall and broken down."""
+    with tempfile.TemporaryDirectory(prefix="treepeat-stats-") as tmp:
+        report = json_report(ctx, Path(tmp) / "report.json", path, **detect_options)
+    duplication = report.get("duplication")
+    if duplication is None:
+        raise click.ClickException("no file could be parsed")
+    if stats_format.lower() == "json":
+        click.echo(json.dumps(duplication, indent=2))
+    else:
+        _print_table(duplication, by.lower(), top)
+
+
+stats = click.Command(
+    name="stats",
+    callback=_stats,
+    params=[
+        *detection_params(),
+        click.Option(
+            ["--by"],
+            type=click.Choice(list(BREAKDOWNS), case_sensitive=False),
+            default="directory",
+            help="Break the duplication down by language, directory (each counting the files below it) or file "
+            "(default: directory)",
+        ),
+        click.Option(
+            ["--top"],
+            type=click.IntRange(min=0),
+            default=20,
+            help="Show only the most duplicated rows (default: 20, 0 for all)",
+        ),
+        click.Option(
+            ["--format", "stats_format"],
+            type=click.Choice(["table", "json"], case_sensitive=False),
+            default="table",
+            help="table for people, json (every breakdown) for dashboards",
+        ),
+    ],
+    help=(
+        "Measure how duplicated PATH is: the share of its compared lines covered by a clone, overall and by "
+        "language, directory or file. The same figures are in the 'duplication' section of json reports."
+    ),
+)
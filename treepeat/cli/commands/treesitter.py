@@ -24,9 +24,9 @@ def _parse_patterns(pattern_string: str) -> list[str]:
     return [p.strip() for p in pattern_string.split(",") if p.strip()]
 
 
-def _create_rules_settings(ruleset: str) -> RulesSettings:
+def _create_rules_settings(ruleset: str, custom_rulesets: dict[str, Any] | None = None) -> RulesSettings:
     """Create RulesSettings."""
-    return RulesSettings(ruleset=ruleset)
+    return RulesSettings(ruleset=ruleset, custom_rulesets=custom_rulesets or {})
 
 
 def _configure_settings(
@@ -35,6 +35,7 @@ def _configure_settings(
     min_lines: int,
     ignore: str,
     ignore_files: str,
+    custom_rulesets: dict[str, Any] | None = None,
 ) -> None:
     """Configure pipeline settings."""
     # Create LSH settings - if threshold is None, use internal defaults
@@ -44,7 +45,7 @@ def _configure_settings(
         lsh_settings = LSHSettings(min_lines=min_lines)
 
     settings = PipelineSettings(
-        rules=_create_rules_settings(ruleset),
+        rules=_create_rules_settings(ruleset, custom_rulesets),
         shingle=ShingleSettings(),  # Uses default k=3
         minhash=MinHashSettings(),  # Uses default num_perm=128
         lsh=lsh_settings,
@@ -306,7 +307,7 @@ def treesitter(
     from treepeat.pipeline.shingle import ASTShingler
 
     ruleset = ctx.obj["ruleset"]
-    _configure_settings(ruleset, 1.0, 5, "", "**/.*ignore")
+    _configure_settings(ruleset, 1.0, 5, "", "**/.*ignore", ctx.obj.get("rulesets"))
 
     # Parse the file
     try:
@@ -0,0 +1,95 @@
+import json
+from pathlib import Path
+
+import click
+from rich.console import Console
+from rich.table import Table
+
+from treepeat.history import DEFAULT_HISTORY_PATH, ROOT_DIRECTORY, HistoryError, RecordedRun, recorded_runs
+
+console = Console()
+
+# Width, in characters, of the bar of the most duplicated run
+_BAR_WIDTH = 30
+
+
+def _change(run: RecordedRun, previous: RecordedRun | None) -> str:
+    """The percentage points of duplication gained (+) or lost (-) since the previous run."""
+    return "" if previous is None else f"{run.percent - previous.percent:+.1f}"
+
+
+def _row(run: RecordedRun, previous: RecordedRun | None, highest: float) -> list[str]:
+    """The cells of a run, its bar scaled to the most duplicated run."""
+    return [
+        run.recorded_at.strftime("%Y-%m-%d %H:%M"),
+        (run.commit or "-")[:12],
+        str(run.lines),
+        str(run.duplicated_lines),
+        f"{run.percent:.1f}",
+        _change(run, previous),
+        str(run.clone_classes),
+        "█" * round(_BAR_WIDTH * run.percent / highest),
+    ]
+
+
+def _print_table(runs: list[RecordedRun], directory: str) -> None:
+    """One row per run, oldest first, with a bar of its duplication."""
+    table = Table(title=f"Duplication of {directory} per recorded run")
+    for column in ("Recorded", "Commit", "Lines", "Duplicated", "%", "Change", "Clone classes"):
+        table.add_column(column, justify="left" if column in ("Recorded", "Commit") else "right")
+    table.add_column("", justify="left")
+    highest = max(run.percent for run in runs) or 1.0
+    for previous, run in zip([None, *runs], runs, strict=False):
+        table.add_row(*_row(run, previous, highest))
+    console.print(table)
+
+
+def _run_to_dict(run: RecordedRun) -> dict[str, object]:
+    return {
+        "recorded_at": run.recorded_at.isoformat(),
+        "commit": run.commit,
+        "lines": run.lines,
+        "duplicated_lines": run.duplicated_lines,
+        "percent": round(run.percent, 2),
+        "clone_classes": run.clone_classes,
+        "clone_instances": run.clone_instances,
+    }
+
+
+@click.command()
+@click.option(
+    "--history",
+    type=click.Path(dir_okay=False, path_type=Path),
+    default=DEFAULT_HISTORY_PATH,
+    show_default=True,
+    help="SQLite database written by treepeat record",
+)
+@click.option(
+    "--directory",
+    default=ROOT_DIRECTORY,
+    help="Follow a directory, relative to the recorded path, instead of the whole path",
+)
+@click.option("--last", type=click.IntRange(min=1), help="Only show the latest runs")
+@click.option(
+    "--format",
+    "output_format",
+    type=click.Choice(["table", "json"], case_sensitive=False),
+    default="table",
+    help="table (with a bar chart) for people, json to chart elsewhere",
+)
+def trend(history: Path, directory: str, last: int | None, output_format: str) -> None:
+    """Show how duplication evolved across the runs recorded by treepeat record.
+
+    Clone counts are those of the whole path, whichever directory is followed.
+    """
+    try:
+        runs = recorded_runs(history, directory)
+    except HistoryError as e:
+        raise click.ClickException(str(e)) from e
+    runs = runs[-last:] if last else runs
+    if output_format.lower() == "json":
+        click.echo(json.dumps([_run_to_dict(run) for run in runs], indent=2))
+    elif runs:
+        _print_table(runs, directory)
+    else:
+        click.echo(f"No recorded run measured {directory}", err=True)
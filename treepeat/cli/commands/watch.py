@@ -0,0 +1,94 @@
+import json
+import tempfile
+import time
+from collections.abc import Callable
+from pathlib import Path
+from typing import Any
+
+import click
+from rich.console import Console
+from rich.markup import escape
+
+from treepeat.cli.commands.detect import detect, detection_params
+from treepeat.pipeline.parse import collect_source_files
+from treepeat.watch import CloneState, Snapshot, changed_paths, take_snapshot
+
+console = Console()
+
+Scan = Callable[[set[Path]], dict[str, Any]]
+
+
+def _locations(clone: dict[str, Any]) -> str:
+    """The copies of a clone class, as path:start-end."""
+    return escape(", ".join(clone["locations"]))
+
+
+def _print_changes(changes: dict[str, list[Any]]) -> None:
+    """Print one line per clone class introduced, removed or grown."""
+    stamp = time.strftime("%H:%M:%S")
+    for clone in changes["introduced"]:
+        copies = f"{clone['instances']} copies"
+        console.print(f"{stamp} [green]+ {clone['fingerprint']}[/green] {copies}: {_locations(clone)}")
+    for clone in changes["removed"]:
+        console.print(f"{stamp} [red]- {clone['fingerprint']}[/red] {_locations(clone)}")
+    for change in changes["grown"]:
+        before, after = change["before"], change["after"]
+        console.print(
+            f"{stamp} [yellow]~ {change['fingerprint']}[/yellow] grew from {before['instances']} to "
+            f"{after['instances']} copies, {before['lines']} to {after['lines']} lines: {_locations(after)}"
+        )
+
+
+def _poll(path: Path, snapshot: Snapshot, state: CloneState, scan: Scan) -> Snapshot:
+    """Rescan the files changed since the last snapshot, print what that changed, and return the new snapshot."""
+    current = take_snapshot(collect_source_files(path))
+    changed = changed_paths(snapshot, current)
+    if changed:
+        _print_changes(state.update(scan(changed), path, changed))
+    return current
+
+
+@click.pass_context
+def _watch(ctx: click.Context, path: Path, interval: float, **detect_options: Any) -> None:
+    """Report clones as they appear and disappear while the files under PATH are edited."""
+    with tempfile.TemporaryDirectory(prefix="treepeat-watch-") as tmp:
+        report_path = Path(tmp) / "report.json"
+
+        def scan(changed: set[Path]) -> dict[str, Any]:
+            changed_file = tuple(sorted(changed))
+            ctx.invoke(
+                detect, path=path, changed_file=changed_file, output_format="json", output=report_path, **detect_options
+            )
+            report: dict[str, Any] = json.loads(report_path.read_text(encoding="utf-8"))
+            return report
+
+        state = CloneState()
+        state.update(scan(set()), path, None)
+        snapshot = take_snapshot(collect_source_files(path))
+        console.print(f"Watching {escape(str(path))}: {len(state)} clone class(es). Press Ctrl+C to stop.")
+        try:
+            while True:
+                time.sleep(interval)
+                snapshot = _poll(path, snapshot, state, scan)
+        except KeyboardInterrupt:
+            pass
+
+
+watch = click.Command(
+    name="watch",
+    callback=_watch,
+    params=[
+        *[param for param in detection_params() if param.name not in ("changed_file", "changed_since")],
+        click.Option(
+            ["--interval"],
+            type=click.FloatRange(min=0.1),
+            default=1.0,
+            show_default=True,
+            help="Seconds between two checks for modified files",
+        ),
+    ],
+    help=(
+        "Scan PATH, then keep watching it: whenever files change, the clones involving them are looked up again "
+        "(against the rest of PATH) and the clone classes introduced (+), removed (-) and grown (~) are printed."
+    ),
+)
@@ -1,6 +1,22 @@
-from pydantic import Field
+from pathlib import Path
+from typing import Annotated, Any
+
+from pydantic import BaseModel, Field
 from pydantic_settings import BaseSettings, SettingsConfigDict
 
+# Regexes for the markers common code generators leave at the top of a file
+DEFAULT_GENERATED_MARKERS = [
+    r"Code generated .* DO NOT EDIT",  # Go convention (stringer, protoc-gen-go, mockgen, ...)
+    r"@generated",  # Facebook/Android tooling, Rust, Hack
+    r"Generated by the protocol buffer compiler",  # protoc for C++, Python, Java, ...
+    r"<auto-generated",  # .NET designers and tools
+    r"(?i)this (?:file|code) (?:is|was) (?:automatically|auto-?)generated",
+]
+
+# Widest stretch allowed inside a near-miss clone: a number of lines, or a percentage of the lines of the
+# longer region such as "10%"
+MaxGap = Annotated[int, Field(ge=0)] | Annotated[str, Field(pattern=r"^\d+(\.\d+)?%$")]
+
 
 class RulesSettings(BaseSettings):
     """Settings for the rules engine."""
@@ -14,6 +30,11 @@ class RulesSettings(BaseSettings):
         description="Ruleset profile to use",
     )
 
+    custom_rulesets: dict[str, Any] = Field(
+        default_factory=dict,
+        description="User-defined rulesets (the [rulesets] tables of a config file), by name",
+    )
+
     # Mapping of language -> allowed region node types to extract
     # When provided, only these languages and node types are used to build
     # region-extraction and normalization rules for the rule engine.
@@ -28,6 +49,22 @@ class RulesSettings(BaseSettings):
             "(e.g., {'python': {'decorated_definition'}})"
         ),
     )
+    ignore_qualifiers: bool = Field(
+        default=False,
+        description="Strip access/storage modifiers (public, private, static, ...) before comparison",
+    )
+    normalize_signature_types: bool = Field(
+        default=False,
+        description="Normalize parameter and return types in function signatures (bodies are untouched)",
+    )
+    flatten_preprocessor: bool = Field(
+        default=False,
+        description="Compare only the first branch of C/C++ #if/#ifdef conditionals, ignoring their conditions",
+    )
+    normalize: list[str] = Field(
+        default_factory=list,
+        description="Extra normalizations applied on top of the ruleset ('identifiers', 'literals', 'comments')",
+    )
     excluded_regions: dict[str, set[str]] = Field(
         default_factory=dict,
         description=(
@@ -49,6 +86,23 @@ class ShingleSettings(BaseSettings):
         ge=1,
         description="Length of k-grams (number of nodes in each shingle path)",
     )
+    cross_language: bool = Field(
+        default=False,
+        description="Shingle a language-neutral projection of the AST so clones can match across languages",
+    )
+    canonicalize: bool = Field(
+        default=False,
+        description="Canonicalize commutative operands, trivial constant expressions and loop forms before hashing",
+    )
+    strategy: str = Field(
+        default="shingle",
+        description="How regions are fingerprinted ('shingle' = every k-gram; 'winnow' = winnowed k-grams)",
+    )
+    winnow_window: int = Field(
+        default=4,
+        ge=1,
+        description="Window size for the winnow strategy (a fingerprint is kept for every window of k-grams)",
+    )
 
 
 class MinHashSettings(BaseSettings):
@@ -65,6 +119,16 @@ class MinHashSettings(BaseSettings):
     )
 
 
+class LanguageThresholds(BaseModel):
+    """Thresholds of one language, overriding the global ones (None keeps the global value)."""
+
+    min_lines: int | None = Field(default=None, ge=0, description="Minimum number of lines of each copy")
+    min_tokens: int | None = Field(
+        default=None, ge=0, description="Minimum number of normalized tokens (shingles) of each copy"
+    )
+    min_similarity: float | None = Field(default=None, ge=0.0, le=1.0, description="Minimum similarity (fraction)")
+
+
 class LSHSettings(BaseSettings):
     """Settings for Locality Sensitive Hashing."""
 
@@ -78,13 +142,32 @@ class LSHSettings(BaseSettings):
         description="Minimum number of lines for a match to be considered valid",
     )
 
+    min_complexity: int = Field(
+        default=1,
+        ge=1,
+        description="Minimum cyclomatic-style complexity (1 + control-flow branches) for a region to be compared",
+    )
+
     similarity_percent: float = Field(default=0.8, ge=0.0, le=1.0, description="% treesitter similarity")
 
+    max_gap_lines: MaxGap | None = Field(
+        default=None,
+        description=(
+            "Largest inserted/deleted/modified stretch allowed inside a near-miss clone, in lines "
+            "or as a percentage of the region's lines (e.g. '10%')"
+        ),
+    )
+
     ignore_node_types: list[str] = Field(
         default_factory=list,
         description="Node types to ignore during region extraction (e.g., ['parameters', 'argument_list'])",
     )
 
+    language_thresholds: dict[str, LanguageThresholds] = Field(
+        default_factory=dict,
+        description="Per-language overrides of min_lines, min_tokens and similarity_percent (e.g. {'go': ...})",
+    )
+
 
 class PipelineSettings(BaseSettings):
     """Global settings for the entire pipeline."""
@@ -105,6 +188,87 @@ class PipelineSettings(BaseSettings):
         default_factory=lambda: ["**/.*ignore"],
         description="List of glob patterns to find ignore files (like .gitignore)",
     )
+    include_patterns: list[str] = Field(
+        default_factory=list,
+        description="When set, only scan files matching one of these globs (applied after ignore files)",
+    )
+    exclude_patterns: list[str] = Field(
+        default_factory=list,
+        description="Skip files matching any of these globs (applied after ignore files)",
+    )
+    include_vendored: bool = Field(
+        default=False,
+        description="Scan dependency and build-output directories (vendor/, node_modules/, ...), skipped by default",
+    )
+    follow_symlinks: bool = Field(
+        default=False,
+        description="Follow symlinks while scanning (each target once, skipping cycles and links into the root)",
+    )
+    tests: str = Field(
+        default="include",
+        description="Test code: 'include' it, report its clones in a 'separate' section, or 'exclude' test files",
+    )
+    changed_files: set[Path] | None = Field(
+        default=None,
+        description="When set, only clones with a copy in one of these (absolute) files are looked for and reported",
+    )
+    shard: tuple[int, int] | None = Field(
+        default=None,
+        description="When set, only scan the files of shard K of N (1-based), for 'treepeat merge' to combine",
+    )
+    detect_comments: bool = Field(
+        default=False,
+        description="Also detect clones in prose such as notebook markdown cells",
+    )
+    cache_dir: Path | None = Field(
+        default=None,
+        description="Directory of the on-disk fingerprint cache, reused between runs (None = no cache)",
+    )
+    max_index_memory: int | None = Field(
+        default=None,
+        gt=0,
+        description=(
+            "Bytes the LSH band buckets may take in memory before they are spilled to disk (None = no limit); "
+            "signatures and shingles are not counted"
+        ),
+    )
+    jobs: int = Field(
+        default=1,
+        ge=1,
+        description="Number of workers used to parse files, fingerprint regions and compare candidates",
+    )
+    parse_timeout: float | None = Field(
+        default=None,
+        gt=0,
+        description="Seconds allowed for parsing a single file before it is skipped (None = no limit)",
+    )
+    max_file_size: int | None = Field(
+        default=None,
+        gt=0,
+        description="Bytes a file may hold before it is skipped without being read (None = no limit)",
+    )
+    include_minified: bool = Field(
+        default=False,
+        description="Scan minified and bundled JavaScript, which is skipped by default",
+    )
+    include_generated: bool = Field(
+        default=False,
+        description="Scan files carrying a generated-code marker, which are skipped by default",
+    )
+    generated_markers: list[str] = Field(
+        default_factory=lambda: list(DEFAULT_GENERATED_MARKERS),
+        description="Regexes searched in the first lines of a file to recognize generated code",
+    )
+    file_similarity: float | None = Field(
+        default=None,
+        ge=0.0,
+        le=1.0,
+        description="Report whole files at least this similar before fragment analysis (None = no file pass)",
+    )
+    fallback: str | None = Field(
+        default=None,
+        description="How to scan files without a tree-sitter grammar ('token' = lexical tokens; None = skip them)",
+    )
 
 
 # Global settings instance that can be accessed throughout the application
@@ -0,0 +1,67 @@
+import tomllib
+from pathlib import Path
+from typing import Any
+
+# Dedicated config files, looked for before a [tool.treepeat] table in pyproject.toml
+CONFIG_FILE_NAMES = (".treepeat.toml", "treepeat.toml")
+
+# Commands that run detect: the [detect] table of a config file applies to them too
+DETECTING_COMMANDS = (
+    "baseline",
+    "diff",
+    "explain",
+    "hook",
+    "hotspots",
+    "lsp",
+    "mcp",
+    "record",
+    "serve",
+    "stats",
+    "watch",
+)
+
+
+class ConfigFileError(ValueError):
+    """Raised when a config file cannot be read or parsed."""
+
+
+def _read_toml(path: Path) -> dict[str, Any]:
+    try:
+        with path.open("rb") as f:
+            return tomllib.load(f)
+    except (OSError, tomllib.TOMLDecodeError) as e:
+        raise ConfigFileError(f"cannot read {path}: {e}") from e
+
+
+def _has_tool_table(pyproject: Path) -> bool:
+    """True if a pyproject.toml exists and holds a [tool.treepeat] table."""
+    return pyproject.is_file() and "treepeat" in _read_toml(pyproject).get("tool", {})
+
+
+def _config_in(directory: Path) -> Path | None:
+    """The config file of one directory, if it has one."""
+    named = [directory / name for name in CONFIG_FILE_NAMES if (directory / name).is_file()]
+    if named:
+        return named[0]
+    pyproject = directory / "pyproject.toml"
+    return pyproject if _has_tool_table(pyproject) else None
+
+
+def find_config_file(start: Path) -> Path | None:
+    """The nearest config file in ``start`` or its parents, up to the repository root (the directory holding .git)."""
+    for directory in (start, *start.parents):
+        found = _config_in(directory)
+        if found is not None or (directory / ".git").exists():
+            return found
+    return None
+
+
+def load_config_file(path: Path) -> dict[str, Any]:
+    """Read the settings of a config file: the whole file, or the [tool.treepeat] table of a pyproject.toml."""
+    document = _read_toml(path)
+    if path.name != "pyproject.toml":
+        return document
+    config = document.get("tool", {}).get("treepeat", {})
+    if not isinstance(config, dict):
+        raise ConfigFileError(f"[tool.treepeat] of {path} is not a table")
+    return config
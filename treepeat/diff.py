@@ -4,6 +4,7 @@ from collections.abc import Sequence
 from rich.console import Console
 from rich.markup import escape
 
+from treepeat.formatters.snippets import read_region_lines
 from treepeat.models.similarity import Region
 from treepeat.terminal_detect import get_diff_colors
 
@@ -18,29 +19,14 @@ def _truncate_line(line: str, max_width: int) -> str:
     return line[:max_width] if len(line) > max_width else line
 
 
-def _read_region_lines(region: Region) -> list[str]:
-    """Read lines from a file for a specific region."""
-    try:
-        with open(region.path, "r", encoding="utf-8") as f:
-            lines = f.readlines()
-            # Extract lines for this region (1-indexed to 0-indexed)
-            return lines[region.start_line - 1 : region.end_line]
-    except Exception:
-        return []
-
-
 def _prepare_diff_lines(region1: Region, region2: Region) -> tuple[list[str], list[str]] | None:
     """Read and prepare lines from both regions for diff."""
-    lines1 = _read_region_lines(region1)
-    lines2 = _read_region_lines(region2)
+    lines1 = read_region_lines(region1)
+    lines2 = read_region_lines(region2)
 
     if not lines1 or not lines2:
         return None
 
-    # Strip newlines from lines
-    lines1 = [line.rstrip("\n\r") for line in lines1]
-    lines2 = [line.rstrip("\n\r") for line in lines2]
-
     return (lines1, lines2)
 
 
@@ -0,0 +1,83 @@
+import difflib
+from typing import Any
+
+from treepeat.config import PipelineSettings
+from treepeat.pipeline.thresholds import effective_thresholds
+from treepeat.pipeline.token_fallback import tokenize
+
+# Id of a clone class without a fingerprint, as the csv format numbers them
+_GROUP_PREFIX = "group-"
+
+
+class CloneNotFoundError(Exception):
+    """No clone class, or more than one, matches a clone id."""
+
+
+def _by_number(clone_classes: list[dict[str, Any]], clone_id: str) -> list[dict[str, Any]]:
+    number = clone_id.removeprefix(_GROUP_PREFIX)
+    if clone_id.startswith(_GROUP_PREFIX) and number.isdigit() and 0 < int(number) <= len(clone_classes):
+        return [clone_classes[int(number) - 1]]
+    return []
+
+
+def _by_fingerprint(clone_classes: list[dict[str, Any]], clone_id: str) -> list[dict[str, Any]]:
+    return [clone for clone in clone_classes if clone["fingerprint"] == clone_id]
+
+
+def _by_prefix(clone_classes: list[dict[str, Any]], clone_id: str) -> list[dict[str, Any]]:
+    return [clone for clone in clone_classes if clone["fingerprint"].startswith(clone_id)]
+
+
+def find_clone_class(clone_classes: list[dict[str, Any]], clone_id: str) -> dict[str, Any]:
+    """The clone class of a json report with a fingerprint (or unique fingerprint prefix, or group-N id)."""
+    matches = (
+        _by_fingerprint(clone_classes, clone_id)
+        or _by_prefix(clone_classes, clone_id)
+        or _by_number(clone_classes, clone_id)
+    )
+    if not matches:
+        raise CloneNotFoundError(f"no clone class matches '{clone_id}'")
+    if len(matches) > 1:
+        raise CloneNotFoundError(f"'{clone_id}' matches {len(matches)} clone classes; give more of the fingerprint")
+    return matches[0]
+
+
+def differing_tokens(left: list[str], right: list[str]) -> list[tuple[str, str]]:
+    """The token runs that differ between two copies, as (left text, right text); '' for a missing side."""
+    left_tokens = [token for token, _ in tokenize(left, [])]
+    right_tokens = [token for token, _ in tokenize(right, [])]
+    matcher = difflib.SequenceMatcher(None, left_tokens, right_tokens, autojunk=False)
+    return [
+        (" ".join(left_tokens[i1:i2]), " ".join(right_tokens[j1:j2]))
+        for tag, i1, i2, j1, j2 in matcher.get_opcodes()
+        if tag != "equal"
+    ]
+
+
+def _strategy(settings: PipelineSettings) -> str:
+    shingle = settings.shingle
+    window = f", window {shingle.winnow_window}" if shingle.strategy == "winnow" else ""
+    return f"{shingle.strategy} (k={shingle.k}{window})"
+
+
+def _thresholds(language: str, settings: PipelineSettings) -> str:
+    thresholds = effective_thresholds(language, settings.lsh)
+    return (
+        f"similarity >= {thresholds.min_similarity:.0%}, at least {thresholds.min_lines} line(s) "
+        f"and {thresholds.min_tokens} token(s) per copy"
+    )
+
+
+def match_criteria(clone: dict[str, Any], settings: PipelineSettings) -> list[tuple[str, str]]:
+    """Label and value of each setting that made a json report clone class count as a clone."""
+    instances = clone["instances"]
+    languages = sorted({instance["language"] for instance in instances})
+    criteria = [
+        ("Ruleset", settings.rules.ruleset),
+        ("Matched regions", ", ".join(sorted({instance["region_type"] for instance in instances}))),
+        ("Strategy", _strategy(settings)),
+        ("Normalization", ", ".join(settings.rules.normalize) or "none"),
+    ]
+    criteria += [(f"Thresholds ({language})", _thresholds(language, settings)) for language in languages]
+    criteria.append(("Similarity", f"{clone['similarity']:.1%}"))
+    return criteria
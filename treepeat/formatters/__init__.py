@@ -0,0 +1,27 @@
+from collections.abc import Callable
+
+from treepeat.formatters.codeclimate import format_as_codeclimate
+from treepeat.formatters.cpd import format_as_cpd_xml
+from treepeat.formatters.csv import format_as_csv
+from treepeat.formatters.dot import format_as_dot
+from treepeat.formatters.gerrit import format_as_gerrit
+from treepeat.formatters.github import format_as_github
+from treepeat.formatters.html import format_as_html
+from treepeat.formatters.json import format_as_json
+from treepeat.formatters.junit import format_as_junit
+from treepeat.formatters.sonarqube import format_as_sonarqube
+from treepeat.models.similarity import SimilarityResult
+
+# Output formats (besides console and sarif) that render the whole result as text
+FILE_FORMATTERS: dict[str, Callable[[SimilarityResult], str]] = {
+    "codeclimate": format_as_codeclimate,
+    "cpd-xml": format_as_cpd_xml,
+    "csv": format_as_csv,
+    "dot": format_as_dot,
+    "gerrit": format_as_gerrit,
+    "github": format_as_github,
+    "html": format_as_html,
+    "json": format_as_json,
+    "junit": format_as_junit,
+    "sonarqube": format_as_sonarqube,
+}
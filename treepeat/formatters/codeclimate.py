@@ -0,0 +1,57 @@
+import hashlib
+import json
+from typing import Any
+
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+
+CHECK_NAME = "treepeat/clone"
+
+
+def _severity(similarity: float) -> str:
+    """Map a similarity score onto a Code Quality severity."""
+    if similarity >= 0.95:
+        return "major"
+    if similarity >= 0.85:
+        return "minor"
+    return "info"
+
+
+def _location(region: Region) -> dict[str, Any]:
+    """Build a Code Climate location for a region."""
+    return {"path": str(region.path), "lines": {"begin": region.start_line, "end": region.end_line}}
+
+
+def _issue_fingerprint(group: SimilarRegionGroup, region: Region) -> str:
+    """Stable per-instance id: GitLab uses it to track an issue across pipelines, so it must be unique."""
+    same_file = sorted((r for r in group.regions if r.path == region.path), key=lambda r: (r.start_line, r.end_line))
+    ordinal = next(i for i, r in enumerate(same_file) if r is region)
+    key = f"{group.fingerprint}:{region.path}:{ordinal}"
+    return hashlib.md5(key.encode("utf-8")).hexdigest()
+
+
+def _issues_for_group(group: SimilarRegionGroup) -> list[dict[str, Any]]:
+    """Report one issue per clone instance, pointing at the other copies."""
+    issues = []
+    for region in group.regions:
+        others = [r for r in group.regions if r is not region]
+        issues.append(
+            {
+                "type": "issue",
+                "check_name": CHECK_NAME,
+                "description": (
+                    f"Similar code ({group.similarity:.1%} similar) found in {len(others)} other location(s)"
+                ),
+                "categories": ["Duplication"],
+                "severity": _severity(group.similarity),
+                "fingerprint": _issue_fingerprint(group, region),
+                "location": _location(region),
+                "other_locations": [_location(r) for r in others],
+            }
+        )
+    return issues
+
+
+def format_as_codeclimate(result: SimilarityResult) -> str:
+    """Format similarity detection results as a GitLab Code Quality (Code Climate) report."""
+    issues = [issue for group in result.similar_groups for issue in _issues_for_group(group)]
+    return json.dumps(issues, indent=2)
@@ -0,0 +1,37 @@
+import xml.etree.ElementTree as ET
+
+from treepeat.formatters.snippets import read_region_lines
+from treepeat.models.similarity import SimilarityResult, SimilarRegionGroup, TokenCounts, region_key, token_counts
+
+
+def _duplication_element(group: SimilarRegionGroup, counts: TokenCounts) -> ET.Element:
+    """Build a CPD <duplication> element for one clone class."""
+    lines = max(r.end_line - r.start_line + 1 for r in group.regions)
+    # The normalized shingle count is the closest analogue of CPD's token count
+    tokens = max(counts.get(region_key(r), 0) for r in group.regions)
+    duplication = ET.Element(
+        "duplication", lines=str(lines), tokens=str(tokens), similarity=f"{group.similarity:.4f}"
+    )
+    if group.fingerprint:
+        duplication.set("fingerprint", group.fingerprint)
+    for region in group.regions:
+        ET.SubElement(
+            duplication,
+            "file",
+            line=str(region.start_line),
+            endline=str(region.end_line),
+            path=str(region.path),
+        )
+    fragment = ET.SubElement(duplication, "codefragment")
+    fragment.text = "\n".join(read_region_lines(group.representative))
+    return duplication
+
+
+def format_as_cpd_xml(result: SimilarityResult) -> str:
+    """Format similarity detection results as a PMD CPD XML report."""
+    root = ET.Element("pmd-cpd")
+    counts = token_counts(result.signatures)
+    for group in result.similar_groups:
+        root.append(_duplication_element(group, counts))
+    ET.indent(root)
+    return '<?xml version="1.0" encoding="UTF-8"?>\n' + ET.tostring(root, encoding="unicode")
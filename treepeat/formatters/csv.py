@@ -0,0 +1,43 @@
+import csv
+import io
+
+from treepeat.models.similarity import SimilarityResult, region_key, token_counts
+
+COLUMNS = [
+    "clone_id",
+    "path",
+    "language",
+    "region_name",
+    "start_line",
+    "end_line",
+    "lines",
+    "tokens",
+    "similarity",
+    "group_size",
+]
+
+
+def format_as_csv(result: SimilarityResult) -> str:
+    """Format similarity detection results as CSV, one row per clone instance."""
+    counts = token_counts(result.signatures)
+    buffer = io.StringIO()
+    writer = csv.writer(buffer, lineterminator="\n")
+    writer.writerow(COLUMNS)
+    for index, group in enumerate(result.similar_groups, start=1):
+        clone_id = group.fingerprint or f"group-{index}"
+        for region in group.regions:
+            writer.writerow(
+                [
+                    clone_id,
+                    str(region.path),
+                    region.language,
+                    region.region_name,
+                    region.start_line,
+                    region.end_line,
+                    region.end_line - region.start_line + 1,
+                    counts.get(region_key(region), ""),
+                    f"{group.similarity:.4f}",
+                    group.size,
+                ]
+            )
+    return buffer.getvalue()
@@ -0,0 +1,76 @@
+from collections import defaultdict
+from itertools import combinations
+from pathlib import Path
+
+from treepeat.models.similarity import Region, SimilarityResult
+
+_Nodes = dict[Path, dict[str, str]]
+_Edges = dict[tuple[str, str], int]
+_Similarities = dict[tuple[str, str], float]
+_Fingerprints = dict[tuple[str, str], set[str]]
+
+
+def _quote(text: str) -> str:
+    """Quote a DOT identifier or label, keeping newlines as line breaks."""
+    escaped = text.replace("\\", "\\\\").replace('"', '\\"').replace("\n", "\\n")
+    return f'"{escaped}"'
+
+
+def _node_id(region: Region) -> str:
+    """Identify a region node by its file and start line."""
+    return f"{region.path}:{region.start_line}"
+
+
+def _region_lines(region: Region) -> int:
+    """Number of lines spanned by a region."""
+    return region.end_line - region.start_line + 1
+
+
+def _collect_graph(result: SimilarityResult) -> tuple[_Nodes, _Edges, _Similarities, _Fingerprints]:
+    """Collect region nodes per file and, per clone edge, its duplicated lines, best similarity and fingerprints."""
+    nodes: _Nodes = defaultdict(dict)
+    edges: _Edges = defaultdict(int)
+    similarities: _Similarities = defaultdict(float)
+    fingerprints: _Fingerprints = defaultdict(set)
+    for group in result.similar_groups:
+        for region in group.regions:
+            nodes[region.path][_node_id(region)] = f"{region.region_name}\n{region.start_line}-{region.end_line}"
+        for a, b in combinations(group.regions, 2):
+            first, second = sorted((_node_id(a), _node_id(b)))
+            edges[(first, second)] += min(_region_lines(a), _region_lines(b))
+            similarities[(first, second)] = max(similarities[(first, second)], group.similarity)
+            fingerprints[(first, second)].update({group.fingerprint} - {""})
+    return nodes, edges, similarities, fingerprints
+
+
+def _tooltip(similarity: float, fingerprints: set[str]) -> str:
+    """Describe an edge: its best similarity, then the clone classes it belongs to."""
+    shared = f" ({', '.join(sorted(fingerprints))})" if fingerprints else ""
+    return f"{similarity:.1%} similar{shared}"
+
+
+def format_as_dot(result: SimilarityResult) -> str:
+    """Format the clone relationships as a Graphviz DOT graph.
+
+    Files are clusters, regions are nodes, and edges join clone instances,
+    labelled (and thickened) by the number of duplicated lines between them;
+    their tooltip gives the highest similarity and the fingerprints of the
+    clone classes they share.
+    """
+    nodes, edges, similarities, fingerprints = _collect_graph(result)
+    lines = ["graph treepeat {", "  node [shape=box];"]
+    for index, (path, file_nodes) in enumerate(sorted(nodes.items())):
+        lines.append(f"  subgraph cluster_{index} {{")
+        lines.append(f"    label={_quote(str(path))};")
+        lines += [f"    {_quote(node)} [label={_quote(label)}];" for node, label in sorted(file_nodes.items())]
+        lines.append("  }")
+    heaviest = max(edges.values(), default=1)
+    for (a, b), weight in sorted(edges.items()):
+        penwidth = 1 + 4 * weight / heaviest
+        tooltip = _tooltip(similarities[(a, b)], fingerprints[(a, b)])
+        lines.append(
+            f'  {_quote(a)} -- {_quote(b)} [label="{weight}", weight={weight}, penwidth={penwidth:.1f}, '
+            f"tooltip={_quote(tooltip)}];"
+        )
+    lines.append("}")
+    return "\n".join(lines) + "\n"
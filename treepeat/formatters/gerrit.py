@@ -0,0 +1,48 @@
+import hashlib
+import json
+from typing import Any
+
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+
+ROBOT_ID = "treepeat"
+
+# Gerrit shows only the latest review of an autogenerated tag, hiding the comments of earlier runs
+REVIEW_TAG = "autogenerated:treepeat"
+
+
+def _describe(region: Region) -> str:
+    return f"{region.path}:{region.start_line}-{region.end_line}"
+
+
+def _run_id(result: SimilarityResult) -> str:
+    """Id of a run, the same for runs finding the same clone classes."""
+    fingerprints = "\n".join(sorted(group.fingerprint for group in result.similar_groups))
+    return hashlib.sha1(fingerprints.encode("utf-8")).hexdigest()[:12]
+
+
+def _robot_comment(group: SimilarRegionGroup, region: Region, run_id: str) -> dict[str, Any]:
+    """A robot comment on one clone instance, naming the other copies."""
+    others = ", ".join(_describe(r) for r in group.regions if r is not region)
+    return {
+        "robot_id": ROBOT_ID,
+        "robot_run_id": run_id,
+        "line": region.start_line,
+        "message": (
+            f"Duplicated code ({group.similarity:.1%} similar, {group.fingerprint}): "
+            f"lines {region.start_line}-{region.end_line} are also found at {others}"
+        ),
+        "properties": {"fingerprint": group.fingerprint},
+    }
+
+
+def format_as_gerrit(result: SimilarityResult) -> str:
+    """Format similarity detection results as a Gerrit review with a robot comment per clone instance.
+
+    The output is a ReviewInput, ready to post to a revision's review endpoint.
+    """
+    run_id = _run_id(result)
+    comments: dict[str, list[dict[str, Any]]] = {}
+    for group in result.similar_groups:
+        for region in group.regions:
+            comments.setdefault(str(region.path), []).append(_robot_comment(group, region, run_id))
+    return json.dumps({"tag": REVIEW_TAG, "robot_comments": comments}, indent=2)
@@ -0,0 +1,41 @@
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+
+TITLE = "Duplicated code"
+
+
+def _escape_data(text: str) -> str:
+    """Escape a workflow command message."""
+    return text.replace("%", "%25").replace("\r", "%0D").replace("\n", "%0A")
+
+
+def _escape_property(text: str) -> str:
+    """Escape a workflow command property value."""
+    return _escape_data(text).replace(":", "%3A").replace(",", "%2C")
+
+
+def _describe(region: Region) -> str:
+    return f"{region.path}:{region.start_line}-{region.end_line}"
+
+
+def _annotation(group: SimilarRegionGroup, region: Region) -> str:
+    """A warning on one clone instance, naming the other copies."""
+    others = ", ".join(_describe(r) for r in group.regions if r is not region)
+    properties = ",".join(
+        f"{name}={_escape_property(value)}"
+        for name, value in (
+            ("file", str(region.path)),
+            ("line", str(region.start_line)),
+            ("endLine", str(region.end_line)),
+            ("title", TITLE),
+        )
+    )
+    message = f"{group.similarity:.1%} similar to {others} ({group.fingerprint})"
+    return f"::warning {properties}::{_escape_data(message)}"
+
+
+def format_as_github(result: SimilarityResult) -> str:
+    """Format similarity detection results as GitHub Actions workflow commands (one warning per clone instance).
+
+    Printed in a workflow step, each line becomes an annotation on the pull request diff.
+    """
+    return "\n".join(_annotation(group, region) for group in result.similar_groups for region in group.regions)
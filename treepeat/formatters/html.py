@@ -0,0 +1,171 @@
+import difflib
+from html import escape
+
+from treepeat.formatters.snippets import describe_region, read_region_lines
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+from treepeat.stats import DuplicationStats, LineCounts, duplication_stats, most_duplicated
+
+_STYLE = """
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #1f2328; }
+header { margin-bottom: 1rem; }
+.controls { display: flex; gap: 1rem; margin-bottom: 1.5rem; flex-wrap: wrap; }
+.clone { border: 1px solid #d0d7de; border-radius: 6px; margin-bottom: 1.5rem; padding: 0.75rem 1rem; }
+.clone h2 { font-size: 1rem; margin: 0 0 0.5rem; }
+.fingerprint { color: #656d76; font-family: monospace; font-weight: normal; }
+.instances { display: grid; grid-auto-flow: column; grid-auto-columns: minmax(0, 1fr); gap: 0.75rem; }
+.instance h3 { font-size: 0.85rem; font-family: monospace; margin: 0 0 0.25rem; word-break: break-all; }
+.owner { font-size: 0.8rem; color: #656d76; margin: 0 0 0.25rem; }
+pre { background: #f6f8fa; margin: 0; padding: 0.5rem; overflow-x: auto; font-size: 0.8rem; }
+pre span { display: block; white-space: pre; }
+pre span.changed { background: #fff8c5; }
+.hidden { display: none; }
+.stats { display: flex; gap: 2rem; flex-wrap: wrap; margin-bottom: 1.5rem; font-size: 0.85rem; }
+.stats th, .stats td { padding: 0.15rem 0.5rem; text-align: right; }
+.stats th:first-child, .stats td:first-child { text-align: left; font-family: monospace; }
+"""
+
+_SCRIPT = """
+const clones = Array.from(document.querySelectorAll('.clone'));
+const container = document.getElementById('clones');
+function applyFilters() {
+  const file = document.getElementById('file-filter').value.toLowerCase();
+  const language = document.getElementById('language-filter').value;
+  for (const clone of clones) {
+    const fileMatch = !file || clone.dataset.files.toLowerCase().includes(file);
+    const languageMatch = !language || clone.dataset.languages.split(' ').includes(language);
+    clone.classList.toggle('hidden', !(fileMatch && languageMatch));
+  }
+}
+function applySort() {
+  const key = document.getElementById('sort').value;
+  clones.sort((a, b) => Number(b.dataset[key]) - Number(a.dataset[key]));
+  for (const clone of clones) container.appendChild(clone);
+}
+document.getElementById('file-filter').addEventListener('input', applyFilters);
+document.getElementById('language-filter').addEventListener('change', applyFilters);
+document.getElementById('sort').addEventListener('change', applySort);
+applySort();
+"""
+
+
+def _region_lines(region: Region) -> int:
+    """Number of lines spanned by a region."""
+    return region.end_line - region.start_line + 1
+
+
+def _changed_lines(reference: list[str], lines: list[str]) -> set[int]:
+    """Return indexes of lines that differ from the reference instance."""
+    matcher = difflib.SequenceMatcher(None, reference, lines, autojunk=False)
+    return {j for tag, _, _, j1, j2 in matcher.get_opcodes() if tag != "equal" for j in range(j1, j2)}
+
+
+def _render_snippet(lines: list[str], changed: set[int]) -> str:
+    """Render source lines, highlighting those that differ from the first instance."""
+    spans = (
+        f'<span class="{"changed" if i in changed else "same"}">{escape(line) or " "}</span>'
+        for i, line in enumerate(lines)
+    )
+    return f"<pre>{''.join(spans)}</pre>"
+
+
+def _render_owner(region: Region) -> str:
+    """Render who an instance belongs to, when detect --owners looked it up."""
+    if region.owner is None:
+        return ""
+    parts = [f"by {region.owner.author}"] if region.owner.author else []
+    parts += [f"owned by {' '.join(region.owner.owners)}"] if region.owner.owners else []
+    return f'<p class="owner">{escape(", ".join(parts))}</p>'
+
+
+def _render_instance(region: Region, reference: list[str]) -> str:
+    """Render one clone instance with its snippet."""
+    lines = read_region_lines(region)
+    return (
+        '<div class="instance">'
+        f"<h3>{escape(describe_region(region))} ({_region_lines(region)} lines) "
+        f"{escape(region.region_name)}</h3>"
+        f"{_render_owner(region)}"
+        f"{_render_snippet(lines, _changed_lines(reference, lines))}"
+        "</div>"
+    )
+
+
+def _render_group(group: SimilarRegionGroup) -> str:
+    """Render a clone class: its instances side by side."""
+    reference = read_region_lines(group.representative)
+    files = " ".join(sorted({str(r.path) for r in group.regions}))
+    languages = " ".join(sorted({r.language for r in group.regions}))
+    lines = max(_region_lines(r) for r in group.regions)
+    instances = "".join(_render_instance(region, reference) for region in group.regions)
+    return (
+        f'<section class="clone" data-similarity="{group.similarity:.4f}" data-lines="{lines}" '
+        f'data-files="{escape(files)}" data-languages="{escape(languages)}">'
+        f'<h2>{group.similarity:.1%} similar, {group.size} instances, up to {lines} lines '
+        f'<span class="fingerprint">{escape(group.fingerprint)}</span></h2>'
+        f'<div class="instances">{instances}</div>'
+        "</section>"
+    )
+
+
+def _render_controls(result: SimilarityResult) -> str:
+    """Render the filter and sort controls."""
+    languages = sorted({r.language for g in result.similar_groups for r in g.regions})
+    options = "".join(f'<option value="{escape(lang)}">{escape(lang)}</option>' for lang in languages)
+    return (
+        '<div class="controls">'
+        '<label>File <input id="file-filter" type="search" placeholder="path contains..."></label>'
+        f'<label>Language <select id="language-filter"><option value="">All</option>{options}</select></label>'
+        '<label>Sort by <select id="sort"><option value="lines">Size</option>'
+        '<option value="similarity">Similarity</option></select></label>'
+        "</div>"
+    )
+
+
+# Rows shown in each duplication table, the most duplicated first
+_STATS_ROWS = 10
+
+
+def _render_stats_table(title: str, breakdown: dict[str, LineCounts]) -> str:
+    """Render the most duplicated entries of a breakdown of the duplication."""
+    rows = "".join(
+        f"<tr><td>{escape(name)}</td><td>{counts.duplicated_lines}</td><td>{counts.lines}</td>"
+        f"<td>{counts.percent:.1f}%</td></tr>"
+        for name, counts in most_duplicated(breakdown)[:_STATS_ROWS]
+    )
+    return (
+        f"<table><thead><tr><th>{title}</th><th>Duplicated</th><th>Lines</th><th>%</th></tr></thead>"
+        f"<tbody>{rows}</tbody></table>"
+    )
+
+
+def _render_stats(stats: DuplicationStats) -> str:
+    """Render the duplication by language and by directory."""
+    return (
+        '<div class="stats">'
+        f"{_render_stats_table('Language', stats.by_language)}"
+        f"{_render_stats_table('Directory', stats.by_directory)}"
+        "</div>"
+    )
+
+
+def format_as_html(result: SimilarityResult) -> str:
+    """Format similarity detection results as a self-contained HTML report."""
+    groups = "".join(_render_group(group) for group in result.similar_groups)
+    stats = duplication_stats(result)
+    summary = (
+        f"{len(result.similar_groups)} clone group(s) across "
+        f"{len({r.path for g in result.similar_groups for r in g.regions})} file(s); "
+        f"{stats.total.duplicated_lines} of {stats.total.lines} compared lines duplicated ({stats.total.percent:.1f}%)"
+    )
+    return (
+        "<!DOCTYPE html>\n"
+        '<html lang="en"><head><meta charset="utf-8">'
+        "<title>treepeat clone report</title>"
+        f"<style>{_STYLE}</style></head><body>"
+        f"<header><h1>treepeat clone report</h1><p>{summary}</p></header>"
+        f"{_render_stats(stats)}"
+        f"{_render_controls(result)}"
+        f'<main id="clones">{groups}</main>'
+        f"<script>{_SCRIPT}</script>"
+        "</body></html>\n"
+    )
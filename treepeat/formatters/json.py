@@ -0,0 +1,70 @@
+import json
+from pathlib import Path
+from typing import Any
+
+from treepeat.models.similarity import Ownership, Region, SimilarityResult, SimilarRegionGroup
+from treepeat.stats import duplication_stats, fragment_types_to_list, stats_to_dict
+
+# Bump when the document structure changes incompatibly (see docs/schema/report-v1.schema.json)
+SCHEMA_VERSION = 1
+
+
+def region_to_dict(region: Region) -> dict[str, Any]:
+    """Serialize a clone instance (with its owner when detect --owners looked it up)."""
+    instance: dict[str, Any] = {
+        "path": str(region.path),
+        "language": region.language,
+        "region_type": region.region_type,
+        "region_name": region.region_name,
+        "start_line": region.start_line,
+        "end_line": region.end_line,
+        "lines": region.end_line - region.start_line + 1,
+    }
+    if region.owner is not None:
+        instance["owner"] = region.owner.model_dump(exclude_none=True)
+    return instance
+
+
+def region_from_dict(instance: dict[str, Any]) -> Region:
+    """Rebuild a clone instance serialized by ``region_to_dict``."""
+    return Region(
+        path=Path(instance["path"]),
+        language=instance["language"],
+        region_type=instance["region_type"],
+        region_name=instance["region_name"],
+        start_line=instance["start_line"],
+        end_line=instance["end_line"],
+        owner=Ownership.model_validate(instance["owner"]) if "owner" in instance else None,
+    )
+
+
+def group_to_dict(group: SimilarRegionGroup) -> dict[str, Any]:
+    """Serialize a clone class and its instances."""
+    return {
+        "fingerprint": group.fingerprint,
+        "similarity": round(group.similarity, 4),
+        "instances": [region_to_dict(region) for region in group.regions],
+    }
+
+
+def result_to_dict(result: SimilarityResult) -> dict[str, Any]:
+    """Serialize a whole similarity result as a versioned report document."""
+    return {
+        "schema_version": SCHEMA_VERSION,
+        "tool": "treepeat",
+        "summary": {
+            "files": result.total_files,
+            "regions": result.success_count,
+            "clone_classes": len(result.similar_groups),
+            "clone_instances": sum(group.size for group in result.similar_groups),
+        },
+        "clone_classes": [group_to_dict(group) for group in result.similar_groups],
+        "test_clone_classes": [group_to_dict(group) for group in result.test_groups],
+        "duplication": stats_to_dict(duplication_stats(result)),
+        "fragment_types": fragment_types_to_list(result),
+    }
+
+
+def format_as_json(result: SimilarityResult, *, pretty: bool = True) -> str:
+    """Format similarity detection results as a versioned JSON document."""
+    return json.dumps(result_to_dict(result), indent=2 if pretty else None)
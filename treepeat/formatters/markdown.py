@@ -0,0 +1,61 @@
+from treepeat.formatters.snippets import describe_region
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+
+# Number of clone classes listed in the summary table
+MAX_ROWS = 10
+
+LINK_FIELDS = ("path", "start_line", "end_line")
+
+
+def validate_link_template(template: str) -> None:
+    """Raise ValueError if a link template uses placeholders other than {path}, {start_line} and {end_line}."""
+    try:
+        template.format(path="", start_line=1, end_line=1)
+    except (KeyError, IndexError, ValueError) as e:
+        raise ValueError(f"Invalid link template '{template}': supported fields are {', '.join(LINK_FIELDS)}") from e
+
+
+def _escape(text: str) -> str:
+    """Escape characters that would break a markdown table cell."""
+    return text.replace("|", "\\|")
+
+
+def _group_lines(group: SimilarRegionGroup) -> int:
+    """Total lines duplicated by a clone class (every instance counted)."""
+    return sum(r.end_line - r.start_line + 1 for r in group.regions)
+
+
+def _instance(region: Region, link_template: str | None) -> str:
+    """Describe one clone instance, linked when a template is given."""
+    text = f"`{_escape(describe_region(region))}`"
+    if link_template is None:
+        return text
+    url = link_template.format(path=region.path.as_posix(), start_line=region.start_line, end_line=region.end_line)
+    return f"[{text}]({url})"
+
+
+def _row(group: SimilarRegionGroup, link_template: str | None) -> str:
+    """Render a clone class as a table row."""
+    instances = "<br>".join(_instance(region, link_template) for region in group.regions)
+    return f"| {group.fingerprint or '-'} | {group.similarity:.1%} | {_group_lines(group)} | {instances} |"
+
+
+def format_as_markdown(result: SimilarityResult, link_template: str | None = None) -> str:
+    """Format a compact markdown summary of the largest clone classes, e.g. for a pull request comment.
+
+    ``link_template`` turns instances into links, e.g.
+    ``https://github.com/org/repo/blob/main/{path}#L{start_line}-L{end_line}``.
+    """
+    groups = sorted(result.similar_groups, key=_group_lines, reverse=True)
+    duplicated = sum(_group_lines(group) for group in groups)
+    lines = [
+        "## treepeat duplication report",
+        "",
+        f"{len(groups)} clone class(es), {duplicated} duplicated line(s) across {result.total_files} file(s).",
+    ]
+    if groups:
+        lines += ["", "| Clone | Similarity | Lines | Instances |", "| --- | ---: | ---: | --- |"]
+        lines += [_row(group, link_template) for group in groups[:MAX_ROWS]]
+    if len(groups) > MAX_ROWS:
+        lines += ["", f"...and {len(groups) - MAX_ROWS} more clone class(es)."]
+    return "\n".join(lines) + "\n"
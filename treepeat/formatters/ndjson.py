@@ -0,0 +1,26 @@
+import json
+from typing import IO, Iterable
+
+from treepeat.formatters.json import group_to_dict
+from treepeat.models.similarity import SimilarRegionGroup
+from treepeat.pipeline.fingerprint import normalize_fingerprint
+
+
+def group_to_line(group: SimilarRegionGroup) -> str:
+    """Serialize one clone class as a single JSON line (same shape as a json report clone class)."""
+    return json.dumps(group_to_dict(group)) + "\n"
+
+
+class NdjsonWriter:
+    """Group callback that writes each clone class to a stream as soon as it is found."""
+
+    def __init__(self, stream: IO[str], excluded_fingerprints: Iterable[str] = ()) -> None:
+        self.stream = stream
+        self.excluded = {normalize_fingerprint(fp) for fp in excluded_fingerprints if fp.strip()}
+
+    def __call__(self, group: SimilarRegionGroup) -> None:
+        if group.fingerprint in self.excluded:
+            return
+        self.stream.write(group_to_line(group))
+        self.stream.flush()
+
@@ -1,3 +1,5 @@
+from typing import Any
+
 from sarif_pydantic import (  # type: ignore[import-untyped]
     ArtifactLocation,
     Level,
@@ -13,36 +15,118 @@ from sarif_pydantic import (  # type: ignore[import-untyped]
     ToolDriver,
 )
 
+from treepeat.formatters.snippets import describe_region
 from treepeat.models.similarity import SimilarityResult, SimilarRegionGroup
+from treepeat.ownership import owners_of
+
+
+GENERIC_RULE_ID = "similar-code"
+
+_REFACTOR_HELP = (
+    "Consider refactoring similar code blocks into shared "
+    "functions or modules to improve maintainability and reduce duplication."
+)
+_RULE_PROPERTIES = {
+    "tags": ["maintainability", "code-duplication", "refactoring"],
+    "precision": "high",
+}
+
+
+# (rule id, minimum lines of the largest region, default level, short description, full description)
+_SIZE_BUCKETS = [
+    (
+        "treepeat/clone-large",
+        50,
+        "error",
+        "Large duplicated code block",
+        "A code block of 50 or more lines is structurally similar to code elsewhere. Duplication "
+        "this large is expensive to keep in sync and is usually worth extracting into shared code.",
+    ),
+    (
+        "treepeat/clone-medium",
+        15,
+        "warning",
+        "Medium duplicated code block",
+        "A code block of 15 to 49 lines is structurally similar to code elsewhere. Consider "
+        "extracting the shared logic before the copies drift apart.",
+    ),
+    (
+        "treepeat/clone-small",
+        0,
+        "note",
+        "Small duplicated code block",
+        "A code block of fewer than 15 lines is structurally similar to code elsewhere. Small "
+        "clones are often acceptable, but repeated ones may hint at a missing helper.",
+    ),
+]
+
 
+def format_as_sarif(
+    result: SimilarityResult, *, pretty: bool = True, size_buckets: bool = False, report_suppressed: bool = False
+) -> str:
+    """Format similarity detection results as SARIF JSON.
 
-def format_as_sarif(result: SimilarityResult, *, pretty: bool = True) -> str:
-    """Format similarity detection results as SARIF JSON."""
-    sarif_log = _create_sarif_log(result)
+    With ``size_buckets``, each result maps to a rule per clone size (e.g.
+    ``treepeat/clone-large``) instead of the generic ``similar-code`` rule.
+    With ``report_suppressed``, groups hidden by ``treepeat:ignore`` comments are
+    also listed, carrying an ``inSource`` suppression.
+    """
+    sarif_log = _create_sarif_log(result, size_buckets, report_suppressed)
 
     indent = 2 if pretty else None
     json_output: str = sarif_log.model_dump_json(indent=indent, exclude_none=True)
     return json_output
 
 
-def _create_sarif_log(result: SimilarityResult) -> Sarif:
+def _create_sarif_log(result: SimilarityResult, size_buckets: bool = False, report_suppressed: bool = False) -> Sarif:
     """Create a SARIF log object from similarity results."""
     return Sarif(
         version="2.1.0",
         schema_uri="https://json.schemastore.org/sarif-2.1.0.json",
-        runs=[_create_run(result)],
+        runs=[_create_run(result, size_buckets, report_suppressed)],
     )
 
 
-def _create_run(result: SimilarityResult) -> Run:
+def _create_run(result: SimilarityResult, size_buckets: bool = False, report_suppressed: bool = False) -> Run:
     """Create a SARIF run object."""
+    reported = len(result.similar_groups)
+    groups = result.similar_groups + (result.suppressed_groups if report_suppressed else [])
+    rule_ids = [_rule_id_for_group(group, size_buckets) for group in groups]
     return Run(
-        tool=_create_tool(),
-        results=_create_results(result),
+        tool=_create_tool(_create_rules(rule_ids, size_buckets)),
+        results=[
+            _create_result_from_group(group, rule_id, suppressed=index >= reported)
+            for index, (group, rule_id) in enumerate(zip(groups, rule_ids, strict=True))
+        ],
     )
 
 
-def _create_tool() -> Tool:
+def _size_bucket_for_group(group: SimilarRegionGroup) -> str:
+    """Return the size bucket rule id for a group, based on its largest region."""
+    lines = max(region.end_line - region.start_line + 1 for region in group.regions)
+    for rule_id, min_lines, _, _, _ in _SIZE_BUCKETS:
+        if lines >= min_lines:
+            return rule_id
+    return _SIZE_BUCKETS[-1][0]
+
+
+def _rule_id_for_group(group: SimilarRegionGroup, size_buckets: bool) -> str:
+    """Return the rule id a group's result is reported under."""
+    return _size_bucket_for_group(group) if size_buckets else GENERIC_RULE_ID
+
+
+def _create_rules(rule_ids: list[str], size_buckets: bool) -> list[ReportingDescriptor]:
+    """Create the reporting descriptors, limited to rules with results when bucketing by size."""
+    if not size_buckets:
+        return [_create_generic_rule()]
+    return [
+        _create_size_bucket_rule(rule_id, level, short, full)
+        for rule_id, _, level, short, full in _SIZE_BUCKETS
+        if rule_id in rule_ids
+    ]
+
+
+def _create_tool(rules: list[ReportingDescriptor]) -> Tool:
     """Create the SARIF tool descriptor."""
     return Tool(
         driver=ToolDriver(
@@ -50,52 +134,62 @@ def _create_tool() -> Tool:
             informationUri="https://github.com/dsummersl/treepeat",
             version="0.0.1",
             semanticVersion="0.0.1",
-            rules=[
-                ReportingDescriptor(
-                    id="similar-code",
-                    name="SimilarCode",
-                    shortDescription=Message(text="Structurally similar code detected"),
-                    fullDescription=Message(
-                        text=(
-                            "This rule identifies code blocks that are structurally similar based on "
-                            "Abstract Syntax Tree (AST) analysis using MinHash and Locality-Sensitive "
-                            "Hashing (LSH). Similar code may indicate opportunities for refactoring or "
-                            "potential code duplication issues."
-                        )
-                    ),
-                    help=Message(
-                        text=(
-                            "Consider refactoring similar code blocks into shared "
-                            "functions or modules to improve maintainability and reduce duplication."
-                        )
-                    ),
-                    defaultConfiguration={"level": "warning"},
-                    properties={
-                        "tags": ["maintainability", "code-duplication", "refactoring"],
-                        "precision": "high",
-                    },
-                )
-            ],
+            rules=rules,
         )
     )
 
 
-def _create_result_from_group(group: SimilarRegionGroup) -> Result:
+def _create_generic_rule() -> ReportingDescriptor:
+    """Create the generic similar-code rule."""
+    return ReportingDescriptor(
+        id=GENERIC_RULE_ID,
+        name="SimilarCode",
+        shortDescription=Message(text="Structurally similar code detected"),
+        fullDescription=Message(
+            text=(
+                "This rule identifies code blocks that are structurally similar based on "
+                "Abstract Syntax Tree (AST) analysis using MinHash and Locality-Sensitive "
+                "Hashing (LSH). Similar code may indicate opportunities for refactoring or "
+                "potential code duplication issues."
+            )
+        ),
+        help=Message(text=_REFACTOR_HELP),
+        defaultConfiguration={"level": "warning"},
+        properties=_RULE_PROPERTIES,
+    )
+
+
+def _create_size_bucket_rule(rule_id: str, level: str, short: str, full: str) -> ReportingDescriptor:
+    """Create a rule describing clones of one size bucket, more severe for larger clones."""
+    return ReportingDescriptor(
+        id=rule_id,
+        name="".join(part.capitalize() for part in rule_id.split("/")[-1].split("-")),
+        shortDescription=Message(text=short),
+        fullDescription=Message(text=full),
+        help=Message(text=_REFACTOR_HELP),
+        defaultConfiguration={"level": level},
+        properties=_RULE_PROPERTIES,
+    )
+
+
+def _create_result_from_group(
+    group: SimilarRegionGroup, rule_id: str = GENERIC_RULE_ID, suppressed: bool = False
+) -> Result:
     """Create a SARIF result from a similarity group."""
     similarity_percent = group.similarity * 100
     level = _get_level(group.similarity)
 
     # Create message describing the group
     region_descriptions = [
-        f"{region.path}:{region.start_line}-{region.end_line} ({region.end_line - region.start_line + 1} lines)"
+        f"{describe_region(region)} ({region.end_line - region.start_line + 1} lines)"
         for region in group.regions
     ]
     message_text = f"Code similarity detected ({similarity_percent:.1f}% similar, {group.size} regions). " + ". ".join(
         region_descriptions
     )
 
-    # Use first region as primary location
-    primary_region = group.regions[0]
+    # Use the representative as primary location
+    primary_region = group.representative
 
     # All other regions are related locations
     related_locations = [
@@ -118,7 +212,7 @@ def _create_result_from_group(group: SimilarRegionGroup) -> Result:
     ]
 
     return Result(
-        ruleId="similar-code",
+        ruleId=rule_id,
         level=level,
         message=Message(text=message_text),
         locations=[
@@ -137,28 +231,41 @@ def _create_result_from_group(group: SimilarRegionGroup) -> Result:
             )
         ],
         relatedLocations=related_locations if related_locations else None,
+        partialFingerprints={"treepeat/v1": group.fingerprint} if group.fingerprint else None,
+        suppressions=[{"kind": "inSource", "justification": "treepeat:ignore comment"}] if suppressed else None,
         properties={
+            "fingerprint": group.fingerprint,
             "similarity": group.similarity,
             "similarityPercent": similarity_percent,
             "groupSize": group.size,
-            "regions": [
-                {
-                    "path": str(region.path),
-                    "startLine": region.start_line,
-                    "endLine": region.end_line,
-                    "lines": region.end_line - region.start_line + 1,
-                    "type": region.region_type,
-                    "name": region.region_name,
-                }
-                for region in group.regions
-            ],
+            "regions": _region_properties(group),
+            **_owner_properties(group),
         },
     )
 
 
-def _create_results(similarity_result: SimilarityResult) -> list[Result]:
-    """Create SARIF result objects from similar region groups."""
-    return [_create_result_from_group(group) for group in similarity_result.similar_groups]
+def _region_properties(group: SimilarRegionGroup) -> list[dict[str, Any]]:
+    """Describe the instances of a result, each with its owner when detect --owners looked it up."""
+    regions = []
+    for region in group.regions:
+        properties: dict[str, Any] = {
+            "path": str(region.path),
+            "startLine": region.start_line,
+            "endLine": region.end_line,
+            "lines": region.end_line - region.start_line + 1,
+            "type": region.region_type,
+            "name": region.region_name,
+        }
+        if region.owner is not None:
+            properties["owner"] = region.owner.model_dump(exclude_none=True)
+        regions.append(properties)
+    return regions
+
+
+def _owner_properties(group: SimilarRegionGroup) -> dict[str, list[str]]:
+    """The code owners of any instance of a result, for routing it (nothing when none are known)."""
+    owners = owners_of(group.regions)
+    return {"owners": owners} if owners else {}
 
 
 def _get_level(similarity: float) -> Level:
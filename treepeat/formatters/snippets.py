@@ -0,0 +1,24 @@
+from treepeat.models.similarity import Region
+from treepeat.pipeline.notebook import describe_notebook_location, is_notebook, read_notebook_lines
+
+
+def describe_region(region: Region) -> str:
+    """Describe a region's location, mapping notebook lines back to their cell."""
+    notebook_location = describe_notebook_location(region)
+    if notebook_location is not None:
+        return notebook_location
+    return f"{region.path}:{region.start_line}-{region.end_line}"
+
+
+def read_region_lines(region: Region) -> list[str]:
+    """Read a region's source lines (without line endings), or [] if unreadable."""
+    try:
+        if is_notebook(region.path):
+            lines = read_notebook_lines(region.path)
+        else:
+            with open(region.path, "r", encoding="utf-8") as f:
+                lines = f.readlines()
+        # Extract lines for this region (1-indexed to 0-indexed)
+        return [line.rstrip("\n\r") for line in lines[region.start_line - 1 : region.end_line]]
+    except Exception:
+        return []
@@ -0,0 +1,58 @@
+import json
+from typing import Any
+
+from treepeat.models.similarity import Region, SimilarityResult, SimilarRegionGroup
+
+ENGINE_ID = "treepeat"
+RULE_ID = "treepeat/clone"
+
+_RULE: dict[str, Any] = {
+    "id": RULE_ID,
+    "name": "Similar code block",
+    "description": "Similar or duplicate code found by treepeat. Consider extracting the shared logic.",
+    "engineId": ENGINE_ID,
+    "cleanCodeAttribute": "DISTINCT",
+    "type": "CODE_SMELL",
+    "severity": "MAJOR",
+    "impacts": [{"softwareQuality": "MAINTAINABILITY", "severity": "MEDIUM"}],
+}
+
+
+def _impact_severity(similarity: float) -> str:
+    """Map a similarity score onto a Sonar impact severity."""
+    if similarity >= 0.95:
+        return "HIGH"
+    if similarity >= 0.85:
+        return "MEDIUM"
+    return "LOW"
+
+
+def _location(region: Region, message: str) -> dict[str, Any]:
+    """Build a Sonar issue location for a region."""
+    return {
+        "message": message,
+        "filePath": str(region.path),
+        "textRange": {"startLine": region.start_line, "endLine": region.end_line},
+    }
+
+
+def _issue(group: SimilarRegionGroup) -> dict[str, Any]:
+    """Report a clone class as one issue on its first instance, with the other copies as secondary locations."""
+    primary, *others = group.regions
+    message = f"Similar code ({group.similarity:.1%} similar) found in {len(others)} other location(s)"
+    if group.fingerprint:
+        # Sonar has no field for it; the message keeps the id needed for --exclude-group and baselines
+        message += f" [{group.fingerprint}]"
+    return {
+        "ruleId": RULE_ID,
+        "effortMinutes": 10,
+        "impacts": [{"softwareQuality": "MAINTAINABILITY", "severity": _impact_severity(group.similarity)}],
+        "primaryLocation": _location(primary, message),
+        "secondaryLocations": [_location(region, "Similar code") for region in others],
+    }
+
+
+def format_as_sonarqube(result: SimilarityResult) -> str:
+    """Format similarity detection results in SonarQube's generic external issue import format."""
+    issues = [_issue(group) for group in result.similar_groups]
+    return json.dumps({"rules": [_RULE] if issues else [], "issues": issues}, indent=2)
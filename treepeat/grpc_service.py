@@ -0,0 +1,146 @@
+"""gRPC API over the warm clone index of ``treepeat serve``, described by ``proto/treepeat.proto``.
+
+The service needs the optional grpc dependencies (``pip install 'treepeat[grpc]'``);
+its messages are compiled from the published .proto when the server starts.
+"""
+
+import logging
+from collections.abc import Iterator
+from concurrent.futures import ThreadPoolExecutor
+from http import HTTPStatus
+from typing import Any, NoReturn
+
+from treepeat.server import CloneIndex, RootIndex, ServerError
+
+logger = logging.getLogger(__name__)
+
+SERVICE_NAME = "treepeat.v1.CloneService"
+
+# The .proto, relative to the directory holding the treepeat package
+PROTO_PATH = "treepeat/proto/treepeat.proto"
+
+# Fields of a json report clone instance carried by a CloneInstance message
+_INSTANCE_FIELDS = ("path", "language", "region_type", "region_name", "start_line", "end_line")
+
+
+class GrpcUnavailableError(Exception):
+    """The optional grpc dependencies are not installed."""
+
+
+def _load_messages() -> Any:
+    """The message classes of the .proto, compiled at runtime."""
+    try:
+        import grpc
+    except ImportError as e:
+        raise GrpcUnavailableError("the gRPC API needs the grpc extra: pip install 'treepeat[grpc]'") from e
+    return grpc.protos(PROTO_PATH)
+
+
+def _clone_message(messages: Any, clone: dict[str, Any]) -> Any:
+    """A CloneClass message of a json report clone class."""
+    return messages.CloneClass(
+        fingerprint=clone["fingerprint"],
+        similarity=clone["similarity"],
+        instances=[
+            messages.CloneInstance(**{field: instance[field] for field in _INSTANCE_FIELDS if field in instance})
+            for instance in clone["instances"]
+        ],
+    )
+
+
+class CloneServicer:
+    """Answers the CloneService calls from a clone index."""
+
+    def __init__(self, index: CloneIndex, messages: Any):
+        self.index = index
+        self.messages = messages
+
+    def _abort(self, context: Any, error: ServerError) -> NoReturn:
+        """End a call with the status matching an index error."""
+        import grpc
+
+        code = grpc.StatusCode.NOT_FOUND if error.status == HTTPStatus.NOT_FOUND else grpc.StatusCode.INVALID_ARGUMENT
+        context.abort(code, str(error))
+        raise error  # not reached: abort raises
+
+    def _progress(self, root: RootIndex, done: int, total: int) -> Any:
+        summary = root.summary()
+        return self.messages.ScanEvent(
+            progress=self.messages.ScanProgress(
+                root=summary["root"],
+                clone_classes=summary["clone_classes"],
+                scanned_at=summary["scanned_at"],
+                roots_done=done,
+                roots_total=total,
+            )
+        )
+
+    def _scan_events(self, request: Any) -> Iterator[Any]:
+        rescans = self.index.rescans(request.root or None, list(request.paths))
+        total = 1 if request.root else len(self.index.roots)
+        for done, (root, found) in enumerate(rescans, 1):
+            for clone in found:
+                yield self.messages.ScanEvent(clone_class=_clone_message(self.messages, clone))
+            yield self._progress(root, done, total)
+
+    def scan(self, request: Any, context: Any) -> Iterator[Any]:
+        try:
+            yield from self._scan_events(request)
+        except ServerError as e:
+            self._abort(context, e)
+
+    def clones_of(self, request: Any, context: Any) -> Any:
+        line = request.line if request.HasField("line") else None
+        try:
+            clones = self.index.clones_of(request.file, line)
+        except ServerError as e:
+            self._abort(context, e)
+        return self.messages.CloneClasses(clone_classes=[_clone_message(self.messages, clone) for clone in clones])
+
+    def report(self, request: Any, context: Any) -> Iterator[Any]:
+        try:
+            clones = self.index.report(request.root)["clone_classes"]
+        except ServerError as e:
+            self._abort(context, e)
+        for clone in clones:
+            yield _clone_message(self.messages, clone)
+
+
+def _handlers(servicer: CloneServicer) -> dict[str, Any]:
+    """The method handlers of the service."""
+    import grpc
+
+    messages = servicer.messages
+    return {
+        "Scan": grpc.unary_stream_rpc_method_handler(
+            servicer.scan,
+            request_deserializer=messages.ScanRequest.FromString,
+            response_serializer=messages.ScanEvent.SerializeToString,
+        ),
+        "ClonesOf": grpc.unary_unary_rpc_method_handler(
+            servicer.clones_of,
+            request_deserializer=messages.ClonesOfRequest.FromString,
+            response_serializer=messages.CloneClasses.SerializeToString,
+        ),
+        "Report": grpc.unary_stream_rpc_method_handler(
+            servicer.report,
+            request_deserializer=messages.ReportRequest.FromString,
+            response_serializer=messages.CloneClass.SerializeToString,
+        ),
+    }
+
+
+def start_grpc_server(index: CloneIndex, host: str, port: int, workers: int = 4) -> tuple[Any, int]:
+    """Start answering CloneService calls from ``index`` on host:port.
+
+    Returns the running grpc.Server and the port it listens on (chosen by the system when ``port`` is 0).
+    """
+    servicer = CloneServicer(index, _load_messages())
+    import grpc
+
+    server = grpc.server(ThreadPoolExecutor(max_workers=workers))
+    server.add_generic_rpc_handlers((grpc.method_handlers_generic_handler(SERVICE_NAME, _handlers(servicer)),))
+    bound = server.add_insecure_port(f"{host}:{port}")
+    server.start()
+    logger.info("Serving gRPC on %s:%d", host, bound)
+    return server, bound
@@ -0,0 +1,133 @@
+"""A history of detect runs in a SQLite database, to follow how duplication evolves commit after commit.
+
+Each run keeps the figures of its json report: the overall and per-directory
+duplication, the clone counts, the commit checked out and when it ran.
+Directories are stored relative to the scanned path, so that runs of different
+checkouts of a repository line up.
+"""
+
+import sqlite3
+from dataclasses import dataclass
+from datetime import datetime
+from pathlib import Path
+from typing import Any
+
+DEFAULT_HISTORY_PATH = Path(".treepeat-history.sqlite")
+
+# The directory of the runs' totals
+ROOT_DIRECTORY = "."
+
+_SCHEMA = """
+CREATE TABLE IF NOT EXISTS runs (
+    id INTEGER PRIMARY KEY,
+    recorded_at TEXT NOT NULL,
+    commit_sha TEXT,
+    path TEXT NOT NULL,
+    clone_classes INTEGER NOT NULL,
+    clone_instances INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS directories (
+    run_id INTEGER NOT NULL REFERENCES runs (id),
+    path TEXT NOT NULL,
+    lines INTEGER NOT NULL,
+    duplicated_lines INTEGER NOT NULL,
+    PRIMARY KEY (run_id, path)
+);
+"""
+
+
+class HistoryError(Exception):
+    """Raised when a history database cannot be read or written."""
+
+
+@dataclass(frozen=True)
+class RecordedRun:
+    """The figures of one recorded run, for the whole path or one of its directories."""
+
+    recorded_at: datetime
+    commit: str | None
+    lines: int
+    duplicated_lines: int
+    clone_classes: int
+    clone_instances: int
+
+    @property
+    def percent(self) -> float:
+        return 100.0 * self.duplicated_lines / self.lines if self.lines else 0.0
+
+
+def _connect(path: Path) -> sqlite3.Connection:
+    """Open a history database, creating it (and its tables) if needed."""
+    try:
+        db = sqlite3.connect(path)
+    except sqlite3.Error as e:
+        raise HistoryError(f"cannot open {path}: {e}") from e
+    try:
+        db.executescript(_SCHEMA)
+    except sqlite3.Error as e:
+        db.close()
+        raise HistoryError(f"{path} is not a treepeat history database: {e}") from e
+    return db
+
+
+def _relative_directory(directory: str, root: Path) -> str:
+    """A directory of a report, relative to the scanned path (as is when it is not below it)."""
+    try:
+        return Path(directory).resolve().relative_to(root.resolve()).as_posix()
+    except ValueError:
+        return directory
+
+
+def _directory_rows(duplication: dict[str, Any], root: Path) -> dict[str, tuple[int, int]]:
+    """The lines and duplicated lines of the scanned path and of each directory of a report's duplication."""
+    rows = {ROOT_DIRECTORY: (duplication["lines"], duplication["duplicated_lines"])}
+    for row in duplication["by_directory"]:
+        rows.setdefault(_relative_directory(row["path"], root), (row["lines"], row["duplicated_lines"]))
+    return rows
+
+
+def record_run(db_path: Path, report: dict[str, Any], path: Path, commit: str | None, recorded_at: datetime) -> int:
+    """Append the figures of the json report of a run of ``path`` to a history; return the id of the run."""
+    db = _connect(db_path)
+    try:
+        with db:
+            run = db.execute(
+                "INSERT INTO runs (recorded_at, commit_sha, path, clone_classes, clone_instances) "
+                "VALUES (?, ?, ?, ?, ?)",
+                (
+                    recorded_at.isoformat(),
+                    commit,
+                    str(path),
+                    report["summary"]["clone_classes"],
+                    report["summary"]["clone_instances"],
+                ),
+            )
+            run_id = int(run.lastrowid or 0)
+            db.executemany(
+                "INSERT INTO directories VALUES (?, ?, ?, ?)",
+                [(run_id, name, *counts) for name, counts in _directory_rows(report["duplication"], path).items()],
+            )
+    except sqlite3.Error as e:
+        raise HistoryError(f"cannot record the run in {db_path}: {e}") from e
+    finally:
+        db.close()
+    return run_id
+
+
+def recorded_runs(db_path: Path, directory: str = ROOT_DIRECTORY) -> list[RecordedRun]:
+    """The runs of a history that measured ``directory`` (relative to the scanned path), oldest first."""
+    if not db_path.exists():
+        raise HistoryError(f"{db_path} does not exist (record runs with treepeat record)")
+    db = _connect(db_path)
+    try:
+        rows = db.execute(
+            "SELECT recorded_at, commit_sha, lines, duplicated_lines, clone_classes, clone_instances "
+            "FROM runs JOIN directories ON directories.run_id = runs.id "
+            "WHERE directories.path = ? ORDER BY recorded_at, runs.id",
+            (Path(directory).as_posix(),),
+        ).fetchall()
+    except sqlite3.Error as e:
+        raise HistoryError(f"cannot read {db_path}: {e}") from e
+    finally:
+        db.close()
+    return [RecordedRun(datetime.fromisoformat(row[0]), *row[1:]) for row in rows]
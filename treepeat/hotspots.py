@@ -0,0 +1,66 @@
+"""Hotspots: the clone classes whose copies keep being changed, the riskiest to consolidate first.
+
+Churn is counted per file, as the number of commits that touched it: a copy
+scores its lines times the commits of its file, and a clone class the sum of
+its copies, so a large clone spread over busy files ranks first.
+"""
+
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Any
+
+
+@dataclass(frozen=True)
+class HotCopy:
+    """A clone instance (as serialized in a json report) with the commits that touched its file."""
+
+    instance: dict[str, Any]
+    commits: int
+
+    @property
+    def score(self) -> int:
+        return int(self.instance["lines"]) * self.commits
+
+
+@dataclass(frozen=True)
+class Hotspot:
+    """A clone class (as serialized in a json report) and the churn of its copies, the most changed first."""
+
+    clone: dict[str, Any]
+    copies: list[HotCopy]
+
+    @property
+    def commits(self) -> int:
+        """Commits that touched the files of the copies, each file counted once."""
+        return sum({copy.instance["path"]: copy.commits for copy in self.copies}.values())
+
+    @property
+    def score(self) -> int:
+        return sum(copy.score for copy in self.copies)
+
+
+def _hotspot(clone: dict[str, Any], churn: dict[Path, int]) -> Hotspot:
+    copies = [HotCopy(instance, churn.get(Path(instance["path"]).resolve(), 0)) for instance in clone["instances"]]
+    return Hotspot(clone, sorted(copies, key=lambda copy: -copy.score))
+
+
+def rank_hotspots(clone_classes: list[dict[str, Any]], churn: dict[Path, int]) -> list[Hotspot]:
+    """The clone classes of a json report ranked by the churn of their copies (commits by absolute path).
+
+    Clone classes whose files were never changed are left out.
+    """
+    hotspots = [_hotspot(clone, churn) for clone in clone_classes]
+    return sorted(
+        (hotspot for hotspot in hotspots if hotspot.score),
+        key=lambda hotspot: (-hotspot.score, hotspot.clone["fingerprint"]),
+    )
+
+
+def hotspot_to_dict(hotspot: Hotspot) -> dict[str, Any]:
+    """Serialize a hotspot: its clone class, with the commits and score of it and of each copy."""
+    return {
+        **hotspot.clone,
+        "commits": hotspot.commits,
+        "score": hotspot.score,
+        "instances": [{**copy.instance, "commits": copy.commits, "score": copy.score} for copy in hotspot.copies],
+    }
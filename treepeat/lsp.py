@@ -0,0 +1,220 @@
+"""Language server: publishes the clones of a workspace as diagnostics, over stdio.
+
+Implements the part of the Language Server Protocol an editor needs to show
+clones: each copy of a clone class gets a diagnostic whose related
+information points at the other copies, and a code action per other copy
+asks the editor to open it (``window/showDocument``). The workspace is
+rescanned when the server is initialized and whenever a file is saved.
+"""
+
+import json
+import logging
+from collections.abc import Callable
+from pathlib import Path
+from typing import Any, BinaryIO
+from urllib.parse import unquote, urlparse
+
+logger = logging.getLogger(__name__)
+
+# Command of the code actions that jump to another copy of a clone
+SHOW_COPY_COMMAND = "treepeat.showCopy"
+
+# LSP constants
+_SEVERITY_WARNING = 2
+_METHOD_NOT_FOUND = -32601
+_MESSAGE_TYPE_ERROR = 1
+
+# Finds the clones under a root, as a json report
+Scan = Callable[[Path], dict[str, Any]]
+
+
+def _read_headers(stream: BinaryIO) -> dict[str, str] | None:
+    """The headers of the next message, by lowercase name, or None at the end of the stream."""
+    headers: dict[str, str] = {}
+    while line := stream.readline():
+        if not line.strip():
+            return headers
+        name, _, value = line.decode("ascii").partition(":")
+        headers[name.strip().lower()] = value.strip()
+    return None
+
+
+def read_message(stream: BinaryIO) -> dict[str, Any] | None:
+    """Read one message framed by a Content-Length header, or None at the end of the stream."""
+    headers = _read_headers(stream)
+    if headers is None:
+        return None
+    if "content-length" not in headers:
+        raise ValueError("message without a Content-Length header")
+    message: dict[str, Any] = json.loads(stream.read(int(headers["content-length"])).decode("utf-8"))
+    return message
+
+
+def write_message(stream: BinaryIO, message: dict[str, Any]) -> None:
+    """Write one message framed by a Content-Length header."""
+    body = json.dumps(message).encode("utf-8")
+    stream.write(f"Content-Length: {len(body)}\r\n\r\n".encode("ascii") + body)
+    stream.flush()
+
+
+def uri_to_path(uri: str) -> Path:
+    """The path of a file:// URI."""
+    return Path(unquote(urlparse(uri).path))
+
+
+def _range(instance: dict[str, Any]) -> dict[str, Any]:
+    """The whole lines of a clone instance (LSP lines are 0-based, the end is exclusive)."""
+    return {
+        "start": {"line": instance["start_line"] - 1, "character": 0},
+        "end": {"line": instance["end_line"], "character": 0},
+    }
+
+
+def _location(instance: dict[str, Any]) -> dict[str, Any]:
+    return {"uri": Path(instance["path"]).resolve().as_uri(), "range": _range(instance)}
+
+
+def _describe(instance: dict[str, Any]) -> str:
+    return f"{Path(instance['path']).name}:{instance['start_line']}-{instance['end_line']} ({instance['region_name']})"
+
+
+def _diagnostic(clone: dict[str, Any], instance: dict[str, Any]) -> dict[str, Any]:
+    """The diagnostic of one copy of a clone class, related to the other copies."""
+    others = [other for other in clone["instances"] if other is not instance]
+    return {
+        "range": _range(instance),
+        "severity": _SEVERITY_WARNING,
+        "source": "treepeat",
+        "code": clone["fingerprint"],
+        "message": f"Duplicated in {len(others)} other place(s), {clone['similarity']:.0%} similar",
+        "relatedInformation": [{"location": _location(other), "message": _describe(other)} for other in others],
+    }
+
+
+def report_diagnostics(report: dict[str, Any]) -> dict[str, list[dict[str, Any]]]:
+    """Map the URI of each file holding a copy of a clone class of a json report to its diagnostics."""
+    diagnostics: dict[str, list[dict[str, Any]]] = {}
+    for clone in report["clone_classes"]:
+        for instance in clone["instances"]:
+            uri = Path(instance["path"]).resolve().as_uri()
+            diagnostics.setdefault(uri, []).append(_diagnostic(clone, instance))
+    return diagnostics
+
+
+def code_actions(diagnostics: list[dict[str, Any]]) -> list[dict[str, Any]]:
+    """One action per other copy of the clones the diagnostics of a code action request point at."""
+    actions = []
+    for diagnostic in diagnostics:
+        if diagnostic.get("source") != "treepeat":
+            continue
+        for related in diagnostic.get("relatedInformation", []):
+            title = f"Go to copy {related['message']}"
+            command = {"title": title, "command": SHOW_COPY_COMMAND, "arguments": [related["location"]]}
+            actions.append({"title": title, "diagnostics": [diagnostic], "command": command})
+    return actions
+
+
+def _root_uri(params: dict[str, Any]) -> str | None:
+    folders = params.get("workspaceFolders") or []
+    return params.get("rootUri") or (folders[0]["uri"] if folders else None)
+
+
+def _root(params: dict[str, Any]) -> Path:
+    """The workspace root an initialize request names (the current directory if none)."""
+    uri = _root_uri(params)
+    if uri:
+        return uri_to_path(uri)
+    return Path(params.get("rootPath") or ".")
+
+
+class LanguageServer:
+    """State of a session: the workspace root and the files diagnostics were last published for."""
+
+    def __init__(self, output: BinaryIO, scan: Scan):
+        self.output = output
+        self.scan = scan
+        self.root = Path(".")
+        self.published: set[str] = set()
+        self.running = True
+        self._next_id = 0
+
+    def _notify(self, method: str, params: dict[str, Any]) -> None:
+        write_message(self.output, {"jsonrpc": "2.0", "method": method, "params": params})
+
+    def _request(self, method: str, params: dict[str, Any]) -> None:
+        self._next_id += 1
+        message = {"jsonrpc": "2.0", "id": f"treepeat-{self._next_id}", "method": method, "params": params}
+        write_message(self.output, message)
+
+    def publish(self) -> None:
+        """Rescan the workspace and publish its diagnostics, clearing those of files without clones anymore."""
+        try:
+            diagnostics = report_diagnostics(self.scan(self.root))
+        except Exception as e:
+            logger.exception("Scan of %s failed", self.root)
+            self._notify("window/showMessage", {"type": _MESSAGE_TYPE_ERROR, "message": f"treepeat: {e}"})
+            return
+        for uri in sorted(self.published - diagnostics.keys()):
+            self._notify("textDocument/publishDiagnostics", {"uri": uri, "diagnostics": []})
+        for uri, file_diagnostics in sorted(diagnostics.items()):
+            self._notify("textDocument/publishDiagnostics", {"uri": uri, "diagnostics": file_diagnostics})
+        self.published = set(diagnostics)
+
+    def initialize(self, params: dict[str, Any]) -> dict[str, Any]:
+        self.root = _root(params)
+        return {
+            "capabilities": {
+                "textDocumentSync": {"openClose": True, "change": 0, "save": True},
+                "codeActionProvider": True,
+                "executeCommandProvider": {"commands": [SHOW_COPY_COMMAND]},
+            },
+            "serverInfo": {"name": "treepeat"},
+        }
+
+    def execute_command(self, params: dict[str, Any]) -> None:
+        """Ask the editor to open the copy a code action points at."""
+        if params.get("command") == SHOW_COPY_COMMAND and params.get("arguments"):
+            location = params["arguments"][0]
+            show = {"uri": location["uri"], "takeFocus": True, "selection": location["range"]}
+            self._request("window/showDocument", show)
+
+    def _handlers(self) -> dict[str, Callable[[dict[str, Any]], Any]]:
+        """The requests the server answers."""
+        return {
+            "initialize": self.initialize,
+            "textDocument/codeAction": lambda params: code_actions(params["context"]["diagnostics"]),
+            "workspace/executeCommand": self.execute_command,
+            "shutdown": lambda params: None,
+        }
+
+    def _handle_notification(self, method: str) -> None:
+        if method in ("initialized", "textDocument/didSave"):
+            self.publish()
+        elif method == "exit":
+            self.running = False
+
+    def handle(self, message: dict[str, Any]) -> None:
+        """Answer a request, act on a notification, and ignore the responses of the editor."""
+        method = message.get("method")
+        if method is None:
+            return
+        if "id" not in message:
+            self._handle_notification(method)
+            return
+        handler = self._handlers().get(method)
+        response: dict[str, Any]
+        if handler is None:
+            response = {"error": {"code": _METHOD_NOT_FOUND, "message": f"Method not found: {method}"}}
+        else:
+            response = {"result": handler(message.get("params") or {})}
+        write_message(self.output, {"jsonrpc": "2.0", "id": message["id"], **response})
+
+
+def serve(input_stream: BinaryIO, output_stream: BinaryIO, scan: Scan) -> None:
+    """Run a session until the editor sends ``exit`` or closes the input."""
+    server = LanguageServer(output_stream, scan)
+    while server.running:
+        message = read_message(input_stream)
+        if message is None:
+            return
+        server.handle(message)
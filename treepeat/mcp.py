@@ -0,0 +1,259 @@
+"""Model Context Protocol server: lets AI coding assistants look up clones before writing more of them.
+
+Speaks MCP over stdio (one JSON-RPC message per line) and offers three tools:
+``find_clones_of`` (the copies elsewhere of the code in a file, or in some of
+its lines), ``duplication_summary`` (how much of a directory is duplicated,
+and its largest clone classes) and ``suggest_consolidation`` (a function the
+copies of a clone class could be replaced with).
+"""
+
+import json
+import sys
+from collections.abc import Callable
+from pathlib import Path
+from typing import Any, TextIO
+
+from treepeat.explain import CloneNotFoundError, find_clone_class
+from treepeat.formatters.json import region_from_dict
+from treepeat.formatters.snippets import read_region_lines
+from treepeat.refactor import suggest_extraction, suggestion_to_dict
+from treepeat.watch import Scan
+
+PROTOCOL_VERSION = "2024-11-05"
+
+# Largest clone classes a duplication summary lists
+SUMMARY_CLONES = 10
+
+_METHOD_NOT_FOUND = -32601
+_INVALID_PARAMS = -32602
+
+TOOLS = [
+    {
+        "name": "find_clones_of",
+        "description": (
+            "Find code elsewhere in the project that duplicates the code of a file, or of a range of its lines. "
+            "Use it before writing or copying code, to reuse what already exists."
+        ),
+        "inputSchema": {
+            "type": "object",
+            "properties": {
+                "file": {"type": "string", "description": "File to look up, relative to the project root"},
+                "start_line": {"type": "integer", "minimum": 1, "description": "First line of the range (1-based)"},
+                "end_line": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "description": "Last line of the range (default: the end of the file)",
+                },
+            },
+            "required": ["file"],
+        },
+    },
+    {
+        "name": "duplication_summary",
+        "description": "Summarize the duplicated code under a directory: counts, and its largest clone classes.",
+        "inputSchema": {
+            "type": "object",
+            "properties": {
+                "path": {"type": "string", "description": "Directory to summarize, relative to the project root"}
+            },
+        },
+    },
+    {
+        "name": "suggest_consolidation",
+        "description": (
+            "Suggest how to consolidate the copies of a clone class into one function: a skeleton of the code "
+            "with placeholders for what differs, the value of each placeholder in each copy, and where to put it."
+        ),
+        "inputSchema": {
+            "type": "object",
+            "properties": {
+                "clone_id": {
+                    "type": "string",
+                    "description": "Fingerprint of the clone class (or a unique prefix), as the other tools return",
+                }
+            },
+            "required": ["clone_id"],
+        },
+    },
+]
+
+
+class ToolError(Exception):
+    """A tool call that cannot be answered, reported to the assistant as a failed tool result."""
+
+
+class UnknownToolError(Exception):
+    """A call of a tool the server does not offer."""
+
+
+def _line_argument(arguments: dict[str, Any], name: str, default: int) -> int:
+    value = arguments.get(name, default)
+    if not isinstance(value, int) or value < 1:
+        raise ToolError(f"{name} must be a line number")
+    return value
+
+
+def _relative(name: str, root: Path) -> str:
+    """A path relative to the project root (absolute outside of it)."""
+    path = Path(name).resolve()
+    return path.relative_to(root).as_posix() if path.is_relative_to(root) else str(path)
+
+
+def _copy(instance: dict[str, Any], root: Path) -> dict[str, Any]:
+    """A clone instance, with its path relative to the project root."""
+    return {
+        "path": _relative(instance["path"], root),
+        "region_name": instance.get("region_name"),
+        "start_line": instance["start_line"],
+        "end_line": instance["end_line"],
+    }
+
+
+def _overlaps(instance: dict[str, Any], path: Path, start: int, end: int) -> bool:
+    return Path(instance["path"]).resolve() == path and instance["start_line"] <= end and start <= instance["end_line"]
+
+
+def _clone_of(clone: dict[str, Any], root: Path, path: Path, start: int, end: int) -> dict[str, Any] | None:
+    """A clone class with a copy overlapping lines start-end of a file, and its other copies (None if no copy is)."""
+    mine = [i for i in clone["instances"] if _overlaps(i, path, start, end)]
+    if not mine:
+        return None
+    return {
+        "fingerprint": clone["fingerprint"],
+        "similarity": clone["similarity"],
+        "copy": _copy(mine[0], root),
+        "elsewhere": [_copy(i, root) for i in clone["instances"] if not _overlaps(i, path, start, end)],
+    }
+
+
+def clones_of(report: dict[str, Any], root: Path, path: Path, start: int, end: int) -> list[dict[str, Any]]:
+    """The clone classes of a report with a copy overlapping lines start-end of a file, and their other copies."""
+    found = (_clone_of(clone, root, path, start, end) for clone in report["clone_classes"])
+    return [clone for clone in found if clone is not None]
+
+
+def _clone_lines(clone: dict[str, Any]) -> int:
+    return sum(i["end_line"] - i["start_line"] + 1 for i in clone["instances"])
+
+
+def summarize(report: dict[str, Any], root: Path) -> dict[str, Any]:
+    """Duplication counts of a report, and its largest clone classes (by duplicated lines)."""
+    clones = sorted(report["clone_classes"], key=lambda clone: (-_clone_lines(clone), clone["fingerprint"]))
+    return {
+        **report.get("summary", {}),
+        "duplicated_lines": sum(_clone_lines(clone) for clone in clones),
+        "largest_clone_classes": [
+            {
+                "fingerprint": clone["fingerprint"],
+                "similarity": clone["similarity"],
+                "lines": _clone_lines(clone),
+                "copies": [_copy(i, root) for i in clone["instances"]],
+            }
+            for clone in clones[:SUMMARY_CLONES]
+        ],
+    }
+
+
+def consolidation(clone: dict[str, Any], root: Path) -> dict[str, Any]:
+    """A suggested function replacing the copies of a clone class, with the copies it would replace."""
+    sources = [read_region_lines(region_from_dict(instance)) for instance in clone["instances"]]
+    suggestion = suggest_extraction(clone, sources)
+    if suggestion is None:
+        raise ToolError(f"{clone['fingerprint']} spans languages or cannot be read: no suggestion")
+    return {
+        "fingerprint": clone["fingerprint"],
+        **suggestion_to_dict(suggestion),
+        "target": _relative(suggestion.target, root),
+        "copies": [_copy(instance, root) for instance in clone["instances"]],
+    }
+
+
+class McpServer:
+    """A session: answers the requests of one assistant about the project under ``root``."""
+
+    def __init__(self, root: Path, output: TextIO, scan: Scan):
+        self.root = root.resolve()
+        self.output = output
+        self.scan = scan
+
+    def _within(self, relative: str) -> Path:
+        """A path of a tool argument, which must lie inside the project."""
+        path = (self.root / relative).resolve()
+        if not path.is_relative_to(self.root):
+            raise ToolError(f"{relative} is outside the project")
+        if not path.exists():
+            raise ToolError(f"{relative} does not exist")
+        return path
+
+    def find_clones_of(self, arguments: dict[str, Any]) -> Any:
+        path = self._within(str(arguments.get("file", "")))
+        if not path.is_file():
+            raise ToolError(f"{arguments['file']} is not a file")
+        start, end = _line_argument(arguments, "start_line", 1), _line_argument(arguments, "end_line", sys.maxsize)
+        return {"clones": clones_of(self.scan(self.root, {path}), self.root, path, start, end)}
+
+    def duplication_summary(self, arguments: dict[str, Any]) -> Any:
+        path = self._within(str(arguments.get("path", ".")))
+        return summarize(self.scan(path, None), self.root)
+
+    def suggest_consolidation(self, arguments: dict[str, Any]) -> Any:
+        try:
+            clone = find_clone_class(self.scan(self.root, None)["clone_classes"], str(arguments.get("clone_id", "")))
+        except CloneNotFoundError as e:
+            raise ToolError(str(e)) from e
+        return consolidation(clone, self.root)
+
+    def call_tool(self, params: dict[str, Any]) -> dict[str, Any]:
+        """Run a tool, failures included in its result for the assistant to read."""
+        tools: dict[str, Callable[[dict[str, Any]], Any]] = {
+            "find_clones_of": self.find_clones_of,
+            "duplication_summary": self.duplication_summary,
+            "suggest_consolidation": self.suggest_consolidation,
+        }
+        tool = tools.get(params.get("name", ""))
+        if tool is None:
+            raise UnknownToolError(f"Unknown tool: {params.get('name')}")
+        try:
+            result = tool(params.get("arguments") or {})
+        except ToolError as e:
+            return {"content": [{"type": "text", "text": str(e)}], "isError": True}
+        return {"content": [{"type": "text", "text": json.dumps(result, indent=2)}], "isError": False}
+
+    def _handlers(self) -> dict[str, Callable[[dict[str, Any]], Any]]:
+        """The requests the server answers."""
+        return {
+            "initialize": lambda params: {
+                "protocolVersion": PROTOCOL_VERSION,
+                "capabilities": {"tools": {}},
+                "serverInfo": {"name": "treepeat"},
+            },
+            "ping": lambda params: {},
+            "tools/list": lambda params: {"tools": TOOLS},
+            "tools/call": self.call_tool,
+        }
+
+    def _answer(self, message: dict[str, Any]) -> dict[str, Any]:
+        method = message["method"]
+        handler = self._handlers().get(method)
+        if handler is None:
+            return {"error": {"code": _METHOD_NOT_FOUND, "message": f"Method not found: {method}"}}
+        try:
+            return {"result": handler(message.get("params") or {})}
+        except UnknownToolError as e:
+            return {"error": {"code": _INVALID_PARAMS, "message": str(e)}}
+
+    def handle(self, message: dict[str, Any]) -> None:
+        """Answer a request; notifications need no answer."""
+        if "method" not in message or "id" not in message:
+            return
+        response = {"jsonrpc": "2.0", "id": message["id"], **self._answer(message)}
+        self.output.write(json.dumps(response) + "\n")
+        self.output.flush()
+
+
+def serve(root: Path, input_stream: TextIO, output_stream: TextIO, scan: Scan) -> None:
+    """Answer the messages of an assistant, one JSON object per line, until the input is closed."""
+    server = McpServer(root, output_stream, scan)
+    for line in input_stream:
+        if line.strip():
+            server.handle(json.loads(line))
@@ -0,0 +1,89 @@
+"""Prometheus metrics of ``treepeat serve``: how long scans take, and how duplicated each root is.
+
+Rendered in the Prometheus text exposition format, one series per served root.
+"""
+
+import threading
+from collections.abc import Callable
+from dataclasses import dataclass
+from pathlib import Path
+
+from treepeat.pipeline.verbose_metrics import VerboseMetrics
+
+PROMETHEUS_CONTENT_TYPE = "text/plain; version=0.0.4; charset=utf-8"
+
+
+@dataclass
+class RootMetrics:
+    """What the scans of one root measured: totals over every scan, and the figures of the latest one."""
+
+    scans: int = 0
+    scan_seconds: float = 0.0
+    last_scan_seconds: float = 0.0
+    files: int = 0
+    lines: int = 0
+    cache_hits: int = 0
+    cache_misses: int = 0
+
+
+def _percent(duplicated: int, lines: int) -> float:
+    return 100.0 * duplicated / lines if lines else 0.0
+
+
+# Name, type, help and value (from the metrics, clone classes and duplicated lines of a root) of each metric
+METRICS: list[tuple[str, str, str, Callable[[RootMetrics, tuple[int, int]], float]]] = [
+    ("treepeat_scans_total", "counter", "Scans of the root", lambda m, c: m.scans),
+    ("treepeat_scan_duration_seconds_total", "counter", "Time spent scanning the root", lambda m, c: m.scan_seconds),
+    (
+        "treepeat_last_scan_duration_seconds",
+        "gauge",
+        "Duration of the latest scan of the root",
+        lambda m, c: m.last_scan_seconds,
+    ),
+    ("treepeat_files_scanned", "gauge", "Files parsed by the latest scan of the root", lambda m, c: m.files),
+    ("treepeat_lines_scanned", "gauge", "Lines parsed by the latest scan of the root", lambda m, c: m.lines),
+    ("treepeat_clone_classes", "gauge", "Clone classes of the root", lambda m, c: c[0]),
+    ("treepeat_duplicated_lines", "gauge", "Lines of the root covered by a clone", lambda m, c: c[1]),
+    (
+        "treepeat_duplication_percent",
+        "gauge",
+        "Duplicated lines as a percentage of the lines of the root",
+        lambda m, c: _percent(c[1], m.lines),
+    ),
+    ("treepeat_cache_hits_total", "counter", "Files found in the fingerprint cache", lambda m, c: m.cache_hits),
+    ("treepeat_cache_misses_total", "counter", "Files missing from the fingerprint cache", lambda m, c: m.cache_misses),
+]
+
+
+def _label(value: str) -> str:
+    return value.replace("\\", "\\\\").replace('"', '\\"').replace("\n", "\\n")
+
+
+class ServerMetrics:
+    """The metrics of the roots a server scans, recorded scan by scan."""
+
+    def __init__(self) -> None:
+        self.roots: dict[Path, RootMetrics] = {}
+        self._lock = threading.Lock()
+
+    def observe(self, root: Path, seconds: float, scanned: VerboseMetrics) -> None:
+        """Record a scan of a root that took ``seconds``, with the pipeline metrics of that scan."""
+        with self._lock:
+            metrics = self.roots.setdefault(root, RootMetrics())
+            metrics.scans += 1
+            metrics.scan_seconds += seconds
+            metrics.last_scan_seconds = seconds
+            metrics.files = scanned.stage_counts.get("parse", 0)
+            metrics.lines = scanned.parsed_lines
+            metrics.cache_hits += scanned.cache_hits
+            metrics.cache_misses += scanned.cache_misses
+
+    def render(self, clones: dict[Path, tuple[int, int]]) -> str:
+        """The metrics in the Prometheus text format, given the clone classes and duplicated lines of each root."""
+        with self._lock:
+            roots = [(root, metrics, clones.get(root, (0, 0))) for root, metrics in sorted(self.roots.items())]
+        lines = []
+        for name, kind, description, value in METRICS:
+            lines += [f"# HELP {name} {description}", f"# TYPE {name} {kind}"]
+            lines += [f'{name}{{root="{_label(str(root))}"}} {value(m, c)}' for root, m, c in roots]
+        return "\n".join(lines) + "\n"
@@ -1,11 +1,20 @@
 """Models for similarity detection."""
 
 from pathlib import Path
+from typing import Callable, Iterable
 
 from datasketch import MinHash  # type: ignore[import-untyped]
 from pydantic import BaseModel, Field
 
 
+class Ownership(BaseModel):
+    """Who a region belongs to: the author of most of its lines and the code owners of its file."""
+
+    author: str | None = Field(default=None, description="Author of most of the region's lines (git blame)")
+    author_email: str | None = Field(default=None, description="Email of that author")
+    owners: list[str] = Field(default_factory=list, description="Code owners of the file (CODEOWNERS)")
+
+
 class Region(BaseModel):
     """A region within a file (function, class, section, paragraph, etc)."""
 
@@ -15,6 +24,7 @@ class Region(BaseModel):
     region_name: str = Field(description="Name or identifier of the region")
     start_line: int = Field(ge=1, description="Start line number (1-indexed)")
     end_line: int = Field(ge=1, description="End line number (1-indexed)")
+    owner: Ownership | None = Field(default=None, description="Who owns the region (only with detect --owners)")
 
     def __repr__(self) -> str:
         """Format as human-readable string."""
@@ -33,6 +43,23 @@ class RegionSignature(BaseModel):
     region: Region = Field(description="The region")
     minhash: MinHash = Field(description="MinHash signature")
     shingle_count: int = Field(description="Number of shingles used to create signature")
+    distinct_shingles: int = Field(default=0, description="Number of distinct shingles (0 = unknown)")
+    shingle_bloom: int = Field(default=0, description="Bloom filter of the shingles, as a bitset")
+    bloom_bits: int = Field(default=0, description="Size of the Bloom filter in bits (0 = no filter)")
+
+
+RegionKey = tuple[Path, int, int, str]
+TokenCounts = dict[RegionKey, int]
+
+
+def region_key(region: Region) -> RegionKey:
+    """Identify a region (models are not hashable)."""
+    return (region.path, region.start_line, region.end_line, region.region_name)
+
+
+def token_counts(signatures: Iterable[RegionSignature]) -> TokenCounts:
+    """Map each region to its normalized token (shingle) count."""
+    return {region_key(sig.region): sig.shingle_count for sig in signatures}
 
 
 class SimilarRegionGroup(BaseModel):
@@ -42,6 +69,9 @@ class SimilarRegionGroup(BaseModel):
     similarity: float = Field(
         ge=0.0, le=1.0, description="Estimated Jaccard similarity (0.0 to 1.0)"
     )
+    fingerprint: str = Field(
+        default="", description="Short stable id derived from the normalized content (e.g. clone-1a2b3c4d)"
+    )
 
     @property
     def is_self_similarity(self) -> bool:
@@ -51,6 +81,11 @@ class SimilarRegionGroup(BaseModel):
         first_path = self.regions[0].path
         return all(r.path == first_path for r in self.regions)
 
+    @property
+    def representative(self) -> Region:
+        """The instance shown as the clone class's canonical copy (its earliest occurrence)."""
+        return self.regions[0]
+
     @property
     def size(self) -> int:
         """Number of regions in the group."""
@@ -61,6 +96,10 @@ class SimilarRegionGroup(BaseModel):
         return f"SimilarRegionGroup({self.size} regions: {region_names} {self.similarity:.2%} similar)"
 
 
+# Called with each verified group as soon as it is found (used for streaming output)
+GroupCallback = Callable[[SimilarRegionGroup], None]
+
+
 class SimilarityResult(BaseModel):
     """Result of similarity detection."""
 
@@ -70,6 +109,15 @@ class SimilarityResult(BaseModel):
     similar_groups: list[SimilarRegionGroup] = Field(
         default_factory=list, description="Groups of similar regions above threshold"
     )
+    suppressed_groups: list[SimilarRegionGroup] = Field(
+        default_factory=list, description="Groups hidden by inline treepeat:ignore comments"
+    )
+    test_groups: list[SimilarRegionGroup] = Field(
+        default_factory=list, description="Groups only in test code, kept apart with --tests separate"
+    )
+    fragment_counts: dict[tuple[str, str], int] = Field(
+        default_factory=dict, description="Regions extracted per (language, region type), in a clone or not"
+    )
 
     @property
     def total_files(self) -> int:
@@ -0,0 +1,73 @@
+"""Notifications of duplication regressions, posted to a webhook (Slack, Teams, Mattermost, ...).
+
+The payload is a template whose ``$variables`` each expand to a JSON value,
+quotes included, so a template stays valid JSON whatever the values hold:
+the default ``{"text": $text}`` suits Slack incoming webhooks.
+"""
+
+import json
+import urllib.error
+import urllib.request
+from pathlib import Path
+from string import Template
+from typing import Any
+
+DEFAULT_TEMPLATE = Template('{"text": $text}')
+
+# Variables a payload template can use
+TEMPLATE_VARIABLES = ("text", "path", "violations", "clone_classes", "new_clone_classes")
+
+# Seconds to wait for the webhook to answer
+TIMEOUT = 10.0
+
+
+class NotifyError(Exception):
+    """A notification that could not be delivered."""
+
+
+def load_template(path: Path) -> Template:
+    """Read a payload template, raising ValueError if it uses unknown variables."""
+    template = Template(path.read_text(encoding="utf-8"))
+    if not template.is_valid():
+        raise ValueError(f"{path} is not a valid template (write a literal $ as $$)")
+    unknown = sorted(set(template.get_identifiers()) - set(TEMPLATE_VARIABLES))
+    if unknown:
+        known = ", ".join(TEMPLATE_VARIABLES)
+        raise ValueError(f"{path} uses unknown variable(s) {', '.join(unknown)} (known: {known})")
+    return template
+
+
+def notification_variables(
+    path: Path, violations: list[str], clone_classes: int, new_clone_classes: int
+) -> dict[str, Any]:
+    """The template variables of a run of ``path`` that broke the policy."""
+    text = "\n".join([f"treepeat: duplication regression in {path}", *(f"- {v}" for v in violations)])
+    return {
+        "text": text,
+        "path": str(path),
+        "violations": violations,
+        "clone_classes": clone_classes,
+        "new_clone_classes": new_clone_classes,
+    }
+
+
+def render(template: Template, variables: dict[str, Any]) -> str:
+    """The payload of a template, each variable expanded to a JSON value."""
+    return template.substitute({name: json.dumps(value) for name, value in variables.items()})
+
+
+def send(url: str, payload: str) -> None:
+    """POST a JSON payload to a webhook, raising NotifyError if it is not accepted."""
+    request = urllib.request.Request(
+        url,
+        data=payload.encode("utf-8"),
+        headers={"Content-Type": "application/json", "User-Agent": "treepeat"},
+        method="POST",
+    )
+    try:
+        with urllib.request.urlopen(request, timeout=TIMEOUT):
+            pass
+    except urllib.error.HTTPError as e:
+        raise NotifyError(f"{e.code} {e.reason} from the webhook") from e
+    except (urllib.error.URLError, TimeoutError) as e:
+        raise NotifyError(f"cannot reach the webhook: {getattr(e, 'reason', e)}") from e
@@ -0,0 +1,101 @@
+"""Ownership of clone instances: who wrote them (git blame) and who owns their files (CODEOWNERS).
+
+Annotating each instance with an owner lets a duplication report be routed
+to the people who can consolidate it. The author of an instance is the one
+who last changed most of its committed lines; its owners are those of the
+last CODEOWNERS rule matching its file, as on GitHub and GitLab.
+"""
+
+import logging
+from collections import Counter
+from dataclasses import dataclass
+from pathlib import Path
+
+from treepeat.models.similarity import Ownership, Region, SimilarityResult, SimilarRegionGroup
+from treepeat.pipeline.parse import matches_pattern
+from treepeat.revisions import RevisionError, blame_authors, repository_root
+
+logger = logging.getLogger(__name__)
+
+# Where a repository's CODEOWNERS file may be, in the order GitHub looks for it
+CODEOWNERS_LOCATIONS = (".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS")
+
+
+@dataclass(frozen=True)
+class OwnerRule:
+    """A CODEOWNERS line: a gitignore-style pattern and the users, teams or emails owning what it matches."""
+
+    pattern: str
+    owners: tuple[str, ...]
+
+
+def _rule(line: str) -> OwnerRule | None:
+    """The rule of a CODEOWNERS line (None for blank lines, comments and GitLab [Section] headers)."""
+    fields = line.split("#", 1)[0].split()
+    if not fields or fields[0].startswith("["):
+        return None
+    return OwnerRule(fields[0], tuple(fields[1:]))
+
+
+def parse_codeowners(text: str) -> list[OwnerRule]:
+    """The rules of a CODEOWNERS file, in file order."""
+    rules = [_rule(line) for line in text.splitlines()]
+    return [rule for rule in rules if rule is not None]
+
+
+def load_codeowners(root: Path) -> list[OwnerRule]:
+    """The rules of the CODEOWNERS file of a repository (none if it has no readable one)."""
+    for location in CODEOWNERS_LOCATIONS:
+        try:
+            return parse_codeowners((root / location).read_text(encoding="utf-8"))
+        except (OSError, UnicodeDecodeError):
+            continue
+    return []
+
+
+def code_owners(file: Path, rules: list[OwnerRule], root: Path) -> list[str]:
+    """The owners of a file: those of the last rule matching it (a rule without owners unsets them)."""
+    matching = [rule for rule in rules if matches_pattern(file, rule.pattern, root)]
+    return list(matching[-1].owners) if matching else []
+
+
+def _primary_author(region: Region, root: Path) -> tuple[str, str] | tuple[None, None]:
+    """The (author, email) who last changed most of a region's committed lines, if git can blame it."""
+    try:
+        authors = blame_authors(region.path, region.start_line, region.end_line, root)
+    except RevisionError as e:
+        logger.debug(f"Cannot blame {region.path}:{region.start_line}-{region.end_line}: {e}")
+        return None, None
+    return authors.most_common(1)[0][0] if authors else (None, None)
+
+
+def region_ownership(region: Region, rules: list[OwnerRule], root: Path) -> Ownership | None:
+    """Who a region belongs to (None if it has neither a committed author nor code owners)."""
+    author, email = _primary_author(region, root)
+    owners = code_owners(region.path, rules, root)
+    if author is None and not owners:
+        return None
+    return Ownership(author=author, author_email=email, owners=owners)
+
+
+def _owned(group: SimilarRegionGroup, rules: list[OwnerRule], root: Path) -> SimilarRegionGroup:
+    regions = [region.model_copy(update={"owner": region_ownership(region, rules, root)}) for region in group.regions]
+    return group.model_copy(update={"regions": regions})
+
+
+def annotate_ownership(result: SimilarityResult, path: Path) -> SimilarityResult:
+    """The result with an owner on each reported clone instance, raising RevisionError outside a git repository."""
+    root = repository_root(path)
+    rules = load_codeowners(root)
+    return result.model_copy(
+        update={
+            "similar_groups": [_owned(group, rules, root) for group in result.similar_groups],
+            "test_groups": [_owned(group, rules, root) for group in result.test_groups],
+        }
+    )
+
+
+def owners_of(regions: list[Region]) -> list[str]:
+    """The code owners of any of some regions, each once, most frequent first."""
+    counts = Counter(owner for region in regions if region.owner for owner in region.owner.owners)
+    return [owner for owner, _ in counts.most_common()]
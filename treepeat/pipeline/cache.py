@@ -0,0 +1,187 @@
+import hashlib
+import json
+import logging
+import os
+import tempfile
+from collections.abc import Callable
+from importlib.metadata import PackageNotFoundError, version
+from itertools import chain
+from pathlib import Path
+from typing import Any
+
+from treepeat.config import PipelineSettings
+from treepeat.models.ast import ParsedFile
+from treepeat.models.shingle import ShingledRegion
+from treepeat.pipeline.thresholds import loosest_min_lines
+from treepeat.pipeline.verbose_metrics import (
+    fragment_counts_since,
+    get_verbose_metrics,
+    record_cache_lookups,
+    record_fragment_counts,
+)
+
+logger = logging.getLogger(__name__)
+
+# Bumped whenever the layout of a cache entry changes
+CACHE_FORMAT = 2
+
+# Packages whose version changes the parse trees or shingles of a file
+_VERSIONED_PACKAGES = ("treepeat", "tree-sitter", "tree-sitter-language-pack")
+
+
+def default_cache_dir() -> Path:
+    """``$XDG_CACHE_HOME/treepeat``, or ``~/.cache/treepeat``."""
+    return Path(os.environ.get("XDG_CACHE_HOME") or Path.home() / ".cache") / "treepeat"
+
+
+def _package_version(package: str) -> str:
+    try:
+        return version(package)
+    except PackageNotFoundError:
+        return "unknown"
+
+
+def _json_default(value: Any) -> Any:
+    """Serialize sets in a stable order, so the same settings always give the same key."""
+    return sorted(value) if isinstance(value, (set, frozenset)) else str(value)
+
+
+def stable_json(value: Any) -> str:
+    """JSON of settings that is the same for the same settings, whatever the order of their sets."""
+    return json.dumps(value, sort_keys=True, default=_json_default)
+
+
+def config_key(settings: PipelineSettings) -> str:
+    """Hash of everything besides a file's content that decides its shingled regions."""
+    config = {
+        "format": CACHE_FORMAT,
+        "versions": {package: _package_version(package) for package in _VERSIONED_PACKAGES},
+        "rules": settings.rules.model_dump(),
+        "shingle": settings.shingle.model_dump(include={"k", "cross_language", "canonicalize"}),
+        "min_lines": loosest_min_lines(settings.lsh),
+        "min_complexity": settings.lsh.min_complexity,
+        "ignore_node_types": settings.lsh.ignore_node_types,
+    }
+    return hashlib.sha256(stable_json(config).encode("utf-8")).hexdigest()
+
+
+class FingerprintCache:
+    """On-disk cache of the shingled regions of each file, keyed by content hash and configuration.
+
+    A file whose content, grammar versions and rule configuration are unchanged
+    since a previous run reuses that run's regions instead of being extracted
+    and shingled again. Entries do not depend on where the file lives, so a
+    moved or copied file is a hit too. Each entry also keeps how many regions of
+    each type were extracted from the file, so ``--verbose`` counts them on a hit.
+    """
+
+    def __init__(self, directory: Path, settings: PipelineSettings):
+        self.directory = directory
+        self._config_key = config_key(settings)
+
+    def _entry(self, files: list[ParsedFile]) -> Path:
+        digest = hashlib.sha256(self._config_key.encode("utf-8"))
+        for parsed_file in files:
+            digest.update(parsed_file.language.encode("utf-8") + b"\0")
+            digest.update(hashlib.sha256(parsed_file.source).digest())
+        key = digest.hexdigest()
+        return self.directory / key[:2] / f"{key}.json"
+
+    def load(self, path: Path, files: list[ParsedFile]) -> list[ShingledRegion] | None:
+        """The cached regions of a file (parsed into ``files``), or None on a miss.
+
+        A hit records the file's cached fragment counts, as extracting it would have.
+        """
+        try:
+            entry = json.loads(self._entry(files).read_text(encoding="utf-8"))
+            regions = [ShingledRegion.model_validate(region) for region in entry["regions"]]
+            fragments = {(language, region_type): count for language, region_type, count in entry["fragments"]}
+        except FileNotFoundError:
+            return None
+        except (OSError, ValueError, KeyError, TypeError) as e:
+            logger.debug("Ignoring unreadable cache entry for %s: %s", path, e)
+            return None
+        record_fragment_counts(path, fragments)
+        return [
+            region.model_copy(update={"region": region.region.model_copy(update={"path": path})})
+            for region in regions
+        ]
+
+    def store(
+        self,
+        files: list[ParsedFile],
+        regions: list[ShingledRegion],
+        fragments: dict[tuple[str, str], int] | None = None,
+    ) -> None:
+        """Cache the regions of a file, replacing the entry atomically so concurrent runs never read half of it."""
+        entry = self._entry(files)
+        counts = [[language, region_type, count] for (language, region_type), count in (fragments or {}).items()]
+        payload = json.dumps({"regions": [region.model_dump(mode="json") for region in regions], "fragments": counts})
+        try:
+            entry.parent.mkdir(parents=True, exist_ok=True)
+            with tempfile.NamedTemporaryFile("w", dir=entry.parent, delete=False, encoding="utf-8") as f:
+                f.write(payload)
+            os.replace(f.name, entry)
+        except OSError as e:
+            logger.debug("Could not write cache entry %s: %s", entry, e)
+
+
+def _by_path(parsed_files: list[ParsedFile]) -> dict[Path, list[ParsedFile]]:
+    """Parsed files grouped by path, in scan order (a notebook can parse into several)."""
+    files: dict[Path, list[ParsedFile]] = {}
+    for parsed_file in parsed_files:
+        files.setdefault(parsed_file.path, []).append(parsed_file)
+    return files
+
+
+def _regions_by_path(regions: list[ShingledRegion]) -> dict[Path, list[ShingledRegion]]:
+    grouped: dict[Path, list[ShingledRegion]] = {}
+    for region in regions:
+        grouped.setdefault(region.region.path, []).append(region)
+    return grouped
+
+
+def _load_cached(files: dict[Path, list[ParsedFile]], cache: FingerprintCache) -> dict[Path, list[ShingledRegion]]:
+    """The cached regions of each file that has an entry."""
+    cached = {}
+    for path, group in files.items():
+        regions = cache.load(path, group)
+        if regions is not None:
+            cached[path] = regions
+    return cached
+
+
+def _shingle_misses(
+    misses: dict[Path, list[ParsedFile]],
+    cache: FingerprintCache,
+    shingle: Callable[[list[ParsedFile]], list[ShingledRegion]],
+) -> dict[Path, list[ShingledRegion]]:
+    """Shingle the files missing from the cache and cache their regions (none is an answer worth caching too)."""
+    if not misses:
+        return {}
+    file_fragments = get_verbose_metrics().file_fragment_counts
+    fragments_before = {path: dict(file_fragments.get(path, {})) for path in misses}
+    fresh = _regions_by_path(shingle(list(chain.from_iterable(misses.values()))))
+    regions = {path: fresh.get(path, []) for path in misses}
+    for path, group in misses.items():
+        cache.store(group, regions[path], fragment_counts_since(file_fragments.get(path, {}), fragments_before[path]))
+    return regions
+
+
+def shingle_with_cache(
+    parsed_files: list[ParsedFile],
+    cache: FingerprintCache,
+    shingle: Callable[[list[ParsedFile]], list[ShingledRegion]],
+) -> list[ShingledRegion]:
+    """Shingle only the files missing from the cache, then cache their regions.
+
+    Regions come back in the order of ``parsed_files``, whether they were
+    cached or not, so results do not depend on the state of the cache.
+    """
+    files = _by_path(parsed_files)
+    cached = _load_cached(files, cache)
+    misses = {path: group for path, group in files.items() if path not in cached}
+    logger.info("Fingerprint cache: %d hit(s), %d miss(es)", len(cached), len(misses))
+    record_cache_lookups(len(cached), len(misses))
+    cached.update(_shingle_misses(misses, cache, shingle))
+    return [region for path in files for region in cached[path]]
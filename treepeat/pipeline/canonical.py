@@ -0,0 +1,192 @@
+from operator import add, mul, sub
+
+from tree_sitter import Node
+
+LOOP_TOKEN = "<LOOP>"
+
+# Loop forms across grammars; all of them shingle as LOOP_TOKEN over their header and body
+LOOP_NODE_TYPES = {
+    "for_statement",
+    "for_in_statement",
+    "enhanced_for_statement",
+    "while_statement",
+    "do_statement",
+    "for_expression",
+    "while_expression",
+    "loop_expression",
+}
+
+# Fields of the init and update clauses of a C-style for; Go keeps its clauses in a for_clause child.
+# Such a loop shingles as the equivalent ``init; while (condition) { body; update }``
+FOR_INIT_FIELDS = ("initializer", "init")
+FOR_UPDATE_FIELDS = ("update", "increment")
+FOR_CLAUSE_TYPE = "for_clause"
+
+# Nodes only wrapping an expression, unwrapped in loop headers and bodies so that a for and a while
+# line up: their conditions, and the update of a for with the last statement of the while's body
+WRAPPER_NODE_TYPES = {"expression_statement", "parenthesized_expression"}
+
+# Binary expression node types that expose left/operator/right fields
+BINARY_NODE_TYPES = {"binary_operator", "boolean_operator", "binary_expression"}
+
+# Operators whose operands are put in a canonical order (a + b == b + a)
+COMMUTATIVE_OPERATORS = {"+", "*", "==", "!=", "&", "|", "^", "&&", "||", "and", "or"}
+
+# Operators folded when both operands are numeric literals (60 * 60 == 3600)
+FOLDABLE_OPERATORS = {"+": add, "-": sub, "*": mul}
+
+# Mapping entries (JSON/YAML keys) are put in key order: key order is not significant in config
+MAPPING_ENTRY_TYPES = {"pair", "block_mapping_pair", "flow_pair"}
+MAPPING_NODE_TYPES = {"object", "block_mapping", "flow_mapping"}
+
+NUMBER_NODE_TYPES = {
+    "integer",
+    "float",
+    "number",
+    "int_literal",
+    "float_literal",
+    "integer_literal",
+    "decimal_integer_literal",
+    "decimal_floating_point_literal",
+}
+
+
+def _text(node: Node, source: bytes) -> str:
+    return source[node.start_byte : node.end_byte].decode("utf-8", errors="ignore")
+
+
+def _number(text: str) -> int | float | None:
+    """Parse a numeric literal such as '42', '0x2a', '1_000' or '1.5'."""
+    cleaned = text.replace("_", "")
+    try:
+        return int(cleaned, 0)
+    except ValueError:
+        pass
+    try:
+        return float(cleaned)
+    except ValueError:
+        return None
+
+
+def binary_operands(node: Node, source: bytes) -> tuple[Node, str, Node] | None:
+    """Return (left, operator, right) for a binary expression, or None for any other node."""
+    if node.type not in BINARY_NODE_TYPES:
+        return None
+    left, operator, right = (node.child_by_field_name(f) for f in ("left", "operator", "right"))
+    if left is None or operator is None or right is None:
+        return None
+    return left, _text(operator, source), right
+
+
+def fold_constant(node: Node, source: bytes) -> str | None:
+    """Return the value of a trivial constant expression (two numeric literals), or None."""
+    operands = binary_operands(node, source)
+    if operands is None or operands[1] not in FOLDABLE_OPERATORS:
+        return None
+    left, operator, right = operands
+    if left.type not in NUMBER_NODE_TYPES or right.type not in NUMBER_NODE_TYPES:
+        return None
+    a, b = _number(_text(left, source)), _number(_text(right, source))
+    if a is None or b is None:
+        return None
+    return str(FOLDABLE_OPERATORS[operator](a, b))
+
+
+def _index(children: list[Node], node: Node) -> int:
+    return next(i for i, child in enumerate(children) if child.id == node.id)
+
+
+def _key_ordered(children: list[Node], source: bytes) -> list[Node]:
+    """Sort mapping entries by their text (which starts with the key), leaving punctuation in place."""
+    entries = iter(sorted((c for c in children if c.type in MAPPING_ENTRY_TYPES), key=lambda c: _text(c, source)))
+    return [next(entries) if child.type in MAPPING_ENTRY_TYPES else child for child in children]
+
+
+def _unwrapped(node: Node) -> Node:
+    """The expression inside an expression statement or parentheses, or the node itself."""
+    while node.type in WRAPPER_NODE_TYPES and node.named_child_count == 1:
+        node = node.named_children[0]
+    return node
+
+
+def _field(node: Node, fields: tuple[str, ...]) -> Node | None:
+    """The child under the first of some field names the node has."""
+    return next((child for child in map(node.child_by_field_name, fields) if child is not None), None)
+
+
+def _for_clauses(node: Node) -> Node | None:
+    """The node holding the init/condition/update of a C-style for (the loop, or Go's for_clause), if it is one."""
+    if node.type not in LOOP_NODE_TYPES:
+        return None
+    clauses = next((child for child in node.named_children if child.type == FOR_CLAUSE_TYPE), node)
+    if _field(clauses, FOR_INIT_FIELDS) is None and _field(clauses, FOR_UPDATE_FIELDS) is None:
+        return None
+    return clauses
+
+
+def _is_loop_body(node: Node) -> bool:
+    parent = node.parent
+    body = parent.child_by_field_name("body") if parent is not None and parent.type in LOOP_NODE_TYPES else None
+    return body is not None and body.id == node.id
+
+
+def _loop_children(node: Node) -> list[Node]:
+    """A loop's header and body without keywords or punctuation.
+
+    A C-style for keeps only its condition and body: its init is hoisted before
+    the loop (see hoisted_children) and its update appended to the body (see
+    appended_children).
+    """
+    clauses = _for_clauses(node)
+    if clauses is None:
+        return [_unwrapped(child) for child in node.named_children]
+    parts = (clauses.child_by_field_name("condition"), node.child_by_field_name("body"))
+    return [_unwrapped(part) for part in parts if part is not None]
+
+
+def hoisted_children(node: Node) -> list[Node]:
+    """Nodes shingled just before a node, as its siblings: the init of a C-style for."""
+    clauses = _for_clauses(node)
+    init = _field(clauses, FOR_INIT_FIELDS) if clauses is not None else None
+    return [init] if init is not None else []
+
+
+def appended_children(parent: Node, child: Node) -> list[Node]:
+    """Nodes shingled after a child's own children: the update of a C-style for, at the end of its body."""
+    clauses = _for_clauses(parent)
+    if clauses is None or not _is_loop_body(child):
+        return []
+    update = _field(clauses, FOR_UPDATE_FIELDS)
+    return [_unwrapped(update)] if update is not None else []
+
+
+def _operand_ordered(node: Node, source: bytes) -> list[Node]:
+    """Children with the operands of a commutative operator sorted, or none for a folded constant."""
+    children = list(node.children)
+    operands = binary_operands(node, source)
+    if operands is None:
+        return children
+    if fold_constant(node, source) is not None:
+        return []
+    left, operator, right = operands
+    if operator in COMMUTATIVE_OPERATORS and _text(right, source) < _text(left, source):
+        i, j = _index(children, left), _index(children, right)
+        children[i], children[j] = children[j], children[i]
+    return children
+
+
+def canonical_children(node: Node, source: bytes) -> list[Node]:
+    """Children in canonical order: header and body for loops (statements of their body unwrapped), sorted
+    entries for mappings, none for folded constants and sorted operands for commutative operators."""
+    if node.type in LOOP_NODE_TYPES:
+        return _loop_children(node)
+    if node.type in MAPPING_NODE_TYPES:
+        return _key_ordered(list(node.children), source)
+    if _is_loop_body(node):
+        return [_unwrapped(child) for child in node.children]
+    return _operand_ordered(node, source)
+
+
+def canonical_name(name: str, node: Node) -> str:
+    """Shingle every loop form under the same name."""
+    return LOOP_TOKEN if node.type in LOOP_NODE_TYPES else name
@@ -0,0 +1,47 @@
+from tree_sitter import Node
+
+# Control-flow node types (across the supported grammars) that add a decision point
+DECISION_NODE_TYPES = frozenset(
+    {
+        # conditionals
+        "if_statement",
+        "if_expression",
+        "elif_clause",
+        "conditional_expression",
+        "ternary_expression",
+        # loops
+        "for_statement",
+        "for_in_statement",
+        "for_expression",
+        "enhanced_for_statement",
+        "while_statement",
+        "while_expression",
+        "do_statement",
+        "do_while_statement",
+        "loop_expression",
+        # branches of switch/match/when/select
+        "switch_case",
+        "case_clause",
+        "expression_case",
+        "type_case",
+        "communication_case",
+        "switch_block_statement_group",
+        "match_arm",
+        "when_entry",
+        # exception handlers
+        "catch_clause",
+        "except_clause",
+    }
+)
+
+
+def compute_complexity(nodes: list[Node]) -> int:
+    """Cyclomatic-style complexity of a fragment: 1 + the number of decision points."""
+    complexity = 1
+    stack = list(nodes)
+    while stack:
+        node = stack.pop()
+        if node.type in DECISION_NODE_TYPES:
+            complexity += 1
+        stack.extend(node.children)
+    return complexity
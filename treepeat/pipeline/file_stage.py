@@ -0,0 +1,64 @@
+import logging
+from pathlib import Path
+
+from treepeat.models.shingle import Shingle, ShingledRegion, ShingleList
+from treepeat.models.similarity import GroupCallback, Region, SimilarRegionGroup
+from treepeat.pipeline.imports import import_lines
+from treepeat.pipeline.lsh_stage import detect_similarity
+from treepeat.pipeline.minhash_stage import compute_region_signatures
+
+logger = logging.getLogger(__name__)
+
+FILE_REGION_TYPE = "file"
+
+
+def _line_shingles(lines: list[str]) -> list[Shingle]:
+    """One shingle per non-blank line other than imports, with whitespace collapsed."""
+    imports = import_lines(lines)
+    return [
+        Shingle(content=" ".join(line.split()), start_line=number, end_line=number)
+        for number, line in enumerate(lines, start=1)
+        if line.strip() and number not in imports
+    ]
+
+
+def shingle_file(path: Path, language: str) -> ShingledRegion | None:
+    """Shingle a whole file by its lines, or return None if it is empty, only imports or unreadable."""
+    try:
+        lines = path.read_text(encoding="utf-8", errors="replace").splitlines()
+    except OSError as e:
+        logger.warning("Failed to read %s: %s", path, e)
+        return None
+    shingles = _line_shingles(lines)
+    if not shingles:
+        return None
+    region = Region(
+        path=path,
+        language=language,
+        region_type=FILE_REGION_TYPE,
+        region_name=path.name,
+        start_line=1,
+        end_line=len(lines),
+    )
+    return ShingledRegion(region=region, shingles=ShingleList(shingles=shingles))
+
+
+def detect_duplicate_files(
+    files: dict[Path, str],
+    similarity_percent: float,
+    min_lines: int,
+    num_perm: int = 128,
+    on_group: GroupCallback | None = None,
+) -> list[SimilarRegionGroup]:
+    """Find whole files that are identical or at least ``similarity_percent`` similar.
+
+    Files are compared line by line, which is much cheaper than shingling
+    their syntax trees; ``files`` maps each path to its language.
+    """
+    shingled = [sr for sr in (shingle_file(path, language) for path, language in files.items()) if sr is not None]
+    if len(shingled) < 2:
+        return []
+    signatures = compute_region_signatures(shingled, num_perm=num_perm)
+    result = detect_similarity(signatures, similarity_percent, shingled, min_lines=min_lines, on_group=on_group)
+    logger.info("Whole-file pass: %d group(s) of duplicate files", len(result.similar_groups))
+    return result.similar_groups
@@ -0,0 +1,45 @@
+import hashlib
+from typing import Iterable
+
+from treepeat.models.shingle import ShingledRegion
+from treepeat.models.similarity import SimilarityResult
+
+FINGERPRINT_PREFIX = "clone-"
+FINGERPRINT_HEX_LENGTH = 8
+
+
+def region_fingerprint(shingled_region: ShingledRegion) -> str:
+    """Hash a region's normalized shingles, independent of its path and line numbers."""
+    content = "\n".join(shingled_region.shingles.get_contents())
+    return hashlib.sha1(content.encode("utf-8")).hexdigest()
+
+
+def group_fingerprint(shingled_regions: list[ShingledRegion]) -> str:
+    """Return a short stable id for a clone group.
+
+    The smallest region hash is used so that the id does not depend on the
+    order of the regions nor change when code merely moves within a file.
+    """
+    if not shingled_regions:
+        return ""
+    smallest = min(region_fingerprint(sr) for sr in shingled_regions)
+    return f"{FINGERPRINT_PREFIX}{smallest[:FINGERPRINT_HEX_LENGTH]}"
+
+
+def normalize_fingerprint(value: str) -> str:
+    """Normalize a user supplied fingerprint ('clone-1a2b3c4d', '1A2B3C4D', ...)."""
+    value = value.strip().lower()
+    if not value.startswith(FINGERPRINT_PREFIX):
+        value = f"{FINGERPRINT_PREFIX}{value}"
+    return value
+
+
+def exclude_groups(result: SimilarityResult, fingerprints: Iterable[str]) -> tuple[SimilarityResult, set[str]]:
+    """Drop groups whose fingerprint is listed; also return fingerprints that matched no group."""
+    excluded = {normalize_fingerprint(fp) for fp in fingerprints if fp.strip()}
+    if not excluded:
+        return result, set()
+
+    kept = [group for group in result.similar_groups if group.fingerprint not in excluded]
+    unknown = excluded - {group.fingerprint for group in result.similar_groups}
+    return result.model_copy(update={"similar_groups": kept}), unknown
@@ -0,0 +1,21 @@
+import re
+from itertools import islice
+from pathlib import Path
+
+# Lines at the top of a file searched for a generator marker
+HEADER_LINES = 10
+
+
+def read_header(path: Path, lines: int = HEADER_LINES) -> str:
+    """Read the first lines of a file, or '' if it cannot be read."""
+    try:
+        with path.open("r", encoding="utf-8", errors="replace") as f:
+            return "".join(islice(f, lines))
+    except OSError:
+        return ""
+
+
+def is_generated(path: Path, markers: list[str]) -> bool:
+    """True if a generator marker appears in the first lines of a file."""
+    header = read_header(path)
+    return any(re.search(marker, header) for marker in markers)
@@ -0,0 +1,74 @@
+import logging
+import os
+import subprocess
+from pathlib import Path
+
+logger = logging.getLogger(__name__)
+
+
+def _read_gitdir_file(dot_git: Path) -> Path | None:
+    """Resolve a ".git" file (linked worktree or submodule): "gitdir: <path>"."""
+    try:
+        content = dot_git.read_text(encoding="utf-8").strip()
+    except OSError:
+        return None
+    if not content.startswith("gitdir:"):
+        return None
+    return (dot_git.parent / content.removeprefix("gitdir:").strip()).resolve()
+
+
+def _common_dir(git_dir: Path) -> Path:
+    """The directory holding info/exclude, shared by every worktree of a repository."""
+    commondir = git_dir / "commondir"
+    if commondir.is_file():
+        return (git_dir / commondir.read_text(encoding="utf-8").strip()).resolve()
+    return git_dir
+
+
+def find_worktree(path: Path) -> tuple[Path, Path] | None:
+    """Return (worktree root, git directory) of the git worktree containing a path, if any."""
+    for directory in (path, *path.parents):
+        dot_git = directory / ".git"
+        git_dir = dot_git if dot_git.is_dir() else _read_gitdir_file(dot_git) if dot_git.is_file() else None
+        if git_dir is not None:
+            return directory, _common_dir(git_dir)
+    return None
+
+
+def _configured_excludes_file(worktree: Path) -> str:
+    """Read core.excludesFile through git, so every config level (system, global, repo) applies."""
+    try:
+        completed = subprocess.run(
+            ["git", "config", "--path", "--get", "core.excludesFile"],
+            cwd=worktree,
+            capture_output=True,
+            text=True,
+            timeout=5,
+        )
+    except (OSError, subprocess.SubprocessError) as e:
+        logger.debug(f"Cannot read core.excludesFile: {e}")
+        return ""
+    return completed.stdout.strip()
+
+
+def global_excludes_file(worktree: Path) -> Path:
+    """core.excludesFile, defaulting like git to $XDG_CONFIG_HOME/git/ignore."""
+    configured = _configured_excludes_file(worktree)
+    if configured:
+        return Path(configured).expanduser()
+    config_home = os.environ.get("XDG_CONFIG_HOME") or str(Path.home() / ".config")
+    return Path(config_home) / "git" / "ignore"
+
+
+def git_exclude_files(path: Path) -> tuple[Path, list[Path]] | None:
+    """Return the worktree root of a path and its exclude files, lowest precedence first.
+
+    These are the files git consults besides .gitignore: core.excludesFile and
+    .git/info/exclude, both anchored at the worktree root.
+    """
+    worktree = find_worktree(path.resolve())
+    if worktree is None:
+        return None
+    root, git_dir = worktree
+    candidates = [global_excludes_file(root), git_dir / "info" / "exclude"]
+    return root, [candidate for candidate in candidates if candidate.is_file()]
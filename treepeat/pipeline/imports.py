@@ -0,0 +1,46 @@
+import re
+
+# Statements that only bring names into scope: import/include/using/require lines of the common languages
+_IMPORT = re.compile(
+    r"(?:import\b|from\s+\S+\s+import\b|export\s+(?:\*|\{[^}]*\}?)(?:\s+as\s+\w+)?\s+from\b"
+    r"|#\s*(?:include|import)\b|@import\b|using\s+(?:static\s+)?[\w.]+(?:\s*=\s*[\w.<>]+)?\s*;"
+    r"|(?:pub\s+)?use\s+[\w:\\{}, *]+;?|extern\s+crate\b|require(?:_relative|_once)?\b|include_once\b"
+    r"|(?:const|let|var)\s+[\w{}, ]+=\s*require\(|package\s+[\w.]+;?$|library\()"
+)
+
+
+def _still_open(line: str, in_block: bool) -> bool:
+    """True if a multi-line import (``import (``, ``import {``, ``use a::{``) goes on after this line."""
+    if in_block:
+        return not any(closer in line for closer in ")}")
+    return line.count("(") + line.count("{") > line.count(")") + line.count("}")
+
+
+def import_lines(lines: list[str]) -> set[int]:
+    """The 1-indexed numbers of the lines of import, include and using statements, multi-line ones included."""
+    numbers = set()
+    in_block = False
+    for number, line in enumerate(lines, start=1):
+        if in_block or _IMPORT.match(line.strip()):
+            numbers.add(number)
+            in_block = _still_open(line, in_block)
+    return numbers
+
+
+def is_mostly_imports(lines: list[str], start: int, end: int, imports: set[int]) -> bool:
+    """True if more than half of the non-blank lines of a span are imports."""
+    code = [number for number in range(start, end + 1) if lines[number - 1].strip()]
+    return bool(code) and 2 * len(imports.intersection(code)) > len(code)
+
+
+def _skippable(lines: list[str], number: int, imports: set[int]) -> bool:
+    return number in imports or not lines[number - 1].strip()
+
+
+def trim_imports(lines: list[str], start: int, end: int, imports: set[int]) -> tuple[int, int]:
+    """Shrink a span so it neither starts nor ends in imports (or blank lines); start > end if nothing is left."""
+    while start <= end and _skippable(lines, start, imports):
+        start += 1
+    while end >= start and _skippable(lines, end, imports):
+        end -= 1
+    return start, end
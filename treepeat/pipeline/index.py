@@ -0,0 +1,135 @@
+import hashlib
+import logging
+import os
+import tempfile
+from pathlib import Path
+
+from pydantic import BaseModel, Field, ValidationError
+
+from treepeat.config import PipelineSettings
+from treepeat.models.similarity import SimilarRegionGroup
+from treepeat.pipeline.cache import config_key, stable_json
+
+logger = logging.getLogger(__name__)
+
+# Settings that change how fast clones are found, or which files are looked at, but never which clones exist
+_UNRELATED_SETTINGS = {"jobs", "cache_dir", "changed_files"}
+
+
+class CloneIndex(BaseModel):
+    """The region clone groups of a previous run, with the content hash of every file it scanned."""
+
+    files: dict[Path, str] = Field(default_factory=dict, description="Content hash of each scanned file")
+    groups: list[SimilarRegionGroup] = Field(default_factory=list, description="Reported region clone groups")
+
+
+def index_file(cache_dir: Path, root: Path, settings: PipelineSettings) -> Path:
+    """Where the clone index of a scanned path lives: one per path and detection configuration."""
+    settings_json = stable_json(settings.model_dump(exclude=_UNRELATED_SETTINGS))
+    key = hashlib.sha256(f"{config_key(settings)}\0{root.resolve()}\0{settings_json}".encode("utf-8")).hexdigest()
+    return cache_dir / "index" / f"{key}.json"
+
+
+def content_hashes(paths: set[Path]) -> dict[Path, str]:
+    """The content hash of each readable file."""
+    hashes = {}
+    for path in paths:
+        try:
+            hashes[path] = hashlib.sha256(path.read_bytes()).hexdigest()
+        except OSError as e:
+            logger.debug("Not indexing %s: %s", path, e)
+    return hashes
+
+
+def load_index(path: Path) -> CloneIndex | None:
+    """The clone index of a previous run, or None if there is none (or it cannot be read)."""
+    try:
+        return CloneIndex.model_validate_json(path.read_text(encoding="utf-8"))
+    except FileNotFoundError:
+        return None
+    except (OSError, ValidationError) as e:
+        logger.debug("Ignoring unreadable clone index %s: %s", path, e)
+        return None
+
+
+def save_index(path: Path, index: CloneIndex) -> None:
+    """Write a clone index, replacing the previous one atomically."""
+    try:
+        path.parent.mkdir(parents=True, exist_ok=True)
+        with tempfile.NamedTemporaryFile("w", dir=path.parent, delete=False, encoding="utf-8") as f:
+            f.write(index.model_dump_json())
+        os.replace(f.name, path)
+    except OSError as e:
+        logger.debug("Could not write clone index %s: %s", path, e)
+
+
+def _touches(group: SimilarRegionGroup, paths: set[Path]) -> bool:
+    return any(region.path in paths for region in group.regions)
+
+
+def changed_paths(previous: CloneIndex, hashes: dict[Path, str]) -> set[Path]:
+    """Files added, modified or removed since the previous run."""
+    return {path for path in previous.files.keys() | hashes.keys() if previous.files.get(path) != hashes.get(path)}
+
+
+def _partition(
+    groups: list[SimilarRegionGroup], changed: set[Path]
+) -> tuple[list[SimilarRegionGroup], list[SimilarRegionGroup]]:
+    """Split groups into (untouched by the changed files, with a copy in one of them)."""
+    kept: list[SimilarRegionGroup] = []
+    dropped: list[SimilarRegionGroup] = []
+    for group in groups:
+        (dropped if _touches(group, changed) else kept).append(group)
+    return kept, dropped
+
+
+def _paths(groups: list[SimilarRegionGroup]) -> set[Path]:
+    return {region.path for group in groups for region in group.regions}
+
+
+def plan_update(previous: CloneIndex, hashes: dict[Path, str]) -> tuple[list[SimilarRegionGroup], set[Path]]:
+    """Split the work of an update into (groups kept as they are, files whose clones must be looked for again).
+
+    Groups with a copy in a changed file are dropped, and every file they
+    touched is searched again, so the copies left in unchanged files are
+    still reported if they remain clones of each other.
+    """
+    changed = changed_paths(previous, hashes)
+    kept, dropped = _partition(previous.groups, changed)
+    return kept, (changed | _paths(dropped)) & hashes.keys()
+
+
+def _region_keys(group: SimilarRegionGroup) -> frozenset[tuple[Path, int, int]]:
+    return frozenset((region.path, region.start_line, region.end_line) for region in group.regions)
+
+
+def merge_groups(kept: list[SimilarRegionGroup], found: list[SimilarRegionGroup]) -> list[SimilarRegionGroup]:
+    """The groups found again, then the kept groups that none of them already covers."""
+    found_keys = [_region_keys(group) for group in found]
+    return found + [group for group in kept if not any(_region_keys(group) <= keys for keys in found_keys)]
+
+
+class IncrementalRun:
+    """One update of a clone index: the groups reused from the previous run and the files to search again."""
+
+    def __init__(self, path: Path, hashes: dict[Path, str]):
+        self.path = path
+        self.hashes = hashes
+        previous = load_index(path)
+        # Without a previous run every file is searched
+        self.kept: list[SimilarRegionGroup] = []
+        self.focus: set[Path] | None = None
+        if previous is not None:
+            self.kept, self.focus = plan_update(previous, hashes)
+            logger.info(
+                "Clone index: reusing %d group(s), searching %d of %d file(s) again",
+                len(self.kept),
+                len(self.focus),
+                len(hashes),
+            )
+
+    def finish(self, found: list[SimilarRegionGroup]) -> list[SimilarRegionGroup]:
+        """Merge the groups found in this run with the reused ones, and save them as the new index."""
+        groups = merge_groups(self.kept, found)
+        save_index(self.path, CloneIndex(files=self.hashes, groups=groups))
+        return groups
@@ -1,92 +1,196 @@
+from typing import Callable
+
+from tree_sitter import Language, Parser
+from tree_sitter_language_pack import get_language, get_parser
+
 from .astro import AstroConfig
 from .base import LanguageConfig
 from .bash import BashConfig
+from .c import CConfig
+from .cpp import CppConfig
+from .csharp import CSharpConfig
 from .css import CSSConfig
+from .dockerfile import DockerfileConfig
 from .go import GoConfig
+from .hcl import HCLConfig
 from .html import HTMLConfig
 from .java import JavaConfig
 from .javascript import JavaScriptConfig
+from .json import JSONConfig
 from .jsx import JsxConfig
 from .kotlin import KotlinConfig
 from .markdown import MarkdownConfig
+from .php import PHPConfig
+from .proto import ProtoConfig
 from .python import PythonConfig
+from .ruby import RubyConfig
 from .rust import RustConfig
 from .sql import SQLConfig
+from .svelte import SvelteConfig
+from .swift import SwiftConfig
+from .template import TemplateConfig, directives_to_comments
+from .thrift import ThriftConfig
 from .tsx import TsxConfig
 from .typescript import TypeScriptConfig
+from .vue import VueConfig
 from .yaml import YAMLConfig
 
 # Registry mapping language names to their configurations
 LANGUAGE_CONFIGS: dict[str, LanguageConfig] = {
     "astro": AstroConfig(),
     "bash": BashConfig(),
+    "c": CConfig(),
+    "cpp": CppConfig(),
+    "csharp": CSharpConfig(),
     "css": CSSConfig(),
+    "dockerfile": DockerfileConfig(),
     "go": GoConfig(),
+    "hcl": HCLConfig(),
     "html": HTMLConfig(),
     "java": JavaConfig(),
     "javascript": JavaScriptConfig(),
+    "json": JSONConfig(),
     "jsx": JsxConfig(),
     "kotlin": KotlinConfig(),
     "markdown": MarkdownConfig(),
+    "php": PHPConfig(),
+    "proto": ProtoConfig(),
     "python": PythonConfig(),
+    "ruby": RubyConfig(),
     "rust": RustConfig(),
     "sql": SQLConfig(),
+    "svelte": SvelteConfig(),
+    "swift": SwiftConfig(),
+    "template": TemplateConfig(),
+    "thrift": ThriftConfig(),
     "tsx": TsxConfig(),
     "typescript": TypeScriptConfig(),
+    "vue": VueConfig(),
     "yaml": YAMLConfig(),
 }
 
 LANGUAGE_EXTENSIONS: dict[str, list[str]] = {
     "astro": [".astro"],
     "bash": [".sh", ".bash"],
+    "c": [".c"],
+    # Headers may hold either language; the C++ grammar parses plain C headers as well
+    "cpp": [".cpp", ".cc", ".cxx", ".h", ".hpp", ".hh", ".hxx"],
+    "csharp": [".cs"],
     "css": [".css"],
+    "dockerfile": [".dockerfile"],
     "go": [".go"],
+    "hcl": [".tf", ".tfvars", ".hcl"],
     "html": [".html", ".htm"],
     "java": [".java"],
     "javascript": [".js"],
+    "json": [".json"],
     "jsx": [".jsx"],
     "kotlin": [".kt", ".kts"],
-    "markdown": [".md", ".markdown"],
+    "markdown": [".md", ".markdown", ".mdx"],
+    "php": [".php", ".phtml"],
+    "proto": [".proto"],
     "python": [".py"],
+    "ruby": [".rb", ".rake"],
     "rust": [".rs"],
     "sql": [".sql"],
+    "svelte": [".svelte"],
+    "swift": [".swift"],
+    "template": [".erb", ".ejs", ".j2", ".jinja", ".jinja2", ".tmpl", ".gotmpl", ".gohtml"],
+    "thrift": [".thrift"],
     "tsx": [".tsx"],
     "typescript": [".ts"],
+    "vue": [".vue"],
     "yaml": [".yaml", ".yml"],
 }
 
+# Languages detected from a file name glob rather than an extension (Dockerfile.prod, ...).
+LANGUAGE_FILENAMES: dict[str, list[str]] = {
+    "dockerfile": ["Dockerfile", "Dockerfile.*", "Containerfile", "Containerfile.*"],
+}
+
 # Languages that share a tree-sitter grammar with another language.
 # JSX is parsed with the JavaScript grammar since no separate jsx grammar exists.
+# Templates are parsed with the HTML grammar once their directives are rewritten (see template.py).
 GRAMMAR_ALIASES: dict[str, str] = {
     "jsx": "javascript",
+    "template": "html",
 }
 
 
+# Languages whose source is rewritten before parsing; line numbers must be preserved.
+SOURCE_PREPROCESSORS: dict[str, Callable[[bytes], bytes]] = {
+    "template": directives_to_comments,
+}
+
+
+# Grammars loaded from plugins (see plugins.py); every other grammar comes from tree-sitter-language-pack
+PLUGIN_GRAMMARS: dict[str, Language] = {}
+
+
 def get_grammar(language: str) -> str:
     """Return the tree-sitter grammar name for a language."""
     return GRAMMAR_ALIASES.get(language, language)
 
 
+def grammar_language(grammar: str) -> Language:
+    """Return the tree-sitter language of a grammar, built in or loaded from a plugin."""
+    if grammar in PLUGIN_GRAMMARS:
+        return PLUGIN_GRAMMARS[grammar]
+    return get_language(grammar)  # type: ignore[arg-type]
+
+
+def grammar_parser(grammar: str) -> Parser:
+    """Return a parser for a grammar, built in or loaded from a plugin."""
+    if grammar in PLUGIN_GRAMMARS:
+        return Parser(PLUGIN_GRAMMARS[grammar])
+    return get_parser(grammar)  # type: ignore[arg-type]
+
+
+def preprocess_source(language: str, source: bytes) -> bytes:
+    """Return the source to parse for a file of this language."""
+    preprocessor = SOURCE_PREPROCESSORS.get(language)
+    return preprocessor(source) if preprocessor else source
+
+
 __all__ = [
     "LanguageConfig",
     "LANGUAGE_CONFIGS",
     "LANGUAGE_EXTENSIONS",
+    "LANGUAGE_FILENAMES",
     "GRAMMAR_ALIASES",
+    "PLUGIN_GRAMMARS",
     "get_grammar",
+    "grammar_language",
+    "grammar_parser",
+    "preprocess_source",
     "PythonConfig",
+    "ProtoConfig",
+    "PHPConfig",
     "JavaScriptConfig",
+    "JSONConfig",
     "JsxConfig",
     "TypeScriptConfig",
     "TsxConfig",
     "HTMLConfig",
+    "HCLConfig",
     "CSSConfig",
+    "CSharpConfig",
+    "DockerfileConfig",
     "JavaConfig",
     "KotlinConfig",
     "SQLConfig",
+    "SvelteConfig",
+    "SwiftConfig",
+    "TemplateConfig",
+    "ThriftConfig",
     "BashConfig",
+    "CConfig",
+    "CppConfig",
+    "RubyConfig",
     "RustConfig",
     "GoConfig",
     "MarkdownConfig",
     "AstroConfig",
+    "VueConfig",
     "YAMLConfig",
 ]
@@ -22,6 +22,9 @@ _IDENTIFIER_NODES = frozenset(
     {"identifier", "property_identifier", "type_identifier", "field_identifier"}
 )
 
+# Name of the --normalize-signature-types rules, by which verification recognizes them
+SIGNATURE_TYPE_RULE_NAME = "Normalize signature types"
+
 # In a tree-sitter query a named node type is a lowercase word directly after an
 # opening paren, e.g. "(function_declaration". Extracting these as whole tokens
 # (rather than substring-scanning the query) avoids matching predicate strings
@@ -93,6 +96,43 @@ class LanguageConfig(ABC):
         """Return list of regions to perform similarity comparisons for this language."""
         pass
 
+    def get_qualifier_rules(self) -> list[Rule]:
+        """Return rules that strip access/storage modifiers (enabled by --ignore-qualifiers)."""
+        return []
+
+    def get_preprocessor_rules(self) -> list[Rule]:
+        """Return rules that flatten preprocessor conditionals (enabled by --flatten-preprocessor)."""
+        return []
+
+    def get_signature_type_rules(self) -> list[Rule]:
+        """Return rules that normalize parameter/return types (enabled by --normalize-signature-types)."""
+        return []
+
+    def get_identifier_rules(self) -> list[Rule]:
+        """Return rules that abstract variable/function names (enabled by --normalize identifiers)."""
+        return []
+
+    def get_literal_rules(self) -> list[Rule]:
+        """Return rules that abstract literal values (enabled by --normalize literals)."""
+        return []
+
+    def get_comment_rules(self) -> list[Rule]:
+        """Return rules that strip comments and doc comments (enabled by --normalize comments).
+
+        These are the default ruleset's rules removing comment nodes, so that
+        rulesets without them (e.g. ``none``) can still ignore comments.
+        """
+        return [rule for rule in self.get_default_rules() if _removes_comments(rule)]
+
+    def get_neutral_node_types(self) -> dict[str, str]:
+        """Map node types onto language-neutral categories (used by --cross-language)."""
+        return {}
+
+
+def _removes_comments(rule: Rule) -> bool:
+    """True if a rule removes comment nodes (comment, line_comment, block_comment, ...)."""
+    return rule.action is RuleAction.REMOVE and any("comment" in node for node in _query_node_types(rule.query))
+
 
 def _rule_anonymizes_name(rule: Rule, language: str, node_types: tuple[str, ...]) -> bool:
     """True if this rule replaces an identifier on one of the given declaration nodes.
@@ -125,3 +165,8 @@ def rules_anonymize_region_name(
     if not node_types:
         return False
     return any(_rule_anonymizes_name(rule, language, node_types) for rule in rules)
+
+
+def rules_normalize_signature_types(rules: list[Rule], language: str) -> bool:
+    """Return True if the ACTIVE rules normalize this language's signature types."""
+    return any(rule.name == SIGNATURE_TYPE_RULE_NAME and rule.matches_language(language) for rule in rules)
@@ -0,0 +1,107 @@
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import LanguageConfig, RegionExtractionRule
+
+
+class CConfig(LanguageConfig):
+    """Configuration for C."""
+
+    language = "c"
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore comments",
+                languages=[self.language],
+                query="(comment) @comment",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Ignore includes",
+                languages=[self.language],
+                query="(preproc_include) @include",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Anonymize function names",
+                languages=[self.language],
+                query="(function_declarator declarator: (identifier) @name)",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "FUNC"},
+            ),
+        ]
+
+    def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
+            *self.get_default_rules(),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize identifiers",
+                languages=[self.language],
+                query="[(identifier) (field_identifier)] @var",
+                action=RuleAction.ANONYMIZE,
+                params={"prefix": "VAR"},
+            ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize literals",
+                languages=[self.language],
+                query="[(number_literal) (string_content) (character)] @lit",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<LIT>"},
+            ),
+        ]
+
+    def get_qualifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore storage and type qualifiers",
+                languages=[self.language],
+                query="[(storage_class_specifier) (type_qualifier)] @qualifier",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_preprocessor_rules(self) -> list[Rule]:
+        return [
+            # Only the first branch of a conditional is compared, for every conditional
+            Rule(
+                name="Ignore alternative preprocessor branches",
+                languages=[self.language],
+                query="[(preproc_else) (preproc_elif)] @branch",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Ignore preprocessor conditions",
+                languages=[self.language],
+                query=(
+                    "["
+                    '(preproc_ifdef name: (identifier) @condition) (preproc_if condition: (_) @condition)'
+                    ' (preproc_ifdef ["#ifdef" "#ifndef"] @condition) (preproc_if "#if" @condition)'
+                    "]"
+                ),
+                action=RuleAction.REMOVE,
+            ),
+            # #if / #ifdef / #ifndef guards compare alike
+            Rule(
+                name="Unify preprocessor conditionals",
+                languages=[self.language],
+                query="[(preproc_if) (preproc_ifdef)] @conditional",
+                action=RuleAction.REPLACE_NODE_TYPE,
+                params={"token": "<PREPROC>"},
+            ),
+        ]
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            RegionExtractionRule.from_node_type("function_definition"),
+            RegionExtractionRule(query="(struct_specifier body: (field_declaration_list)) @region", label="struct"),
+        ]
@@ -0,0 +1,43 @@
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import RegionExtractionRule
+from .c import CConfig
+
+
+class CppConfig(CConfig):
+    """Configuration for C++ (shares the C rules; adds classes, methods and namespaces)."""
+
+    language = "cpp"
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            *super().get_default_rules(),
+            Rule(
+                name="Anonymize method names",
+                languages=["cpp"],
+                query=(
+                    "[(function_declarator declarator: (field_identifier) @name)"
+                    " (function_declarator declarator: (qualified_identifier name: (identifier) @name))]"
+                ),
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "FUNC"},
+            ),
+        ]
+
+    def get_qualifier_rules(self) -> list[Rule]:
+        return [
+            *super().get_qualifier_rules(),
+            Rule(
+                name="Ignore access specifiers",
+                languages=["cpp"],
+                query="(access_specifier) @qualifier",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            *super().get_region_extraction_rules(),
+            RegionExtractionRule(query="(class_specifier body: (field_declaration_list)) @region", label="class"),
+            RegionExtractionRule.from_node_type("namespace_definition"),
+        ]
@@ -0,0 +1,100 @@
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import LanguageConfig, RegionExtractionRule
+
+
+class CSharpConfig(LanguageConfig):
+    """Configuration for C# language."""
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore using directives",
+                languages=["csharp"],
+                query="(using_directive) @using",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Ignore comments",
+                languages=["csharp"],
+                query="(comment) @comment",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Ignore attributes",
+                languages=["csharp"],
+                query="(attribute_list) @attribute",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Anonymize method names",
+                languages=["csharp"],
+                query="(method_declaration name: (identifier) @func)",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "FUNC"},
+            ),
+            Rule(
+                name="Anonymize type names",
+                languages=["csharp"],
+                query=(
+                    "[(class_declaration name: (identifier) @type) (struct_declaration name: (identifier) @type) "
+                    "(interface_declaration name: (identifier) @type) (record_declaration name: (identifier) @type)]"
+                ),
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "TYPE"},
+            ),
+        ]
+
+    def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
+            *self.get_default_rules(),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize identifiers",
+                languages=["csharp"],
+                query="(identifier) @id",
+                action=RuleAction.ANONYMIZE,
+                params={"prefix": "VAR"},
+            ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize literals",
+                languages=["csharp"],
+                query="[(string_literal_content) (integer_literal) (real_literal) (boolean_literal)] @lit",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<LIT>"},
+            ),
+        ]
+
+    def get_qualifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore access and storage modifiers",
+                languages=["csharp"],
+                query="(modifier) @modifier",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            RegionExtractionRule.from_node_type("method_declaration"),
+            RegionExtractionRule.from_node_type("constructor_declaration"),
+            RegionExtractionRule.from_node_type("property_declaration"),
+            # Each part of a partial class (often spread across a hand-written and a generated file) is a
+            # region of its own; listed first, it takes the place of the class_declaration region of the part
+            RegionExtractionRule(query='(class_declaration (modifier "partial")) @region', label="partial_class"),
+            RegionExtractionRule.from_node_type("class_declaration"),
+            RegionExtractionRule.from_node_type("struct_declaration"),
+            RegionExtractionRule.from_node_type("interface_declaration"),
+            RegionExtractionRule.from_node_type("record_declaration"),
+            RegionExtractionRule(query="(query_expression) @region", label="linq"),
+        ]
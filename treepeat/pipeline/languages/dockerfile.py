@@ -0,0 +1,67 @@
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import LanguageConfig, RegionExtractionRule
+
+
+class DockerfileConfig(LanguageConfig):
+    """Configuration for Dockerfiles (``Dockerfile``, ``Dockerfile.*``, ``*.dockerfile``, ``Containerfile``)."""
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore comments",
+                languages=["dockerfile"],
+                query="(comment) @comment",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                # Stages built on a pinned base image compare equal to the same stage on another tag
+                name="Ignore image tags",
+                languages=["dockerfile"],
+                query="[(image_tag) (image_digest)] @tag",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
+            *self.get_default_rules(),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize variables",
+                languages=["dockerfile"],
+                query="(variable) @var",
+                action=RuleAction.ANONYMIZE,
+                params={"prefix": "VAR"},
+            ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize strings",
+                languages=["dockerfile"],
+                query="[(double_quoted_string) (single_quoted_string) (json_string)] @str",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<STR>"},
+            ),
+        ]
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            # The whole instruction sequence: Dockerfiles that repeat the same RUN/COPY steps
+            # on top of different base images are candidates for a shared base image
+            RegionExtractionRule(query="(source_file) @region", label="dockerfile"),
+            # Long RUN chains are compared as shell so they also match the equivalent script
+            RegionExtractionRule(
+                query="(run_instruction) @region",
+                label="run",
+                target_language="bash",
+                content_query="(shell_command) @content",
+            ),
+        ]
@@ -1,6 +1,6 @@
 from treepeat.pipeline.rules.models import Rule, RuleAction
 
-from .base import LanguageConfig, RegionExtractionRule
+from .base import SIGNATURE_TYPE_RULE_NAME, LanguageConfig, RegionExtractionRule
 
 
 class GoConfig(LanguageConfig):
@@ -52,20 +52,26 @@ class GoConfig(LanguageConfig):
     def get_loose_rules(self) -> list[Rule]:
         return [
             *self.get_default_rules(),
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
             Rule(
-                name="Anonymize string literals",
+                name="Anonymize binary expressions",
                 languages=["go"],
-                query="[(interpreted_string_literal) (raw_string_literal)] @str",
-                action=RuleAction.REPLACE_VALUE,
-                params={"value": "<STR>"},
+                query="(binary_expression) @binop",
+                action=RuleAction.REPLACE_NODE_TYPE,
+                params={"token": "<BINOP>"},
             ),
             Rule(
-                name="Anonymize numeric literals",
+                name="Anonymize unary expressions",
                 languages=["go"],
-                query="[(int_literal) (float_literal) (imaginary_literal) (rune_literal)] @num",
-                action=RuleAction.REPLACE_VALUE,
-                params={"value": "<NUM>"},
+                query="(unary_expression) @unop",
+                action=RuleAction.REPLACE_NODE_TYPE,
+                params={"token": "<UNOP>"},
             ),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
+        return [
             Rule(
                 name="Anonymize identifiers",
                 languages=["go"],
@@ -80,19 +86,41 @@ class GoConfig(LanguageConfig):
                 action=RuleAction.ANONYMIZE,
                 params={"prefix": "FIELD"},
             ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
             Rule(
-                name="Anonymize binary expressions",
+                name="Anonymize string literals",
                 languages=["go"],
-                query="(binary_expression) @binop",
-                action=RuleAction.REPLACE_NODE_TYPE,
-                params={"token": "<BINOP>"},
+                query="[(interpreted_string_literal) (raw_string_literal)] @str",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<STR>"},
             ),
             Rule(
-                name="Anonymize unary expressions",
+                name="Anonymize numeric literals",
                 languages=["go"],
-                query="(unary_expression) @unop",
-                action=RuleAction.REPLACE_NODE_TYPE,
-                params={"token": "<UNOP>"},
+                query="[(int_literal) (float_literal) (imaginary_literal) (rune_literal)] @num",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<NUM>"},
+            ),
+        ]
+
+    def get_signature_type_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name=SIGNATURE_TYPE_RULE_NAME,
+                languages=["go"],
+                query="""[
+                    (function_declaration result: (type_identifier) @type)
+                    (function_declaration result: (_ (type_identifier) @type))
+                    (method_declaration result: (type_identifier) @type)
+                    (method_declaration result: (_ (type_identifier) @type))
+                    (parameter_declaration type: (type_identifier) @type)
+                    (parameter_declaration type: (_ (type_identifier) @type))
+                ]""",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<TYPE>"},
             ),
         ]
 
@@ -102,3 +130,33 @@ class GoConfig(LanguageConfig):
             RegionExtractionRule.from_node_type("method_declaration"),
             RegionExtractionRule.from_node_type("type_declaration"),
         ]
+
+    def get_neutral_node_types(self) -> dict[str, str]:
+        return {
+            **dict.fromkeys(["function_declaration", "method_declaration", "func_literal"], "<FUNC>"),
+            **dict.fromkeys(
+                ["assignment_statement", "short_var_declaration", "inc_statement", "dec_statement"], "<ASSIGN>"
+            ),
+            **dict.fromkeys(["identifier", "field_identifier"], "<ID>"),
+            **dict.fromkeys(
+                [
+                    "interpreted_string_literal",
+                    "raw_string_literal",
+                    "int_literal",
+                    "float_literal",
+                    "true",
+                    "false",
+                    "nil",
+                ],
+                "<LIT>",
+            ),
+            **dict.fromkeys(["binary_expression", "unary_expression"], "<OP>"),
+            "if_statement": "<IF>",
+            "for_statement": "<LOOP>",
+            "call_expression": "<CALL>",
+            "return_statement": "<RETURN>",
+            "break_statement": "<BREAK>",
+            "continue_statement": "<CONTINUE>",
+            "selector_expression": "<ATTR>",
+            "index_expression": "<INDEX>",
+        }
@@ -0,0 +1,49 @@
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import LanguageConfig, RegionExtractionRule
+
+
+class HCLConfig(LanguageConfig):
+    """Configuration for HCL (Terraform ``.tf``, ``.tfvars`` and ``.hcl``)."""
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore comments",
+                languages=["hcl"],
+                query="(comment) @comment",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                # The last label names the block (resource "aws_instance" "web_prod", module "vpc_staging"),
+                # so copies of an environment that only renamed their blocks still match
+                name="Anonymize block names",
+                languages=["hcl"],
+                query="(block (string_lit (template_literal) @name) . (block_start))",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "NAME"},
+            ),
+        ]
+
+    def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_default_rules(),
+            *self.get_literal_rules(),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize literals",
+                languages=["hcl"],
+                query="[(template_literal) (numeric_lit) (bool_lit)] @lit",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<LIT>"},
+            ),
+        ]
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            # resource, data, module, variable, locals ... and their nested blocks
+            RegionExtractionRule.from_node_type("block"),
+        ]
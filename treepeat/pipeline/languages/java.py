@@ -1,6 +1,6 @@
 from treepeat.pipeline.rules.models import Rule, RuleAction
 
-from .base import LanguageConfig, RegionExtractionRule
+from .base import SIGNATURE_TYPE_RULE_NAME, LanguageConfig, RegionExtractionRule
 
 
 class JavaConfig(LanguageConfig):
@@ -39,6 +39,12 @@ class JavaConfig(LanguageConfig):
     def get_loose_rules(self) -> list[Rule]:
         return [
             *self.get_default_rules(),
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
+        return [
             Rule(
                 name="Anonymize identifiers",
                 languages=["java"],
@@ -46,6 +52,10 @@ class JavaConfig(LanguageConfig):
                 action=RuleAction.ANONYMIZE,
                 params={"prefix": "VAR"},
             ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
             Rule(
                 name="Anonymize literals",
                 languages=["java"],
@@ -58,8 +68,76 @@ class JavaConfig(LanguageConfig):
             ),
         ]
 
+    def get_qualifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore access and storage modifiers",
+                languages=["java"],
+                query=(
+                    '(modifiers ["public" "protected" "private" "static" "final" "abstract" '
+                    '"synchronized" "native" "transient" "volatile" "strictfp"] @modifier)'
+                ),
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_signature_type_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name=SIGNATURE_TYPE_RULE_NAME,
+                languages=["java"],
+                query="""[
+                    (method_declaration type: (type_identifier) @type)
+                    (formal_parameter type: (type_identifier) @type)
+                ]""",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<TYPE>"},
+            ),
+            Rule(
+                name="Ignore primitive signature type keywords",
+                languages=["java"],
+                query="""[
+                    (method_declaration type: (integral_type _ @keyword))
+                    (method_declaration type: (floating_point_type _ @keyword))
+                    (formal_parameter type: (integral_type _ @keyword))
+                    (formal_parameter type: (floating_point_type _ @keyword))
+                ]""",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
     def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
         return [
             RegionExtractionRule.from_node_type("method_declaration"),
             RegionExtractionRule.from_node_type("class_declaration"),
         ]
+
+    def get_neutral_node_types(self) -> dict[str, str]:
+        return {
+            **dict.fromkeys(["method_declaration", "constructor_declaration", "lambda_expression"], "<FUNC>"),
+            **dict.fromkeys(["if_statement", "ternary_expression"], "<IF>"),
+            **dict.fromkeys(["for_statement", "enhanced_for_statement", "while_statement", "do_statement"], "<LOOP>"),
+            **dict.fromkeys(["method_invocation", "object_creation_expression"], "<CALL>"),
+            **dict.fromkeys(["assignment_expression", "variable_declarator", "update_expression"], "<ASSIGN>"),
+            **dict.fromkeys(
+                [
+                    "string_literal",
+                    "character_literal",
+                    "decimal_integer_literal",
+                    "decimal_floating_point_literal",
+                    "true",
+                    "false",
+                    "null_literal",
+                ],
+                "<LIT>",
+            ),
+            **dict.fromkeys(["binary_expression", "unary_expression"], "<OP>"),
+            "return_statement": "<RETURN>",
+            "break_statement": "<BREAK>",
+            "continue_statement": "<CONTINUE>",
+            "throw_statement": "<THROW>",
+            "try_statement": "<TRY>",
+            "identifier": "<ID>",
+            "field_access": "<ATTR>",
+            "array_access": "<INDEX>",
+        }
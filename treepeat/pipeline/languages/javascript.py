@@ -47,6 +47,32 @@ class JavaScriptConfig(LanguageConfig):
         ]
 
     def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
+            Rule(
+                name="Anonymize collections",
+                languages=["javascript", "typescript", "tsx", "jsx"],
+                query="[(array) (object)] @coll",
+                target="coll",
+                action=RuleAction.REPLACE_NODE_TYPE,
+                params={"token": "<COLL>"},
+            ),
+            Rule(
+                name="Anonymize expressions",
+                languages=["javascript", "typescript", "tsx", "jsx"],
+                query=(
+                    "[(binary_expression) (unary_expression) (update_expression) "
+                    "(assignment_expression) (ternary_expression)] @exp"
+                ),
+                target="exp",
+                action=RuleAction.REPLACE_NODE_TYPE,
+                params={"token": "<EXP>"},
+            ),
+            *self.get_default_rules(),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
         return [
             Rule(
                 name="Anonymize identifiers",
@@ -56,6 +82,10 @@ class JavaScriptConfig(LanguageConfig):
                 action=RuleAction.ANONYMIZE,
                 params={"prefix": "VAR"},
             ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
             Rule(
                 name="Anonymize literal values",
                 languages=["javascript", "typescript", "tsx", "jsx"],
@@ -64,26 +94,22 @@ class JavaScriptConfig(LanguageConfig):
                 action=RuleAction.REPLACE_VALUE,
                 params={"value": "<LIT>"},
             ),
+        ]
+
+    def get_qualifier_rules(self) -> list[Rule]:
+        return [
             Rule(
-                name="Anonymize collections",
+                name="Ignore static modifiers",
                 languages=["javascript", "typescript", "tsx", "jsx"],
-                query="[(array) (object)] @coll",
-                target="coll",
-                action=RuleAction.REPLACE_NODE_TYPE,
-                params={"token": "<COLL>"},
+                query='(method_definition "static" @modifier)',
+                action=RuleAction.REMOVE,
             ),
             Rule(
-                name="Anonymize expressions",
-                languages=["javascript", "typescript", "tsx", "jsx"],
-                query=(
-                    "[(binary_expression) (unary_expression) (update_expression) "
-                    "(assignment_expression) (ternary_expression)] @exp"
-                ),
-                target="exp",
-                action=RuleAction.REPLACE_NODE_TYPE,
-                params={"token": "<EXP>"},
+                name="Ignore accessibility modifiers",
+                languages=["typescript", "tsx"],
+                query="(accessibility_modifier) @modifier",
+                action=RuleAction.REMOVE,
             ),
-            *self.get_default_rules(),
         ]
 
     def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
@@ -95,3 +121,32 @@ class JavaScriptConfig(LanguageConfig):
             RegionExtractionRule.from_node_type("method_definition"),
             RegionExtractionRule.from_node_type("class_declaration"),
         ]
+
+    def get_neutral_node_types(self) -> dict[str, str]:
+        return {
+            **dict.fromkeys(
+                ["function_declaration", "function_expression", "arrow_function", "method_definition"], "<FUNC>"
+            ),
+            **dict.fromkeys(["if_statement", "ternary_expression"], "<IF>"),
+            **dict.fromkeys(["for_statement", "for_in_statement", "while_statement", "do_statement"], "<LOOP>"),
+            **dict.fromkeys(
+                [
+                    "assignment_expression",
+                    "augmented_assignment_expression",
+                    "variable_declarator",
+                    "update_expression",
+                ],
+                "<ASSIGN>",
+            ),
+            **dict.fromkeys(["identifier", "property_identifier", "shorthand_property_identifier"], "<ID>"),
+            **dict.fromkeys(["string", "template_string", "number", "true", "false", "null", "undefined"], "<LIT>"),
+            **dict.fromkeys(["binary_expression", "unary_expression"], "<OP>"),
+            "call_expression": "<CALL>",
+            "return_statement": "<RETURN>",
+            "break_statement": "<BREAK>",
+            "continue_statement": "<CONTINUE>",
+            "throw_statement": "<THROW>",
+            "try_statement": "<TRY>",
+            "member_expression": "<ATTR>",
+            "subscript_expression": "<INDEX>",
+        }
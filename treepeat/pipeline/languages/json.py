@@ -0,0 +1,53 @@
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import LanguageConfig, RegionExtractionRule
+
+
+class JSONConfig(LanguageConfig):
+    """Configuration for JSON (config files, fixtures, manifests).
+
+    Objects are compared on their parsed structure; with ``--canonicalize`` the
+    order of their keys is ignored as well.
+    """
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore comments",
+                languages=["json"],
+                query="(comment) @comment",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_default_rules(),
+            *self.get_literal_rules(),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize scalar values",
+                languages=["json"],
+                query="[(number) (true) (false) (null)] @lit",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<LIT>"},
+            ),
+            Rule(
+                # Only values: keys carry the structure being compared
+                name="Anonymize string values",
+                languages=["json"],
+                query="[(pair value: (string (string_content) @lit)) (array (string (string_content) @lit))]",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<LIT>"},
+            ),
+        ]
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            RegionExtractionRule(query="(pair value: [(object) (array)]) @region", label="pair"),
+            RegionExtractionRule(query="(document (object) @region)", label="document"),
+            RegionExtractionRule.from_node_type("array"),
+        ]
@@ -37,6 +37,13 @@ class KotlinConfig(LanguageConfig):
         ]
 
     def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
+            *self.get_default_rules(),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
         return [
             Rule(
                 name="Anonymize identifiers",
@@ -45,6 +52,10 @@ class KotlinConfig(LanguageConfig):
                 action=RuleAction.ANONYMIZE,
                 params={"prefix": "VAR"},
             ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
             Rule(
                 name="Anonymize literals",
                 languages=["kotlin"],
@@ -55,11 +66,25 @@ class KotlinConfig(LanguageConfig):
                 action=RuleAction.REPLACE_VALUE,
                 params={"value": "<LIT>"},
             ),
-            *self.get_default_rules(),
+        ]
+
+    def get_qualifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore access and storage modifiers",
+                languages=["kotlin"],
+                query=(
+                    "[(visibility_modifier) (inheritance_modifier) "
+                    "(member_modifier) (function_modifier)] @modifier"
+                ),
+                action=RuleAction.REMOVE,
+            ),
         ]
 
     def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
         return [
             RegionExtractionRule.from_node_type("function_declaration"),
             RegionExtractionRule.from_node_type("class_declaration"),
+            RegionExtractionRule.from_node_type("object_declaration"),
+            RegionExtractionRule.from_node_type("when_expression"),
         ]
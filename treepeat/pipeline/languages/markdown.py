@@ -16,8 +16,22 @@ _INFO_STRING_ALIASES = {
     "py": "python",
     "sh": "bash",
     "shell": "bash",
+    "zsh": "bash",
+    "golang": "go",
+    "jsonc": "json",
+    "rb": "ruby",
+    "c++": "cpp",
+    "cxx": "cpp",
+    "h": "c",
+    "cs": "csharp",
+    "c#": "csharp",
     "rs": "rust",
     "kt": "kotlin",
+    "docker": "dockerfile",
+    "containerfile": "dockerfile",
+    "protobuf": "proto",
+    "tf": "hcl",
+    "terraform": "hcl",
     "md": "markdown",
 }
 
@@ -0,0 +1,101 @@
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import LanguageConfig, RegionExtractionRule
+
+
+class PHPConfig(LanguageConfig):
+    """Configuration for PHP language.
+
+    The grammar also parses HTML outside of <?php ?> tags (as text nodes), so
+    mixed HTML+PHP templates are scanned as a whole.
+    """
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore namespace and use declarations",
+                languages=["php"],
+                query="[(namespace_definition) (namespace_use_declaration)] @import",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Ignore include and require expressions",
+                languages=["php"],
+                query=(
+                    "[(include_expression) (include_once_expression) "
+                    "(require_expression) (require_once_expression)] @include"
+                ),
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Ignore comments",
+                languages=["php"],
+                query="(comment) @comment",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Anonymize function names",
+                languages=["php"],
+                query="[(function_definition name: (name) @func) (method_declaration name: (name) @func)]",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "FUNC"},
+            ),
+            Rule(
+                name="Anonymize class names",
+                languages=["php"],
+                query=(
+                    "[(class_declaration name: (name) @name) (interface_declaration name: (name) @name) "
+                    "(trait_declaration name: (name) @name)]"
+                ),
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "CLASS"},
+            ),
+        ]
+
+    def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
+            *self.get_default_rules(),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize variables",
+                languages=["php"],
+                query="(variable_name (name) @var)",
+                action=RuleAction.ANONYMIZE,
+                params={"prefix": "VAR"},
+            ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize literals",
+                languages=["php"],
+                query="[(string_content) (integer) (float) (boolean)] @lit",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<LIT>"},
+            ),
+        ]
+
+    def get_qualifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore access and storage modifiers",
+                languages=["php"],
+                query="[(visibility_modifier) (static_modifier) (final_modifier) (abstract_modifier)] @modifier",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            RegionExtractionRule.from_node_type("function_definition"),
+            RegionExtractionRule.from_node_type("method_declaration"),
+            RegionExtractionRule.from_node_type("class_declaration"),
+            RegionExtractionRule.from_node_type("interface_declaration"),
+            RegionExtractionRule.from_node_type("trait_declaration"),
+        ]
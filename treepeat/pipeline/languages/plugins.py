@@ -0,0 +1,149 @@
+"""Grammar plugins: tree-sitter grammars for languages treepeat does not ship, loaded at startup.
+
+Each subdirectory of the plugins directory holding a ``plugin.toml`` is a
+plugin. The manifest names the language, the compiled grammar and the files
+it parses, and the regions and default rules of the language:
+
+    name = "zig"
+    grammar = "zig.so"           # built with `tree-sitter build`
+    extensions = [".zig"]
+    regions = ["function_declaration", "test_declaration"]
+
+    [[rules]]
+    name = "Ignore comments"
+    query = "(comment) @comment"
+    action = "remove"
+"""
+
+import ctypes
+import logging
+import os
+import tomllib
+from pathlib import Path
+from typing import Any
+
+from tree_sitter import Language
+
+from treepeat.pipeline.rules.models import Rule
+from treepeat.pipeline.rules.parser import RuleParseError, parse_rules
+
+from . import LANGUAGE_CONFIGS, LANGUAGE_EXTENSIONS, LANGUAGE_FILENAMES, PLUGIN_GRAMMARS
+from .base import LanguageConfig, RegionExtractionRule
+
+logger = logging.getLogger(__name__)
+
+MANIFEST_NAME = "plugin.toml"
+
+# Name a tree-sitter language capsule must carry to be accepted by tree_sitter.Language
+_CAPSULE_NAME = b"tree_sitter.Language"
+
+# Manifest of each registered plugin, so loading the same directory again is a no-op
+_registered: dict[str, Path] = {}
+
+
+class PluginError(Exception):
+    """A grammar plugin that cannot be loaded."""
+
+
+def default_plugins_dir() -> Path:
+    """``$XDG_CONFIG_HOME/treepeat/plugins``, or ``~/.config/treepeat/plugins``."""
+    return Path(os.environ.get("XDG_CONFIG_HOME") or Path.home() / ".config") / "treepeat" / "plugins"
+
+
+class PluginLanguageConfig(LanguageConfig):
+    """The regions and default rules a plugin manifest declares for its language."""
+
+    def __init__(self, regions: list[str], rules: list[Rule]):
+        self._regions = regions
+        self._rules = rules
+
+    def get_default_rules(self) -> list[Rule]:
+        return self._rules
+
+    def get_loose_rules(self) -> list[Rule]:
+        return self._rules
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [RegionExtractionRule.from_node_type(node_type) for node_type in self._regions]
+
+
+def load_grammar(library: Path, symbol: str) -> Language:
+    """Load a tree-sitter grammar compiled to a shared library (``tree-sitter build``)."""
+    if library.suffix == ".wasm":
+        raise PluginError(
+            f"{library}: the tree-sitter Python bindings cannot load WASM grammars; "
+            "build the grammar as a shared library with `tree-sitter build` instead"
+        )
+    try:
+        entry = getattr(ctypes.CDLL(str(library)), symbol)
+    except (OSError, AttributeError) as e:
+        raise PluginError(f"cannot load {symbol} from {library}: {e}") from e
+    entry.restype = ctypes.c_void_p
+    new_capsule = ctypes.pythonapi.PyCapsule_New
+    new_capsule.restype = ctypes.py_object
+    new_capsule.argtypes = (ctypes.c_void_p, ctypes.c_char_p, ctypes.c_void_p)
+    return Language(new_capsule(entry(), _CAPSULE_NAME, None))
+
+
+def _read_manifest(path: Path) -> dict[str, Any]:
+    try:
+        with path.open("rb") as f:
+            manifest = tomllib.load(f)
+    except (OSError, tomllib.TOMLDecodeError) as e:
+        raise PluginError(f"cannot read {path}: {e}") from e
+    for key in ("name", "grammar", "extensions"):
+        if key not in manifest:
+            raise PluginError(f"{path} is missing '{key}'")
+    return manifest
+
+
+def _manifest_rules(manifest: dict[str, Any], path: Path) -> list[Rule]:
+    """The default rules of a manifest, applying to its language unless they say otherwise."""
+    name = manifest["name"]
+    try:
+        return parse_rules([{"languages": [name], **rule} for rule in manifest.get("rules", [])], str(path))
+    except RuleParseError as e:
+        raise PluginError(f"{path}: {e}") from e
+
+
+def _warn_taken_extensions(name: str, extensions: list[str]) -> None:
+    """Extensions of a built-in language keep being parsed by it."""
+    taken = {ext for exts in LANGUAGE_EXTENSIONS.values() for ext in exts} & {ext.lower() for ext in extensions}
+    if taken:
+        logger.warning("Plugin %s: %s already belong to another language", name, ", ".join(sorted(taken)))
+
+
+def _register(manifest: dict[str, Any], path: Path) -> str:
+    name = manifest["name"]
+    if _registered.get(name) == path:
+        return name
+    if name in LANGUAGE_CONFIGS:
+        raise PluginError(f"{path}: language '{name}' is already defined")
+    _warn_taken_extensions(name, manifest["extensions"])
+    grammar = load_grammar(path.parent / manifest["grammar"], manifest.get("symbol", f"tree_sitter_{name}"))
+    LANGUAGE_CONFIGS[name] = PluginLanguageConfig(manifest.get("regions", []), _manifest_rules(manifest, path))
+    LANGUAGE_EXTENSIONS[name] = [ext.lower() for ext in manifest["extensions"]]
+    if manifest.get("filenames"):
+        LANGUAGE_FILENAMES[name] = list(manifest["filenames"])
+    PLUGIN_GRAMMARS[name] = grammar
+    _registered[name] = path
+    return name
+
+
+def load_plugins(directory: Path, strict: bool = True) -> list[str]:
+    """Register the grammar plugins under a directory, returning the languages they add.
+
+    A broken plugin raises PluginError, or unless ``strict`` is skipped with a warning.
+    """
+    if not directory.is_dir():
+        return []
+    names = []
+    for manifest in sorted(directory.glob(f"*/{MANIFEST_NAME}")):
+        try:
+            names.append(_register(_read_manifest(manifest), manifest))
+        except PluginError as e:
+            if strict:
+                raise
+            logger.warning("Skipping grammar plugin %s: %s", manifest.parent.name, e)
+    logger.info("Loaded %d grammar plugin(s) from %s: %s", len(names), directory, ", ".join(names))
+    return names
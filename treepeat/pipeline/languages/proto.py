@@ -0,0 +1,61 @@
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import LanguageConfig, RegionExtractionRule
+
+
+class ProtoConfig(LanguageConfig):
+    """Configuration for Protocol Buffers (.proto)."""
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore comments",
+                languages=["proto"],
+                query="(comment) @comment",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                # A message copied into another service's package is still a copy
+                name="Ignore package and imports",
+                languages=["proto"],
+                query="[(package) (import)] @header",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
+            *self.get_default_rules(),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize identifiers",
+                languages=["proto"],
+                query="(identifier) @var",
+                action=RuleAction.ANONYMIZE,
+                params={"prefix": "VAR"},
+            ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                # Field numbers are renumbered when a message is pasted next to existing fields
+                name="Anonymize literals",
+                languages=["proto"],
+                query="[(int_lit) (string)] @lit",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<LIT>"},
+            ),
+        ]
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            RegionExtractionRule.from_node_type("message"),
+            RegionExtractionRule.from_node_type("enum"),
+            RegionExtractionRule.from_node_type("service"),
+        ]
@@ -1,6 +1,6 @@
 from treepeat.pipeline.rules.models import Rule, RuleAction
 
-from .base import LanguageConfig, RegionExtractionRule
+from .base import SIGNATURE_TYPE_RULE_NAME, LanguageConfig, RegionExtractionRule
 
 
 class PythonConfig(LanguageConfig):
@@ -76,29 +76,8 @@ class PythonConfig(LanguageConfig):
 
     def get_loose_rules(self) -> list[Rule]:
         return [
-            Rule(
-                name="Ignore string content",
-                languages=["python"],
-                query="(string_content) @content",
-                target="content",
-                action=RuleAction.REMOVE,
-            ),
-            Rule(
-                name="Anonymize identifiers",
-                languages=["python"],
-                query="(identifier) @var",
-                target="var",
-                action=RuleAction.ANONYMIZE,
-                params={"prefix": "VAR"},
-            ),
-            Rule(
-                name="Anonymize literals",
-                languages=["python"],
-                query="[(string) (integer) (float) (true) (false) (none)] @lit",
-                target="lit",
-                action=RuleAction.REPLACE_VALUE,
-                params={"value": "<LIT>"},
-            ),
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
             Rule(
                 name="Anonymize operators",
                 languages=["python"],
@@ -126,8 +105,79 @@ class PythonConfig(LanguageConfig):
             *self.get_default_rules(),
         ]
 
+    def get_comment_rules(self) -> list[Rule]:
+        return [rule for rule in self.get_default_rules() if rule.name in ("Ignore comments", "Ignore docstrings")]
+
+    def get_identifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize identifiers",
+                languages=["python"],
+                query="(identifier) @var",
+                target="var",
+                action=RuleAction.ANONYMIZE,
+                params={"prefix": "VAR"},
+            ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore string content",
+                languages=["python"],
+                query="(string_content) @content",
+                target="content",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Anonymize literals",
+                languages=["python"],
+                query="[(string) (integer) (float) (true) (false) (none)] @lit",
+                target="lit",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<LIT>"},
+            ),
+        ]
+
+    def get_signature_type_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name=SIGNATURE_TYPE_RULE_NAME,
+                languages=["python"],
+                query="""[
+                    (function_definition return_type: (type (identifier) @type))
+                    (typed_parameter type: (type (identifier) @type))
+                    (typed_default_parameter type: (type (identifier) @type))
+                ]""",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<TYPE>"},
+            ),
+        ]
+
     def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
         return [
             RegionExtractionRule.from_node_type("function_definition"),
             RegionExtractionRule.from_node_type("class_definition"),
         ]
+
+    def get_neutral_node_types(self) -> dict[str, str]:
+        return {
+            **dict.fromkeys(["function_definition", "lambda"], "<FUNC>"),
+            **dict.fromkeys(["if_statement", "elif_clause", "conditional_expression"], "<IF>"),
+            **dict.fromkeys(["for_statement", "while_statement"], "<LOOP>"),
+            **dict.fromkeys(["assignment", "augmented_assignment"], "<ASSIGN>"),
+            **dict.fromkeys(["string", "integer", "float", "true", "false", "none"], "<LIT>"),
+            **dict.fromkeys(
+                ["binary_operator", "comparison_operator", "boolean_operator", "unary_operator", "not_operator"],
+                "<OP>",
+            ),
+            "call": "<CALL>",
+            "return_statement": "<RETURN>",
+            "break_statement": "<BREAK>",
+            "continue_statement": "<CONTINUE>",
+            "raise_statement": "<THROW>",
+            "try_statement": "<TRY>",
+            "identifier": "<ID>",
+            "attribute": "<ATTR>",
+            "subscript": "<INDEX>",
+        }
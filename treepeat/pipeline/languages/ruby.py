@@ -0,0 +1,70 @@
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import LanguageConfig, RegionExtractionRule
+
+
+class RubyConfig(LanguageConfig):
+    """Configuration for Ruby language."""
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore comments",
+                languages=["ruby"],
+                query="(comment) @comment",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Anonymize method names",
+                languages=["ruby"],
+                query="[(method name: (identifier) @name) (singleton_method name: (identifier) @name)]",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "FUNC"},
+            ),
+            Rule(
+                name="Anonymize class and module names",
+                languages=["ruby"],
+                query="[(class name: (constant) @name) (module name: (constant) @name)]",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "CLASS"},
+            ),
+        ]
+
+    def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
+            *self.get_default_rules(),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize identifiers",
+                languages=["ruby"],
+                query="[(identifier) (instance_variable)] @id",
+                action=RuleAction.ANONYMIZE,
+                params={"prefix": "VAR"},
+            ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize literals",
+                languages=["ruby"],
+                query="[(string_content) (simple_symbol) (integer) (float) (true) (false) (nil)] @lit",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<LIT>"},
+            ),
+        ]
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            RegionExtractionRule.from_node_type("method"),
+            RegionExtractionRule.from_node_type("singleton_method"),
+            RegionExtractionRule.from_node_type("class"),
+            RegionExtractionRule.from_node_type("module"),
+            # Blocks carry most of the logic in DSL-heavy code (Rails routes, RSpec, rake tasks)
+            RegionExtractionRule(query="[(do_block) (block)] @region", label="block"),
+        ]
@@ -1,6 +1,6 @@
 from treepeat.pipeline.rules.models import Rule, RuleAction
 
-from .base import LanguageConfig, RegionExtractionRule
+from .base import SIGNATURE_TYPE_RULE_NAME, LanguageConfig, RegionExtractionRule
 
 
 class RustConfig(LanguageConfig):
@@ -94,12 +94,31 @@ class RustConfig(LanguageConfig):
                 query="[(macro_invocation (identifier) @name) (macro_invocation (scoped_identifier) @name)]",
                 action=RuleAction.REMOVE,
             ),
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
             Rule(
-                name="Ignore string content",
+                # Covers arithmetic, comparison, logical, and bitwise operators.
+                # compound_assignment_expr (+=, -=, etc.) is a separate node type
+                # and is not anonymized here. The same gap exists in Go and Python
+                # configs and is accepted as a known limitation.
+                name="Anonymize binary expressions",
                 languages=["rust"],
-                query="(string_content) @content",
-                action=RuleAction.REMOVE,
+                query="(binary_expression) @binop",
+                action=RuleAction.REPLACE_NODE_TYPE,
+                params={"token": "<BINOP>"},
             ),
+            Rule(
+                name="Anonymize unary expressions",
+                languages=["rust"],
+                query="(unary_expression) @unop",
+                action=RuleAction.REPLACE_NODE_TYPE,
+                params={"token": "<UNOP>"},
+            ),
+            *self.get_default_rules(),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
+        return [
             Rule(
                 name="Anonymize identifiers",
                 languages=["rust"],
@@ -107,6 +126,16 @@ class RustConfig(LanguageConfig):
                 action=RuleAction.ANONYMIZE,
                 params={"prefix": "VAR"},
             ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore string content",
+                languages=["rust"],
+                query="(string_content) @content",
+                action=RuleAction.REMOVE,
+            ),
             Rule(
                 # Covers regular strings, raw strings, and character literals.
                 # byte strings (b"...") parse as string_literal and are included.
@@ -132,25 +161,39 @@ class RustConfig(LanguageConfig):
                 action=RuleAction.REPLACE_VALUE,
                 params={"value": "<BOOL>"},
             ),
+        ]
+
+    def get_qualifier_rules(self) -> list[Rule]:
+        return [
             Rule(
-                # Covers arithmetic, comparison, logical, and bitwise operators.
-                # compound_assignment_expr (+=, -=, etc.) is a separate node type
-                # and is not anonymized here. The same gap exists in Go and Python
-                # configs and is accepted as a known limitation.
-                name="Anonymize binary expressions",
+                name="Ignore visibility modifiers",
                 languages=["rust"],
-                query="(binary_expression) @binop",
-                action=RuleAction.REPLACE_NODE_TYPE,
-                params={"token": "<BINOP>"},
+                query="(visibility_modifier) @modifier",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_signature_type_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name=SIGNATURE_TYPE_RULE_NAME,
+                languages=["rust"],
+                query="""[
+                    (function_item return_type: (type_identifier) @type)
+                    (parameter type: (type_identifier) @type)
+                ]""",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<TYPE>"},
             ),
             Rule(
-                name="Anonymize unary expressions",
+                name="Ignore primitive signature type keywords",
                 languages=["rust"],
-                query="(unary_expression) @unop",
-                action=RuleAction.REPLACE_NODE_TYPE,
-                params={"token": "<UNOP>"},
+                query="""[
+                    (function_item return_type: (primitive_type _ @keyword))
+                    (parameter type: (primitive_type _ @keyword))
+                ]""",
+                action=RuleAction.REMOVE,
             ),
-            *self.get_default_rules(),
         ]
 
     def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
@@ -161,4 +204,24 @@ class RustConfig(LanguageConfig):
             RegionExtractionRule.from_node_type("enum_item"),
             RegionExtractionRule.from_node_type("trait_item"),
             RegionExtractionRule.from_node_type("macro_definition"),
+            RegionExtractionRule.from_node_type("match_expression"),
         ]
+
+    def get_neutral_node_types(self) -> dict[str, str]:
+        return {
+            **dict.fromkeys(["function_item", "closure_expression"], "<FUNC>"),
+            **dict.fromkeys(["for_expression", "while_expression", "loop_expression"], "<LOOP>"),
+            **dict.fromkeys(["call_expression", "macro_invocation"], "<CALL>"),
+            **dict.fromkeys(["let_declaration", "assignment_expression", "compound_assignment_expr"], "<ASSIGN>"),
+            **dict.fromkeys(["identifier", "field_identifier"], "<ID>"),
+            **dict.fromkeys(
+                ["string_literal", "char_literal", "integer_literal", "float_literal", "boolean_literal"], "<LIT>"
+            ),
+            **dict.fromkeys(["binary_expression", "unary_expression"], "<OP>"),
+            "if_expression": "<IF>",
+            "return_expression": "<RETURN>",
+            "break_expression": "<BREAK>",
+            "continue_expression": "<CONTINUE>",
+            "field_expression": "<ATTR>",
+            "index_expression": "<INDEX>",
+        }
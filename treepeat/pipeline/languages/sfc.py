@@ -0,0 +1,86 @@
+import re
+
+from tree_sitter import Node
+
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import LanguageConfig, RegionExtractionRule
+
+# The lang="..." attribute on a <script> or <style> start tag
+_LANG_ATTRIBUTE = re.compile(rb"""\blang\s*=\s*["']?([\w-]+)""")
+
+_SCRIPT_LANGUAGES = {"ts": "typescript", "typescript": "typescript", "tsx": "tsx", "jsx": "jsx"}
+
+
+def _lang_attribute(node: Node, source: bytes) -> str:
+    """Return the lowercased lang attribute of an element's start tag, or an empty string."""
+    start_tag = node.children[0] if node.children else node
+    match = _LANG_ATTRIBUTE.search(source[start_tag.start_byte : start_tag.end_byte])
+    return match.group(1).decode("utf-8").lower() if match else ""
+
+
+def script_language(node: Node, source: bytes) -> str:
+    """Language of a <script> block: TypeScript for lang="ts", JavaScript otherwise."""
+    return _SCRIPT_LANGUAGES.get(_lang_attribute(node, source), "javascript")
+
+
+class SingleFileComponentConfig(LanguageConfig):
+    """Shared configuration for single-file components (Vue, Svelte).
+
+    The ``<script>`` and ``<style>`` blocks are re-parsed with the JavaScript/TypeScript
+    and CSS grammars (language injection, as for Astro frontmatter), so logic copied
+    between a component and a ``.ts`` module is detected with the right line numbers.
+    """
+
+    language = ""
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore comments",
+                languages=[self.language],
+                query="(comment) @comment",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_default_rules(),
+            Rule(
+                name="Anonymize tag names",
+                languages=[self.language],
+                query="(tag_name) @tag",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<TAG>"},
+            ),
+            Rule(
+                name="Anonymize attribute names",
+                languages=[self.language],
+                query="(attribute_name) @attr",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<ATTR>"},
+            ),
+        ]
+
+    def get_template_rules(self) -> list[RegionExtractionRule]:
+        """Return the regions holding the component's markup."""
+        return []
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            RegionExtractionRule(
+                query="(script_element) @region",
+                label="script",
+                target_language=script_language,
+                content_query="(raw_text) @content",
+            ),
+            RegionExtractionRule(
+                query="(style_element) @region",
+                label="style",
+                # scss/less blocks are close enough to CSS to parse with its grammar
+                target_language="css",
+                content_query="(raw_text) @content",
+            ),
+            *self.get_template_rules(),
+        ]
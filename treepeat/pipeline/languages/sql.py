@@ -50,4 +50,9 @@ class SQLConfig(LanguageConfig):
             RegionExtractionRule.from_node_type("select"),
             RegionExtractionRule.from_node_type("insert"),
             RegionExtractionRule.from_node_type("delete"),
+            RegionExtractionRule.from_node_type("update"),
+            RegionExtractionRule.from_node_type("create_view"),
+            # Migration bodies: stored functions and schema changes
+            RegionExtractionRule.from_node_type("create_function"),
+            RegionExtractionRule.from_node_type("alter_table"),
         ]
@@ -0,0 +1,12 @@
+from .base import RegionExtractionRule
+from .sfc import SingleFileComponentConfig
+
+
+class SvelteConfig(SingleFileComponentConfig):
+    """Configuration for Svelte components (.svelte)."""
+
+    language = "svelte"
+
+    def get_template_rules(self) -> list[RegionExtractionRule]:
+        # Svelte markup sits at the top level of the document, next to <script> and <style>
+        return [RegionExtractionRule(query="(document (element) @region)", label="template")]
@@ -0,0 +1,90 @@
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import LanguageConfig, RegionExtractionRule
+
+
+class SwiftConfig(LanguageConfig):
+    """Configuration for Swift language."""
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore import declarations",
+                languages=["swift"],
+                query="(import_declaration) @import",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Ignore comments",
+                languages=["swift"],
+                query="[(comment) (multiline_comment)] @comment",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Anonymize function names",
+                languages=["swift"],
+                query="(function_declaration name: (simple_identifier) @func)",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "FUNC"},
+            ),
+            Rule(
+                # Covers class, struct, enum and actor declarations
+                name="Anonymize type names",
+                languages=["swift"],
+                query="(class_declaration name: (type_identifier) @type)",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "TYPE"},
+            ),
+        ]
+
+    def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
+            *self.get_default_rules(),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize identifiers",
+                languages=["swift"],
+                query="(simple_identifier) @id",
+                action=RuleAction.ANONYMIZE,
+                params={"prefix": "VAR"},
+            ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize literals",
+                languages=["swift"],
+                query=(
+                    "[(line_string_literal) (line_str_text) (integer_literal) "
+                    "(real_literal) (boolean_literal)] @lit"
+                ),
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<LIT>"},
+            ),
+        ]
+
+    def get_qualifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore access and storage modifiers",
+                languages=["swift"],
+                query="(modifiers) @modifier",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            RegionExtractionRule.from_node_type("function_declaration"),
+            RegionExtractionRule.from_node_type("init_declaration"),
+            # Classes, structs, enums and extensions all parse as class_declaration
+            RegionExtractionRule.from_node_type("class_declaration"),
+            RegionExtractionRule.from_node_type("protocol_declaration"),
+            RegionExtractionRule(query="(lambda_literal) @region", label="closure"),
+        ]
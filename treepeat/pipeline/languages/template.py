@@ -0,0 +1,92 @@
+import re
+
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import LanguageConfig, RegionExtractionRule
+
+# {# comment #}, <%# comment %>, {{/* comment */}}
+_TEMPLATE_COMMENT = re.compile(rb"\{#.*?#\}|<%#.*?%>|\{\{-?\s*/\*.*?\*/\s*-?\}\}", re.DOTALL)
+
+# {% tag %} (Jinja2/Django), <% code %> / <%= expr %> (ERB/EJS), {{ action }} (Jinja2 output, Go templates)
+_DIRECTIVE = re.compile(rb"\{%-?(?P<block>.*?)-?%\}|<%(?P<erb>.*?)[-_]?%>|\{\{-?(?P<curly>.*?)-?\}\}", re.DOTALL)
+
+# Go template actions that are control flow rather than output
+_GO_KEYWORDS = {b"if", b"else", b"range", b"with", b"end", b"define", b"template", b"block"}
+
+# Prefix that marks an HTML comment as a rewritten directive
+DIRECTIVE_MARKER = "<!--%"
+
+OUTPUT_KEYWORD = b"="
+
+
+def _keyword(directive: re.Match[bytes]) -> bytes:
+    """Reduce a directive to its keyword: "for" for {% for x in xs %}, "=" for an output expression."""
+    code = (directive.group("block") or directive.group("erb") or directive.group("curly") or b"").strip()
+    if directive.group("erb") is not None and code.startswith((b"=", b"-")):
+        return OUTPUT_KEYWORD
+    first = code.split(maxsplit=1)[0] if code else b""
+    if directive.group("curly") is not None and first not in _GO_KEYWORDS:
+        return OUTPUT_KEYWORD
+    return first
+
+
+def _directive_comment(directive: re.Match[bytes]) -> bytes:
+    """Render a directive as an HTML comment, keeping the lines it spanned."""
+    newlines = directive.group().count(b"\n")
+    return DIRECTIVE_MARKER.encode() + _keyword(directive) + b"%-->" + b"\n" * newlines
+
+
+def _blank(match: re.Match[bytes]) -> bytes:
+    return b"\n" * match.group().count(b"\n")
+
+
+def directives_to_comments(source: bytes) -> bytes:
+    """Rewrite template directives as HTML comments so the HTML grammar parses every template flavor."""
+    source = _TEMPLATE_COMMENT.sub(_blank, source)
+    return _DIRECTIVE.sub(_directive_comment, source)
+
+
+class TemplateConfig(LanguageConfig):
+    """Configuration for server-side templates (ERB/EJS, Jinja2, Go html/template).
+
+    Templates are parsed with the HTML grammar after each directive is rewritten
+    to an HTML comment holding just its keyword (``{% for x in xs %}`` becomes
+    ``<!--%for%-->``, ``{{ x }}`` and ``<%= x %>`` become ``<!--%=%-->``), so the
+    markup and the control flow are compared while the template expressions are
+    not. Line numbers are preserved.
+    """
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore comments",
+                languages=["template"],
+                query=f'((comment) @comment (#not-match? @comment "^{DIRECTIVE_MARKER}"))',
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_default_rules(),
+            Rule(
+                name="Anonymize attribute values",
+                languages=["template"],
+                query="(attribute_value) @val",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<VAL>"},
+            ),
+            Rule(
+                name="Ignore text content",
+                languages=["template"],
+                query="(text) @text",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            # Pages and partials as a whole, plus each top-level fragment within them
+            RegionExtractionRule(query="(document) @region", label="template"),
+            RegionExtractionRule(query="(document (element) @region)", label="fragment"),
+        ]
@@ -0,0 +1,61 @@
+from treepeat.pipeline.rules.models import Rule, RuleAction
+
+from .base import LanguageConfig, RegionExtractionRule
+
+
+class ThriftConfig(LanguageConfig):
+    """Configuration for Apache Thrift IDL (.thrift)."""
+
+    def get_default_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Ignore comments",
+                languages=["thrift"],
+                query="(comment) @comment",
+                action=RuleAction.REMOVE,
+            ),
+            Rule(
+                name="Ignore namespaces and includes",
+                languages=["thrift"],
+                query="[(namespace_declaration) (include_statement)] @header",
+                action=RuleAction.REMOVE,
+            ),
+        ]
+
+    def get_loose_rules(self) -> list[Rule]:
+        return [
+            *self.get_identifier_rules(),
+            *self.get_literal_rules(),
+            *self.get_default_rules(),
+        ]
+
+    def get_identifier_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize identifiers",
+                languages=["thrift"],
+                query="(identifier) @var",
+                action=RuleAction.ANONYMIZE,
+                params={"prefix": "VAR"},
+            ),
+        ]
+
+    def get_literal_rules(self) -> list[Rule]:
+        return [
+            Rule(
+                name="Anonymize literals",
+                languages=["thrift"],
+                query="[(field_id) (number) (string)] @lit",
+                action=RuleAction.REPLACE_VALUE,
+                params={"value": "<LIT>"},
+            ),
+        ]
+
+    def get_region_extraction_rules(self) -> list[RegionExtractionRule]:
+        return [
+            RegionExtractionRule.from_node_type("struct_definition"),
+            RegionExtractionRule.from_node_type("union_definition"),
+            RegionExtractionRule.from_node_type("exception_definition"),
+            RegionExtractionRule.from_node_type("enum_definition"),
+            RegionExtractionRule.from_node_type("service_definition"),
+        ]
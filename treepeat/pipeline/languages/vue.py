@@ -0,0 +1,11 @@
+from .base import RegionExtractionRule
+from .sfc import SingleFileComponentConfig
+
+
+class VueConfig(SingleFileComponentConfig):
+    """Configuration for Vue single-file components (.vue)."""
+
+    language = "vue"
+
+    def get_template_rules(self) -> list[RegionExtractionRule]:
+        return [RegionExtractionRule(query="(template_element) @region", label="template")]
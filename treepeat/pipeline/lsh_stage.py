@@ -1,20 +1,25 @@
 """LSH stage for finding similar region pairs."""
 
 import logging
-import sys
+from collections.abc import Iterator
+from contextlib import contextmanager
 from pathlib import Path
 from typing import TYPE_CHECKING
 
 from datasketch import MinHashLSH  # type: ignore[import-untyped]
-from tqdm import tqdm
 
+from treepeat.config import MaxGap
 from treepeat.models.shingle import ShingledRegion
 from treepeat.models.similarity import (
+    GroupCallback,
     Region,
     RegionSignature,
     SimilarityResult,
     SimilarRegionGroup,
 )
+from treepeat.pipeline.prefilter import Prefilter
+from treepeat.pipeline.progress import track
+from treepeat.pipeline.spill import SpilledLSH, index_memory
 
 if TYPE_CHECKING:
     from treepeat.pipeline.rules.models import Rule
@@ -31,18 +36,24 @@ def _sig_key(sig: RegionSignature) -> str:
     return f"{r.path}:{r.region_name}:{r.start_line}-{r.end_line}"
 
 
+def _lsh_threshold(similarity_percent: float) -> float:
+    """The LSH candidate threshold for a similarity threshold.
+
+    Use a lower threshold for LSH candidate finding to avoid missing matches
+    Cap at 0.5 to avoid being too restrictive with high similarity thresholds
+    For low thresholds, scale down proportionally to find appropriate candidates
+    The actual similarity_percent filtering happens later in the pipeline
+    """
+    return min(0.5, 0.7 * similarity_percent)
+
+
 def _create_lsh_index(
     signatures: list[RegionSignature],
     similarity_percent: float,
 ) -> MinHashLSH:
     """Create and populate LSH index."""
     num_perm = signatures[0].minhash.hashvalues.shape[0]
-
-    # Use a lower threshold for LSH candidate finding to avoid missing matches
-    # Cap at 0.5 to avoid being too restrictive with high similarity thresholds
-    # For low thresholds, scale down proportionally to find appropriate candidates
-    # The actual similarity_percent filtering happens later in the pipeline
-    lsh_similarity_percent = min(0.5, 0.7 * similarity_percent)
+    lsh_similarity_percent = _lsh_threshold(similarity_percent)
     lsh = MinHashLSH(lsh_similarity_percent, num_perm)
 
     for sig in signatures:
@@ -57,6 +68,27 @@ def _create_lsh_index(
     return lsh
 
 
+@contextmanager
+def _lsh_index(
+    signatures: list[RegionSignature], similarity_percent: float, max_index_memory: int | None
+) -> Iterator[MinHashLSH | SpilledLSH]:
+    """The candidate index: in memory, or spilled to disk when it would take more than ``max_index_memory`` bytes."""
+    threshold = _lsh_threshold(similarity_percent)
+    num_perm = signatures[0].minhash.hashvalues.shape[0]
+    estimate = index_memory(len(signatures), threshold, num_perm)
+    if max_index_memory is None or estimate <= max_index_memory:
+        yield _create_lsh_index(signatures, similarity_percent)
+        return
+    logger.info(
+        "LSH index of %d signature(s) needs ~%d MB, more than --max-index-memory allows: spilling it to disk",
+        len(signatures),
+        estimate // 2**20,
+    )
+    with SpilledLSH(threshold, num_perm) as lsh:
+        lsh.insert_all((_sig_key(sig), sig.minhash) for sig in signatures)
+        yield lsh
+
+
 def _regions_overlap(r1: Region, r2: Region) -> bool:
     """Check if two regions overlap in the same file."""
     if r1.path != r2.path:
@@ -142,6 +174,7 @@ def _is_pairwise_similar(
     sig: RegionSignature,
     key_to_sig: dict[str, RegionSignature],
     similarity_percent: float,
+    prefilter: Prefilter,
 ) -> bool:
     similar_sig = key_to_sig.get(other_key)
     if similar_sig is None:
@@ -150,6 +183,10 @@ def _is_pairwise_similar(
     if _regions_overlap(sig.region, similar_sig.region):
         return False
 
+    # Pairs whose sizes rule out a match are dropped before their signatures are compared
+    if not prefilter.admits(sig, similar_sig):
+        return False
+
     pair_similarity_percent = _compute_pair_similarity(sig, similar_sig)
     if pair_similarity_percent < similarity_percent:
         return False
@@ -164,24 +201,39 @@ def _append_pairwise_similar(
     sig: RegionSignature,
     key_to_sig: dict[str, RegionSignature],
     similarity_percent: float,
+    prefilter: Prefilter,
 ) -> None:
     pairwise_similar_keys = [
         sk
         for sk in similar_keys
         if sk != current_key
-        if _is_pairwise_similar(sk, sig, key_to_sig, similarity_percent)
+        if _is_pairwise_similar(sk, sig, key_to_sig, similarity_percent, prefilter)
     ]
     for similar_key in pairwise_similar_keys:
         uf.union(current_key, str(similar_key))
 
 
+def _query_signatures(signatures: list[RegionSignature], focus: set[Path] | None) -> list[RegionSignature]:
+    """Signatures to look up in the index: all of them, or only those of the focus files."""
+    if focus is None:
+        return signatures
+    queried = [sig for sig in signatures if sig.region.path in focus]
+    logger.info("Querying %d of %d region(s), those of the %d focus file(s)", len(queried), len(signatures), len(focus))
+    return queried
+
+
 def _build_union_find_from_lsh(
     signatures: list[RegionSignature],
-    lsh: MinHashLSH,
+    lsh: MinHashLSH | SpilledLSH,
     similarity_percent: float,
     progress: bool = False,
+    focus: set[Path] | None = None,
 ) -> tuple[UnionFind, dict[str, RegionSignature]]:
-    """Build union-find structure from LSH queries."""
+    """Build union-find structure from LSH queries.
+
+    Every signature is in the index, but only those of ``focus`` files (all when
+    None) are looked up, so each group holds at least one focus region.
+    """
     uf = UnionFind()
 
     key_to_sig: dict[str, RegionSignature] = {
@@ -192,11 +244,13 @@ def _build_union_find_from_lsh(
     # Use a lower threshold for pairwise filtering since LSH similarity is approximate
     # The actual verified similarity may be higher than the MinHash Jaccard similarity
     min_pair_similarity = 0.8 * similarity_percent
+    prefilter = Prefilter(min_pair_similarity)
 
+    queried = _query_signatures(signatures, focus)
     iterable = (
-        tqdm(signatures, desc="LSH", unit="signature", file=sys.stderr)
+        track(queried, "LSH", "signature")
         if progress
-        else signatures
+        else queried
     )
 
     for sig in iterable:
@@ -212,9 +266,10 @@ def _build_union_find_from_lsh(
         )
 
         _append_pairwise_similar(
-            uf, current_key, similar_keys, sig, key_to_sig, min_pair_similarity
+            uf, current_key, similar_keys, sig, key_to_sig, min_pair_similarity, prefilter
         )
 
+    prefilter.log_summary()
     return uf, key_to_sig
 
 
@@ -252,7 +307,8 @@ def _create_group_from_keys(
         )
         return None
 
-    regions = [sig.region for sig in group_sigs]
+    # One clone class per fragment: order instances so the earliest occurrence is its representative
+    regions = sorted((sig.region for sig in group_sigs), key=lambda r: (str(r.path), r.start_line))
     logger.debug(
         "Found similar group of %d region(s) with %.1f%% similarity",
         len(regions),
@@ -272,9 +328,10 @@ def _create_group_from_keys(
 
 def _collect_candidate_groups(
     signatures: list[RegionSignature],
-    lsh: MinHashLSH,
+    lsh: MinHashLSH | SpilledLSH,
     similarity_percent: float,
     progress: bool = False,
+    focus: set[Path] | None = None,
 ) -> list[SimilarRegionGroup]:
     """Collect similar region groups from LSH queries."""
     # Build union-find structure
@@ -283,6 +340,7 @@ def _collect_candidate_groups(
         lsh,
         similarity_percent,
         progress=progress,
+        focus=focus,
     )
 
     # Extract groups from union-find
@@ -303,9 +361,16 @@ def _collect_candidate_groups(
 
 
 def find_similar_groups(
-    signatures: list[RegionSignature], similarity_percent: float, progress: bool = False
+    signatures: list[RegionSignature],
+    similarity_percent: float,
+    progress: bool = False,
+    focus: set[Path] | None = None,
+    max_index_memory: int | None = None,
 ) -> list[SimilarRegionGroup]:
-    """Find similar region groups using LSH."""
+    """Find similar region groups using LSH, limited to groups with a region in ``focus`` files when given.
+
+    With ``max_index_memory`` (bytes), an index estimated to need more is kept on disk instead.
+    """
     if len(signatures) < 2:
         logger.info("Need at least 2 regions to find similar groups")
         return []
@@ -316,8 +381,8 @@ def find_similar_groups(
         len(signatures),
     )
 
-    lsh = _create_lsh_index(signatures, similarity_percent)
-    groups = _collect_candidate_groups(signatures, lsh, similarity_percent, progress=progress)
+    with _lsh_index(signatures, similarity_percent, max_index_memory) as lsh:
+        groups = _collect_candidate_groups(signatures, lsh, similarity_percent, progress=progress, focus=focus)
 
     groups.sort(key=lambda g: g.similarity, reverse=True)
     logger.info(
@@ -334,24 +399,35 @@ def _verify_and_filter_groups(
     similarity_percent: float,
     rules: "list[Rule]",
     progress: bool = False,
+    jobs: int = 1,
+    on_group: GroupCallback | None = None,
+    max_gap_lines: MaxGap | None = None,
 ) -> list[SimilarRegionGroup]:
-    """Verify candidate groups and filter by minimum similarity similarity_percent."""
-    from treepeat.pipeline.verification import verify_similar_groups
+    """Verify candidate groups and filter by minimum similarity similarity_percent.
+
+    ``on_group`` is called with each group that passes, as soon as it is verified.
+    """
+    from treepeat.pipeline.verification import iter_verified_groups
 
     logger.info("Verifying %d candidate group(s)", len(candidate_groups))
-    verified_groups = verify_similar_groups(
-        candidate_groups,
-        shingled_regions,
-        rules=rules,
-        progress=progress,
+    verified_count = 0
+    similar_groups = []
+    verified = iter_verified_groups(
+        candidate_groups, shingled_regions, rules=rules, progress=progress, jobs=jobs, max_gap_lines=max_gap_lines
     )
+    for group in verified:
+        verified_count += 1
+        # Filter groups that fall below minimum similarity after verification
+        if group.similarity < similarity_percent:
+            continue
+        similar_groups.append(group)
+        if on_group is not None:
+            on_group(group)
 
-    # Filter groups that fall below minimum similarity after verification
-    similar_groups = [g for g in verified_groups if g.similarity >= similarity_percent]
-    if len(similar_groups) < len(verified_groups):
+    if len(similar_groups) < verified_count:
         logger.info(
             "Filtered %d group(s) below similarity_percent similarity_percent (%.1f%%) after verification",
-            len(verified_groups) - len(similar_groups),
+            verified_count - len(similar_groups),
             similarity_percent * 100,
         )
     return similar_groups
@@ -427,12 +503,22 @@ def detect_similarity(
     min_lines: int = 5,
     rules: "list[Rule] | None" = None,
     progress: bool = False,
+    jobs: int = 1,
+    on_group: GroupCallback | None = None,
+    max_gap_lines: MaxGap | None = None,
+    focus: set[Path] | None = None,
+    max_index_memory: int | None = None,
 ) -> SimilarityResult:
     """Detect similar regions using LSH.
 
     ``rules`` is the active ruleset, used during verification to decide whether
     a name-only signature difference is intentional (see verification). When
     omitted, signature verification runs without anonymization awareness.
+    ``jobs`` is the number of worker processes used to verify candidate groups.
+    ``on_group`` is called with each similar group as soon as it is verified.
+    ``max_gap_lines`` rejects pairs whose largest differing stretch is wider than that.
+    ``focus`` limits the search to groups with a region in one of those files.
+    ``max_index_memory`` caps the bytes of the candidate index before it is spilled to disk.
     """
     filtered_signatures, filtered_shingled = _filter_by_min_lines(
         signatures, shingled_regions, min_lines
@@ -446,6 +532,8 @@ def detect_similarity(
         filtered_signatures,
         similarity_percent,
         progress=progress,
+        focus=focus,
+        max_index_memory=max_index_memory,
     )
 
     total_pairs = sum(
@@ -465,6 +553,9 @@ def detect_similarity(
         similarity_percent,
         rules=rules or [],
         progress=progress,
+        jobs=jobs,
+        on_group=on_group,
+        max_gap_lines=max_gap_lines,
     )
 
     return SimilarityResult(
@@ -1,13 +1,16 @@
 """MinHash stage for similarity detection."""
 
 import logging
-import sys
+from collections.abc import Iterable, Iterator
+from concurrent.futures import ProcessPoolExecutor
+from itertools import repeat
 
 from datasketch import MinHash  # type: ignore[import-untyped]
-from tqdm import tqdm
 
 from treepeat.models.shingle import ShingledRegion
 from treepeat.models.similarity import RegionSignature
+from treepeat.pipeline.prefilter import bloom_size, shingle_bloom
+from treepeat.pipeline.progress import track
 
 logger = logging.getLogger(__name__)
 
@@ -23,50 +26,70 @@ def create_minhash_signature(
     return minhash
 
 
+def _minhash_or_error(shingles: set[str], num_perm: int) -> MinHash | Exception:
+    """Create a MinHash signature, returning the error instead of raising it (runs in a worker process)."""
+    try:
+        return create_minhash_signature(shingles, num_perm=num_perm)
+    except Exception as e:
+        return e
+
+
+def _iter_minhashes(shingle_sets: list[set[str]], num_perm: int, jobs: int) -> Iterator[MinHash | Exception]:
+    """Yield the signature of each shingle set in order, over ``jobs`` worker processes when there are several."""
+    if jobs <= 1 or len(shingle_sets) < 2:
+        yield from (_minhash_or_error(shingles, num_perm) for shingles in shingle_sets)
+        return
+    chunksize = max(1, len(shingle_sets) // (jobs * 4))
+    with ProcessPoolExecutor(max_workers=jobs) as executor:
+        yield from executor.map(_minhash_or_error, shingle_sets, repeat(num_perm), chunksize=chunksize)
+
+
 def compute_region_signatures(
     shingled_regions: list[ShingledRegion],
     num_perm: int = 128,
     progress: bool = False,
+    jobs: int = 1,
 ) -> list[RegionSignature]:
-    """Compute MinHash signatures for all shingled regions. """
+    """Compute MinHash signatures for all shingled regions.
+
+    ``jobs`` > 1 hashes regions in worker processes; a region that fails is
+    logged and skipped without aborting the others.
+    """
     logger.info(
         "Computing MinHash signatures for %d region(s) with num_perm=%d",
         len(shingled_regions),
         num_perm,
     )
 
-    signatures = []
-    iterable = (
-        tqdm(shingled_regions, desc="MinHash", unit="region", file=sys.stderr)
-        if progress
-        else shingled_regions
-    )
+    shingle_sets = [set(shingled_region.shingles.get_contents()) for shingled_region in shingled_regions]
+    minhashes: Iterable[MinHash | Exception] = _iter_minhashes(shingle_sets, num_perm, jobs)
+    if progress:
+        minhashes = track(minhashes, "MinHash", "region", total=len(shingled_regions))
 
-    for shingled_region in iterable:
-        try:
-            # Get shingle contents as strings for MinHash
-            shingle_contents = shingled_region.shingles.get_contents()
-            minhash = create_minhash_signature(
-                set(shingle_contents),
-                num_perm=num_perm,
+    signatures = []
+    for shingled_region, shingles, minhash in zip(shingled_regions, shingle_sets, minhashes, strict=True):
+        if isinstance(minhash, Exception):
+            logger.error(
+                "Failed to create MinHash for %s: %s", shingled_region.region.region_name, minhash
             )
+            continue
 
-            signature = RegionSignature(
+        signatures.append(
+            RegionSignature(
                 region=shingled_region.region,
                 minhash=minhash,
                 shingle_count=shingled_region.shingle_count,
+                distinct_shingles=len(shingles),
+                shingle_bloom=shingle_bloom(shingles, bloom_size(len(shingles))),
+                bloom_bits=bloom_size(len(shingles)),
             )
-            signatures.append(signature)
+        )
 
-            logger.debug(
-                "Created MinHash signature for %s (%d shingles)",
-                shingled_region.region.region_name,
-                shingled_region.shingle_count,
-            )
-        except Exception as e:
-            logger.error(
-                "Failed to create MinHash for %s: %s", shingled_region.region.region_name, e
-            )
+        logger.debug(
+            "Created MinHash signature for %s (%d shingles)",
+            shingled_region.region.region_name,
+            shingled_region.shingle_count,
+        )
 
     logger.info("MinHash computation complete: %d signatures created", len(signatures))
     return signatures
@@ -0,0 +1,26 @@
+import re
+from pathlib import Path
+
+# Languages whose files are checked; other generated code is handled by the generated-code markers
+MINIFIABLE_LANGUAGES = {"javascript"}
+
+# A file with a line this long whose lines also average AVERAGE_LINE_LENGTH is minified
+LONG_LINE_LENGTH = 1000
+AVERAGE_LINE_LENGTH = 200
+
+_MINIFIED_NAME = re.compile(r"[.-]min\.js$|\.bundle\.js$|\.chunk\.js$")
+_BUNDLE_MARKERS = re.compile(rb"^//[#@] sourceMappingURL=|webpackBootstrap|__webpack_require__", re.MULTILINE)
+
+
+def _has_minified_shape(source: bytes) -> bool:
+    """True if the source is a few enormous lines rather than formatted code."""
+    lines = source.splitlines() or [b""]
+    longest = max(len(line) for line in lines)
+    return longest >= LONG_LINE_LENGTH and len(source) / len(lines) >= AVERAGE_LINE_LENGTH
+
+
+def is_minified(path: Path, source: bytes) -> bool:
+    """True for minified or bundled JavaScript: a .min.js/.bundle.js name, bundler markers or minified shape."""
+    if _MINIFIED_NAME.search(path.name):
+        return True
+    return _BUNDLE_MARKERS.search(source) is not None or _has_minified_shape(source)
@@ -0,0 +1,173 @@
+import json
+import logging
+from dataclasses import dataclass
+from functools import lru_cache
+from pathlib import Path
+from typing import Any
+
+from treepeat.models.similarity import Region
+from treepeat.pipeline.languages import LANGUAGE_CONFIGS
+
+logger = logging.getLogger(__name__)
+
+NOTEBOOK_EXTENSIONS = [".ipynb"]
+
+# Kernel language names that differ from treepeat's language names.
+_KERNEL_LANGUAGE_ALIASES = {
+    "python3": "python",
+    "ipython": "python",
+    "ipython3": "python",
+    "sh": "bash",
+    "shell": "bash",
+}
+
+# Cell magics whose body is still Python (%%time, ...); the body of any other (%%bash, %%sql) is skipped
+_PYTHON_CELL_MAGICS = {"time", "timeit", "capture", "prun", "debug"}
+
+
+def _is_magic(line: str) -> bool:
+    """True for IPython line magics (%matplotlib inline) and shell escapes (!pip install)."""
+    return line.lstrip().startswith(("%", "!"))
+
+
+def _python_lines(lines: tuple[str, ...]) -> tuple[str, ...]:
+    """Blank out IPython-only syntax so a code cell parses (and matches) like a regular .py file."""
+    first = lines[0].strip() if lines else ""
+    if first.startswith("%%") and first[2:].split(" ", 1)[0] not in _PYTHON_CELL_MAGICS:
+        return tuple("" for _ in lines)
+    return tuple("" if _is_magic(line) else line for line in lines)
+
+
+@dataclass(frozen=True)
+class NotebookCell:
+    """A single notebook cell and where it sits in the virtual source."""
+
+    index: int
+    cell_type: str
+    start_line: int  # 1-indexed line of the cell's first line in the virtual source
+    lines: tuple[str, ...]
+
+    @property
+    def end_line(self) -> int:
+        return self.start_line + max(len(self.lines), 1) - 1
+
+
+@dataclass(frozen=True)
+class Notebook:
+    """A notebook laid out as one virtual source, one cell after another."""
+
+    path: Path
+    language: str
+    cells: tuple[NotebookCell, ...]
+
+    def source_for(self, cell_types: set[str], strip_magics: bool = True) -> bytes:
+        """Return the virtual source with cells of other types blanked out.
+
+        Every cell keeps its line range, so regions found in either the code or
+        the markdown source share one coordinate system. IPython magics are
+        blanked too unless ``strip_magics`` is False (when showing snippets).
+        """
+        out: list[str] = []
+        for cell in self.cells:
+            keep = cell.cell_type in cell_types
+            out.extend(line if keep else "" for line in self._lines(cell, strip_magics) or ("",))
+            out.append("")  # blank separator so adjacent cells never merge
+        return "\n".join(out).encode("utf-8")
+
+    def _lines(self, cell: NotebookCell, strip_magics: bool) -> tuple[str, ...]:
+        if strip_magics and cell.cell_type == "code" and self.language == "python":
+            return _python_lines(cell.lines)
+        return cell.lines
+
+    def cell_at(self, line: int) -> NotebookCell | None:
+        """Return the cell containing a virtual source line."""
+        for cell in self.cells:
+            if cell.start_line <= line <= cell.end_line:
+                return cell
+        return None
+
+
+def is_notebook(path: Path) -> bool:
+    """Check whether a path is a Jupyter notebook."""
+    return path.suffix.lower() in NOTEBOOK_EXTENSIONS
+
+
+def _cell_source_lines(cell: dict[str, Any]) -> tuple[str, ...]:
+    source = cell.get("source", "")
+    text = "".join(source) if isinstance(source, list) else str(source)
+    return tuple(text.splitlines())
+
+
+def _notebook_language(data: dict[str, Any]) -> str:
+    """Return the notebook's language from kernel or language_info metadata."""
+    metadata = data.get("metadata", {})
+    raw = (
+        metadata.get("kernelspec", {}).get("language")
+        or metadata.get("language_info", {}).get("name")
+        or "python"
+    )
+    language = str(raw).lower()
+    return _KERNEL_LANGUAGE_ALIASES.get(language, language)
+
+
+def _layout_cells(raw_cells: list[dict[str, Any]]) -> tuple[NotebookCell, ...]:
+    cells: list[NotebookCell] = []
+    line = 1
+    for index, raw in enumerate(raw_cells):
+        cell = NotebookCell(
+            index=index,
+            cell_type=str(raw.get("cell_type", "code")),
+            start_line=line,
+            lines=_cell_source_lines(raw),
+        )
+        cells.append(cell)
+        line = cell.end_line + 2  # skip the blank separator line
+    return tuple(cells)
+
+
+def load_notebook(path: Path) -> Notebook:
+    """Load a notebook file and lay its cells out as a virtual source."""
+    try:
+        data = json.loads(path.read_text(encoding="utf-8"))
+    except Exception as e:
+        raise ValueError(f"Failed to read notebook {path}: {e}") from e
+
+    language = _notebook_language(data)
+    if language not in LANGUAGE_CONFIGS:
+        raise ValueError(f"Unsupported notebook language '{language}' in {path}")
+
+    return Notebook(path=path, language=language, cells=_layout_cells(data.get("cells", [])))
+
+
+@lru_cache(maxsize=256)
+def _load_notebook_version(version: tuple[Path, int, int]) -> Notebook:
+    """Load the notebook at a path, cached by its path, modification time (ns) and size."""
+    return load_notebook(version[0])
+
+
+def _cached_notebook(path: Path) -> Notebook:
+    """A notebook, loaded again once the file has changed."""
+    try:
+        stat = path.stat()
+    except OSError as e:
+        raise ValueError(f"Failed to read notebook {path}: {e}") from e
+    return _load_notebook_version((path, stat.st_mtime_ns, stat.st_size))
+
+
+def read_notebook_lines(path: Path) -> list[str]:
+    """Return every line of a notebook's virtual source (all cell types)."""
+    notebook = _cached_notebook(path)
+    return notebook.source_for({"code", "markdown", "raw"}, strip_magics=False).decode("utf-8").splitlines()
+
+
+def describe_notebook_location(region: Region) -> str | None:
+    """Describe a region's start as ``path:cell[N]:line L``, or None if not a notebook."""
+    if not is_notebook(region.path):
+        return None
+    try:
+        cell = _cached_notebook(region.path).cell_at(region.start_line)
+    except ValueError:
+        return None
+    if cell is None:
+        return None
+    return f"{region.path}:cell[{cell.index}]:line {region.start_line - cell.start_line + 1}"
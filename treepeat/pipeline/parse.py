@@ -1,25 +1,117 @@
+import hashlib
 import logging
-import sys
+import os
+import time
+from collections.abc import Iterable
+from concurrent.futures import ThreadPoolExecutor
 from fnmatch import fnmatch
 from pathlib import Path
 
-from tqdm import tqdm
-from tree_sitter_language_pack import get_parser
+from tree_sitter import Parser, Tree
 
 from treepeat.config import get_settings
 from treepeat.models import ParsedFile, ParseResult
-from treepeat.pipeline.languages import LANGUAGE_EXTENSIONS, get_grammar
+from treepeat.pipeline.generated import is_generated
+from treepeat.pipeline.git_excludes import git_exclude_files
+from treepeat.pipeline.languages import (
+    LANGUAGE_EXTENSIONS,
+    LANGUAGE_FILENAMES,
+    get_grammar,
+    grammar_parser,
+    preprocess_source,
+)
+from treepeat.pipeline.minified import MINIFIABLE_LANGUAGES, is_minified
+from treepeat.pipeline.notebook import NOTEBOOK_EXTENSIONS, is_notebook, load_notebook
+from treepeat.pipeline.progress import track
+from treepeat.pipeline.testcode import is_test_path
+from treepeat.pipeline.verbose_metrics import record_stage_timing
 
 logger = logging.getLogger(__name__)
 
+# Always honored, whatever --ignore-files says, and applied after the other ignore files of its directory
+TREEPEAT_IGNORE_FILE = ".treepeatignore"
+
+# Dependency and build-output directories skipped unless --include-vendored is given
+VENDORED_DIRECTORIES = {
+    "vendor",
+    "node_modules",
+    "bower_components",
+    "jspm_packages",
+    "third_party",
+    "third-party",
+    "thirdparty",
+    ".venv",
+    "venv",
+    "site-packages",
+    "__pypackages__",
+    ".tox",
+    "target",
+    "Pods",
+    "Carthage",
+}
+
+# Interpreter named by a "#!" line -> language, for extensionless scripts (bin/deploy, scripts/release, ...)
+SHEBANG_INTERPRETERS = {
+    "sh": "bash",
+    "bash": "bash",
+    "dash": "bash",
+    "ksh": "bash",
+    "zsh": "bash",
+    "python": "python",
+    "node": "javascript",
+    "ruby": "ruby",
+    "php": "php",
+}
+
+
+class ParseTimeoutError(RuntimeError):
+    """Raised when parsing a single file exceeds the configured timeout."""
+
+
+def _read_shebang(file_path: Path) -> str:
+    """Return the first line of a file if it is a "#!" line, else an empty string."""
+    try:
+        with file_path.open("rb") as f:
+            first_line = f.readline(256)
+    except OSError:
+        return ""
+    return first_line.decode("utf-8", errors="replace").strip() if first_line.startswith(b"#!") else ""
+
+
+def _interpreter(shebang: str) -> str:
+    """Name the interpreter of a shebang line, looking through /usr/bin/env and its flags."""
+    words = shebang[2:].split()
+    if words and Path(words[0]).name == "env":
+        words = [word for word in words[1:] if not word.startswith("-") and "=" not in word]
+    # python3.11 -> python
+    return Path(words[0]).name.rstrip("0123456789.") if words else ""
+
+
+def detect_shebang_language(file_path: Path) -> str | None:
+    """Detect the language of an extensionless script from its shebang line."""
+    if file_path.suffix:
+        return None
+    return SHEBANG_INTERPRETERS.get(_interpreter(_read_shebang(file_path)))
+
+
+def detect_filename_language(file_path: Path) -> str | None:
+    """Detect the language of files recognized by name rather than extension (e.g. Dockerfile.prod)."""
+    for lang, patterns in LANGUAGE_FILENAMES.items():
+        if any(fnmatch(file_path.name, pattern) for pattern in patterns):
+            return lang
+    return None
+
 
 def detect_language(file_path: Path) -> str | None:
-    """Detect programming language from file extension."""
+    """Detect programming language from file name or extension, or the shebang of an extensionless script."""
+    named = detect_filename_language(file_path)
+    if named:
+        return named
     suffix = file_path.suffix.lower()
     for lang, exts in LANGUAGE_EXTENSIONS.items():
         if suffix in exts:
             return lang
-    return None
+    return detect_shebang_language(file_path)
 
 
 def read_source_file(file_path: Path) -> bytes:
@@ -30,18 +122,35 @@ def read_source_file(file_path: Path) -> bytes:
         raise ValueError(f"Failed to read file {file_path}: {e}") from e
 
 
+def _parse_with_timeout(parser: Parser, source: bytes, timeout: float | None) -> Tree:
+    """Parse source, cancelling through tree-sitter's progress callback once the timeout elapses."""
+    if timeout is None:
+        return parser.parse(source)
+
+    deadline = time.monotonic() + timeout
+    try:
+        return parser.parse(source, progress_callback=lambda *_: time.monotonic() > deadline)
+    except ValueError as e:
+        # tree-sitter reports a cancelled parse as a failed parse
+        if time.monotonic() > deadline:
+            raise ParseTimeoutError(f"parsing exceeded {timeout:g}s") from e
+        raise
+
+
 def parse_source_code(
     source: bytes, language_name: str, file_path: Path
 ) -> ParsedFile:
     """Parse source code using tree-sitter."""
     grammar = get_grammar(language_name)
     try:
-        parser = get_parser(grammar)  # type: ignore[arg-type]
+        parser = grammar_parser(grammar)
     except Exception as e:
         raise RuntimeError(f"Failed to get parser for {language_name}: {e}") from e
 
     try:
-        tree = parser.parse(source)
+        tree = _parse_with_timeout(parser, source, get_settings().parse_timeout)
+    except ParseTimeoutError:
+        raise
     except Exception as e:
         raise RuntimeError(f"Failed to parse {file_path}: {e}") from e
 
@@ -61,13 +170,34 @@ def parse_file(file_path: Path) -> ParsedFile:
 
     logger.debug(f"Detected language: {language_name}")
 
-    source = read_source_file(file_path)
+    source = preprocess_source(language_name, read_source_file(file_path))
     parsed = parse_source_code(source, language_name, file_path)
 
     logger.debug(f"Successfully parsed {file_path}")
     return parsed
 
 
+def parse_notebook(file_path: Path) -> list[ParsedFile]:
+    """Parse the code cells of a notebook (and markdown cells when detecting comments).
+
+    Cells are laid out one after another in a virtual source so that region line
+    numbers can be mapped back to a cell (see ``describe_notebook_location``).
+    """
+    notebook = load_notebook(file_path)
+    parsed = [parse_source_code(notebook.source_for({"code"}), notebook.language, file_path)]
+    if get_settings().detect_comments and notebook.language != "markdown":
+        parsed.append(parse_source_code(notebook.source_for({"markdown"}), "markdown", file_path))
+    logger.debug(f"Parsed notebook {file_path} as {notebook.language}")
+    return parsed
+
+
+def _parse_any(file_path: Path) -> list[ParsedFile]:
+    """Parse a source file or notebook into one or more parsed files."""
+    if is_notebook(file_path):
+        return parse_notebook(file_path)
+    return [parse_file(file_path)]
+
+
 def parse_ignore_file(ignore_file: Path) -> list[str]:
     """Parse an ignore file and return list of patterns."""
     try:
@@ -97,17 +227,45 @@ def _process_ignore_file(ignore_file: Path, ignore_files_map: dict[Path, list[st
     logger.debug(f"Loaded {len(patterns)} patterns from {ignore_file}")
 
 
+def _ignore_file_order(ignore_file: Path) -> tuple[bool, str]:
+    """Sort key putting .treepeatignore last, so its negations can re-include what a VCS ignore file excludes."""
+    return ignore_file.name == TREEPEAT_IGNORE_FILE, str(ignore_file)
+
+
+def _add_git_excludes(target_path: Path, ignore_files_map: dict[Path, list[str]]) -> None:
+    """Add core.excludesFile and .git/info/exclude patterns, keyed by the (absolute) worktree root."""
+    git_excludes = git_exclude_files(target_path)
+    if git_excludes is None:
+        return
+    root, exclude_files = git_excludes
+    for exclude_file in exclude_files:
+        ignore_files_map.setdefault(root, []).extend(parse_ignore_file(exclude_file))
+        logger.debug(f"Loaded git excludes from {exclude_file}")
+
+
 def find_ignore_files(target_path: Path, ignore_file_patterns: list[str]) -> dict[Path, list[str]]:
-    """Find all ignore files in the directory hierarchy."""
+    """Find all ignore files in the directory hierarchy.
+
+    When ignore files are enabled and the target is in a git worktree, the
+    patterns git reads from core.excludesFile and .git/info/exclude come first,
+    so that any ignore file in the tree can override them.
+    """
     ignore_files_map: dict[Path, list[str]] = {}
 
     if not target_path.is_dir():
         return ignore_files_map
 
-    for pattern in ignore_file_patterns:
-        for ignore_file in target_path.glob(pattern):
-            if ignore_file.is_file():
-                _process_ignore_file(ignore_file, ignore_files_map)
+    if ignore_file_patterns:
+        _add_git_excludes(target_path, ignore_files_map)
+
+    ignore_files = {
+        ignore_file
+        for pattern in [*ignore_file_patterns, f"**/{TREEPEAT_IGNORE_FILE}"]
+        for ignore_file in target_path.glob(pattern)
+        if ignore_file.is_file()
+    }
+    for ignore_file in sorted(ignore_files, key=_ignore_file_order):
+        _process_ignore_file(ignore_file, ignore_files_map)
 
     return ignore_files_map
 
@@ -134,11 +292,15 @@ def _match_double_star_pattern(rel_path_str: str, file_name: str, pattern: str)
 
 
 def _get_relative_path(file_path: Path, base_path: Path) -> str | None:
-    """Get relative path string, returning None if file is not under base."""
-    try:
-        return str(file_path.relative_to(base_path))
-    except ValueError:
-        return None
+    """Get relative path string, returning None if file is not under base.
+
+    Both paths are resolved when they cannot be compared as given (e.g. a
+    relative file against the absolute root of a git worktree).
+    """
+    for file, base in ((file_path, base_path), (file_path.resolve(), base_path.resolve())):
+        if file.is_relative_to(base):
+            return str(file.relative_to(base))
+    return None
 
 
 def _match_path_component(rel_path_str: str, pattern: str) -> bool:
@@ -162,20 +324,35 @@ def _match_simple_pattern(rel_path_str: str, file_name: str, pattern: str) -> bo
     return _match_path_component(rel_path_str, pattern)
 
 
-def _check_directory_pattern(rel_path_str: str, file_path: Path, pattern: str) -> tuple[bool, bool]:
-    """Check if pattern is a directory pattern and match accordingly."""
-    if pattern.endswith("/"):
-        dir_name = pattern.rstrip("/")
-        if file_path.is_dir():
-            return True, (fnmatch(rel_path_str, dir_name) or fnmatch(file_path.name, dir_name))
-        else:
-            return True, rel_path_str.startswith(dir_name + "/")
+def _match_anchored(rel_path_str: str, pattern: str) -> bool:
+    """Match a pattern anchored at the ignore file's directory ("/build", "docs/*.md") segment by segment.
 
-    return False, False
+    As in git, "*" does not cross a "/", and a pattern that matches one of the
+    path's leading directories ignores everything beneath it.
+    """
+    pattern_parts = pattern.strip("/").split("/")
+    path_parts = Path(rel_path_str).parts
+    return len(path_parts) >= len(pattern_parts) and all(
+        fnmatch(part, pattern_part) for part, pattern_part in zip(path_parts, pattern_parts)
+    )
+
+
+def _match_path(rel_path_str: str, file_name: str, pattern: str) -> bool:
+    """Match a non-directory pattern, anchoring it when it contains a slash (other than "**")."""
+    if pattern.startswith("/") or ("/" in pattern and "**" not in pattern):
+        return _match_anchored(rel_path_str, pattern)
+    return _match_simple_pattern(rel_path_str, file_name, pattern)
+
+
+def _match_directory_pattern(rel_path_str: str, is_dir: bool, dir_pattern: str) -> bool:
+    """Match a "dir/" pattern against the directories of a path (the path itself only if it is one)."""
+    parts = Path(rel_path_str).parts if is_dir else Path(rel_path_str).parts[:-1]
+    directories = ["/".join(parts[:i]) for i in range(1, len(parts) + 1)]
+    return any(_match_path(directory, Path(directory).name, dir_pattern) for directory in directories)
 
 
 def matches_pattern(file_path: Path, pattern: str, base_path: Path) -> bool:
-    """Check if a file matches an ignore pattern."""
+    """Check if a file matches an ignore pattern, relative to the directory the pattern comes from."""
     if pattern.startswith("!"):
         return False
 
@@ -183,23 +360,23 @@ def matches_pattern(file_path: Path, pattern: str, base_path: Path) -> bool:
     if rel_path_str is None:
         return False
 
-    should_return, is_match = _check_directory_pattern(rel_path_str, file_path, pattern)
-    if should_return:
-        return is_match
-
-    if pattern.startswith("/"):
-        return fnmatch(rel_path_str, pattern.lstrip("/"))
+    if pattern.endswith("/"):
+        return _match_directory_pattern(rel_path_str, file_path.is_dir(), pattern.rstrip("/"))
+    return _match_path(rel_path_str, file_path.name, pattern)
 
-    return _match_simple_pattern(rel_path_str, file_path.name, pattern)
 
+def _check_patterns_in_directory(file_path: Path, directory: Path, patterns: list[str]) -> bool | None:
+    """Return whether the last pattern from a directory that matches the file ignores it (None if none match).
 
-def _check_patterns_in_directory(file_path: Path, directory: Path, patterns: list[str]) -> bool:
-    """Check if file matches any patterns from a directory."""
+    As in gitignore, a later "!pattern" re-includes a file an earlier pattern excluded.
+    """
+    ignored = None
     for pattern in patterns:
-        if matches_pattern(file_path, pattern, directory):
+        negated = pattern.startswith("!")
+        if matches_pattern(file_path, pattern[1:] if negated else pattern, directory):
             logger.debug(f"File {file_path} matched pattern '{pattern}' from {directory}")
-            return True
-    return False
+            ignored = not negated
+    return ignored
 
 
 def _should_stop_traversal(current: Path, target: Path) -> bool:
@@ -222,15 +399,28 @@ def _get_parent_directories(file_path: Path, target_path: Path) -> list[Path]:
     return directories
 
 
+def _outer_directories(target_path: Path, ignore_files_map: dict[Path, list[str]]) -> list[Path]:
+    """Directories with patterns that the walk from the target down does not visit, outermost first."""
+    resolved = target_path.resolve()
+    return [d for d in (*reversed(resolved.parents), resolved) if d in ignore_files_map and d != target_path]
+
+
 def _check_hierarchical_ignores(
     file_path: Path, target_path: Path, ignore_files_map: dict[Path, list[str]]
 ) -> bool:
-    """Check if file matches any hierarchical ignore patterns."""
-    for directory in _get_parent_directories(file_path, target_path):
-        if directory in ignore_files_map:
-            if _check_patterns_in_directory(file_path, directory, ignore_files_map[directory]):
-                return True
-    return False
+    """Check if file matches any hierarchical ignore patterns.
+
+    Directories are visited from the top down so a deeper ignore file overrides its parents,
+    starting with those above the target (the git worktree root holding git's own excludes).
+    """
+    directories = _outer_directories(target_path, ignore_files_map)
+    directories.extend(reversed(_get_parent_directories(file_path, target_path)))
+    ignored = False
+    for directory in directories:
+        decision = _check_patterns_in_directory(file_path, directory, ignore_files_map.get(directory, []))
+        if decision is not None:
+            ignored = decision
+    return ignored
 
 
 def should_ignore_file(
@@ -264,23 +454,141 @@ def _collect_single_file(
     return [target_path]
 
 
+def _source_extensions() -> list[str]:
+    """Return every file extension treepeat can scan."""
+    return [ext for exts in LANGUAGE_EXTENSIONS.values() for ext in exts] + NOTEBOOK_EXTENSIONS
+
+
+def _follow_link(link: Path, root: Path, visited: set[Path]) -> bool:
+    """Follow a symlink only once per target, and never back into the scan root (scanned directly)."""
+    target = link.resolve()
+    if not target.exists() or target in visited:
+        logger.debug(f"Skipping symlink {link}: broken, cyclic or already followed")
+        return False
+    if target.is_relative_to(root):
+        logger.debug(f"Skipping symlink {link}: its target is scanned directly under {root}")
+        return False
+    visited.add(target)
+    return True
+
+
+def _is_vendored(directory: Path) -> bool:
+    """True for a dependency or build-output directory (node_modules, vendor, target, ...) to skip."""
+    return directory.name in VENDORED_DIRECTORIES and not get_settings().include_vendored
+
+
+def _keep_entry(path: Path, root: Path, visited: set[Path]) -> bool:
+    """Keep regular entries; keep symlinks only with --follow-symlinks and when worth following."""
+    if not path.is_symlink():
+        return True
+    return get_settings().follow_symlinks and _follow_link(path, root, visited)
+
+
+def walk_files(target_path: Path) -> list[Path]:
+    """List every file under a directory, in a stable order.
+
+    Vendored directories are not descended into, and symlinks are skipped
+    unless --follow-symlinks is given. Followed links that
+    point into the scan root, to a broken target, or to a directory already
+    walked (such as a link to one of its own parents) are skipped, so the walk
+    always ends.
+    """
+    root = target_path.resolve()
+    visited: set[Path] = set()
+    files: list[Path] = []
+    for dirpath, dirnames, filenames in os.walk(target_path, followlinks=get_settings().follow_symlinks):
+        directory = Path(dirpath)
+        dirnames[:] = sorted(
+            name
+            for name in dirnames
+            if not _is_vendored(directory / name) and _keep_entry(directory / name, root, visited)
+        )
+        files.extend(directory / name for name in sorted(filenames) if _keep_entry(directory / name, root, visited))
+    return files
+
+
+def _unextended_sources(files: list[Path], target_path: Path) -> list[Path]:
+    """Find sources not recognized by extension: named files (Dockerfile.prod) and shebang scripts."""
+    return [
+        file
+        for file in files
+        if not _is_hidden(file, target_path) and (detect_filename_language(file) or detect_shebang_language(file))
+    ]
+
+
 def _collect_directory_files(
     target_path: Path, ignore_patterns: list[str], ignore_file_patterns: list[str]
 ) -> list[Path]:
     """Collect all source files from a directory."""
     ignore_files_map = find_ignore_files(target_path, ignore_file_patterns)
-
-    files: list[Path] = []
-    for _lang, exts in LANGUAGE_EXTENSIONS.items():
-        for ext in exts:
-            for file in target_path.rglob(f"*{ext}"):
-                if not should_ignore_file(file, target_path, ignore_patterns, ignore_files_map):
-                    files.append(file)
-
+    walked = walk_files(target_path)
+    extensions = tuple(_source_extensions())
+    candidates = [file for file in walked if file.name.endswith(extensions)] + _unextended_sources(walked, target_path)
+    files = [
+        file for file in candidates if not should_ignore_file(file, target_path, ignore_patterns, ignore_files_map)
+    ]
+
+    # A named file may also carry a scanned extension (Containerfile.yaml is still a Dockerfile)
+    files = list(dict.fromkeys(files))
     logger.info(f"Found {len(files)} source files in directory (after applying ignore patterns)")
     return files
 
 
+def in_scope(file_path: Path, base_path: Path, include: list[str], exclude: list[str]) -> bool:
+    """True if a file matches an --include glob (when any are given) and no --exclude glob."""
+    if include and not any(matches_pattern(file_path, pattern, base_path) for pattern in include):
+        return False
+    return not any(matches_pattern(file_path, pattern, base_path) for pattern in exclude)
+
+
+def _apply_globs(files: list[Path], base_path: Path) -> list[Path]:
+    """Narrow collected files to the --include/--exclude globs, relative to the scanned directory."""
+    settings = get_settings()
+    if not (settings.include_patterns or settings.exclude_patterns):
+        return files
+    scoped = [f for f in files if in_scope(f, base_path, settings.include_patterns, settings.exclude_patterns)]
+    logger.info(f"{len(scoped)} of {len(files)} files left after --include/--exclude")
+    return scoped
+
+
+def _without_tests(files: list[Path], base_path: Path) -> list[Path]:
+    """Drop test files (by their name or directory below the scanned directory) with --tests exclude."""
+    if get_settings().tests != "exclude":
+        return files
+    kept = [f for f in files if not is_test_path(f, base_path)]
+    logger.info(f"{len(kept)} of {len(files)} files left after --tests exclude")
+    return kept
+
+
+def in_shard(file_path: Path, base_path: Path, shard: tuple[int, int]) -> bool:
+    """True if a file falls in shard K of N, by a hash of its path below the scanned directory.
+
+    The same file always lands in the same shard, whatever machine or order it is scanned in.
+    """
+    index, count = shard
+    try:
+        relative = file_path.relative_to(base_path).as_posix()
+    except ValueError:
+        relative = file_path.as_posix()
+    digest = hashlib.sha1(relative.encode("utf-8")).digest()
+    return int.from_bytes(digest[:8], "big") % count == index - 1
+
+
+def _in_shard(files: list[Path], base_path: Path) -> list[Path]:
+    """Keep only the files of this run's slice with --shard."""
+    shard = get_settings().shard
+    if shard is None:
+        return files
+    kept = [f for f in files if in_shard(f, base_path, shard)]
+    logger.info(f"{len(kept)} of {len(files)} files left in shard {shard[0]}/{shard[1]}")
+    return kept
+
+
+def _apply_scope(files: list[Path], base_path: Path) -> list[Path]:
+    """Narrow collected files to the --include/--exclude globs, --tests and --shard."""
+    return _in_shard(_without_tests(_apply_globs(files, base_path), base_path), base_path)
+
+
 def collect_source_files(target_path: Path) -> list[Path]:
     """Collect all source files from a path with ignore patterns applied."""
     settings = get_settings()
@@ -288,41 +596,124 @@ def collect_source_files(target_path: Path) -> list[Path]:
     ignore_file_patterns = settings.ignore_file_patterns
 
     if target_path.is_file():
-        return _collect_single_file(target_path, ignore_patterns, ignore_file_patterns)
+        return _apply_scope(
+            _collect_single_file(target_path, ignore_patterns, ignore_file_patterns), target_path.parent
+        )
 
     if target_path.is_dir():
-        return _collect_directory_files(target_path, ignore_patterns, ignore_file_patterns)
+        return _apply_scope(_collect_directory_files(target_path, ignore_patterns, ignore_file_patterns), target_path)
 
     return []
 
 
-def parse_files(files: list[Path], result: ParseResult, progress: bool = False) -> None:
-    """Parse a list of files and update the result."""
-    iterable = (
-        tqdm(files, desc="Parsing", unit="file", file=sys.stderr)
-        if progress
-        else files
-    )
-    for file_path in iterable:
-        try:
-            parsed = parse_file(file_path)
-            result.parsed_files.append(parsed)
-        except Exception as e:
-            logger.warning(f"Failed to parse {file_path}: {e}")
+def _is_hidden(file_path: Path, target_path: Path) -> bool:
+    """True if the file or a directory between it and the target is hidden (e.g. .git)."""
+    rel_path = _get_relative_path(file_path, target_path)
+    return rel_path is not None and any(part.startswith(".") for part in Path(rel_path).parts)
+
+
+def collect_fallback_files(target_path: Path) -> list[Path]:
+    """Collect files that no tree-sitter grammar handles (for the token fallback), honoring ignores."""
+    if not target_path.is_dir():
+        candidates = [target_path]
+        target_path = target_path.parent
+    else:
+        candidates = [f for f in walk_files(target_path) if not _is_hidden(f, target_path)]
+
+    settings = get_settings()
+    ignore_files_map = find_ignore_files(target_path, settings.ignore_file_patterns)
+    known_extensions = set(_source_extensions())
+    files = [
+        f
+        for f in sorted(candidates)
+        if f.suffix.lower() not in known_extensions
+        and not detect_language(f)
+        and not should_ignore_file(f, target_path, settings.ignore_patterns, ignore_files_map)
+    ]
+    return _within_size_limit(_apply_scope(files, target_path))
+
+
+def _within_size_limit(files: list[Path]) -> list[Path]:
+    """Drop (with a warning) files larger than --max-file-size, before they are read."""
+    max_size = get_settings().max_file_size
+    if max_size is None:
+        return files
+    kept = []
+    for file_path in files:
+        size = file_path.stat().st_size
+        if size > max_size:
+            logger.warning(f"Skipping {file_path}: {size} bytes exceeds the {max_size} byte limit")
+        else:
+            kept.append(file_path)
+    return kept
+
+
+def _skip_reason(file_path: Path) -> str | None:
+    """Why a collected file is not scanned (generated code, minified JavaScript), or None to scan it."""
+    settings = get_settings()
+    if not settings.include_generated and is_generated(file_path, settings.generated_markers):
+        return "generated code (use --include-generated to scan it)"
+    if settings.include_minified or detect_language(file_path) not in MINIFIABLE_LANGUAGES:
+        return None
+    if is_minified(file_path, read_source_file(file_path)):
+        return "minified or bundled JavaScript (use --include-minified to scan it)"
+    return None
+
 
+def _is_scanned(file_path: Path) -> bool:
+    """True unless the file is skipped as generated or minified, which is logged."""
+    reason = _skip_reason(file_path)
+    if reason is not None:
+        logger.info(f"Skipping {file_path}: {reason}")
+    return reason is None
 
-def parse_path(target_path: Path, progress: bool = False) -> ParseResult:
-    """Parse a file or directory of source files."""
+
+def _parse_isolated(file_path: Path) -> list[ParsedFile]:
+    """Parse one file; a failure is logged and skips only that file."""
+    try:
+        return _parse_any(file_path)
+    except ParseTimeoutError as e:
+        logger.warning(f"Skipping {file_path}: {e}")
+    except Exception as e:
+        logger.warning(f"Failed to parse {file_path}: {e}")
+    return []
+
+
+def parse_files(files: list[Path], result: ParseResult, progress: bool = False, jobs: int = 1) -> None:
+    """Parse a list of files and update the result.
+
+    Files are read and parsed by ``jobs`` worker threads; results are collected
+    in file order, so the outcome does not depend on how they are scheduled.
+    """
+    with ThreadPoolExecutor(max_workers=jobs) as executor:
+        parsed: Iterable[list[ParsedFile]] = executor.map(_parse_isolated, files)
+        if progress:
+            parsed = track(parsed, "Parsing", "file", total=len(files))
+        for file_parsed in parsed:
+            result.parsed_files.extend(file_parsed)
+
+
+def _walk(target_path: Path) -> list[Path]:
+    """Collect the source files under a path, timing it as the walk stage."""
+    started = time.monotonic()
+    files = collect_source_files(target_path)
+    record_stage_timing("walk", time.monotonic() - started)
+    return files
+
+
+def parse_path(target_path: Path, progress: bool = False, files: list[Path] | None = None) -> ParseResult:
+    """Parse a file or directory of source files (``files`` instead of those collected under it, when given)."""
     logger.info(f"Starting parse of: {target_path}")
 
     result = ParseResult()
-    files = collect_source_files(target_path)
+    candidates = _walk(target_path) if files is None else files
+    scanned = [f for f in _within_size_limit(candidates) if _is_scanned(f)]
 
-    if not files:
+    if not scanned:
         logger.warning(f"Path does not exist or contains no source files: {target_path}")
         return result
 
-    parse_files(files, result, progress=progress)
+    parse_files(scanned, result, progress=progress, jobs=get_settings().jobs)
 
     logger.info(f"Parse complete: {result.success_count} succeeded")
 
@@ -2,17 +2,25 @@ import logging
 import time
 from pathlib import Path
 
-from treepeat.config import PipelineSettings, get_settings
+from treepeat.config import LSHSettings, MaxGap, PipelineSettings, get_settings
 from treepeat.models.ast import ParsedFile, ParseResult
 from treepeat.models.shingle import ShingledRegion
 from treepeat.models.similarity import (
+    GroupCallback,
     RegionSignature,
     SimilarityResult,
     SimilarRegionGroup,
+    TokenCounts,
+    token_counts,
 )
+from treepeat.pipeline.cache import FingerprintCache, shingle_with_cache
+from treepeat.pipeline.complexity import compute_complexity
+from treepeat.pipeline.file_stage import detect_duplicate_files
+from treepeat.pipeline.index import IncrementalRun, content_hashes, index_file
+from treepeat.pipeline.imports import import_lines, is_mostly_imports
 from treepeat.pipeline.lsh_stage import detect_similarity
 from treepeat.pipeline.minhash_stage import compute_region_signatures
-from treepeat.pipeline.parse import parse_path
+from treepeat.pipeline.parse import collect_fallback_files, parse_path
 from treepeat.pipeline.region_extraction import (
     ExtractedRegion,
     extract_all_regions,
@@ -20,19 +28,37 @@ from treepeat.pipeline.region_extraction import (
 from treepeat.pipeline.rules.engine import RuleEngine
 from treepeat.pipeline.rules_factory import build_rule_engine
 from treepeat.pipeline.shingle import shingle_regions
-from treepeat.pipeline.verbose_metrics import record_stage_count, record_stage_timing
+from treepeat.pipeline.suppress import SuppressionIndex
+from treepeat.pipeline.testcode import split_test_groups, tests_callback
+from treepeat.pipeline.thresholds import group_meets_thresholds, loosest_min_lines, loosest_similarity
+from treepeat.pipeline.token_fallback import extract_token_regions, is_binary
+from treepeat.pipeline.verbose_metrics import (
+    fragment_counts_since,
+    get_verbose_metrics,
+    record_parsed_lines,
+    record_stage_count,
+    record_stage_timing,
+)
+from treepeat.pipeline.winnow import winnow_regions
 
 logger = logging.getLogger(__name__)
 
 
-def _run_parse_stage(target_path: Path, progress: bool = False) -> ParseResult:
+def _line_count(source: bytes) -> int:
+    """Lines of a source file, a last line without a newline included."""
+    unterminated = bool(source) and not source.endswith(b"\n")
+    return source.count(b"\n") + int(unterminated)
+
+
+def _run_parse_stage(target_path: Path, progress: bool = False, files: list[Path] | None = None) -> ParseResult:
     """Run parsing stage."""
     logger.info("Stage 1/5: Parsing...")
     _t = time.monotonic()
-    parse_result = parse_path(target_path, progress=progress)
+    parse_result = parse_path(target_path, progress=progress, files=files)
     elapsed = time.monotonic() - _t
     record_stage_timing("parse", elapsed)
     record_stage_count("parse", parse_result.success_count)
+    record_parsed_lines(sum(_line_count(parsed.source) for parsed in parse_result.parsed_files))
     logger.info("Parse complete: %d succeeded (%.1fs)", parse_result.success_count, elapsed)
     return parse_result
 
@@ -56,26 +82,111 @@ def _run_extract_stage(
     return extracted_regions
 
 
-def _filter_groups_by_min_lines(
-    groups: list[SimilarRegionGroup], min_lines: int
+def _run_fallback_stage(target_path: Path, settings: PipelineSettings) -> list[ShingledRegion]:
+    """Shingle files without a tree-sitter grammar with the token fallback (when enabled)."""
+    if settings.fallback != "token":
+        return []
+    files = [f for f in collect_fallback_files(target_path) if not is_binary(f)]
+    min_lines = loosest_min_lines(settings.lsh)
+    regions = [
+        region
+        for f in files
+        for region in extract_token_regions(f, settings.shingle.k, min_lines, settings.rules.normalize)
+    ]
+    logger.info("Token fallback: %d region(s) from %d file(s) without a grammar", len(regions), len(files))
+    return regions
+
+
+def _run_file_stage(
+    parsed_files: list[ParsedFile],
+    settings: PipelineSettings,
+    on_group: GroupCallback | None,
+    focus: set[Path] | None = None,
+) -> tuple[list[SimilarRegionGroup], list[ParsedFile]]:
+    """Find whole duplicate files (when enabled) and return them with the files left for fragment analysis.
+
+    Only the representative of each duplicate file group keeps going, so a
+    copied file is reported once rather than once per function it contains.
+    With ``focus`` only the groups involving one of those files are reported.
+    """
+    if settings.file_similarity is None:
+        return [], parsed_files
+    files = {pf.path: pf.language for pf in parsed_files}
+    groups = detect_duplicate_files(
+        files,
+        settings.file_similarity,
+        settings.lsh.min_lines,
+        settings.minhash.num_perm,
+        _focus_callback(on_group, focus),
+    )
+    copies = {region.path for group in groups for region in group.regions[1:]}
+    return _focused_groups(groups, focus), [pf for pf in parsed_files if pf.path not in copies]
+
+
+def _filter_groups_by_thresholds(
+    groups: list[SimilarRegionGroup], lsh: LSHSettings, tokens: TokenCounts
 ) -> list[SimilarRegionGroup]:
-    """Filter similar groups to only include those meeting the minimum line count in all regions."""
+    """Filter similar groups to only include those meeting the (per-language) thresholds in all regions."""
     filtered = []
     for group in groups:
-        # Check if all regions meet the min_lines threshold
-        all_meet_threshold = all(
-            region.end_line - region.start_line + 1 >= min_lines for region in group.regions
-        )
-        if all_meet_threshold:
+        if group_meets_thresholds(group, lsh, tokens):
             filtered.append(group)
         else:
             logger.debug(
-                "Filtered out group with %d regions - at least one region below min_lines threshold",
+                "Filtered out group with %d regions - at least one region below its language's thresholds",
                 len(group.regions),
             )
     return filtered
 
 
+def _focus_paths(
+    parse_result: ParseResult, fallback_regions: list[ShingledRegion], changed_files: set[Path] | None
+) -> set[Path] | None:
+    """The scanned paths that are among the changed files (None when the scan is not limited to changes)."""
+    if changed_files is None:
+        return None
+    paths = {pf.path for pf in parse_result.parsed_files} | {sr.region.path for sr in fallback_regions}
+    focus = {path for path in paths if path.resolve() in changed_files}
+    logger.info("Limiting the search to clones of %d changed file(s) out of %d", len(focus), len(paths))
+    return focus
+
+
+def _touches_focus(group: SimilarRegionGroup, focus: set[Path] | None) -> bool:
+    """True if a group has a region in one of the focus files (or the scan is not limited)."""
+    return focus is None or any(region.path in focus for region in group.regions)
+
+
+def _focused_groups(groups: list[SimilarRegionGroup], focus: set[Path] | None) -> list[SimilarRegionGroup]:
+    """The groups that touch the focus files."""
+    return [group for group in groups if _touches_focus(group, focus)]
+
+
+def _focus_callback(on_group: GroupCallback | None, focus: set[Path] | None) -> GroupCallback | None:
+    """Wrap a group callback so it only sees groups that touch the focus files."""
+    if on_group is None or focus is None:
+        return on_group
+
+    def callback(group: SimilarRegionGroup) -> None:
+        if _touches_focus(group, focus):
+            on_group(group)
+
+    return callback
+
+
+def _thresholds_callback(
+    on_group: GroupCallback | None, lsh: LSHSettings, tokens: TokenCounts
+) -> GroupCallback | None:
+    """Wrap a group callback so it only sees groups that survive the thresholds filter."""
+    if on_group is None:
+        return None
+
+    def callback(group: SimilarRegionGroup) -> None:
+        if group_meets_thresholds(group, lsh, tokens):
+            on_group(group)
+
+    return callback
+
+
 def _run_shingle_stage(
     extracted_regions: list[ExtractedRegion],
     parsed_files: list[ParsedFile],
@@ -92,6 +203,8 @@ def _run_shingle_stage(
         rule_engine=rule_engine,
         k=settings.shingle.k,
         progress=progress,
+        cross_language=settings.shingle.cross_language,
+        canonicalize=settings.shingle.canonicalize,
     )
     elapsed = time.monotonic() - _t
     record_stage_timing("shingle", elapsed)
@@ -101,7 +214,7 @@ def _run_shingle_stage(
 
 
 def _run_minhash_stage(
-    shingled_regions: list[ShingledRegion], num_perm: int, progress: bool = False
+    shingled_regions: list[ShingledRegion], num_perm: int, progress: bool = False, jobs: int = 1
 ) -> list[RegionSignature]:
     """Run MinHash signature computation stage."""
     logger.info("Stage 4/5: Computing MinHash signatures...")
@@ -110,6 +223,7 @@ def _run_minhash_stage(
         shingled_regions,
         num_perm=num_perm,
         progress=progress,
+        jobs=jobs,
     )
     elapsed = time.monotonic() - _t
     record_stage_timing("minhash", elapsed)
@@ -125,6 +239,11 @@ def _run_lsh_stage(
     min_lines: int,
     rule_engine: RuleEngine,
     progress: bool = False,
+    jobs: int = 1,
+    on_group: GroupCallback | None = None,
+    max_gap_lines: MaxGap | None = None,
+    focus: set[Path] | None = None,
+    max_index_memory: int | None = None,
 ) -> SimilarityResult:
     """Run LSH similarity detection stage."""
     logger.info("Stage 5/5: Finding similar pairs...")
@@ -136,6 +255,11 @@ def _run_lsh_stage(
         min_lines=min_lines,
         rules=rule_engine.rules,
         progress=progress,
+        jobs=jobs,
+        on_group=on_group,
+        max_gap_lines=max_gap_lines,
+        focus=focus,
+        max_index_memory=max_index_memory,
     )
     elapsed = time.monotonic() - _t
     record_stage_timing("lsh", elapsed)
@@ -175,31 +299,86 @@ def _filter_regions_by_min_lines(
     return filtered
 
 
-def _run_region_matching(
+def _filter_regions_by_complexity(
+    regions: list[ExtractedRegion], min_complexity: int
+) -> list[ExtractedRegion]:
+    """Filter regions whose control flow is too simple to be meaningful duplication."""
+    if min_complexity <= 1:
+        return regions
+
+    filtered = [r for r in regions if compute_complexity(r.nodes or [r.node]) >= min_complexity]
+    if len(filtered) < len(regions):
+        logger.info(
+            "Filtered %d region(s) below min_complexity=%d before processing",
+            len(regions) - len(filtered),
+            min_complexity,
+        )
+    return filtered
+
+
+def _file_imports(parsed_file: ParsedFile) -> tuple[list[str], set[int]]:
+    lines = parsed_file.source.decode("utf-8", errors="replace").splitlines()
+    return lines, import_lines(lines)
+
+
+def _is_import_region(region: ExtractedRegion, file_imports: dict[Path, tuple[list[str], set[int]]]) -> bool:
+    lines, imports = file_imports[region.region.path]
+    return is_mostly_imports(lines, region.region.start_line, region.region.end_line, imports)
+
+
+def _filter_regions_by_imports(
+    regions: list[ExtractedRegion], parsed_files: list[ParsedFile]
+) -> list[ExtractedRegion]:
+    """Filter regions that mostly consist of import/include/using statements: identical ones are pure noise."""
+    file_imports = {pf.path: _file_imports(pf) for pf in parsed_files}
+    filtered = [r for r in regions if not _is_import_region(r, file_imports)]
+    if len(filtered) < len(regions):
+        logger.info("Filtered %d region(s) of mostly imports before processing", len(regions) - len(filtered))
+    return filtered
+
+
+def _log_groups(groups: list[SimilarRegionGroup]) -> None:
+    """Log each group and its regions at debug level."""
+    for group in groups:
+        logger.debug(
+            "  Group: %d regions, similarity=%.2f%%", len(group.regions), group.similarity * 100
+        )
+        for region in group.regions:
+            lines = region.end_line - region.start_line + 1
+            logger.debug(
+                "    - %s [%d:%d] (%d lines)",
+                region.region_name,
+                region.start_line,
+                region.end_line,
+                lines,
+            )
+
+
+def _extract_and_shingle_files(
     parsed_files: list[ParsedFile],
     rule_engine: RuleEngine,
     settings: PipelineSettings,
     progress: bool = False,
-) -> tuple[list[SimilarRegionGroup], list[RegionSignature]]:
-    """Run region matching for functions and classes."""
-    logger.info("===== REGION MATCHING =====")
-
+) -> list[ShingledRegion]:
+    """Extract regions from parsed files, drop those below the thresholds, and shingle the rest."""
     # Extract regions
     extracted_regions = _run_extract_stage(parsed_files, rule_engine, progress=progress)
 
     # If no regions, skip region matching entirely
     if not extracted_regions:
         logger.info("No regions found, skipping region matching")
-        return [], []
+        return []
 
     # Filter out regions that are too short before processing
-    extracted_regions = _filter_regions_by_min_lines(extracted_regions, settings.lsh.min_lines)
+    extracted_regions = _filter_regions_by_min_lines(extracted_regions, loosest_min_lines(settings.lsh))
+    extracted_regions = _filter_regions_by_complexity(extracted_regions, settings.lsh.min_complexity)
+    extracted_regions = _filter_regions_by_imports(extracted_regions, parsed_files)
     if not extracted_regions:
-        logger.info("No regions above min_lines threshold, skipping region matching")
-        return [], []
+        logger.info("No regions above min_lines/min_complexity thresholds, skipping region matching")
+        return []
 
     # Shingle regions
-    region_shingled = _run_shingle_stage(
+    return _run_shingle_stage(
         extracted_regions,
         parsed_files,
         rule_engine,
@@ -207,44 +386,111 @@ def _run_region_matching(
         progress=progress,
     )
 
+
+def _extract_and_shingle(
+    parsed_files: list[ParsedFile],
+    rule_engine: RuleEngine,
+    settings: PipelineSettings,
+    progress: bool = False,
+) -> list[ShingledRegion]:
+    """Shingle the regions of parsed files, reusing those of files found in the fingerprint cache."""
+    if settings.cache_dir is None:
+        return _extract_and_shingle_files(parsed_files, rule_engine, settings, progress=progress)
+    cache = FingerprintCache(settings.cache_dir, settings)
+    return shingle_with_cache(
+        parsed_files,
+        cache,
+        lambda files: _extract_and_shingle_files(files, rule_engine, settings, progress=progress),
+    )
+
+
+def _apply_strategy(shingled_regions: list[ShingledRegion], settings: PipelineSettings) -> list[ShingledRegion]:
+    """Reduce shingles to winnowed fingerprints when the winnow strategy is selected."""
+    if settings.shingle.strategy != "winnow":
+        return shingled_regions
+    return winnow_regions(shingled_regions, settings.shingle.winnow_window)
+
+
+def _run_region_matching(
+    parsed_files: list[ParsedFile],
+    rule_engine: RuleEngine,
+    settings: PipelineSettings,
+    progress: bool = False,
+    on_group: GroupCallback | None = None,
+    extra_shingled: list[ShingledRegion] | None = None,
+    suppressions: SuppressionIndex | None = None,
+    focus: set[Path] | None = None,
+) -> tuple[list[SimilarRegionGroup], list[RegionSignature]]:
+    """Run region matching for functions and classes.
+
+    ``extra_shingled`` holds regions shingled outside of tree-sitter (the token
+    fallback); they are compared alongside the extracted regions. The extracted
+    regions are registered with ``suppressions`` before any group is reported.
+    Every region is indexed, but only groups with a region in ``focus`` files
+    (all files when None) are looked for.
+    """
+    logger.info("===== REGION MATCHING =====")
+
+    region_shingled = _extract_and_shingle(parsed_files, rule_engine, settings, progress=progress)
+    return _match_shingled(
+        region_shingled + (extra_shingled or []),
+        rule_engine,
+        settings,
+        progress=progress,
+        on_group=on_group,
+        suppressions=suppressions,
+        focus=focus,
+    )
+
+
+def _match_shingled(
+    shingled_regions: list[ShingledRegion],
+    rule_engine: RuleEngine,
+    settings: PipelineSettings,
+    progress: bool = False,
+    on_group: GroupCallback | None = None,
+    suppressions: SuppressionIndex | None = None,
+    focus: set[Path] | None = None,
+) -> tuple[list[SimilarRegionGroup], list[RegionSignature]]:
+    """Fingerprint shingled regions and group the similar ones that meet the thresholds."""
+    region_shingled = _apply_strategy(shingled_regions, settings)
+    if not region_shingled:
+        return [], []
+
     # MinHash region
     region_signatures = _run_minhash_stage(
         region_shingled,
         settings.minhash.num_perm,
         progress=progress,
+        jobs=settings.jobs,
     )
+    if suppressions is not None:
+        suppressions.add_regions(sig.region for sig in region_signatures)
+    tokens = token_counts(region_signatures)
 
+    # Candidates are collected with the loosest per-language thresholds, then filtered per language
     region_result = _run_lsh_stage(
         region_signatures,
         region_shingled,
-        settings.lsh.similarity_percent,
-        settings.lsh.min_lines,
+        loosest_similarity(settings.lsh),
+        loosest_min_lines(settings.lsh),
         rule_engine,
         progress=progress,
+        jobs=settings.jobs,
+        on_group=_thresholds_callback(on_group, settings.lsh, tokens),
+        max_gap_lines=settings.lsh.max_gap_lines,
+        focus=focus,
+        max_index_memory=settings.max_index_memory,
     )
 
-    # Filter by min_lines
+    # Filter by min_lines (and the other per-language thresholds)
     logger.debug(
         "Region matching: Filtering %d groups by min_lines=%d",
         len(region_result.similar_groups),
         settings.lsh.min_lines,
     )
-    for group in region_result.similar_groups:
-        logger.debug(
-            "  Group: %d regions, similarity=%.2f%%", len(group.regions), group.similarity * 100
-        )
-        for region in group.regions:
-            lines = region.end_line - region.start_line + 1
-            logger.debug(
-                "    - %s [%d:%d] (%d lines)",
-                region.region_name,
-                region.start_line,
-                region.end_line,
-                lines,
-            )
-    region_filtered_groups = _filter_groups_by_min_lines(
-        region_result.similar_groups, settings.lsh.min_lines
-    )
+    _log_groups(region_result.similar_groups)
+    region_filtered_groups = _filter_groups_by_thresholds(region_result.similar_groups, settings.lsh, tokens)
     logger.info(
         "Region matching complete: %d groups after filtering (was %d)",
         len(region_filtered_groups),
@@ -254,8 +500,84 @@ def _run_region_matching(
     return region_filtered_groups, region_signatures
 
 
-def run_pipeline(target_path: str | Path, progress: bool = False) -> SimilarityResult:
-    """Run the similarity detection pipeline on a target path."""
+def _incremental_run(
+    target_path: Path,
+    parsed_files: list[ParsedFile],
+    fallback_regions: list[ShingledRegion],
+    settings: PipelineSettings,
+) -> IncrementalRun | None:
+    """Start updating the clone index of the scanned path (only with a cache, and not with --changed-since)."""
+    if settings.cache_dir is None or settings.changed_files is not None:
+        return None
+    scanned = {pf.path for pf in parsed_files} | {sr.region.path for sr in fallback_regions}
+    return IncrementalRun(index_file(settings.cache_dir, target_path, settings), content_hashes(scanned))
+
+
+def _region_focus(incremental: IncrementalRun | None, focus: set[Path] | None) -> set[Path] | None:
+    """The files whose clones are looked for: those the clone index needs searched again, when updating it."""
+    return focus if incremental is None else incremental.focus
+
+
+def _finish_incremental(
+    incremental: IncrementalRun | None, found: list[SimilarRegionGroup], on_group: GroupCallback | None
+) -> list[SimilarRegionGroup]:
+    """Report the groups reused from the clone index with those found in this run, and update the index."""
+    if incremental is None:
+        return found
+    groups = incremental.finish(found)
+    if on_group is not None:
+        for group in groups[len(found):]:
+            on_group(group)
+    return groups
+
+
+def _test_callback(on_group: GroupCallback | None, root: Path, settings: PipelineSettings) -> GroupCallback | None:
+    """Keep the groups only in test code from the streamed groups with --tests separate."""
+    if settings.tests != "separate":
+        return on_group
+    return tests_callback(on_group, root)
+
+
+def _split_tests(
+    groups: list[SimilarRegionGroup], root: Path, settings: PipelineSettings
+) -> tuple[list[SimilarRegionGroup], list[SimilarRegionGroup]]:
+    """Set the groups only in test code apart with --tests separate."""
+    if settings.tests != "separate":
+        return groups, []
+    return split_test_groups(groups, root)
+
+
+def _similarity_result(
+    groups: list[SimilarRegionGroup],
+    signatures: list[RegionSignature],
+    suppressions: SuppressionIndex,
+    root: Path,
+    settings: PipelineSettings,
+) -> SimilarityResult:
+    """The final result: groups hidden by suppression comments and those only in test code set apart."""
+    reported_groups, suppressed_groups = suppressions.split(groups)
+    reported_groups, test_groups = _split_tests(reported_groups, root, settings)
+    return SimilarityResult(
+        signatures=signatures,
+        similar_groups=reported_groups,
+        suppressed_groups=suppressed_groups,
+        test_groups=test_groups,
+    )
+
+
+def run_pipeline(
+    target_path: str | Path,
+    progress: bool = False,
+    on_group: GroupCallback | None = None,
+    files: list[Path] | None = None,
+) -> SimilarityResult:
+    """Run the similarity detection pipeline on a target path.
+
+    ``on_group`` is called with each reported group as soon as it is found,
+    before the pipeline completes; the returned result still holds every group.
+    ``files``, when given, are parsed instead of the source files collected
+    under the target path.
+    """
     settings = get_settings()
     logger.info("Starting pipeline for: %s (min_lines=%d)", target_path, settings.lsh.min_lines)
 
@@ -263,23 +585,80 @@ def run_pipeline(target_path: str | Path, progress: bool = False) -> SimilarityR
         target_path = Path(target_path)
 
     rule_engine = build_rule_engine(settings)
+    fragments_before = dict(get_verbose_metrics().fragment_counts)
 
     # Stage 1: Parse
-    parse_result = _run_parse_stage(target_path, progress=progress)
-    if parse_result.success_count == 0:
+    parse_result = _run_parse_stage(target_path, progress=progress, files=files)
+    fallback_regions = _run_fallback_stage(target_path, settings)
+    if parse_result.success_count == 0 and not fallback_regions:
         logger.warning("No files successfully parsed, returning empty result")
         return SimilarityResult()
 
+    # With --changed-since the whole tree is indexed but only clones of the changed files are reported
+    focus = _focus_paths(parse_result, fallback_regions, settings.changed_files)
+
+    # Groups annotated with treepeat:ignore comments are never streamed nor reported
+    suppressions = SuppressionIndex()
+    on_group = suppressions.callback(on_group)
+    # With --tests separate, groups only in test code are reported apart, at the end
+    on_group = _test_callback(on_group, target_path, settings)
+
+    # Whole-file duplicates are reported first; their extra copies skip fragment analysis
+    file_groups, parsed_files = _run_file_stage(parse_result.parsed_files, settings, on_group, focus)
+
+    # With a cache, groups of files unchanged since the previous run are reused from the clone index
+    incremental = _incremental_run(target_path, parsed_files, fallback_regions, settings)
+
     # Run Region Matching
     similar_groups, signatures = _run_region_matching(
-        parse_result.parsed_files, rule_engine, settings, progress=progress
-    )
-
-    # Create final result
-    final_result = SimilarityResult(
-        signatures=signatures,
-        similar_groups=similar_groups,
+        parsed_files,
+        rule_engine,
+        settings,
+        progress=progress,
+        on_group=on_group,
+        extra_shingled=fallback_regions,
+        suppressions=suppressions,
+        focus=_region_focus(incremental, focus),
     )
+    similar_groups = _finish_incremental(incremental, similar_groups, on_group)
+    final_result = _similarity_result(file_groups + similar_groups, signatures, suppressions, target_path, settings)
+    final_result.fragment_counts = fragment_counts_since(get_verbose_metrics().fragment_counts, fragments_before)
 
-    logger.info("Pipeline complete: %d groups found", len(similar_groups))
+    logger.info("Pipeline complete: %d groups found", len(final_result.similar_groups))
     return final_result
+
+
+def shingle_shard(target_path: Path, progress: bool = False) -> list[ShingledRegion]:
+    """Parse and shingle the files of one --shard slice, leaving the matching to match_shingled_regions."""
+    settings = get_settings()
+    logger.info("Shingling shard %s of: %s", settings.shard, target_path)
+    rule_engine = build_rule_engine(settings)
+    parse_result = _run_parse_stage(target_path, progress=progress)
+    fallback_regions = _run_fallback_stage(target_path, settings)
+    shingled = _extract_and_shingle(parse_result.parsed_files, rule_engine, settings, progress=progress)
+    return shingled + fallback_regions
+
+
+def match_shingled_regions(
+    shingled_regions: list[ShingledRegion], root: Path, progress: bool = False, on_group: GroupCallback | None = None
+) -> SimilarityResult:
+    """Find the clone groups among regions shingled by earlier runs (the shards of one scan of ``root``).
+
+    Suppression comments and --tests separate are applied as in run_pipeline;
+    whole-file duplicates are reported as the region clones they contain.
+    """
+    settings = get_settings()
+    suppressions = SuppressionIndex()
+    on_group = _test_callback(suppressions.callback(on_group), root, settings)
+    logger.info("===== REGION MATCHING =====")
+    groups, signatures = _match_shingled(
+        shingled_regions,
+        build_rule_engine(settings),
+        settings,
+        progress=progress,
+        on_group=on_group,
+        suppressions=suppressions,
+    )
+    result = _similarity_result(groups, signatures, suppressions, root, settings)
+    logger.info("Merge complete: %d groups found", len(result.similar_groups))
+    return result
@@ -0,0 +1,102 @@
+"""Cheap checks that reject LSH candidate pairs which cannot be similar enough, before their signatures are compared."""
+
+import hashlib
+import logging
+import math
+from collections.abc import Iterable
+
+from treepeat.models.similarity import RegionSignature
+
+logger = logging.getLogger(__name__)
+
+# Bits of a region's Bloom filter per distinct shingle (rounded up to a power of two), so that
+# filters stay far from saturated however large the region; each shingle sets two bits
+BLOOM_BITS_PER_SHINGLE = 16
+_BLOOM_HASHES = 2
+_MIN_BLOOM_BITS = 256
+
+# How far below the threshold the Bloom estimate of a pair must fall for it to be rejected:
+# the estimate of small regions is noisy, and rejecting a pair that is similar enough loses a clone
+BLOOM_MARGIN = 0.2
+
+
+def bloom_size(shingle_count: int) -> int:
+    """Bits of the Bloom filter of that many distinct shingles: a power of two, so filters fold onto each other."""
+    return max(_MIN_BLOOM_BITS, 1 << (shingle_count * BLOOM_BITS_PER_SHINGLE - 1).bit_length())
+
+
+def shingle_bloom(shingles: Iterable[str], size: int) -> int:
+    """A Bloom filter of shingles over ``size`` bits, as an int bitset."""
+    bloom = 0
+    for shingle in shingles:
+        digest = int.from_bytes(hashlib.blake2b(shingle.encode("utf-8"), digest_size=8).digest(), "big")
+        bloom |= 1 << (digest % size) | 1 << ((digest >> 32) % size)
+    return bloom
+
+
+def _fold(bloom: int, size: int, target: int) -> int:
+    """A Bloom filter folded onto fewer bits: the filter the same shingles would set over ``target`` bits."""
+    mask = (1 << target) - 1
+    folded = 0
+    for offset in range(0, size, target):
+        folded |= (bloom >> offset) & mask
+    return folded
+
+
+def _estimated_count(set_bits: int, size: int) -> float:
+    """Estimated number of shingles in a Bloom filter with this many bits set (infinite once saturated)."""
+    if set_bits >= size:
+        return math.inf
+    return -size / _BLOOM_HASHES * math.log(1 - set_bits / size)
+
+
+def estimated_jaccard(sig1: RegionSignature, sig2: RegionSignature) -> float:
+    """The Jaccard similarity of two shingle sets, estimated from their Bloom filters and sizes.
+
+    The larger filter is folded onto the size of the smaller; the size of the
+    union follows from the bits set in their union. Unknown filters give 1.0.
+    """
+    if not (sig1.bloom_bits and sig2.bloom_bits):
+        return 1.0
+    size = min(sig1.bloom_bits, sig2.bloom_bits)
+    union_bits = _fold(sig1.shingle_bloom, sig1.bloom_bits, size) | _fold(sig2.shingle_bloom, sig2.bloom_bits, size)
+    union = max(_estimated_count(union_bits.bit_count(), size), sig1.distinct_shingles, sig2.distinct_shingles)
+    shared = max(0.0, sig1.distinct_shingles + sig2.distinct_shingles - union)
+    return shared / union if union else 1.0
+
+
+def sizes_compatible(size1: int, size2: int, threshold: float) -> bool:
+    """False if two shingle sets differ so much in size that their Jaccard similarity is below ``threshold``.
+
+    The Jaccard similarity of two sets is at most the ratio of the smaller to the larger.
+    Unknown (zero) sizes are always compatible.
+    """
+    if not (size1 and size2):
+        return True
+    return min(size1, size2) >= threshold * max(size1, size2)
+
+
+class Prefilter:
+    """Rejects candidate pairs that cannot reach a similarity threshold, and counts them.
+
+    The size check is an exact bound on the Jaccard similarity of the shingle
+    sets. The Bloom filter check is an estimate, like the MinHash one it runs
+    before, so it only rejects pairs estimated well below the threshold.
+    """
+
+    def __init__(self, threshold: float):
+        self.threshold = threshold
+        self.checked = 0
+        self.rejected = 0
+
+    def admits(self, sig1: RegionSignature, sig2: RegionSignature) -> bool:
+        self.checked += 1
+        if sizes_compatible(sig1.distinct_shingles, sig2.distinct_shingles, self.threshold) and (
+            estimated_jaccard(sig1, sig2) >= self.threshold - BLOOM_MARGIN
+        ):
+            return True
+        self.rejected += 1
+        return False
+
+    def log_summary(self) -> None:
+        logger.info("Prefilter rejected %d of %d candidate pair(s)", self.rejected, self.checked)
@@ -0,0 +1,61 @@
+import sys
+import time
+from typing import Iterable, Iterator, Sized, TextIO, TypeVar, cast
+
+from tqdm import tqdm
+
+T = TypeVar("T")
+
+# Seconds between structured progress lines when stderr is not a terminal
+PROGRESS_INTERVAL = 10.0
+
+
+def _eta(done: int, total: int | None, elapsed: float) -> str:
+    """Estimated seconds left at the average rate so far, or '?' before any item is done."""
+    if not done or total is None:
+        return "?"
+    return f"{elapsed / done * (total - done):.1f}s"
+
+
+def progress_line(phase: str, unit: str, done: int, total: int | None, elapsed: float) -> str:
+    """One structured progress line, e.g. for CI logs where a redrawn bar would be noise."""
+    fields = [f"phase={phase}", f"unit={unit}", f"done={done}"]
+    if total is not None:
+        fields += [f"total={total}", f"remaining={total - done}"]
+    fields += [f"elapsed={elapsed:.1f}s", f"eta={_eta(done, total, elapsed)}"]
+    return "treepeat: progress " + " ".join(fields)
+
+
+def iter_progress_lines(
+    items: Iterable[T],
+    phase: str,
+    unit: str,
+    total: int | None,
+    stream: TextIO,
+    interval: float = PROGRESS_INTERVAL,
+) -> Iterator[T]:
+    """Yield items, writing a progress line when the phase starts, every ``interval`` seconds, and at the end."""
+    start = last = time.monotonic()
+    done = 0
+    print(progress_line(phase, unit, done, total, 0.0), file=stream, flush=True)
+    for item in items:
+        yield item
+        done += 1
+        now = time.monotonic()
+        if now - last >= interval:
+            print(progress_line(phase, unit, done, total, now - start), file=stream, flush=True)
+            last = now
+    print(progress_line(phase, unit, done, total, time.monotonic() - start), file=stream, flush=True)
+
+
+def track(items: Iterable[T], phase: str, unit: str, total: int | None = None) -> Iterable[T]:
+    """Report progress through a pipeline stage on stderr.
+
+    A terminal gets a tqdm bar; anything else (CI logs, redirected output) gets
+    periodic structured lines, so long runs never look hung.
+    """
+    if total is None and isinstance(items, Sized):
+        total = len(items)
+    if sys.stderr.isatty():
+        return cast(Iterable[T], tqdm(items, total=total, desc=phase, unit=unit, file=sys.stderr))
+    return iter_progress_lines(items, phase, unit, total, sys.stderr)
@@ -1,18 +1,17 @@
 import logging
-import sys
 from collections import Counter
 from dataclasses import dataclass
 
 from pydantic import BaseModel, Field
-from tqdm import tqdm
 from tree_sitter import Node, Tree
-from tree_sitter_language_pack import get_parser
 
 from treepeat.models.ast import ParsedFile
 from treepeat.models.similarity import Region
+from treepeat.pipeline.languages import grammar_parser
+from treepeat.pipeline.progress import track
 from treepeat.pipeline.rules.engine import RuleEngine
 from treepeat.pipeline.rules.models import Rule
-from treepeat.pipeline.verbose_metrics import record_used_node_type
+from treepeat.pipeline.verbose_metrics import record_fragment_type, record_used_node_type
 
 logger = logging.getLogger(__name__)
 
@@ -137,7 +136,7 @@ def _do_language_injection(
     padded = b"\n" * line_offset + content_bytes
 
     try:
-        parser = get_parser(target_lang)  # type: ignore[arg-type]
+        parser = grammar_parser(target_lang)
         injected_tree = parser.parse(padded)
     except Exception as e:
         logger.debug("Injection: failed to parse content as %s: %s", target_lang, e)
@@ -240,6 +239,7 @@ def extract_regions(
 
     for region in regions:
         record_used_node_type(parsed_file.language, region.region.region_type)
+        record_fragment_type(parsed_file.path, parsed_file.language, region.region.region_type)
 
     logger.debug("Extracted %d explicit region(s) from %s", len(regions), parsed_file.path)
     return regions
@@ -328,7 +328,7 @@ def extract_all_regions(
 
     all_regions: list[ExtractedRegion] = []
 
-    iterable = tqdm(parsed_files, desc="Extracting", unit="file", file=sys.stderr) if progress else parsed_files
+    iterable = track(parsed_files, "Extracting", "file") if progress else parsed_files
 
     for parsed_file in iterable:
         try:
@@ -2,9 +2,8 @@ from collections import defaultdict
 from typing import Any, Callable, DefaultDict, Iterable, Optional
 
 from tree_sitter import Node, Query, QueryCursor
-from tree_sitter_language_pack import get_language
 
-from ..languages import LANGUAGE_CONFIGS, get_grammar
+from ..languages import LANGUAGE_CONFIGS, get_grammar, grammar_language
 from .models import Rule, RuleAction, SkipNodeException
 
 
@@ -66,7 +65,7 @@ class RuleEngine:
         key = (language, query_str)
         if key not in self._compiled_queries:
             grammar = get_grammar(language)
-            lang = get_language(grammar)  # type: ignore[arg-type]
+            lang = grammar_language(grammar)
             self._compiled_queries[key] = Query(lang, query_str)
         return self._compiled_queries[key]
 
@@ -370,6 +369,60 @@ def build_region_extraction_rules() -> list[tuple[Rule, str]]:
     return rules
 
 
+def build_qualifier_rules() -> list[tuple[Rule, str]]:
+    """Build rules that strip access/storage modifiers from language configurations."""
+    rules = []
+    for _lang_name, lang_config in LANGUAGE_CONFIGS.items():
+        for rule in lang_config.get_qualifier_rules():
+            rules.append((rule, rule.name))
+    return rules
+
+
+def build_preprocessor_rules() -> list[tuple[Rule, str]]:
+    """Build rules that flatten preprocessor conditionals from language configurations."""
+    rules = []
+    for _lang_name, lang_config in LANGUAGE_CONFIGS.items():
+        for rule in lang_config.get_preprocessor_rules():
+            rules.append((rule, rule.name))
+    return rules
+
+
+def build_signature_type_rules() -> list[tuple[Rule, str]]:
+    """Build rules that normalize signature types from language configurations."""
+    rules = []
+    for _lang_name, lang_config in LANGUAGE_CONFIGS.items():
+        for rule in lang_config.get_signature_type_rules():
+            rules.append((rule, rule.name))
+    return rules
+
+
+def build_comment_rules() -> list[tuple[Rule, str]]:
+    """Build rules that strip comments and docstrings from language configurations."""
+    rules = []
+    for _lang_name, lang_config in LANGUAGE_CONFIGS.items():
+        for rule in lang_config.get_comment_rules():
+            rules.append((rule, rule.name))
+    return rules
+
+
+def build_identifier_rules() -> list[tuple[Rule, str]]:
+    """Build rules that abstract identifiers from language configurations."""
+    rules = []
+    for _lang_name, lang_config in LANGUAGE_CONFIGS.items():
+        for rule in lang_config.get_identifier_rules():
+            rules.append((rule, rule.name))
+    return rules
+
+
+def build_literal_rules() -> list[tuple[Rule, str]]:
+    """Build rules that abstract literal values from language configurations."""
+    rules = []
+    for _lang_name, lang_config in LANGUAGE_CONFIGS.items():
+        for rule in lang_config.get_literal_rules():
+            rules.append((rule, rule.name))
+    return rules
+
+
 def build_default_rules() -> list[tuple[Rule, str]]:
     """Build default rules from language configurations."""
     rules = []
@@ -1,8 +1,12 @@
-from typing import Any
+from typing import Any, Callable
 
 from .models import Rule, RuleAction
 
 
+# Rules of a built-in ruleset by name; raises RuleParseError for unknown names
+BuiltinRules = Callable[[str], list[Rule]]
+
+
 class RuleParseError(Exception):
     """Raised when a rule cannot be parsed."""
 
@@ -52,12 +56,18 @@ def _get_extended_rules(
     rulesets: dict[str, Any],
     ruleset: dict[str, Any],
     resolved: set[str],
+    builtin: BuiltinRules,
 ) -> list[Rule]:
-    """Get rules from extended rulesets."""
-    if "extends" not in ruleset:
-        return []
-    extended_name = ruleset["extends"]
-    return _resolve_extends(rulesets, extended_name, resolved)
+    """Get rules from the extended ruleset: another user ruleset, else a built-in one ('none' by default)."""
+    extended_name = ruleset.get("extends", "none")
+    if extended_name in rulesets:
+        return _resolve_extends(rulesets, extended_name, resolved, builtin)
+    return builtin(extended_name)
+
+
+def parse_rules(rule_dicts: list[dict[str, Any]], source: str) -> list[Rule]:
+    """Parse a list of rule tables, e.g. the default rules of a grammar plugin."""
+    return [_parse_yaml_rule(rule_dict, source) for rule_dict in rule_dicts]
 
 
 def _parse_ruleset_rules(ruleset: dict[str, Any], ruleset_name: str) -> list[Rule]:
@@ -67,23 +77,82 @@ def _parse_ruleset_rules(ruleset: dict[str, Any], ruleset_name: str) -> list[Rul
     return [_parse_yaml_rule(rule_dict, ruleset_name) for rule_dict in ruleset["rules"]]
 
 
+def _is_node_kind_list(kinds: Any) -> bool:
+    return isinstance(kinds, list) and all(isinstance(kind, str) for kind in kinds)
+
+
+def _parse_node_kinds(ruleset: dict[str, Any], key: str, ruleset_name: str) -> dict[str, list[str]]:
+    """Read a table mapping languages to tree-sitter node kinds (e.g. regions = { go = ["function_declaration"] })."""
+    table = ruleset.get(key, {})
+    if not isinstance(table, dict) or not all(_is_node_kind_list(kinds) for kinds in table.values()):
+        raise RuleParseError(f"Ruleset '{ruleset_name}': '{key}' must map languages to lists of node kinds")
+    return table
+
+
+def _region_rules(regions: dict[str, list[str]]) -> list[Rule]:
+    """Extract exactly the given node kinds as regions."""
+    return [
+        Rule(
+            name=f"Extract {kind} regions for {language}",
+            languages=[language],
+            query=f"({kind}) @region",
+            action=RuleAction.EXTRACT_REGION,
+            params={"region_type": kind},
+        )
+        for language, kinds in regions.items()
+        for kind in kinds
+    ]
+
+
+def _ignore_rules(ignored: dict[str, list[str]]) -> list[Rule]:
+    """Never descend into the given node kinds."""
+    return [
+        Rule(
+            name=f"Ignore {kind} for {language}",
+            languages=[language],
+            query=f"({kind}) @ignore",
+            action=RuleAction.REMOVE,
+        )
+        for language, kinds in ignored.items()
+        for kind in kinds
+    ]
+
+
+def _replaces_regions(rule: Rule, languages: set[str]) -> bool:
+    """True for the inherited region extraction rules of languages whose regions a ruleset redefines."""
+    return rule.action == RuleAction.EXTRACT_REGION and bool(languages.intersection(rule.languages))
+
+
 def _resolve_extends(
     rulesets: dict[str, Any],
     ruleset_name: str,
     resolved: set[str],
+    builtin: BuiltinRules,
 ) -> list[Rule]:
     """Recursively resolve ruleset inheritance."""
     if ruleset_name in resolved:
         raise RuleParseError(f"Circular dependency detected in ruleset '{ruleset_name}'")
 
-    if ruleset_name not in rulesets:
-        raise RuleParseError(f"Ruleset '{ruleset_name}' not found (referenced by 'extends')")
-
     resolved.add(ruleset_name)
     ruleset = rulesets[ruleset_name]
-
-    # Combine rules from extended rulesets and this ruleset
-    extended_rules = _get_extended_rules(rulesets, ruleset, resolved)
-    own_rules = _parse_ruleset_rules(ruleset, ruleset_name)
+    if not isinstance(ruleset, dict):
+        raise RuleParseError(f"Ruleset '{ruleset_name}' is not a table")
+
+    # Combine rules from extended rulesets and this ruleset; its regions replace the inherited ones per language
+    regions = _parse_node_kinds(ruleset, "regions", ruleset_name)
+    extended_rules = [
+        rule for rule in _get_extended_rules(rulesets, ruleset, resolved, builtin)
+        if not _replaces_regions(rule, set(regions))
+    ]
+    own_rules = [
+        *_region_rules(regions),
+        *_ignore_rules(_parse_node_kinds(ruleset, "ignore", ruleset_name)),
+        *_parse_ruleset_rules(ruleset, ruleset_name),
+    ]
 
     return extended_rules + own_rules
+
+
+def resolve_ruleset(rulesets: dict[str, Any], ruleset_name: str, builtin: BuiltinRules) -> list[Rule]:
+    """The rules of a user-defined ruleset, including those of the rulesets it extends."""
+    return _resolve_extends(rulesets, ruleset_name, set(), builtin)
@@ -1,16 +1,26 @@
 import logging
+from typing import Any
 
 from treepeat.config import PipelineSettings
 from treepeat.pipeline.rules.engine import (
     RuleEngine,
+    build_comment_rules,
     build_default_rules,
+    build_identifier_rules,
+    build_literal_rules,
     build_loose_rules,
+    build_preprocessor_rules,
+    build_qualifier_rules,
     build_region_extraction_rules,
+    build_signature_type_rules,
 )
 from treepeat.pipeline.rules.models import Rule, RuleAction
+from treepeat.pipeline.rules.parser import RuleParseError, resolve_ruleset
 
 logger = logging.getLogger(__name__)
 
+BUILTIN_RULESETS = ("none", "default", "loose")
+
 
 def _log_active_rules(rules: list[Rule]) -> None:
     """Log the active rules for debugging."""
@@ -30,17 +40,30 @@ def _log_active_rules(rules: list[Rule]) -> None:
         )
 
 
-def get_ruleset_with_descriptions(
-    ruleset: str, filters: dict[str, set[str]] | None = None
-) -> list[tuple[Rule, str]]:
-    """Get a ruleset with rule descriptions for display purposes."""
+def _builtin_ruleset(ruleset: str) -> list[tuple[Rule, str]]:
+    """Get a built-in ruleset with rule descriptions."""
     ruleset = ruleset.lower()
     if ruleset == "default":
-        rules = build_default_rules()
-    elif ruleset == "loose":
-        rules = build_loose_rules()
-    else:  # none - only region extraction rules, no normalization
-        rules = build_region_extraction_rules()
+        return build_default_rules()
+    if ruleset == "loose":
+        return build_loose_rules()
+    if ruleset == "none":  # only region extraction rules, no normalization
+        return build_region_extraction_rules()
+    raise RuleParseError(f"Ruleset '{ruleset}' not found (built-in rulesets: {', '.join(BUILTIN_RULESETS)})")
+
+
+def _builtin_rules(ruleset: str) -> list[Rule]:
+    return [rule for rule, _ in _builtin_ruleset(ruleset)]
+
+
+def get_ruleset_with_descriptions(
+    ruleset: str, filters: dict[str, set[str]] | None = None, custom_rulesets: dict[str, Any] | None = None
+) -> list[tuple[Rule, str]]:
+    """Get a ruleset (built-in, or user-defined in ``custom_rulesets``) with rule descriptions for display purposes."""
+    if custom_rulesets and ruleset in custom_rulesets:
+        rules = [(rule, rule.name) for rule in resolve_ruleset(custom_rulesets, ruleset, _builtin_rules)]
+    else:
+        rules = _builtin_ruleset(ruleset)
 
     if not filters:
         return rules
@@ -89,9 +112,11 @@ def _build_additional_region_rules(additional_regions: dict[str, set[str]]) -> l
     return rules
 
 
-def _load_ruleset_rules(ruleset: str, filters: dict[str, set[str]] | None = None) -> list[Rule]:
-    """Load rules from a predefined ruleset, honoring optional filters."""
-    rules_with_descriptions = get_ruleset_with_descriptions(ruleset, filters)
+def _load_ruleset_rules(
+    ruleset: str, filters: dict[str, set[str]] | None = None, custom_rulesets: dict[str, Any] | None = None
+) -> list[Rule]:
+    """Load rules from a built-in or user-defined ruleset, honoring optional filters."""
+    rules_with_descriptions = get_ruleset_with_descriptions(ruleset, filters, custom_rulesets)
     if rules_with_descriptions:
         logger.info("Using '%s' ruleset", ruleset)
     return [rule for rule, _ in rules_with_descriptions]
@@ -119,15 +144,44 @@ def _filter_excluded_regions(
     ]
 
 
+NORMALIZATION_BUILDERS = {
+    "identifiers": build_identifier_rules,
+    "literals": build_literal_rules,
+    "comments": build_comment_rules,
+}
+
+
+def _with_normalizations(rules: list[Rule], normalize: list[str]) -> list[Rule]:
+    """Put the requested normalization rules ahead of the ruleset's own rules.
+
+    Later rules win, so the ruleset's more specific rules (e.g. FUNC for function
+    names) still apply; rules the ruleset already contains are not repeated.
+    """
+    extra = [
+        rule
+        for kind in normalize
+        for rule, _ in NORMALIZATION_BUILDERS[kind]()
+        if rule not in rules
+    ]
+    return extra + rules
+
+
 def build_rule_engine(settings: PipelineSettings) -> RuleEngine:
     """Build a rule engine from settings."""
     filters = getattr(settings.rules, "region_filters", {}) or {}
     additional_regions = getattr(settings.rules, "additional_regions", {}) or {}
     excluded_regions = getattr(settings.rules, "excluded_regions", {}) or {}
 
-    rules = _load_ruleset_rules(settings.rules.ruleset.lower(), filters)
+    rules = _load_ruleset_rules(settings.rules.ruleset, filters, settings.rules.custom_rulesets)
+    rules = _with_normalizations(rules, settings.rules.normalize)
     if additional_regions:
         rules.extend(_build_additional_region_rules(additional_regions))
+    if settings.rules.ignore_qualifiers:
+        rules.extend(rule for rule, _ in build_qualifier_rules())
+    if settings.rules.normalize_signature_types:
+        rules.extend(rule for rule, _ in build_signature_type_rules())
+    if settings.rules.flatten_preprocessor:
+        rules.extend(rule for rule, _ in build_preprocessor_rules())
 
     # Apply exclusions after all rules are loaded
     rules = _filter_excluded_regions(rules, excluded_regions)
@@ -1,15 +1,22 @@
 import logging
-import sys
 from collections import deque
 from pathlib import Path
-from typing import Iterable, cast
+from typing import Iterable
 
-from tqdm import tqdm
 from tree_sitter import Node
 
 from treepeat.models.ast import ParsedFile
 from treepeat.models.normalization import NodeRepresentation, SkipNode
 from treepeat.models.shingle import Shingle, ShingledRegion, ShingleList
+from treepeat.pipeline.canonical import (
+    appended_children,
+    canonical_children,
+    canonical_name,
+    fold_constant,
+    hoisted_children,
+)
+from treepeat.pipeline.languages import LANGUAGE_CONFIGS
+from treepeat.pipeline.progress import track
 from treepeat.pipeline.region_extraction import ExtractedRegion
 from treepeat.pipeline.rules.engine import RuleEngine
 from treepeat.pipeline.rules.models import SkipNodeException
@@ -25,11 +32,16 @@ class ASTShingler:
         self,
         rule_engine: RuleEngine,
         k: int = 3,
+        neutral_types: dict[str, dict[str, str]] | None = None,
+        canonicalize: bool = False,
     ):
         if k < 1:
             raise ValueError("k must be at least 1")
         self.rule_engine = rule_engine
         self.k = k
+        # language -> node type -> neutral category; None shingles the concrete AST
+        self.neutral_types = neutral_types
+        self.canonicalize = canonicalize
 
     def _shingle_injected_region(self, extracted_region: ExtractedRegion) -> list[Shingle]:
         injected_tree = extracted_region.injected_tree
@@ -131,6 +143,93 @@ class ASTShingler:
             raise SkipNode(f"Node type '{name}' skipped by rule") from sne
         return NodeRepresentation(name=name, value=value)
 
+    def _path_representation(
+        self,
+        node: Node,
+        language: str,
+        source: bytes,
+        root: Node,
+    ) -> NodeRepresentation | None:
+        """Get the representation pushed onto the shingle path, or None if the node is transparent.
+
+        In cross-language mode only nodes with a neutral category are kept; the
+        rest are flattened so their children attach to the nearest kept ancestor.
+        """
+        node_repr = self._get_node_representation(node, language, source, root)
+        if self.canonicalize:
+            node_repr = self._canonical_representation(node, language, source, root, node_repr)
+        neutral = self.neutral_types.get(language) if self.neutral_types is not None else None
+        if not neutral:
+            return node_repr
+        category = neutral.get(node.type)
+        return NodeRepresentation(name=category) if category else None
+
+    def _canonical_representation(
+        self,
+        node: Node,
+        language: str,
+        source: bytes,
+        root: Node,
+        node_repr: NodeRepresentation,
+    ) -> NodeRepresentation:
+        """Render loops under one name and trivial constant expressions as the literal they fold to."""
+        folded = fold_constant(node, source)
+        if folded is None:
+            return NodeRepresentation(name=canonical_name(node_repr.name, node), value=node_repr.value)
+        # Shingle like the first operand would, so literal rules (e.g. --normalize literals) still apply
+        operand = node.children[0]
+        literal = self._get_node_representation(operand, language, source, root)
+        keep_rule_value = literal.value != self._extract_node_value(operand, source)
+        return NodeRepresentation(name=literal.name, value=literal.value if keep_rule_value else folded)
+
+    def _children(self, node: Node, source: bytes, appended: list[Node]) -> list[tuple[Node, list[Node]]]:
+        """Children to traverse, each with the nodes traversed after its own children.
+
+        When canonicalizing, children come in canonical order, followed by the
+        ``appended`` nodes (the update of a C-style for, at the end of its body).
+        """
+        if not self.canonicalize:
+            return [(child, []) for child in node.children]
+        children = [(child, appended_children(node, child)) for child in canonical_children(node, source)]
+        return children + [(extra, []) for extra in appended]
+
+    def _hoisted(self, node: Node) -> list[Node]:
+        """Nodes traversed as siblings before the node (the init of a C-style for, when canonicalizing)."""
+        return hoisted_children(node) if self.canonicalize else []
+
+    def _path_shingle(self, path: deque[tuple[NodeRepresentation, Node]]) -> Shingle:
+        """Create a shingle from the last k nodes of the path."""
+        shingle_path = list(path)[-self.k :]
+        shingle_reprs = [repr for repr, _ in shingle_path]
+        shingle_nodes = [n for _, n in shingle_path]
+
+        # Create shingle content
+        shingle_content = "→".join(str(repr) for repr in shingle_reprs)
+
+        # Calculate line range from the nodes in this shingle
+        # Use the LAST node in the k-gram (most specific) for line positioning
+        # rather than min/max which often includes the root node spanning the entire file
+        last_node = shingle_nodes[-1]
+        start_line = last_node.start_point[0] + 1
+        end_line = last_node.end_point[0] + 1
+
+        return Shingle(content=shingle_content, start_line=start_line, end_line=end_line)
+
+    def _push_node(
+        self,
+        path: deque[tuple[NodeRepresentation, Node]],
+        node: Node,
+        node_repr: NodeRepresentation | None,
+        shingles: list[Shingle],
+    ) -> bool:
+        """Push a node onto the path, emitting a shingle once the path is long enough."""
+        if node_repr is None:
+            return False
+        path.append((node_repr, node))
+        if len(path) >= self.k:
+            shingles.append(self._path_shingle(path))
+        return True
+
     def _extract_shingles(
         self,
         root: Node,
@@ -147,57 +246,49 @@ class ASTShingler:
         shingles: list[Shingle] = []
 
         # Pre-order traversal to extract all paths
-        def traverse(node: Node, path: deque[tuple[NodeRepresentation, Node]]) -> None:
+        def traverse(node: Node, path: deque[tuple[NodeRepresentation, Node]], appended: list[Node]) -> None:
             # Get normalized representation (may raise SkipNode)
             try:
-                node_repr = self._get_node_representation(node, language, source, root)
+                node_repr = self._path_representation(node, language, source, root)
             except SkipNode:
                 # Skip this node and its entire subtree
                 return
 
-            path.append((node_repr, node))
-
-            # If path is long enough, create a shingle with line range metadata
-            if len(path) >= self.k:
-                shingle_path = list(path)[-self.k :]
-                shingle_reprs = [repr for repr, _ in shingle_path]
-                shingle_nodes = [n for _, n in shingle_path]
+            for hoisted in self._hoisted(node):
+                traverse(hoisted, path, [])
 
-                # Create shingle content
-                shingle_content = "→".join(str(repr) for repr in shingle_reprs)
-
-                # Calculate line range from the nodes in this shingle
-                # Use the LAST node in the k-gram (most specific) for line positioning
-                # rather than min/max which often includes the root node spanning the entire file
-                last_node = shingle_nodes[-1]
-                start_line = last_node.start_point[0] + 1
-                end_line = last_node.end_point[0] + 1
-
-                shingle = Shingle(content=shingle_content, start_line=start_line, end_line=end_line)
-                shingles.append(shingle)
+            pushed = self._push_node(path, node, node_repr, shingles)
 
             # Recursively traverse children
-            for child in node.children:
-                traverse(child, path)
+            for child, child_appended in self._children(node, source, appended):
+                traverse(child, path, child_appended)
 
             # Backtrack
-            _ = path.pop()
+            if pushed:
+                _ = path.pop()
 
-        traverse(root, deque())
+        traverse(root, deque(), [])
         return shingles
 
 
+def _neutral_node_types() -> dict[str, dict[str, str]]:
+    """Collect each language's neutral node type mapping for cross-language shingling."""
+    return {language: config.get_neutral_node_types() for language, config in LANGUAGE_CONFIGS.items()}
+
+
 def _shingle_single_region(
     extracted_region: ExtractedRegion,
-    path_to_source: dict[Path, bytes],
+    path_to_source: dict[tuple[Path, str], bytes],
     shingler: ASTShingler,
 ) -> ShingledRegion | None:
-    # Injected regions carry their own source bytes; other regions look up by path.
+    # Injected regions carry their own source bytes; other regions look up by
+    # path and language (a notebook yields one parsed file per cell language).
     source: bytes | None
     if extracted_region.injected_source is not None:
         source = extracted_region.injected_source
     else:
-        source = path_to_source.get(extracted_region.region.path)
+        region = extracted_region.region
+        source = path_to_source.get((region.path, region.language))
 
     if source is None:
         logger.error(
@@ -231,16 +322,13 @@ def _get_region_shingling_iterable(
     progress: bool,
 ) -> Iterable[ExtractedRegion]:
     if progress:
-        return cast(
-            Iterable[ExtractedRegion],
-            tqdm(extracted_regions, desc="Shingling", unit="region", file=sys.stderr),
-        )
+        return track(extracted_regions, "Shingling", "region")
     return extracted_regions
 
 
 def _append_shingled_region(
     extracted_region: ExtractedRegion,
-    path_to_source: dict[Path, bytes],
+    path_to_source: dict[tuple[Path, str], bytes],
     shingler: ASTShingler,
     shingled_regions: list[ShingledRegion],
 ) -> int:
@@ -266,6 +354,8 @@ def shingle_regions(
     rule_engine: RuleEngine,
     k: int = 3,
     progress: bool = False,
+    cross_language: bool = False,
+    canonicalize: bool = False,
 ) -> list[ShingledRegion]:
     logger.info(
         "Shingling %d region(s) across %d file(s) with k=%d",
@@ -274,8 +364,9 @@ def shingle_regions(
         k,
     )
 
-    path_to_source = {pf.path: pf.source for pf in parsed_files}
-    shingler = ASTShingler(rule_engine=rule_engine, k=k)
+    path_to_source = {(pf.path, pf.language): pf.source for pf in parsed_files}
+    neutral_types = _neutral_node_types() if cross_language else None
+    shingler = ASTShingler(rule_engine=rule_engine, k=k, neutral_types=neutral_types, canonicalize=canonicalize)
     shingled_regions: list[ShingledRegion] = []
     filtered_count = 0
     iterable = _get_region_shingling_iterable(extracted_regions, progress)
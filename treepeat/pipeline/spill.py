@@ -0,0 +1,89 @@
+"""Disk-backed LSH index, used when the in-memory one would exceed --max-index-memory."""
+
+import logging
+import sqlite3
+import tempfile
+from collections.abc import Iterable
+from pathlib import Path
+
+from datasketch import MinHash, MinHashLSH  # type: ignore[import-untyped]
+
+logger = logging.getLogger(__name__)
+
+# Approximate bytes an in-memory index spends per band of a signature on top of the band's
+# hash values: the bucket key bytes object, its set entry and the reference to the region key
+_BUCKET_OVERHEAD = 200
+
+# Signatures written to disk per batch
+_BATCH_SIZE = 10_000
+
+# SQLite page cache per spilled index, in KiB (negative cache_size values are KiB)
+_PAGE_CACHE_KIB = 64 * 1024
+
+
+def banding(threshold: float, num_perm: int) -> tuple[int, int]:
+    """The (bands, rows per band) an LSH index picks for a threshold and number of permutations."""
+    lsh = MinHashLSH(threshold, num_perm)
+    return lsh.b, lsh.r
+
+
+def index_memory(count: int, threshold: float, num_perm: int) -> int:
+    """Estimated bytes of the band buckets of an in-memory LSH index of ``count`` signatures.
+
+    The signatures themselves are held by the caller either way, so they are not counted.
+    """
+    bands, rows = banding(threshold, num_perm)
+    return count * bands * (rows * 8 + _BUCKET_OVERHEAD)
+
+
+class SpilledLSH:
+    """An LSH index whose band buckets are shards of an on-disk SQLite table.
+
+    It answers the same ``query`` as datasketch's in-memory ``MinHashLSH``;
+    buckets are matched through an index on disk, so the buckets take no
+    memory however many signatures are inserted (the caller still holds the
+    signatures). Use it as a context manager: the
+    shards are deleted on exit.
+    """
+
+    def __init__(self, threshold: float, num_perm: int, directory: Path | None = None):
+        self.bands, self.rows = banding(threshold, num_perm)
+        self._tmp = tempfile.TemporaryDirectory(prefix="treepeat-lsh-", dir=directory)
+        self._db = sqlite3.connect(Path(self._tmp.name) / "index.db")
+        self._db.execute("PRAGMA journal_mode = OFF")
+        self._db.execute("PRAGMA synchronous = OFF")
+        self._db.execute(f"PRAGMA cache_size = -{_PAGE_CACHE_KIB}")
+        self._db.execute("CREATE TABLE buckets (band INTEGER, hash BLOB, key TEXT)")
+
+    def __enter__(self) -> "SpilledLSH":
+        return self
+
+    def __exit__(self, *exc_info: object) -> None:
+        self._db.close()
+        self._tmp.cleanup()
+
+    def _band_hashes(self, minhash: MinHash) -> list[bytes]:
+        return [
+            bytes(minhash.hashvalues[band * self.rows : (band + 1) * self.rows].tobytes())
+            for band in range(self.bands)
+        ]
+
+    def insert_all(self, entries: Iterable[tuple[str, MinHash]]) -> None:
+        """Write every (key, signature) to disk in batches, then index the buckets once."""
+        batch: list[tuple[int, bytes, str]] = []
+        for key, minhash in entries:
+            batch.extend((band, band_hash, key) for band, band_hash in enumerate(self._band_hashes(minhash)))
+            if len(batch) >= _BATCH_SIZE * self.bands:
+                self._db.executemany("INSERT INTO buckets VALUES (?, ?, ?)", batch)
+                batch = []
+        self._db.executemany("INSERT INTO buckets VALUES (?, ?, ?)", batch)
+        self._db.execute("CREATE INDEX buckets_by_hash ON buckets (band, hash)")
+        self._db.commit()
+
+    def query(self, minhash: MinHash) -> list[str]:
+        """Keys sharing at least one band bucket with the signature."""
+        keys: set[str] = set()
+        for band, band_hash in enumerate(self._band_hashes(minhash)):
+            rows = self._db.execute("SELECT key FROM buckets WHERE band = ? AND hash = ?", (band, band_hash))
+            keys.update(key for (key,) in rows)
+        return list(keys)
@@ -0,0 +1,107 @@
+import logging
+import re
+from collections import defaultdict
+from pathlib import Path
+from typing import Iterable
+
+from treepeat.models.similarity import GroupCallback, Region, SimilarRegionGroup
+from treepeat.pipeline.notebook import is_notebook, read_notebook_lines
+
+logger = logging.getLogger(__name__)
+
+# `# treepeat:ignore` suppresses findings overlapping its own line;
+# `# treepeat:ignore-next-block` suppresses findings overlapping the block that follows it
+_DIRECTIVE = re.compile(r"(?:#|//|/\*|<!--|--|;|%)\s*treepeat:ignore(?P<next_block>-next-block)?\b")
+
+Span = tuple[int, int]
+
+
+def _read_lines(path: Path) -> list[str]:
+    """Read a file's lines (notebooks as their concatenated cells), or [] if unreadable."""
+    try:
+        if is_notebook(path):
+            return read_notebook_lines(path)
+        return path.read_text(encoding="utf-8", errors="replace").splitlines()
+    except OSError:
+        return []
+
+
+def find_directives(lines: list[str]) -> list[tuple[int, bool]]:
+    """Return (1-indexed line, is next-block) for every suppression comment in a file."""
+    directives = []
+    for number, line in enumerate(lines, start=1):
+        match = _DIRECTIVE.search(line)
+        if match:
+            directives.append((number, match.group("next_block") is not None))
+    return directives
+
+
+def _next_code_line(lines: list[str], number: int) -> int:
+    """First non-blank line after a directive (the directive's own line if there is none)."""
+    for following in range(number + 1, len(lines) + 1):
+        if lines[following - 1].strip():
+            return following
+    return number
+
+
+class SuppressionIndex:
+    """Suppression comments of the scanned files, used to hide the clone groups they annotate.
+
+    The block following an ``ignore-next-block`` comment extends to the end of the
+    largest extracted region starting on its first line; regions are added once known.
+    """
+
+    def __init__(self) -> None:
+        self._directives: dict[Path, list[tuple[int, bool]]] = {}
+        self._lines: dict[Path, list[str]] = {}
+        self._region_ends: dict[tuple[Path, int], int] = defaultdict(int)
+
+    def add_regions(self, regions: Iterable[Region]) -> None:
+        """Remember the extent of extracted regions so next-block comments cover them whole."""
+        for region in regions:
+            key = (region.path, region.start_line)
+            self._region_ends[key] = max(self._region_ends[key], region.end_line)
+
+    def _file_directives(self, path: Path) -> list[tuple[int, bool]]:
+        if path not in self._directives:
+            self._lines[path] = _read_lines(path)
+            self._directives[path] = find_directives(self._lines[path])
+        return self._directives[path]
+
+    def _span(self, path: Path, number: int, next_block: bool) -> Span:
+        """The lines annotated by one directive."""
+        if not next_block:
+            return number, number
+        start = _next_code_line(self._lines[path], number)
+        return start, max(start, self._region_ends.get((path, start), start))
+
+    def spans(self, path: Path) -> list[Span]:
+        """Line spans suppressed in a file."""
+        return [self._span(path, number, next_block) for number, next_block in self._file_directives(path)]
+
+    def suppresses(self, group: SimilarRegionGroup) -> bool:
+        """True if any region of the group overlaps a suppressed span."""
+        return any(
+            start <= region.end_line and region.start_line <= end
+            for region in group.regions
+            for start, end in self.spans(region.path)
+        )
+
+    def split(self, groups: list[SimilarRegionGroup]) -> tuple[list[SimilarRegionGroup], list[SimilarRegionGroup]]:
+        """Partition groups into (reported, suppressed)."""
+        reported = [group for group in groups if not self.suppresses(group)]
+        suppressed = [group for group in groups if self.suppresses(group)]
+        if suppressed:
+            logger.info("Suppressed %d group(s) by inline treepeat:ignore comments", len(suppressed))
+        return reported, suppressed
+
+    def callback(self, on_group: GroupCallback | None) -> GroupCallback | None:
+        """Wrap a group callback so it never sees suppressed groups."""
+        if on_group is None:
+            return None
+
+        def callback(group: SimilarRegionGroup) -> None:
+            if not self.suppresses(group):
+                on_group(group)
+
+        return callback
@@ -0,0 +1,66 @@
+from fnmatch import fnmatch
+from pathlib import Path
+
+from treepeat.models.similarity import GroupCallback, SimilarRegionGroup
+
+# --tests: report clones of test code with the others, in a separate section, or not scan test files at all
+TESTS_MODES = ("include", "separate", "exclude")
+
+# Directories holding test code, at any depth below the scanned path
+TEST_DIRECTORIES = frozenset({"test", "tests", "__tests__", "spec", "specs"})
+
+# Test file names, by the conventions of each ecosystem
+TEST_FILE_PATTERNS = (
+    "*_test.go",
+    "test_*.py",
+    "*_test.py",
+    "*_test.rb",
+    "*_spec.rb",
+    "*.test.*",
+    "*.spec.*",
+    "*Test.java",
+    "*Tests.java",
+    "*Test.kt",
+    "*Tests.cs",
+)
+
+
+def _directories(path: Path, root: Path) -> tuple[str, ...]:
+    """The directories between the scanned path and a file (all of its directories when it is elsewhere)."""
+    relative = path.relative_to(root) if path.is_relative_to(root) else path
+    return relative.parts[:-1]
+
+
+def is_test_path(path: Path, root: Path) -> bool:
+    """True if a file follows a conventional test layout: a test file name, or a test directory below ``root``."""
+    if any(fnmatch(path.name, pattern) for pattern in TEST_FILE_PATTERNS):
+        return True
+    return not TEST_DIRECTORIES.isdisjoint(_directories(path, root))
+
+
+def is_test_group(group: SimilarRegionGroup, root: Path) -> bool:
+    """True if every copy of a clone group is test code."""
+    return all(is_test_path(region.path, root) for region in group.regions)
+
+
+def split_test_groups(
+    groups: list[SimilarRegionGroup], root: Path
+) -> tuple[list[SimilarRegionGroup], list[SimilarRegionGroup]]:
+    """Partition groups into (with production code, only in test code)."""
+    production: list[SimilarRegionGroup] = []
+    tests: list[SimilarRegionGroup] = []
+    for group in groups:
+        (tests if is_test_group(group, root) else production).append(group)
+    return production, tests
+
+
+def tests_callback(on_group: GroupCallback | None, root: Path) -> GroupCallback | None:
+    """Wrap a group callback so it never sees the groups reported in the separate test section."""
+    if on_group is None:
+        return None
+
+    def callback(group: SimilarRegionGroup) -> None:
+        if not is_test_group(group, root):
+            on_group(group)
+
+    return callback
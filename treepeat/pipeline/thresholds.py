@@ -0,0 +1,49 @@
+from treepeat.config import LanguageThresholds, LSHSettings
+from treepeat.models.similarity import Region, SimilarRegionGroup, TokenCounts, region_key
+
+_NO_OVERRIDE = LanguageThresholds()
+
+
+def _overrides(language: str, lsh: LSHSettings) -> LanguageThresholds:
+    return lsh.language_thresholds.get(language, _NO_OVERRIDE)
+
+
+def loosest_min_lines(lsh: LSHSettings) -> int:
+    """The lowest min_lines of any language: shorter regions can never be reported."""
+    overrides = [t.min_lines for t in lsh.language_thresholds.values() if t.min_lines is not None]
+    return min([lsh.min_lines, *overrides])
+
+
+def loosest_similarity(lsh: LSHSettings) -> float:
+    """The lowest similarity threshold of any language, used to collect candidate groups."""
+    overrides = [t.min_similarity for t in lsh.language_thresholds.values() if t.min_similarity is not None]
+    return min([lsh.similarity_percent, *overrides])
+
+
+def _min_similarity(language: str, lsh: LSHSettings) -> float:
+    override = _overrides(language, lsh).min_similarity
+    return lsh.similarity_percent if override is None else override
+
+
+def effective_thresholds(language: str, lsh: LSHSettings) -> LanguageThresholds:
+    """The thresholds a language is held to: its overrides, else the global settings."""
+    overrides = _overrides(language, lsh)
+    return LanguageThresholds(
+        min_lines=lsh.min_lines if overrides.min_lines is None else overrides.min_lines,
+        min_tokens=overrides.min_tokens or 0,
+        min_similarity=_min_similarity(language, lsh),
+    )
+
+
+def _region_meets(region: Region, lsh: LSHSettings, tokens: TokenCounts) -> bool:
+    """True if a region is long enough for its language."""
+    overrides = _overrides(region.language, lsh)
+    min_lines = lsh.min_lines if overrides.min_lines is None else overrides.min_lines
+    lines = region.end_line - region.start_line + 1
+    return lines >= min_lines and tokens.get(region_key(region), 0) >= (overrides.min_tokens or 0)
+
+
+def group_meets_thresholds(group: SimilarRegionGroup, lsh: LSHSettings, tokens: TokenCounts) -> bool:
+    """True if every region meets the thresholds of its language, and the group the strictest similarity of them."""
+    similarity = max(_min_similarity(region.language, lsh) for region in group.regions)
+    return group.similarity >= similarity and all(_region_meets(region, lsh, tokens) for region in group.regions)
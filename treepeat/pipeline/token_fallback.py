@@ -0,0 +1,116 @@
+import logging
+import re
+from itertools import groupby
+from pathlib import Path
+
+from treepeat.models.shingle import Shingle, ShingledRegion, ShingleList
+from treepeat.models.similarity import Region
+from treepeat.pipeline.imports import import_lines, is_mostly_imports, trim_imports
+
+logger = logging.getLogger(__name__)
+
+FALLBACK_LANGUAGE = "text"
+
+_TOKEN = re.compile(
+    r"(?P<str>\"(?:\\.|[^\"\\\n])*\"|'(?:\\.|[^'\\\n])*')"
+    r"|(?P<num>\d+(?:\.\d+)?)"
+    r"|(?P<id>\w+)"
+    r"|(?P<punct>[^\w\s])"
+)
+
+# Token kind -> (--normalize option that abstracts it, placeholder)
+_PLACEHOLDERS = {
+    "str": ("literals", "<STR>"),
+    "num": ("literals", "<NUM>"),
+    "id": ("identifiers", "<ID>"),
+}
+
+# Bytes sniffed to decide whether a file is binary
+_BINARY_SNIFF_BYTES = 8192
+
+
+def is_binary(path: Path) -> bool:
+    """True if a file looks binary (contains a NUL byte near the start) or cannot be read."""
+    try:
+        with path.open("rb") as f:
+            return b"\0" in f.read(_BINARY_SNIFF_BYTES)
+    except OSError:
+        return True
+
+
+def _normalize_token(match: re.Match[str], normalize: list[str]) -> str:
+    """Abstract literals and identifiers when the matching --normalize option is on."""
+    option, placeholder = _PLACEHOLDERS.get(match.lastgroup or "", ("", ""))
+    return placeholder if option in normalize else match.group()
+
+
+def lex(text: str) -> list[re.Match[str]]:
+    """The tokens of a text, as matches of the generic lexer whose group names them (str, num, id or punct)."""
+    return list(_TOKEN.finditer(text))
+
+
+def tokenize(lines: list[str], normalize: list[str]) -> list[tuple[str, int]]:
+    """Split lines into (token, 1-indexed line number) pairs using a generic lexer."""
+    return [
+        (_normalize_token(match, normalize), number)
+        for number, line in enumerate(lines, start=1)
+        for match in _TOKEN.finditer(line)
+    ]
+
+
+def _blocks(lines: list[str]) -> list[tuple[int, int]]:
+    """Return (start, end) line ranges of blank-line separated blocks."""
+    blocks = []
+    for blank, group in groupby(enumerate(lines, start=1), key=lambda item: not item[1].strip()):
+        if not blank:
+            numbers = [number for number, _ in group]
+            blocks.append((numbers[0], numbers[-1]))
+    return blocks
+
+
+def _code_blocks(lines: list[str]) -> list[tuple[int, int]]:
+    """Blocks trimmed of leading and trailing imports, without those that are mostly imports."""
+    imports = import_lines(lines)
+    trimmed = [trim_imports(lines, start, end, imports) for start, end in _blocks(lines)]
+    return [
+        (start, end)
+        for start, end in trimmed
+        if start <= end and not is_mostly_imports(lines, start, end, imports)
+    ]
+
+
+def _shingle_tokens(tokens: list[tuple[str, int]], k: int) -> list[Shingle]:
+    """Build k-token shingles that remember the lines they span."""
+    windows = [tokens[i : i + k] for i in range(max(1, len(tokens) - k + 1))]
+    return [
+        Shingle(content=" ".join(token for token, _ in window), start_line=window[0][1], end_line=window[-1][1])
+        for window in windows
+        if window
+    ]
+
+
+def _block_region(path: Path, start: int, end: int, tokens: list[tuple[str, int]], k: int) -> ShingledRegion:
+    """Shingle the tokens of one block."""
+    region = Region(
+        path=path,
+        language=FALLBACK_LANGUAGE,
+        region_type="lines",
+        region_name=f"lines {start}-{end}",
+        start_line=start,
+        end_line=end,
+    )
+    block_tokens = [(token, line) for token, line in tokens if start <= line <= end]
+    return ShingledRegion(region=region, shingles=ShingleList(shingles=_shingle_tokens(block_tokens, k)))
+
+
+def extract_token_regions(path: Path, k: int, min_lines: int, normalize: list[str]) -> list[ShingledRegion]:
+    """Shingle each blank-line separated block of a file that has no tree-sitter grammar."""
+    lines = path.read_text(encoding="utf-8", errors="replace").splitlines()
+    tokens = tokenize(lines, normalize)
+    regions = [
+        _block_region(path, start, end, tokens, k)
+        for start, end in _code_blocks(lines)
+        if end - start + 1 >= min_lines
+    ]
+    logger.debug("Token fallback extracted %d region(s) from %s", len(regions), path)
+    return regions
@@ -1,4 +1,6 @@
+import time
 from dataclasses import dataclass, field
+from pathlib import Path
 
 
 @dataclass
@@ -9,6 +11,14 @@ class VerboseMetrics:
     used_node_types_by_language: dict[str, set[str]] = field(default_factory=dict)
     stage_timings: dict[str, float] = field(default_factory=dict)
     stage_counts: dict[str, int] = field(default_factory=dict)
+    # (stage, monotonic start time, seconds) of every stage run, in order
+    stage_spans: list[tuple[str, float, float]] = field(default_factory=list)
+    fragment_counts: dict[tuple[str, str], int] = field(default_factory=dict)
+    # The fragment counts of each file, for the fingerprint cache to replay when the file is a hit
+    file_fragment_counts: dict[Path, dict[tuple[str, str], int]] = field(default_factory=dict)
+    parsed_lines: int = 0
+    cache_hits: int = 0
+    cache_misses: int = 0
 
 
 # Global metrics instance
@@ -33,11 +43,43 @@ def record_used_node_type(language: str, node_type: str) -> None:
     _metrics.used_node_types_by_language[language].add(node_type)
 
 
+def record_fragment_type(path: Path, language: str, region_type: str) -> None:
+    """Record that a fragment of this region type was extracted from a file."""
+    record_fragment_counts(path, {(language, region_type): 1})
+
+
+def record_fragment_counts(path: Path, counts: dict[tuple[str, str], int]) -> None:
+    """Record the fragments of a file by (language, region type), extracted or replayed from the cache."""
+    per_file = _metrics.file_fragment_counts.setdefault(path, {})
+    for key, count in counts.items():
+        _metrics.fragment_counts[key] = _metrics.fragment_counts.get(key, 0) + count
+        per_file[key] = per_file.get(key, 0) + count
+
+
+def fragment_counts_since(
+    counts: dict[tuple[str, str], int], before: dict[tuple[str, str], int]
+) -> dict[tuple[str, str], int]:
+    """The fragments counted since ``before`` was a copy of ``counts``."""
+    return {key: count - before.get(key, 0) for key, count in counts.items() if count > before.get(key, 0)}
+
+
 def record_stage_timing(stage: str, elapsed_s: float) -> None:
-    """Record wall-clock time for a pipeline stage."""
+    """Record wall-clock time for a pipeline stage that just finished."""
     _metrics.stage_timings[stage] = elapsed_s
+    _metrics.stage_spans.append((stage, time.monotonic() - elapsed_s, elapsed_s))
 
 
 def record_stage_count(stage: str, count: int) -> None:
     """Record item count produced by a pipeline stage."""
     _metrics.stage_counts[stage] = count
+
+
+def record_parsed_lines(count: int) -> None:
+    """Record the source lines of the files parsed."""
+    _metrics.parsed_lines += count
+
+
+def record_cache_lookups(hits: int, misses: int) -> None:
+    """Record files found in (and missing from) the fingerprint cache."""
+    _metrics.cache_hits += hits
+    _metrics.cache_misses += misses
@@ -1,45 +1,102 @@
 import logging
-import sys
+from concurrent.futures import ProcessPoolExecutor
 from difflib import SequenceMatcher
 from pathlib import Path
-from typing import TYPE_CHECKING
+from typing import TYPE_CHECKING, Iterable, Iterator, Sequence
 
-from tqdm import tqdm
+from tree_sitter import Node
 
-from treepeat.models.shingle import ShingledRegion
-from treepeat.pipeline.languages.base import rules_anonymize_region_name
+from treepeat.models.ast import ParsedFile
+from treepeat.models.shingle import Shingle, ShingledRegion
+from treepeat.pipeline.fingerprint import group_fingerprint
+from treepeat.pipeline.languages import LANGUAGE_CONFIGS
+from treepeat.pipeline.languages.base import rules_anonymize_region_name, rules_normalize_signature_types
+from treepeat.pipeline.notebook import is_notebook, read_notebook_lines
+from treepeat.pipeline.progress import track
 
 if TYPE_CHECKING:
+    from treepeat.config import MaxGap
     from treepeat.models.similarity import Region, SimilarRegionGroup
     from treepeat.pipeline.rules.models import Rule
 
 logger = logging.getLogger(__name__)
 
+# A SequenceMatcher opcode: (tag, i1, i2, j1, j2)
+Opcode = tuple[str, int, int, int, int]
+
 # Threshold above which we verify against raw source text
 # Only check signature for near-perfect matches (98%+) to catch false 100% matches
 # where only the function/class name differs
 SOURCE_VERIFICATION_THRESHOLD = 0.98
 
+# Similarity of regions identical only once their signature types are normalized
+# (--normalize-signature-types), so they are reported as near-matches, not exact clones
+SIGNATURE_TYPE_MATCH_SIMILARITY = 0.95
+
 
-def _compute_ordered_similarity(shingles1: list[str], shingles2: list[str]) -> float:
-    """Compute order-sensitive similarity between two shingle lists.
+def _ordered_match(shingles1: list[str], shingles2: list[str]) -> tuple[float, list[Opcode]]:
+    """Order-sensitive similarity of two shingle lists, with the opcodes it was computed from.
 
     Uses Ratcliff/Obershelp (contiguous matching blocks) via SequenceMatcher,
-    which is C-implemented and far faster than a pure-Python LCS DP table.
+    which is C-implemented and far faster than a pure-Python LCS DP table:
+    the similarity is twice the shingles of the equal opcodes over all shingles.
     autojunk=False ensures common shingles are never silently skipped.
     """
     if not shingles1 or not shingles2:
+        return 0.0, []
+    opcodes = SequenceMatcher(None, shingles1, shingles2, autojunk=False).get_opcodes()
+    matched = sum(i2 - i1 for tag, i1, i2, _j1, _j2 in opcodes if tag == "equal")
+    return 2.0 * matched / (len(shingles1) + len(shingles2)), opcodes
+
+
+def _gap_lines(shingles: Sequence[Shingle | str]) -> int:
+    """Number of source lines touched by a run of differing shingles."""
+    lines = {s.start_line for s in shingles if isinstance(s, Shingle)}
+    return len(lines) if lines else len(shingles)
+
+
+def _largest_gap(opcodes: list[Opcode], shingles1: Sequence[Shingle | str], shingles2: Sequence[Shingle | str]) -> int:
+    """Return the size, in lines, of the largest inserted, deleted or modified stretch between two regions."""
+    return max(
+        (
+            max(_gap_lines(shingles1[i1:i2]), _gap_lines(shingles2[j1:j2]))
+            for tag, i1, i2, j1, j2 in opcodes
+            if tag != "equal"
+        ),
+        default=0,
+    )
+
+
+def _allowed_gap(max_gap_lines: "MaxGap", region1: "Region", region2: "Region") -> int:
+    """Lines a gap may span: max_gap_lines, or its percentage of the lines of the longer region."""
+    if isinstance(max_gap_lines, int):
+        return max_gap_lines
+    lines = max(region.end_line - region.start_line + 1 for region in (region1, region2))
+    return int(float(max_gap_lines.rstrip("%")) * lines / 100)
+
+
+def _shingle_similarity(sr1: ShingledRegion, sr2: ShingledRegion, max_gap_lines: "MaxGap | None") -> float:
+    """Ordered shingle similarity, or 0.0 when the regions differ by a gap wider than max_gap_lines."""
+    similarity, opcodes = _ordered_match(sr1.shingles.get_contents(), sr2.shingles.get_contents())
+    if max_gap_lines is None or similarity == 1.0:
+        return similarity
+    allowed = _allowed_gap(max_gap_lines, sr1.region, sr2.region)
+    if _largest_gap(opcodes, sr1.shingles.shingles, sr2.shingles.shingles) > allowed:
+        logger.debug("Gap between %s and %s exceeds %d line(s)", sr1.region, sr2.region, allowed)
         return 0.0
-    return SequenceMatcher(None, shingles1, shingles2, autojunk=False).ratio()
+    return similarity
 
 
 def _read_source_lines(file_path: Path, start_line: int, end_line: int) -> list[str]:
     """Read source lines from a file."""
     try:
-        with open(file_path, 'r', encoding='utf-8', errors='ignore') as f:
-            lines = f.readlines()
-            # Convert to 0-indexed
-            return [line.rstrip() for line in lines[start_line - 1:end_line]]
+        if is_notebook(file_path):
+            lines = read_notebook_lines(file_path)
+        else:
+            with open(file_path, 'r', encoding='utf-8', errors='ignore') as f:
+                lines = f.readlines()
+        # Convert to 0-indexed
+        return [line.rstrip() for line in lines[start_line - 1:end_line]]
     except Exception as e:
         logger.warning("Failed to read source from %s: %s", file_path, e)
         return []
@@ -63,6 +120,80 @@ def _check_signature_match(
     return lines1[0].strip() == lines2[0].strip()
 
 
+def _signature_type_rules(rules: "list[Rule]", language: str) -> "list[Rule]":
+    """The active rules normalizing the signature types of a language (--normalize-signature-types)."""
+    config = LANGUAGE_CONFIGS.get(language)
+    names = {rule.name for rule in config.get_signature_type_rules()} if config else set()
+    return [rule for rule in rules if rule.name in names and rule.matches_language(language)]
+
+
+def _parse_region_file(path: Path) -> ParsedFile | None:
+    """Parse the file of a region again (the code cells of a notebook), or None if it cannot be."""
+    from treepeat.pipeline.parse import parse_file, parse_notebook
+
+    try:
+        return parse_notebook(path)[0] if is_notebook(path) else parse_file(path)
+    except Exception as e:
+        logger.warning("Failed to parse %s: %s", path, e)
+        return None
+
+
+def _spans_region(node: Node, region: "Region") -> bool:
+    """True if a node covers all the lines of a region."""
+    return node.start_point[0] + 1 <= region.start_line and region.end_line <= node.end_point[0] + 1
+
+
+def _region_node(root: Node, region: "Region") -> Node | None:
+    """The outermost node spanning exactly the lines of a region."""
+    stack = [root]
+    while stack:
+        node = stack.pop()
+        if (node.start_point[0] + 1, node.end_point[0] + 1) == (region.start_line, region.end_line):
+            return node
+        stack.extend(child for child in node.children if _spans_region(child, region))
+    return None
+
+
+def _region_source_node(region: "Region") -> tuple[bytes, Node] | None:
+    """The source of the file of a region and the node the region spans, or None if they cannot be found."""
+    parsed = _parse_region_file(region.path)
+    node = _region_node(parsed.root_node, region) if parsed is not None else None
+    return (parsed.source, node) if parsed is not None and node is not None else None
+
+
+def _signature_types(region: "Region", type_rules: "list[Rule]") -> list[bytes]:
+    """The source of the nodes of a region that its signature type rules normalize."""
+    from treepeat.pipeline.rules.engine import RuleEngine
+
+    found = _region_source_node(region)
+    if found is None:
+        return []
+    source, node = found
+    engine = RuleEngine(type_rules)
+    return [
+        source[match.start_byte : match.end_byte]
+        for rule in type_rules
+        for match in engine.get_nodes_matching_query(node, rule.query, region.language)
+    ]
+
+
+def _matched_through_signature_types(r1: "Region", r2: "Region", similarity: float, rules: "list[Rule]") -> bool:
+    """True if two regions are only identical because the active rules normalize their differing signature types.
+
+    The nodes those rules normalize (e.g. the parameter and return types) are compared as written, so
+    other differences of the signatures, such as parameter names, do not count.
+    """
+    if similarity < 1.0:
+        return False
+    normalized = rules_normalize_signature_types(rules, r1.language) and rules_normalize_signature_types(
+        rules, r2.language
+    )
+    if not normalized:
+        return False
+    types1 = _signature_types(r1, _signature_type_rules(rules, r1.language))
+    return types1 != _signature_types(r2, _signature_type_rules(rules, r2.language))
+
+
 def _build_region_lookup(
     shingled_regions: list[ShingledRegion],
 ) -> dict[Path, dict[int, ShingledRegion]]:
@@ -75,6 +206,14 @@ def _build_region_lookup(
     return region_to_shingled
 
 
+def _lookup_shingled_regions(
+    regions: list["Region"], region_lookup: dict[Path, dict[int, ShingledRegion]]
+) -> list[ShingledRegion]:
+    """Return the shingled regions for the given regions, skipping any not found."""
+    found = (region_lookup.get(r.path, {}).get(r.start_line) for r in regions)
+    return [sr for sr in found if sr is not None]
+
+
 _CODE_REGION_TYPES = ("function", "class", "method")
 
 
@@ -102,11 +241,40 @@ def _should_verify_signatures(
     return not _both_names_anonymized(r1, r2, rules)
 
 
+def _verify_signatures(r1: "Region", r2: "Region", similarity: float, rules: "list[Rule]") -> float:
+    """The shingle similarity of two regions, penalized when their raw signatures tell them apart."""
+    if _matched_through_signature_types(r1, r2, similarity, rules):
+        logger.debug("%s ↔ %s only match through signature types", r1.region_name, r2.region_name)
+        return SIGNATURE_TYPE_MATCH_SIMILARITY
+
+    # For high similarity code regions, verify that signatures match
+    # This catches cases where function/class names differ but bodies are similar
+    if not _should_verify_signatures(r1, r2, similarity, rules):
+        return similarity
+
+    signatures_match = _check_signature_match(
+        r1.path, r1.start_line,
+        r2.path, r2.start_line
+    )
+
+    if not signatures_match:
+        # Penalize signature mismatch - treat as 0.0 similarity
+        logger.debug(
+            "Signature mismatch for %s ↔ %s, treating as 0%% similar",
+            r1.region_name,
+            r2.region_name,
+        )
+        return 0.0
+
+    return similarity
+
+
 def _compute_pair_similarity_with_verification(
     r1: "Region",
     r2: "Region",
     region_lookup: dict[Path, dict[int, ShingledRegion]],
     rules: "list[Rule]",
+    max_gap_lines: "MaxGap | None" = None,
 ) -> float:
     """Compute similarity between two regions with signature verification."""
     sr1 = region_lookup.get(r1.path, {}).get(r1.start_line)
@@ -121,37 +289,14 @@ def _compute_pair_similarity_with_verification(
         return 0.0
 
     # Compute shingle-based similarity using shingle contents
-    shingle_similarity = _compute_ordered_similarity(
-        sr1.shingles.get_contents(),
-        sr2.shingles.get_contents(),
-    )
-
-    # For high similarity code regions, verify that signatures match
-    # This catches cases where function/class names differ but bodies are similar
-    if not _should_verify_signatures(r1, r2, shingle_similarity, rules):
-        return shingle_similarity
-
-    signatures_match = _check_signature_match(
-        r1.path, r1.start_line,
-        r2.path, r2.start_line
-    )
-
-    if not signatures_match:
-        # Penalize signature mismatch - treat as 0.0 similarity
-        logger.debug(
-            "Signature mismatch for %s ↔ %s, treating as 0%% similar",
-            r1.region_name,
-            r2.region_name,
-        )
-        return 0.0
-
-    return shingle_similarity
+    return _verify_signatures(r1, r2, _shingle_similarity(sr1, sr2, max_gap_lines), rules)
 
 
 def _verify_group_pairwise_similarity(
     group_regions: list["Region"],
     region_lookup: dict[Path, dict[int, ShingledRegion]],
     rules: "list[Rule]",
+    max_gap_lines: "MaxGap | None" = None,
 ) -> float:
     """Calculate average pairwise order-sensitive similarity for a group."""
     if len(group_regions) < 2:
@@ -163,7 +308,7 @@ def _verify_group_pairwise_similarity(
     for i, r1 in enumerate(group_regions):
         for r2 in group_regions[i + 1 :]:
             similarity = _compute_pair_similarity_with_verification(
-                r1, r2, region_lookup, rules
+                r1, r2, region_lookup, rules, max_gap_lines
             )
             total_similarity += similarity
             pair_count += 1
@@ -171,52 +316,94 @@ def _verify_group_pairwise_similarity(
     return total_similarity / pair_count if pair_count > 0 else 1.0
 
 
-def verify_similar_groups(
+def _verify_group_payload(
+    payload: "tuple[list[Region], list[ShingledRegion], list[Rule], MaxGap | None]",
+) -> float:
+    """Verify one group from a self-contained payload (runs in a worker process)."""
+    regions, shingled_regions, rules, max_gap_lines = payload
+    return _verify_group_pairwise_similarity(regions, _build_region_lookup(shingled_regions), rules, max_gap_lines)
+
+
+def _iter_group_similarities(
+    groups: list["SimilarRegionGroup"],
+    region_lookup: dict[Path, dict[int, ShingledRegion]],
+    rules: "list[Rule]",
+    progress: bool,
+    jobs: int,
+    max_gap_lines: "MaxGap | None" = None,
+) -> Iterator[float]:
+    """Yield the verified similarity of each group, in the same order as ``groups``.
+
+    With more than one job, groups are verified in worker processes. Results
+    are collected with ``Executor.map``, which preserves input order, so the
+    outcome is identical regardless of how the workers are scheduled.
+    """
+    if jobs <= 1 or len(groups) < 2:
+        iterable = track(groups, "Verifying", "group") if progress else groups
+        for g in iterable:
+            yield _verify_group_pairwise_similarity(g.regions, region_lookup, rules, max_gap_lines)
+        return
+
+    payloads = [
+        (g.regions, _lookup_shingled_regions(g.regions, region_lookup), rules, max_gap_lines) for g in groups
+    ]
+    chunksize = max(1, len(payloads) // (jobs * 4))
+    with ProcessPoolExecutor(max_workers=jobs) as executor:
+        results: Iterable[float] = executor.map(_verify_group_payload, payloads, chunksize=chunksize)
+        if progress:
+            results = track(results, "Verifying", "group", total=len(payloads))
+        yield from results
+
+
+def iter_verified_groups(
     groups: list["SimilarRegionGroup"],
     shingled_regions: list[ShingledRegion],
     rules: "list[Rule]",
     progress: bool = False,
-) -> list["SimilarRegionGroup"]:
-    """Verify candidate groups using order-sensitive similarity.
+    jobs: int = 1,
+    max_gap_lines: "MaxGap | None" = None,
+) -> Iterator["SimilarRegionGroup"]:
+    """Verify candidate groups using order-sensitive similarity, yielding each as soon as it is verified.
 
     For each group, recalculates similarity using pairwise SequenceMatcher
     comparison to ensure matches respect line order (not just set similarity).
     ``rules`` is the active ruleset; it drives whether a name-only signature
     difference is penalized (see ``_should_verify_signatures``). Pass ``[]``
-    to opt out of anonymization-aware verification.
+    to opt out of anonymization-aware verification. ``jobs`` > 1 spreads the
+    comparisons over worker processes without changing the result. With
+    ``max_gap_lines``, pairs whose largest differing stretch is wider than that
+    many lines (or that percentage of their lines) are treated as unrelated (0% similar).
     """
     logger.info("Verifying %d candidate group(s) with order-sensitive similarity", len(groups))
 
-    region_lookup = _build_region_lookup(shingled_regions)
-    verified_groups = []
-
-    iterable = (
-        tqdm(groups, desc="Verifying", unit="group", file=sys.stderr)
-        if progress
-        else groups
-    )
+    # Import here to avoid circular dependency
+    from treepeat.models.similarity import SimilarRegionGroup
 
-    for group in iterable:
-        # Recalculate group similarity using order-sensitive verification
-        verified_similarity = _verify_group_pairwise_similarity(
-            group.regions, region_lookup, rules
-        )
+    region_lookup = _build_region_lookup(shingled_regions)
+    similarities = _iter_group_similarities(groups, region_lookup, rules, progress, jobs, max_gap_lines)
 
+    for group, verified_similarity in zip(groups, similarities, strict=True):
         logger.debug(
             "Verified group of %d regions: LSH=%.1f%%, Ordered=%.1f%%",
             len(group.regions),
             group.similarity * 100,
             verified_similarity * 100,
         )
-
-        # Import here to avoid circular dependency
-        from treepeat.models.similarity import SimilarRegionGroup
-
-        verified_group = SimilarRegionGroup(
+        yield SimilarRegionGroup(
             regions=group.regions,
             similarity=verified_similarity,
+            fingerprint=group_fingerprint(_lookup_shingled_regions(group.regions, region_lookup)),
         )
-        verified_groups.append(verified_group)
 
+
+def verify_similar_groups(
+    groups: list["SimilarRegionGroup"],
+    shingled_regions: list[ShingledRegion],
+    rules: "list[Rule]",
+    progress: bool = False,
+    jobs: int = 1,
+) -> list["SimilarRegionGroup"]:
+    """Verify candidate groups using order-sensitive similarity (see ``iter_verified_groups``)."""
+    verified_groups = list(iter_verified_groups(groups, shingled_regions, rules, progress, jobs))
     logger.info("Verification complete: %d group(s) verified", len(verified_groups))
     return verified_groups
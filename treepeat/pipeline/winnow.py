@@ -0,0 +1,49 @@
+import hashlib
+import logging
+from typing import Sequence
+
+from treepeat.models.shingle import Shingle, ShingledRegion, ShingleList
+
+logger = logging.getLogger(__name__)
+
+STRATEGIES = ["shingle", "winnow"]
+
+
+def _hash(shingle: Shingle | str) -> int:
+    """Stable hash of a shingle's content (Python's hash() is salted per process)."""
+    return int.from_bytes(hashlib.sha1(str(shingle).encode("utf-8")).digest()[:8], "big")
+
+
+def select_fingerprints(shingles: Sequence[Shingle | str], window: int) -> list[Shingle | str]:
+    """Pick the shingles kept by robust winnowing (Schleimer et al., 2003).
+
+    The minimum hash of every ``window`` consecutive shingles is selected
+    (the rightmost one on ties), so any run of ``window`` shared shingles is
+    guaranteed to share at least one fingerprint.
+    """
+    if not shingles:
+        return []
+    hashes = [_hash(s) for s in shingles]
+    selected: list[int] = []
+    for start in range(max(1, len(hashes) - window + 1)):
+        chosen = min(range(start, min(start + window, len(hashes))), key=lambda i: (hashes[i], -i))
+        if not selected or selected[-1] != chosen:
+            selected.append(chosen)
+    return [shingles[i] for i in selected]
+
+
+def winnow_region(shingled_region: ShingledRegion, window: int) -> ShingledRegion:
+    """Replace a region's shingles with its winnowed fingerprints.
+
+    Fingerprints are kept in content order rather than source order, so the
+    order-sensitive verification compares them as a bag and moved blocks of
+    code still match.
+    """
+    fingerprints = sorted(select_fingerprints(shingled_region.shingles.shingles, window), key=str)
+    return ShingledRegion(region=shingled_region.region, shingles=ShingleList(shingles=fingerprints))
+
+
+def winnow_regions(shingled_regions: list[ShingledRegion], window: int) -> list[ShingledRegion]:
+    """Winnow every shingled region."""
+    logger.info("Winnowing %d region(s) with window=%d", len(shingled_regions), window)
+    return [winnow_region(sr, window) for sr in shingled_regions]
@@ -0,0 +1,106 @@
+from collections.abc import Callable
+from dataclasses import dataclass
+from pathlib import Path
+
+from treepeat.models.similarity import SimilarityResult, SimilarRegionGroup
+
+# --fail-on modes: fail on any clone not in the baseline, on any clone at all, or only on exceeded budgets
+FAIL_ON_MODES = ("new-clones", "any", "threshold")
+
+
+@dataclass(frozen=True)
+class FailPolicy:
+    """When a detect run should exit with status 1."""
+
+    fail_on: str | None = None
+    max_duplication_pct: float | None = None
+    max_new_duplicated_lines: int | None = None
+    max_duplication_increase_pct: float | None = None
+
+    @property
+    def has_budget(self) -> bool:
+        budgets = (self.max_duplication_pct, self.max_new_duplicated_lines, self.max_duplication_increase_pct)
+        return any(budget is not None for budget in budgets)
+
+    @property
+    def is_set(self) -> bool:
+        """True if the policy can be broken at all."""
+        return self.fail_on is not None or self.has_budget
+
+
+def duplicated_lines(groups: list[SimilarRegionGroup]) -> int:
+    """Number of distinct source lines covered by a copy of some clone class."""
+    return len(
+        {
+            (region.path, line)
+            for group in groups
+            for region in group.regions
+            for line in range(region.start_line, region.end_line + 1)
+        }
+    )
+
+
+def line_count(path: Path) -> int:
+    """Number of lines of a file (0 if it cannot be read)."""
+    try:
+        with path.open("rb") as f:
+            return sum(1 for _ in f)
+    except OSError:
+        return 0
+
+
+def _percent_of_compared_lines(groups: list[SimilarRegionGroup], result: SimilarityResult) -> float:
+    total = sum(line_count(path) for path in {sig.region.path for sig in result.signatures})
+    return 100.0 * duplicated_lines(groups) / total if total else 0.0
+
+
+def duplication_percent(result: SimilarityResult) -> float:
+    """Duplicated lines as a percentage of the lines of the files that had regions compared."""
+    return _percent_of_compared_lines(result.similar_groups, result)
+
+
+def duplication_increase_percent(found: SimilarityResult, new_groups: list[SimilarRegionGroup]) -> float:
+    """The percentage points of duplication added by the new clones (those not in the baseline)."""
+    return _percent_of_compared_lines(new_groups, found)
+
+
+def _count_violation(groups: list[SimilarRegionGroup], label: str) -> str | None:
+    return f"{len(groups)} {label} found" if groups else None
+
+
+def _budget_violation(budget: float | None, measure: Callable[[], float], message: str) -> str | None:
+    """Describe an exceeded budget; the measure is only taken when a budget is set."""
+    if budget is None:
+        return None
+    value = measure()
+    return message.format(value=value, budget=budget) if value > budget else None
+
+
+def policy_violations(
+    found: SimilarityResult, new_groups: list[SimilarRegionGroup], policy: FailPolicy
+) -> list[str]:
+    """Describe every way a run breaks the policy.
+
+    ``found`` holds every clone class detected, ``new_groups`` those that are not in the baseline.
+    """
+    checks = [
+        _count_violation(found.similar_groups, "clone class(es)") if policy.fail_on == "any" else None,
+        _count_violation(new_groups, "new clone class(es)") if policy.fail_on == "new-clones" else None,
+        _budget_violation(
+            policy.max_duplication_pct,
+            lambda: duplication_percent(found),
+            "duplication is {value:.1f}% of the lines, above --max-duplication-pct {budget:g}",
+        ),
+        _budget_violation(
+            policy.max_new_duplicated_lines,
+            lambda: duplicated_lines(new_groups),
+            "new clones cover {value} lines, above --max-new-duplicated-lines {budget}",
+        ),
+        _budget_violation(
+            policy.max_duplication_increase_pct,
+            lambda: duplication_increase_percent(found, new_groups),
+            "new clones add {value:.1f}% of the lines to the duplication, above --max-duplication-increase-pct "
+            "{budget:g}",
+        ),
+    ]
+    return [violation for violation in checks if violation is not None]
@@ -0,0 +1,89 @@
+"""Profiles of a detect run, to attach to performance bug reports."""
+
+import cProfile
+import json
+import logging
+import os
+import time
+import tracemalloc
+from collections.abc import Iterator
+from contextlib import ExitStack, contextmanager
+from pathlib import Path
+from typing import Any
+
+from treepeat.pipeline.verbose_metrics import get_verbose_metrics
+
+logger = logging.getLogger(__name__)
+
+# Frames kept per allocation in a memory profile
+MEMPROFILE_FRAMES = 25
+
+
+@contextmanager
+def cpu_profile(path: Path) -> Iterator[None]:
+    """Write a cProfile of the enclosed code to ``path`` (read it with pstats, snakeviz, ...)."""
+    profiler = cProfile.Profile()
+    profiler.enable()
+    try:
+        yield
+    finally:
+        profiler.disable()
+        profiler.dump_stats(path)
+        logger.info("Wrote CPU profile to %s", path)
+
+
+@contextmanager
+def memory_profile(path: Path) -> Iterator[None]:
+    """Write a tracemalloc snapshot of what the enclosed code left allocated (``tracemalloc.Snapshot.load``)."""
+    tracemalloc.start(MEMPROFILE_FRAMES)
+    try:
+        yield
+        tracemalloc.take_snapshot().dump(str(path))
+        _, peak = tracemalloc.get_traced_memory()
+        logger.info("Wrote memory profile to %s (peak traced memory %.1f MB)", path, peak / 2**20)
+    finally:
+        tracemalloc.stop()
+
+
+def trace_events(spans: list[tuple[str, float, float]], origin: float) -> dict[str, Any]:
+    """Pipeline stage spans as a Chrome trace (chrome://tracing, Perfetto), in microseconds since ``origin``."""
+    pid = os.getpid()
+    return {
+        "traceEvents": [
+            {
+                "name": stage,
+                "cat": "stage",
+                "ph": "X",
+                "ts": round((start - origin) * 1e6),
+                "dur": round(seconds * 1e6),
+                "pid": pid,
+                "tid": 1,
+            }
+            for stage, start, seconds in spans
+        ],
+        "displayTimeUnit": "ms",
+    }
+
+
+@contextmanager
+def stage_trace(path: Path) -> Iterator[None]:
+    """Write a timeline of the pipeline stages run by the enclosed code to ``path``."""
+    origin = time.monotonic()
+    try:
+        yield
+    finally:
+        path.write_text(json.dumps(trace_events(get_verbose_metrics().stage_spans, origin)), encoding="utf-8")
+        logger.info("Wrote stage trace to %s", path)
+
+
+@contextmanager
+def profiled(cpuprofile: Path | None, memprofile: Path | None, trace: Path | None) -> Iterator[None]:
+    """Profile the enclosed code into each of the requested files."""
+    with ExitStack() as stack:
+        if cpuprofile is not None:
+            stack.enter_context(cpu_profile(cpuprofile))
+        if memprofile is not None:
+            stack.enter_context(memory_profile(memprofile))
+        if trace is not None:
+            stack.enter_context(stage_trace(trace))
+        yield
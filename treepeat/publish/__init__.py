@@ -0,0 +1,147 @@
+"""Publishing clone findings to code review platforms, as comments on the changed lines of a pull request.
+
+A finding is a copy of a clone class that touches lines the pull request
+changed. Each published comment carries a hidden marker naming its finding,
+so publishing again updates the earlier comments instead of adding new ones.
+"""
+
+import re
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Any, Protocol
+
+# Hunk header of a unified diff: @@ -old_start,old_count +new_start,new_count @@
+_HUNK = re.compile(r"^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@")
+# A file section of a multi-file diff (git diff), and the path of the file it changes (absent when deleted)
+_DIFF_SECTION = re.compile(r"^diff --git .*$", re.MULTILINE)
+_NEW_PATH = re.compile(r"^\+\+\+ b/(.+)$", re.MULTILINE)
+_MARKER = re.compile(r"<!-- treepeat:(\S+) -->")
+
+
+class PublishError(Exception):
+    """A finding that could not be published (API failure, missing pull request, ...)."""
+
+
+@dataclass(frozen=True)
+class Finding:
+    """A copy of a clone class touching changed lines, and where the comment about it goes."""
+
+    fingerprint: str
+    path: str  # relative to the repository root, with forward slashes
+    start_line: int
+    end_line: int
+    line: int  # the first changed line of the copy, which review comments are attached to
+    similarity: float
+    others: tuple[str, ...]  # the other copies, as path:start-end
+    ordinal: int = 0  # how many copies of the clone class come before this one in its file
+
+    @property
+    def key(self) -> str:
+        """Identifies the finding across runs, even once edits elsewhere in the file have moved its lines."""
+        key = f"{self.fingerprint}:{self.path}"
+        return f"{key}:{self.ordinal}" if self.ordinal else key
+
+
+class Publisher(Protocol):
+    """A code review platform's pull (or merge) request findings are published to."""
+
+    def changed_lines(self) -> dict[str, set[int]]: ...
+
+    def publish(self, findings: list[Finding]) -> dict[str, int]: ...
+
+
+def added_lines(patch: str) -> set[int]:
+    """The lines of the new file added or modified by a unified diff patch."""
+    lines: set[int] = set()
+    line = 0
+    for text in patch.splitlines():
+        hunk = _HUNK.match(text)
+        if hunk:
+            line = int(hunk.group(1))
+        elif text.startswith("+"):
+            lines.add(line)
+            line += 1
+        elif not text.startswith(("-", "\\")):
+            line += 1
+    return lines
+
+
+def _file_changes(section: str) -> tuple[str, set[int]] | None:
+    """The path and changed lines of a file section of a multi-file diff (None for deleted files)."""
+    path = _NEW_PATH.search(section)
+    hunks = section.find("\n@@")
+    if path is None or hunks < 0:
+        return None
+    return path.group(1), added_lines(section[hunks + 1 :])
+
+
+def diff_changed_lines(diff: str) -> dict[str, set[int]]:
+    """The lines each file of a multi-file unified diff adds or modifies, by path."""
+    changes = (_file_changes(section) for section in _DIFF_SECTION.split(diff))
+    return dict(change for change in changes if change is not None)
+
+
+def _relative(path: str, root: Path) -> str:
+    resolved = Path(path).resolve()
+    return resolved.relative_to(root).as_posix() if resolved.is_relative_to(root) else path
+
+
+def _describe(instance: dict[str, Any], root: Path) -> str:
+    return f"{_relative(instance['path'], root)}:{instance['start_line']}-{instance['end_line']}"
+
+
+def _ordinal(clone: dict[str, Any], instance: dict[str, Any]) -> int:
+    """How many copies of a clone class come before one of them in its file."""
+    span = (instance["start_line"], instance["end_line"])
+    return sum(
+        other["path"] == instance["path"] and (other["start_line"], other["end_line"]) < span
+        for other in clone["instances"]
+    )
+
+
+def _finding(clone: dict[str, Any], instance: dict[str, Any], changed: set[int], root: Path) -> Finding:
+    touched = [line for line in changed if instance["start_line"] <= line <= instance["end_line"]]
+    return Finding(
+        fingerprint=clone["fingerprint"],
+        path=_relative(instance["path"], root),
+        start_line=instance["start_line"],
+        end_line=instance["end_line"],
+        line=min(touched, default=0),
+        similarity=clone["similarity"],
+        others=tuple(_describe(other, root) for other in clone["instances"] if other is not instance),
+        ordinal=_ordinal(clone, instance),
+    )
+
+
+def changed_findings(report: dict[str, Any], changed_lines: dict[str, set[int]], root: Path) -> list[Finding]:
+    """The copies of the clone classes of a json report that touch changed lines (by repository relative path)."""
+    findings = (
+        _finding(clone, instance, changed_lines.get(_relative(instance["path"], root), set()), root)
+        for clone in report["clone_classes"]
+        for instance in clone["instances"]
+    )
+    return [finding for finding in findings if finding.line]
+
+
+def comment_body(finding: Finding) -> str:
+    """The Markdown of a review comment about a finding, ending with its hidden marker."""
+    copies = "\n".join(f"- `{other}`" for other in finding.others)
+    return (
+        f"**Duplicated code** ({finding.similarity:.0%} similar, `{finding.fingerprint}`): "
+        f"lines {finding.start_line}-{finding.end_line} are also found at\n\n{copies}\n\n"
+        f"<!-- treepeat:{finding.key} -->"
+    )
+
+
+def finding_message(finding: Finding) -> str:
+    """The plain text of a comment about a finding, for platforms without Markdown."""
+    return (
+        f"Duplicated code ({finding.similarity:.0%} similar, {finding.fingerprint}): "
+        f"lines {finding.start_line}-{finding.end_line} are also found at {', '.join(finding.others)}"
+    )
+
+
+def comment_key(body: str) -> str | None:
+    """The finding a published comment is about, or None if treepeat did not write it."""
+    match = _MARKER.search(body or "")
+    return match.group(1) if match else None
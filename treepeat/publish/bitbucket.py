@@ -0,0 +1,130 @@
+from abc import ABC, abstractmethod
+from typing import Any
+
+from treepeat.publish import Finding, PublishError, finding_message
+from treepeat.publish.http import ApiClient
+
+BITBUCKET_API_URL = "https://api.bitbucket.org/2.0"
+
+# Key of the Code Insights report: publishing again on a commit replaces the report of the earlier run
+REPORT_KEY = "treepeat"
+
+# Annotations a report accepts, on both Bitbucket Cloud and Bitbucket Server
+MAX_ANNOTATIONS = 1000
+
+# Annotations Bitbucket Cloud accepts per request
+_CLOUD_BATCH = 100
+
+
+def bitbucket_client(token: str, api_url: str = BITBUCKET_API_URL) -> ApiClient:
+    return ApiClient(api_url, {"Authorization": f"Bearer {token}"})
+
+
+def _details(findings: list[Finding]) -> str:
+    if not findings:
+        return "The changes introduce no duplicated code."
+    shown = "" if len(findings) <= MAX_ANNOTATIONS else f" (the first {MAX_ANNOTATIONS} are annotated)"
+    return f"{len(findings)} copies of duplicated code touch the changed lines{shown}."
+
+
+class CodeInsightsPublisher(ABC):
+    """Publishes the findings of a commit as a Code Insights report, with an annotation per finding.
+
+    Bitbucket shows the annotations in the diff of the pull requests of the commit.
+    Subclasses speak the API of Bitbucket Cloud or of Bitbucket Server.
+    """
+
+    def __init__(self, client: ApiClient, report: str, changed: dict[str, set[int]]):
+        self.client = client
+        self.report = report
+        self.changed = changed
+
+    def changed_lines(self) -> dict[str, set[int]]:
+        """The lines the commit changes since its branch point, by repository relative path."""
+        return self.changed
+
+    def _report(self, findings: list[Finding]) -> dict[str, Any]:
+        return {
+            "title": "treepeat",
+            "details": _details(findings),
+            "reporter": "treepeat",
+            "data": [{"title": "Duplicated copies", "type": "NUMBER", "value": len(findings)}],
+        }
+
+    @abstractmethod
+    def _annotate(self, findings: list[Finding]) -> None:
+        """Replace the annotations of the report with those of the findings."""
+
+    def publish(self, findings: list[Finding]) -> dict[str, int]:
+        """Replace the report of the commit, annotating each finding; count the annotations."""
+        annotated = findings[:MAX_ANNOTATIONS]
+        self.client.call("PUT", self.report, self._report(findings))
+        self._annotate(annotated)
+        return {"created": len(annotated), "omitted": len(findings) - len(annotated)}
+
+
+class BitbucketCloudPublisher(CodeInsightsPublisher):
+    """Code Insights of a Bitbucket Cloud repository (``workspace/slug``)."""
+
+    def __init__(self, client: ApiClient, repo: str, commit: str, changed: dict[str, set[int]]):
+        super().__init__(client, f"/repositories/{repo}/commit/{commit}/reports/{REPORT_KEY}", changed)
+
+    def _report(self, findings: list[Finding]) -> dict[str, Any]:
+        return {**super()._report(findings), "report_type": "BUG", "result": "FAILED" if findings else "PASSED"}
+
+    def _annotate(self, findings: list[Finding]) -> None:
+        annotations = [
+            {
+                "external_id": finding.key,
+                "annotation_type": "CODE_SMELL",
+                "severity": "MEDIUM",
+                "path": finding.path,
+                "line": finding.line,
+                "summary": finding_message(finding)[:450],
+            }
+            for finding in findings
+        ]
+        for start in range(0, len(annotations), _CLOUD_BATCH):
+            self.client.call("POST", f"{self.report}/annotations", annotations[start : start + _CLOUD_BATCH])
+
+
+class BitbucketServerPublisher(CodeInsightsPublisher):
+    """Code Insights of a Bitbucket Server (or Data Center) repository (``PROJECT/slug``)."""
+
+    def __init__(self, client: ApiClient, repo: str, commit: str, changed: dict[str, set[int]]):
+        project, _, slug = repo.partition("/")
+        if not slug:
+            raise PublishError(f"expected a PROJECT/slug repository, got {repo!r}")
+        report = f"/insights/1.0/projects/{project}/repos/{slug}/commits/{commit}/reports/{REPORT_KEY}"
+        super().__init__(client, report, changed)
+
+    def _report(self, findings: list[Finding]) -> dict[str, Any]:
+        return {**super()._report(findings), "result": "FAIL" if findings else "PASS"}
+
+    def _annotate(self, findings: list[Finding]) -> None:
+        # Annotations outlive the report they belong to: drop those of earlier runs
+        self.client.call("DELETE", f"{self.report}/annotations")
+        if not findings:
+            return
+        annotations = [
+            {
+                "externalId": finding.key,
+                "type": "CODE_SMELL",
+                "severity": "MEDIUM",
+                "path": finding.path,
+                "line": finding.line,
+                "message": finding_message(finding),
+            }
+            for finding in findings
+        ]
+        self.client.call("POST", f"{self.report}/annotations", {"annotations": annotations})
+
+
+def bitbucket_publisher(
+    token: str, repo: str, commit: str, changed: dict[str, set[int]], server_url: str | None = None
+) -> CodeInsightsPublisher:
+    """The publisher of Bitbucket Server at ``server_url``, or of Bitbucket Cloud without one."""
+    if server_url:
+        client = bitbucket_client(token, f"{server_url.rstrip('/')}/rest")
+        return BitbucketServerPublisher(client, repo, commit, changed)
+    return BitbucketCloudPublisher(bitbucket_client(token), repo, commit, changed)
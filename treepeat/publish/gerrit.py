@@ -0,0 +1,74 @@
+import base64
+from typing import Any
+from urllib.parse import quote
+
+from treepeat.formatters.gerrit import REVIEW_TAG, ROBOT_ID
+from treepeat.publish import Finding, finding_message
+from treepeat.publish.http import ApiClient
+
+# Magic file of a revision holding its commit message
+_COMMIT_MSG = "/COMMIT_MSG"
+
+
+def gerrit_client(url: str, user: str, password: str) -> ApiClient:
+    """A client of the authenticated REST API of the Gerrit at ``url``, with the user's HTTP credentials."""
+    credentials = base64.b64encode(f"{user}:{password}".encode()).decode("ascii")
+    return ApiClient(f"{url.rstrip('/')}/a", {"Authorization": f"Basic {credentials}"})
+
+
+def diff_added_lines(diff: dict[str, Any]) -> set[int]:
+    """The lines of the new file added or modified by a Gerrit DiffInfo."""
+    lines: set[int] = set()
+    line = 1
+    for chunk in diff["content"]:
+        if "b" in chunk:
+            lines.update(range(line, line + len(chunk["b"])))
+        line += len(chunk.get("ab", ())) + len(chunk.get("b", ())) + chunk.get("skip", 0)
+    return lines
+
+
+class GerritPublisher:
+    """Posts a robot comment per finding on a revision (patch set) of a change, skipping those posted before."""
+
+    def __init__(self, client: ApiClient, change: str, revision: str):
+        self.client = client
+        self.run_id = revision
+        self.revision = f"/changes/{quote(change, safe='')}/revisions/{quote(revision, safe='')}"
+
+    def changed_lines(self) -> dict[str, set[int]]:
+        """The lines each file of the revision adds or modifies, by repository relative path."""
+        files = self.client.call("GET", f"{self.revision}/files")
+        return {
+            path: diff_added_lines(self.client.call("GET", f"{self.revision}/files/{quote(path, safe='')}/diff"))
+            for path, info in files.items()
+            if path != _COMMIT_MSG and info.get("status") != "D"
+        }
+
+    def _posted(self) -> set[str]:
+        """The findings of the robot comments posted on the revision by earlier runs."""
+        comments = self.client.call("GET", f"{self.revision}/robotcomments")
+        return {
+            comment["properties"]["key"]
+            for file_comments in comments.values()
+            for comment in file_comments
+            if comment.get("robot_id") == ROBOT_ID and "key" in comment.get("properties", {})
+        }
+
+    def publish(self, findings: list[Finding]) -> dict[str, int]:
+        """Comment on each finding not commented on yet; count the comments by outcome."""
+        posted = self._posted()
+        new = [finding for finding in findings if finding.key not in posted]
+        comments: dict[str, list[dict[str, Any]]] = {}
+        for finding in new:
+            comments.setdefault(finding.path, []).append(
+                {
+                    "robot_id": ROBOT_ID,
+                    "robot_run_id": self.run_id,
+                    "line": finding.line,
+                    "message": finding_message(finding),
+                    "properties": {"fingerprint": finding.fingerprint, "key": finding.key},
+                }
+            )
+        if comments:
+            self.client.call("POST", f"{self.revision}/review", {"tag": REVIEW_TAG, "robot_comments": comments})
+        return {"created": len(new), "unchanged": len(findings) - len(new)}
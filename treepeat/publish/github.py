@@ -0,0 +1,56 @@
+from typing import Any
+
+from treepeat.publish import Finding, added_lines, comment_body, comment_key
+from treepeat.publish.http import ApiClient
+
+GITHUB_API_URL = "https://api.github.com"
+
+
+def github_client(token: str, api_url: str = GITHUB_API_URL) -> ApiClient:
+    headers = {
+        "Authorization": f"Bearer {token}",
+        "Accept": "application/vnd.github+json",
+        "X-GitHub-Api-Version": "2022-11-28",
+    }
+    return ApiClient(api_url, headers)
+
+
+class GitHubPublisher:
+    """Posts findings as review comments on a pull request, updating those of earlier runs."""
+
+    def __init__(self, client: ApiClient, repo: str, pr: int):
+        self.client = client
+        self.pull = f"/repos/{repo}/pulls/{pr}"
+        self.repo = repo
+
+    def changed_lines(self) -> dict[str, set[int]]:
+        """The lines each file of the pull request adds or modifies, by repository relative path."""
+        files = self.client.paginate(f"{self.pull}/files", {"per_page": 100})
+        return {file["filename"]: added_lines(file["patch"]) for file in files if file.get("patch")}
+
+    def _existing_comments(self) -> dict[str, dict[str, Any]]:
+        """The review comments of earlier runs, by finding."""
+        comments = self.client.paginate(f"{self.pull}/comments", {"per_page": 100})
+        return {key: comment for comment in comments if (key := comment_key(comment["body"])) is not None}
+
+    def publish(self, findings: list[Finding]) -> dict[str, int]:
+        """Comment on each finding, or update the comment of an earlier run; count the comments by outcome."""
+        existing = self._existing_comments()
+        head = self.client.call("GET", self.pull)["head"]["sha"]
+        counts = {"created": 0, "updated": 0, "unchanged": 0}
+        for finding in findings:
+            body = comment_body(finding)
+            comment = existing.get(finding.key)
+            if comment is None:
+                self.client.call(
+                    "POST",
+                    f"{self.pull}/comments",
+                    {"body": body, "commit_id": head, "path": finding.path, "line": finding.line, "side": "RIGHT"},
+                )
+                counts["created"] += 1
+            elif comment["body"] != body:
+                self.client.call("PATCH", f"/repos/{self.repo}/pulls/comments/{comment['id']}", {"body": body})
+                counts["updated"] += 1
+            else:
+                counts["unchanged"] += 1
+        return counts
@@ -0,0 +1,83 @@
+from typing import Any
+from urllib.parse import quote
+
+from treepeat.publish import Finding, added_lines, comment_body, comment_key
+from treepeat.publish.http import ApiClient
+
+GITLAB_API_URL = "https://gitlab.com/api/v4"
+
+
+def gitlab_client(token: str, api_url: str = GITLAB_API_URL) -> ApiClient:
+    return ApiClient(api_url, {"PRIVATE-TOKEN": token})
+
+
+class GitLabPublisher:
+    """Opens a merge request discussion per finding, updating those of earlier runs and resolving the fixed ones."""
+
+    def __init__(self, client: ApiClient, project: str, mr: int):
+        self.client = client
+        self.merge_request = f"/projects/{quote(project, safe='')}/merge_requests/{mr}"
+
+    def changed_lines(self) -> dict[str, set[int]]:
+        """The lines each file of the merge request adds or modifies, by repository relative path."""
+        diffs = self.client.paginate(f"{self.merge_request}/diffs", {"per_page": 100})
+        return {diff["new_path"]: added_lines(diff["diff"]) for diff in diffs if not diff.get("deleted_file")}
+
+    def _existing_discussions(self) -> dict[str, dict[str, Any]]:
+        """The discussions of earlier runs, by finding."""
+        discussions = self.client.paginate(f"{self.merge_request}/discussions", {"per_page": 100})
+        return {
+            key: discussion
+            for discussion in discussions
+            if (key := comment_key(discussion["notes"][0]["body"])) is not None
+        }
+
+    def _resolve(self, discussion: dict[str, Any], resolved: bool) -> None:
+        self.client.call(
+            "PUT", f"{self.merge_request}/discussions/{discussion['id']}", params={"resolved": str(resolved).lower()}
+        )
+
+    def _open(self, finding: Finding, refs: dict[str, str]) -> None:
+        position = {
+            "position_type": "text",
+            "base_sha": refs["base_sha"],
+            "start_sha": refs["start_sha"],
+            "head_sha": refs["head_sha"],
+            "old_path": finding.path,
+            "new_path": finding.path,
+            "new_line": finding.line,
+        }
+        body = {"body": comment_body(finding), "position": position}
+        self.client.call("POST", f"{self.merge_request}/discussions", body)
+
+    def _refresh(self, finding: Finding, discussion: dict[str, Any]) -> str:
+        """Bring the discussion of an earlier run up to date, reopening it if it was resolved."""
+        note = discussion["notes"][0]
+        outcome = "unchanged"
+        body = comment_body(finding)
+        if note["body"] != body:
+            path = f"{self.merge_request}/discussions/{discussion['id']}/notes/{note['id']}"
+            self.client.call("PUT", path, {"body": body})
+            outcome = "updated"
+        if note.get("resolved"):
+            self._resolve(discussion, False)
+            outcome = "reopened"
+        return outcome
+
+    def publish(self, findings: list[Finding]) -> dict[str, int]:
+        """Discuss each finding and resolve the discussions of clones gone since; count the discussions by outcome."""
+        existing = self._existing_discussions()
+        refs = self.client.call("GET", self.merge_request)["diff_refs"]
+        counts = dict.fromkeys(("created", "updated", "reopened", "unchanged", "resolved"), 0)
+        for finding in findings:
+            discussion = existing.pop(finding.key, None)
+            if discussion is None:
+                self._open(finding, refs)
+                counts["created"] += 1
+            else:
+                counts[self._refresh(finding, discussion)] += 1
+        for discussion in existing.values():
+            if not discussion["notes"][0].get("resolved"):
+                self._resolve(discussion, True)
+                counts["resolved"] += 1
+        return counts
@@ -0,0 +1,97 @@
+import json
+import logging
+import re
+import time
+import urllib.error
+import urllib.request
+from email.message import Message
+from typing import Any
+from urllib.parse import urlencode
+
+from treepeat.publish import PublishError
+
+logger = logging.getLogger(__name__)
+
+# Longest wait for a rate limit to reset before giving up, in seconds
+MAX_RATE_LIMIT_WAIT = 300.0
+
+_NEXT_LINK = re.compile(r'<([^>]+)>;\s*rel="next"')
+
+# Prefix Gerrit puts before its JSON responses, against cross-site script inclusion
+_XSSI_PREFIX = b")]}'"
+
+
+def _is_rate_limited(status: int, headers: Message) -> bool:
+    """True for 429 responses, and the 403 responses GitHub sends once the quota is used up."""
+    return status == 429 or (status == 403 and headers.get("X-RateLimit-Remaining") == "0")
+
+
+def _rate_limit_wait(status: int, headers: Message) -> float | None:
+    """Seconds to wait before retrying a rate-limited response (None if the response is not rate limited)."""
+    if not _is_rate_limited(status, headers):
+        return None
+    if headers.get("Retry-After"):
+        return float(headers["Retry-After"])
+    reset = headers.get("X-RateLimit-Reset") or headers.get("RateLimit-Reset")
+    return max(float(reset) - time.time(), 1.0) if reset else 60.0
+
+
+def next_page(headers: Message) -> str | None:
+    """The URL of the next page of a paginated response (its Link header), if any."""
+    match = _NEXT_LINK.search(headers.get("Link") or "")
+    return match.group(1) if match else None
+
+
+class ApiClient:
+    """A JSON REST API client: authenticated requests, pagination and waiting out rate limits."""
+
+    def __init__(self, base_url: str, headers: dict[str, str], max_retries: int = 3):
+        self.base_url = base_url.rstrip("/")
+        self.headers = {"Accept": "application/json", "User-Agent": "treepeat", **headers}
+        self.max_retries = max_retries
+
+    def _url(self, path: str, params: dict[str, Any] | None) -> str:
+        url = path if path.startswith(("http://", "https://")) else self.base_url + path
+        return f"{url}?{urlencode(params)}" if params else url
+
+    def _send(self, method: str, url: str, body: Any) -> tuple[Any, Message]:
+        data = json.dumps(body).encode("utf-8") if body is not None else None
+        headers = {**self.headers, **({"Content-Type": "application/json"} if data is not None else {})}
+        request = urllib.request.Request(url, data=data, headers=headers, method=method)
+        with urllib.request.urlopen(request) as response:
+            payload = response.read().removeprefix(_XSSI_PREFIX)
+            return (json.loads(payload) if payload else None), response.headers
+
+    def _retry_wait(self, error: urllib.error.HTTPError, attempt: int) -> float:
+        """Seconds to wait before retrying a failed request, raising PublishError if it should not be retried."""
+        wait = _rate_limit_wait(error.code, error.headers)
+        if wait is None or attempt >= self.max_retries or wait > MAX_RATE_LIMIT_WAIT:
+            detail = error.read().decode("utf-8", errors="replace")
+            raise PublishError(f"{error.code} {error.reason} for {error.url}: {detail}") from error
+        return wait
+
+    def call(self, method: str, path: str, body: Any = None, params: dict[str, Any] | None = None) -> Any:
+        """Send a request and return its JSON response."""
+        return self._call(method, self._url(path, params), body)[0]
+
+    def _call(self, method: str, url: str, body: Any) -> tuple[Any, Message]:
+        for attempt in range(self.max_retries + 1):
+            try:
+                return self._send(method, url, body)
+            except urllib.error.HTTPError as e:
+                wait = self._retry_wait(e, attempt)
+                logger.warning("Rate limited by %s, retrying in %.0fs", self.base_url, wait)
+                time.sleep(wait)
+            except urllib.error.URLError as e:
+                raise PublishError(f"cannot reach {url}: {e.reason}") from e
+        raise PublishError(f"{method} {url} failed")  # not reached: the last attempt raises
+
+    def paginate(self, path: str, params: dict[str, Any] | None = None) -> list[Any]:
+        """The items of every page of a list endpoint, following its Link headers."""
+        items: list[Any] = []
+        url: str | None = self._url(path, params)
+        while url:
+            page, headers = self._call("GET", url, None)
+            items.extend(page)
+            url = next_page(headers)
+        return items
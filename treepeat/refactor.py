@@ -0,0 +1,188 @@
+"""Extract-function suggestions: how the copies of a clone class could become one parameterized function.
+
+A heuristic rather than a refactoring: the copies are lexed with the generic
+lexer of ``--fallback token`` and aligned token by token on the first copy.
+Each run of tokens that differs between copies becomes a parameter of a
+skeleton of the first copy, every copy contributing its own text as the
+argument to pass; a run that differs the same way everywhere (a renamed
+variable, say) is one parameter.
+"""
+
+import difflib
+import os
+import textwrap
+from dataclasses import dataclass
+from itertools import groupby
+from pathlib import Path
+from typing import Any
+
+from treepeat.pipeline.token_fallback import lex
+
+# Placeholder of a parameter in a skeleton, an identifier in most languages
+PLACEHOLDER = "__param{}__"
+
+# Kinds of the parameters whose every value is one token of the same kind
+_KINDS = {"id": "identifier", "num": "number", "str": "string"}
+
+
+@dataclass(frozen=True)
+class Parameter:
+    """A candidate parameter: its placeholder, what its values are, and the value of each copy."""
+
+    name: str
+    kind: str
+    values: tuple[str, ...]
+
+
+@dataclass(frozen=True)
+class Suggestion:
+    """A consolidation of a clone class: one function in ``target``, called from each copy with its values."""
+
+    name: str
+    language: str
+    skeleton: str
+    parameters: list[Parameter]
+    target: str
+
+
+class _Copy:
+    """The source text of a copy and its tokens."""
+
+    def __init__(self, lines: list[str]):
+        self.text = "\n".join(lines)
+        self.tokens = lex(self.text)
+        self.words = [token.group() for token in self.tokens]
+
+    def value(self, run: tuple[int, int], aligned: dict[int, int], reference_length: int) -> str:
+        """The text lined up with a run of differing tokens of the first copy ('' for none).
+
+        The tokens around a run are the same in every copy, so the run maps to what lies between them.
+        """
+        start, end = run
+        first = aligned[start - 1] + 1 if start else 0
+        last = aligned[end] if end < reference_length else len(self.tokens)
+        return self.text[self.tokens[first].start() : self.tokens[last - 1].end()] if first < last else ""
+
+
+def _marked(tag: str, i1: int, i2: int, length: int) -> range:
+    """The tokens of the first copy an opcode marks as differing (an insertion, the token it comes before)."""
+    if tag == "equal":
+        return range(0)
+    if tag == "insert":
+        return range(i1, i1 + 1) if i1 < length else range(max(length - 1, 0), length)
+    return range(i1, i2)
+
+
+def _differing(copies: list[_Copy]) -> list[bool]:
+    """Whether each token of the first copy differs in some other copy."""
+    reference = copies[0].words
+    differs = [False] * len(reference)
+    for other in copies[1:]:
+        matcher = difflib.SequenceMatcher(None, reference, other.words, autojunk=False)
+        for tag, i1, i2, _, _ in matcher.get_opcodes():
+            for index in _marked(tag, i1, i2, len(reference)):
+                differs[index] = True
+    return differs
+
+
+def _runs(differs: list[bool]) -> list[tuple[int, int]]:
+    """The [start, end) runs of differing tokens."""
+    runs = [list(run) for marked, run in groupby(range(len(differs)), key=differs.__getitem__) if marked]
+    return [(run[0], run[-1] + 1) for run in runs]
+
+
+def _aligned(reference: list[str], other: list[str]) -> dict[int, int]:
+    """The index in another copy of each token of the first copy they share."""
+    matcher = difflib.SequenceMatcher(None, reference, other, autojunk=False)
+    return {a + offset: b + offset for a, b, size in matcher.get_matching_blocks() for offset in range(size)}
+
+
+def _values(copies: list[_Copy], runs: list[tuple[int, int]]) -> list[tuple[str, ...]]:
+    """The text of each copy for each run, in the order of the copies."""
+    reference = copies[0].words
+    per_copy = []
+    for copy in copies:
+        aligned = _aligned(reference, copy.words)
+        per_copy.append([copy.value(run, aligned, len(reference)) for run in runs])
+    return list(zip(*per_copy, strict=True)) if runs else []
+
+
+def _token_kind(value: str) -> str:
+    tokens = lex(value)
+    return _KINDS.get(tokens[0].lastgroup or "", "expression") if len(tokens) == 1 else "expression"
+
+
+def _kind(values: tuple[str, ...]) -> str:
+    """identifier, number or string when every value is one such token, else expression."""
+    kinds = {_token_kind(value) for value in values}
+    return kinds.pop() if len(kinds) == 1 else "expression"
+
+
+def _parameters(values: list[tuple[str, ...]]) -> tuple[list[Parameter], list[str]]:
+    """One parameter per distinct set of values, and the placeholder of each run."""
+    parameters: dict[tuple[str, ...], Parameter] = {}
+    for run_values in values:
+        if run_values not in parameters:
+            name = PLACEHOLDER.format(len(parameters) + 1)
+            parameters[run_values] = Parameter(name, _kind(run_values), run_values)
+    return list(parameters.values()), [parameters[run_values].name for run_values in values]
+
+
+def _skeleton(copy: _Copy, runs: list[tuple[int, int]], placeholders: list[str]) -> str:
+    """The text of a copy with each run replaced by its placeholder, dedented."""
+    skeleton, position = "", 0
+    for (start, end), placeholder in zip(runs, placeholders, strict=True):
+        skeleton += copy.text[position : copy.tokens[start].start()] + placeholder
+        position = copy.tokens[end - 1].end()
+    return textwrap.dedent(skeleton + copy.text[position:])
+
+
+def _single_language(instances: list[dict[str, Any]]) -> bool:
+    return len({instance["language"] for instance in instances}) == 1
+
+
+def _name(instances: list[dict[str, Any]]) -> str:
+    """The name the copies share, else that of the first one."""
+    names = {instance["region_name"] for instance in instances}
+    return names.pop() if len(names) == 1 else str(instances[0]["region_name"])
+
+
+def _target(instances: list[dict[str, Any]]) -> str:
+    """Where the function could live: the file every copy is in, else the directory closest to all of them."""
+    files = sorted({instance["path"] for instance in instances})
+    if len(files) == 1:
+        return files[0]
+    return str(Path(os.path.commonpath([str(Path(file).parent) for file in files])))
+
+
+def suggest_extraction(clone: dict[str, Any], sources: list[list[str]]) -> Suggestion | None:
+    """Suggest a function replacing the copies of a clone class (as in a json report), given each copy's lines.
+
+    None for clone classes spanning languages, or whose copies could not all be read.
+    """
+    instances = clone["instances"]
+    if not (_single_language(instances) and all(sources)):
+        return None
+    copies = [_Copy(lines) for lines in sources]
+    runs = _runs(_differing(copies))
+    parameters, placeholders = _parameters(_values(copies, runs))
+    return Suggestion(
+        name=_name(instances),
+        language=instances[0]["language"],
+        skeleton=_skeleton(copies[0], runs, placeholders),
+        parameters=parameters,
+        target=_target(instances),
+    )
+
+
+def suggestion_to_dict(suggestion: Suggestion) -> dict[str, Any]:
+    return {
+        "name": suggestion.name,
+        "language": suggestion.language,
+        "target": suggestion.target,
+        "skeleton": suggestion.skeleton,
+        "parameters": [
+            {"name": parameter.name, "kind": parameter.kind, "values": list(parameter.values)}
+            for parameter in suggestion.parameters
+        ],
+    }
@@ -0,0 +1,58 @@
+import json
+from pathlib import Path
+from typing import Any
+
+CloneSummary = dict[str, Any]
+
+# Change categories, in report order
+CHANGE_KINDS = ("introduced", "removed", "grown")
+
+
+def _relative(path: str, root: Path) -> str:
+    """Express a reported path relative to the tree that was scanned."""
+    try:
+        return Path(path).relative_to(root).as_posix()
+    except ValueError:
+        return path
+
+
+def _summarize(clone: dict[str, Any], root: Path) -> CloneSummary:
+    """Reduce a json report clone class to what a comparison reports."""
+    instances = clone["instances"]
+    return {
+        "fingerprint": clone["fingerprint"],
+        "similarity": clone["similarity"],
+        "instances": len(instances),
+        "lines": sum(instance["lines"] for instance in instances),
+        "locations": [
+            f"{_relative(i['path'], root)}:{i['start_line']}-{i['end_line']}" for i in instances
+        ],
+    }
+
+
+def summarize_report(report: dict[str, Any], root: Path) -> dict[str, CloneSummary]:
+    """Map each clone class of a json report to its summary, by fingerprint."""
+    return {clone["fingerprint"]: _summarize(clone, root) for clone in report["clone_classes"]}
+
+
+def _grew(before: CloneSummary, after: CloneSummary) -> bool:
+    """True if a clone class gained copies or duplicated lines."""
+    return after["instances"] > before["instances"] or after["lines"] > before["lines"]
+
+
+def compare_reports(before: dict[str, CloneSummary], after: dict[str, CloneSummary]) -> dict[str, list[Any]]:
+    """Compare two states: clone classes introduced, removed, and grown (same fingerprint, more copies or lines)."""
+    return {
+        "introduced": [after[fp] for fp in sorted(after.keys() - before.keys())],
+        "removed": [before[fp] for fp in sorted(before.keys() - after.keys())],
+        "grown": [
+            {"fingerprint": fp, "before": before[fp], "after": after[fp]}
+            for fp in sorted(before.keys() & after.keys())
+            if _grew(before[fp], after[fp])
+        ],
+    }
+
+
+def format_changes_as_json(changes: dict[str, list[Any]], before: str, after: str) -> str:
+    """Serialize a comparison of two states."""
+    return json.dumps({"tool": "treepeat", "before": before, "after": after, **changes}, indent=2)
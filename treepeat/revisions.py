@@ -0,0 +1,99 @@
+import io
+import subprocess
+import tarfile
+from collections import Counter
+from pathlib import Path
+
+
+class RevisionError(ValueError):
+    """Raised when a git revision cannot be resolved or exported."""
+
+
+def _git(args: list[str], cwd: Path) -> bytes:
+    """Run a git command and return its stdout, raising RevisionError on failure."""
+    try:
+        completed = subprocess.run(["git", *args], cwd=cwd, capture_output=True, check=True)
+    except FileNotFoundError as e:
+        raise RevisionError("git is not installed") from e
+    except subprocess.CalledProcessError as e:
+        raise RevisionError(e.stderr.decode("utf-8", errors="replace").strip()) from e
+    return completed.stdout
+
+
+def _resolve(ref: str, repo: Path) -> str:
+    """The commit a revision (commit, branch or tag) points at."""
+    try:
+        return _git(["rev-parse", "--verify", "--quiet", f"{ref}^{{commit}}"], repo).decode().strip()
+    except RevisionError as e:
+        raise RevisionError(str(e) or f"unknown revision '{ref}'") from e
+
+
+def export_revision(ref: str, destination: Path, repo: Path) -> Path:
+    """Write the tree of a git revision (commit, branch or tag) of ``repo`` into ``destination``."""
+    commit = _resolve(ref, repo)
+    archive = _git(["archive", "--format=tar", commit], repo)
+    destination.mkdir(parents=True, exist_ok=True)
+    with tarfile.open(fileobj=io.BytesIO(archive)) as tar:
+        tar.extractall(destination, filter="data")
+    return destination
+
+
+def _paths(output: bytes, root: Path) -> set[Path]:
+    """Absolute paths of a NUL separated list of repository relative paths."""
+    return {root / name for name in output.decode("utf-8", errors="surrogateescape").split("\0") if name}
+
+
+def repository_root(path: Path) -> Path:
+    """The top-level directory of the git repository holding ``path``."""
+    cwd = path if path.is_dir() else path.parent
+    return Path(_git(["rev-parse", "--show-toplevel"], cwd).decode().strip()).resolve()
+
+
+def changed_files(ref: str, path: Path) -> set[Path]:
+    """Files of the repository holding ``path`` changed since ``ref``: committed, uncommitted and untracked."""
+    root = repository_root(path)
+    commit = _resolve(ref, root)
+    changed = _git(["diff", "--name-only", "--no-renames", "-z", commit, "--"], root)
+    untracked = _git(["ls-files", "--others", "--exclude-standard", "-z"], root)
+    return _paths(changed, root) | _paths(untracked, root)
+
+
+def staged_files(path: Path) -> set[Path]:
+    """Files added or modified in the index (staged for the next commit) of the repository holding ``path``."""
+    root = repository_root(path)
+    return _paths(_git(["diff", "--cached", "--name-only", "--diff-filter=d", "--no-renames", "-z"], root), root)
+
+
+def head_commit(path: Path) -> str:
+    """The commit checked out in the repository holding ``path``."""
+    return _resolve("HEAD", repository_root(path))
+
+
+def diff_since(ref: str, path: Path) -> str:
+    """Unified diff, without context lines, of the commits on HEAD since it branched off ``ref``."""
+    root = repository_root(path)
+    base = _git(["merge-base", _resolve(ref, root), "HEAD"], root).decode().strip()
+    diff = _git(["diff", "--unified=0", "--no-renames", "--no-color", "--no-ext-diff", base, "HEAD", "--"], root)
+    return diff.decode("utf-8", errors="replace")
+
+
+def file_churn(path: Path, since: str | None = None) -> Counter[Path]:
+    """How many commits of HEAD touched each file under ``path``, since a date git understands (e.g. 6.months)."""
+    root = repository_root(path)
+    args = ["log", "--format=", "--name-only", "--no-renames", "-z", *([f"--since={since}"] if since else [])]
+    output = _git([*args, "--", str(path.resolve())], root)
+    names = output.decode("utf-8", errors="surrogateescape").split("\0")
+    return Counter(root / name.strip("\n") for name in names if name.strip("\n"))
+
+
+def blame_authors(file: Path, start_line: int, end_line: int, root: Path) -> Counter[tuple[str, str]]:
+    """How many of the committed lines ``start_line``-``end_line`` of a file each (author, email) last changed."""
+    output = _git(["blame", "--line-porcelain", "-L", f"{start_line},{end_line}", "--", str(file.resolve())], root)
+    authors: Counter[tuple[str, str]] = Counter()
+    author = ""
+    for line in output.decode("utf-8", errors="replace").splitlines():
+        if line.startswith("author "):
+            author = line.removeprefix("author ")
+        elif line.startswith("author-mail ") and line != "author-mail <not.committed.yet>":
+            authors[author, line.removeprefix("author-mail ").strip("<>")] += 1
+    return authors
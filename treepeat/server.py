@@ -0,0 +1,224 @@
+"""HTTP API over a warm clone index, for tools that need answers faster than a fresh scan.
+
+The index holds the clone classes of one or more roots in memory. Queries
+are answered from it; rescans replace the clone classes involving the
+rescanned files (or all of a root's, for a full rescan):
+
+    GET  /roots                     the indexed roots, their clone class counts and last scan time
+    GET  /clones?file=PATH[&line=N] the clone classes with a copy in a file (at a line)
+    GET  /report?root=PATH          the clone classes of a root, as a json report
+    GET  /metrics                   Prometheus metrics of the scans and the duplication of each root
+    POST /scan {"root": PATH, "paths": [PATH, ...]}
+                                    rescan a root (every root if omitted), limited to some files if given
+"""
+
+import json
+import logging
+import threading
+import time
+from collections.abc import Callable, Iterator
+from http import HTTPStatus
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from pathlib import Path
+from typing import Any
+from urllib.parse import parse_qs, urlparse
+
+from treepeat.metrics import PROMETHEUS_CONTENT_TYPE, ServerMetrics
+from treepeat.watch import Scan, merge_clones
+
+logger = logging.getLogger(__name__)
+
+
+class ServerError(Exception):
+    """A request the index cannot answer, with the HTTP status to answer it with."""
+
+    def __init__(self, status: HTTPStatus, message: str):
+        super().__init__(message)
+        self.status = status
+
+
+class RootIndex:
+    """The clone classes of one root, by fingerprint."""
+
+    def __init__(self, root: Path):
+        self.root = root
+        self.clones: dict[str, dict[str, Any]] = {}
+        self.paths: dict[str, set[Path]] = {}
+        self.scanned_at: float | None = None
+
+    def update(self, report: dict[str, Any], changed: set[Path] | None) -> None:
+        """Merge a json report of the root limited to ``changed`` files (None: all of them)."""
+        fresh = {clone["fingerprint"]: clone for clone in report["clone_classes"]}
+        merge_clones(self.clones, self.paths, report, fresh, changed)
+        self.scanned_at = time.time()
+
+    def clones_of(self, path: Path, line: int | None) -> list[dict[str, Any]]:
+        """The clone classes with a copy in a file, covering ``line`` unless it is None."""
+        return [
+            clone
+            for fingerprint, clone in sorted(self.clones.items())
+            if path in self.paths[fingerprint] and _covers(clone, path, line)
+        ]
+
+    def summary(self) -> dict[str, Any]:
+        return {"root": str(self.root), "clone_classes": len(self.clones), "scanned_at": self.scanned_at}
+
+    def duplicated_lines(self) -> int:
+        """Number of distinct lines covered by a copy of some clone class."""
+        return len(
+            {
+                (instance["path"], line)
+                for clone in self.clones.values()
+                for instance in clone["instances"]
+                for line in range(instance["start_line"], instance["end_line"] + 1)
+            }
+        )
+
+
+def _covers(clone: dict[str, Any], path: Path, line: int | None) -> bool:
+    """True if a copy of a clone class in ``path`` spans ``line`` (any copy there when None)."""
+    return any(
+        Path(i["path"]).resolve() == path and (line is None or i["start_line"] <= line <= i["end_line"])
+        for i in clone["instances"]
+    )
+
+
+class CloneIndex:
+    """The indexes of the served roots, kept up to date by one scan at a time."""
+
+    def __init__(self, roots: list[Path], scan: Scan):
+        self.roots = {root.resolve(): RootIndex(root.resolve()) for root in roots}
+        self.scan = scan
+        self._lock = threading.Lock()
+
+    def _root_of(self, path: Path) -> RootIndex:
+        """The index of the served root holding a path."""
+        for root, index in self.roots.items():
+            if path == root or root in path.parents:
+                return index
+        raise ServerError(HTTPStatus.NOT_FOUND, f"{path} is not under a served root")
+
+    def rescans(self, root: str | None, paths: list[str] | None) -> Iterator[tuple[RootIndex, list[dict[str, Any]]]]:
+        """Rescan a root (every root when None), limited to some files when given.
+
+        Yields the index of each root once rescanned, with the clone classes the scan found.
+        """
+        indexes = [self._root_of(Path(root).resolve())] if root else list(self.roots.values())
+        changed = {Path(path).resolve() for path in paths} if paths else None
+        for index in indexes:
+            logger.info("Rescanning %s", index.root)
+            with self._lock:
+                report = self.scan(index.root, changed)
+                index.update(report, changed)
+            yield index, report["clone_classes"]
+
+    def rescan(self, root: str | None, paths: list[str] | None) -> list[dict[str, Any]]:
+        """Rescan like ``rescans`` and summarize the rescanned roots."""
+        return [index.summary() for index, _ in self.rescans(root, paths)]
+
+    def clones_of(self, file: str, line: int | None) -> list[dict[str, Any]]:
+        path = Path(file).resolve()
+        with self._lock:
+            return self._root_of(path).clones_of(path, line)
+
+    def duplication(self) -> dict[Path, tuple[int, int]]:
+        """The clone classes and duplicated lines of each root."""
+        with self._lock:
+            return {root: (len(index.clones), index.duplicated_lines()) for root, index in self.roots.items()}
+
+    def report(self, root: str) -> dict[str, Any]:
+        index = self._root_of(Path(root).resolve())
+        with self._lock:
+            return {"root": str(index.root), "clone_classes": [index.clones[fp] for fp in sorted(index.clones)]}
+
+
+def _query(url: str) -> dict[str, str]:
+    """The query string parameters of a request (the last value of each)."""
+    return {name: values[-1] for name, values in parse_qs(urlparse(url).query).items()}
+
+
+def _required(query: dict[str, str], name: str) -> str:
+    if name not in query:
+        raise ServerError(HTTPStatus.BAD_REQUEST, f"missing '{name}' parameter")
+    return query[name]
+
+
+def _line(query: dict[str, str]) -> int | None:
+    if "line" not in query:
+        return None
+    if not query["line"].isdigit():
+        raise ServerError(HTTPStatus.BAD_REQUEST, "'line' must be a line number")
+    return int(query["line"])
+
+
+def _scan_request(body: dict[str, Any]) -> tuple[str | None, list[str] | None]:
+    """The root and paths of a scan request body."""
+    root, paths = body.get("root"), body.get("paths")
+    if paths is not None and not isinstance(paths, list):
+        raise ServerError(HTTPStatus.BAD_REQUEST, "'paths' must be a list of paths")
+    return root, paths
+
+
+def make_handler(index: CloneIndex, metrics: ServerMetrics | None = None) -> type[BaseHTTPRequestHandler]:
+    """The request handler class of a server answering from ``index`` (and serving ``metrics`` if given)."""
+
+    class Handler(BaseHTTPRequestHandler):
+        def _answer(self, status: HTTPStatus, payload: Any) -> None:
+            self._send(status, json.dumps(payload).encode("utf-8"), "application/json")
+
+        def _send(self, status: HTTPStatus, body: bytes, content_type: str) -> None:
+            self.send_response(status)
+            self.send_header("Content-Type", content_type)
+            self.send_header("Content-Length", str(len(body)))
+            self.end_headers()
+            self.wfile.write(body)
+
+        def _dispatch(self, route: Callable[[], Any]) -> None:
+            try:
+                self._answer(HTTPStatus.OK, route())
+            except ServerError as e:
+                self._answer(e.status, {"error": str(e)})
+
+        def _get(self) -> Any:
+            query = _query(self.path)
+            routes: dict[str, Callable[[], Any]] = {
+                "/roots": lambda: [root.summary() for root in index.roots.values()],
+                "/clones": lambda: index.clones_of(_required(query, "file"), _line(query)),
+                "/report": lambda: index.report(_required(query, "root")),
+            }
+            return self._route(routes)
+
+        def _post(self) -> Any:
+            length = int(self.headers.get("Content-Length") or 0)
+            try:
+                body = json.loads(self.rfile.read(length) or b"{}")
+            except ValueError as e:
+                raise ServerError(HTTPStatus.BAD_REQUEST, f"invalid JSON body: {e}") from e
+            return self._route({"/scan": lambda: index.rescan(*_scan_request(body))})
+
+        def _route(self, routes: dict[str, Callable[[], Any]]) -> Any:
+            route = routes.get(urlparse(self.path).path)
+            if route is None:
+                raise ServerError(HTTPStatus.NOT_FOUND, f"no such endpoint: {self.command} {self.path}")
+            return route()
+
+        def do_GET(self) -> None:
+            if metrics is not None and urlparse(self.path).path == "/metrics":
+                self._send(HTTPStatus.OK, metrics.render(index.duplication()).encode("utf-8"), PROMETHEUS_CONTENT_TYPE)
+                return
+            self._dispatch(self._get)
+
+        def do_POST(self) -> None:
+            self._dispatch(self._post)
+
+    return Handler
+
+
+def run_server(index: CloneIndex, host: str, port: int, metrics: ServerMetrics | None = None) -> None:
+    """Answer HTTP requests until interrupted."""
+    server = ThreadingHTTPServer((host, port), make_handler(index, metrics))
+    logger.info("Serving %d root(s) on http://%s:%d", len(index.roots), host, server.server_port)
+    try:
+        server.serve_forever()
+    finally:
+        server.server_close()
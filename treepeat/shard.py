@@ -0,0 +1,115 @@
+"""Partial indexes of a scan split across machines with 'detect --shard', combined by 'treepeat merge'."""
+
+import json
+import re
+from pathlib import Path
+from typing import Any
+
+from pydantic import BaseModel, Field, ValidationError
+
+from treepeat.config import PipelineSettings
+from treepeat.models.shingle import ShingledRegion
+
+# Bumped whenever the layout of a shard file changes
+SHARD_FORMAT = 1
+
+# Settings that may differ between the shards of one scan: how each shard ran, not what it indexed
+_PER_SHARD_SETTINGS = {"shard", "jobs", "cache_dir", "max_index_memory"}
+
+_SHARD_SPEC = re.compile(r"^\s*(\d+)\s*/\s*(\d+)\s*$")
+
+
+class ShardError(ValueError):
+    """A --shard spec or a set of shard files that cannot be used."""
+
+
+def parse_shard(spec: str) -> tuple[int, int]:
+    """Parse ``K/N`` (shard K of N, 1-based)."""
+    match = _SHARD_SPEC.match(spec)
+    if not match:
+        raise ShardError(f"expected K/N (e.g. 3/8), got {spec!r}")
+    index, count = int(match.group(1)), int(match.group(2))
+    if not 1 <= index <= count:
+        raise ShardError(f"shard {index}/{count} is out of range: K must be between 1 and N")
+    return index, count
+
+
+def default_shard_path(shard: tuple[int, int]) -> Path:
+    """Where 'detect --shard' writes its partial index without --output."""
+    return Path(f"treepeat-shard-{shard[0]}-of-{shard[1]}.json")
+
+
+class ShardFile(BaseModel):
+    """The shingled regions of one slice of a scan, with the settings they were shingled with."""
+
+    format: int = Field(default=SHARD_FORMAT, description="Layout version of the shard file")
+    shard: tuple[int, int] = Field(description="Shard K of N")
+    root: Path = Field(description="The scanned path")
+    settings: dict[str, Any] = Field(description="Pipeline settings of the run (json mode)")
+    regions: list[ShingledRegion] = Field(default_factory=list, description="Shingled regions of the shard's files")
+
+    def pipeline_settings(self) -> PipelineSettings:
+        return PipelineSettings.model_validate(self.settings)
+
+
+def write_shard(path: Path, root: Path, settings: PipelineSettings, regions: list[ShingledRegion]) -> None:
+    """Write the partial index of the run's --shard slice."""
+    if settings.shard is None:
+        raise ShardError("not a --shard run")
+    document = ShardFile(shard=settings.shard, root=root, settings=settings.model_dump(mode="json"), regions=regions)
+    path.write_text(document.model_dump_json(), encoding="utf-8")
+
+
+def _read_shard(path: Path) -> ShardFile:
+    try:
+        shard = ShardFile.model_validate_json(path.read_text(encoding="utf-8"))
+    except (OSError, ValidationError) as e:
+        raise ShardError(f"{path} is not a treepeat shard file: {e}") from e
+    if shard.format != SHARD_FORMAT:
+        raise ShardError(f"{path} has shard format {shard.format}, this treepeat reads format {SHARD_FORMAT}")
+    return shard
+
+
+def _comparable_settings(shard: ShardFile) -> str:
+    settings = {key: value for key, value in shard.settings.items() if key not in _PER_SHARD_SETTINGS}
+    return json.dumps(settings, sort_keys=True)
+
+
+def _check_compatible(shards: dict[Path, ShardFile]) -> None:
+    """Every shard must come from one split of one scan, with the same detection settings."""
+    first_path, first = next(iter(shards.items()))
+    for path, shard in shards.items():
+        if shard.shard[1] != first.shard[1]:
+            raise ShardError(
+                f"{path} is shard {shard.shard[0]}/{shard.shard[1]}, but {first_path} is of {first.shard[1]}"
+            )
+        if _comparable_settings(shard) != _comparable_settings(first):
+            raise ShardError(f"{path} was scanned with other detection settings than {first_path}")
+
+
+def _check_coverage(shards: dict[Path, ShardFile]) -> None:
+    """Every shard of the split is given exactly once."""
+    seen: dict[int, Path] = {}
+    for path, shard in shards.items():
+        if shard.shard[0] in seen:
+            raise ShardError(f"{path} and {seen[shard.shard[0]]} are both shard {shard.shard[0]}")
+        seen[shard.shard[0]] = path
+    count = next(iter(shards.values())).shard[1]
+    missing = sorted(set(range(1, count + 1)) - seen.keys())
+    if missing:
+        raise ShardError(f"missing shard(s) {', '.join(f'{index}/{count}' for index in missing)}")
+
+
+def load_shards(paths: list[Path]) -> tuple[PipelineSettings, Path, list[ShingledRegion]]:
+    """Read the shard files of one scan: (its settings, the scanned path, the regions of every shard).
+
+    Raises ShardError unless the files are every shard of one split, all scanned with the same settings.
+    """
+    if not paths:
+        raise ShardError("no shard files given")
+    shards = {path: _read_shard(path) for path in paths}
+    _check_compatible(shards)
+    _check_coverage(shards)
+    ordered = sorted(shards.values(), key=lambda shard: shard.shard[0])
+    settings = ordered[0].pipeline_settings().model_copy(update={"shard": None})
+    return settings, ordered[0].root, [region for shard in ordered for region in shard.regions]
@@ -0,0 +1,126 @@
+"""Duplication metrics: how many of the compared lines are covered by a clone, by file, directory and language.
+
+The lines counted are those of the files that had regions compared; a directory
+counts the files anywhere below it, up to the directory the files have in common.
+Fragment types count the regions extracted of each kind, and how many are in a clone.
+"""
+
+import os
+from collections import Counter, defaultdict
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any
+
+from treepeat.models.similarity import SimilarityResult
+from treepeat.pipeline.parse import detect_language
+from treepeat.policy import line_count
+
+# The breakdowns of the duplication, by the key of the rows of each
+BREAKDOWNS = {"language": "language", "directory": "path", "file": "path"}
+
+
+@dataclass
+class LineCounts:
+    """The lines of some files, and how many of them are duplicated."""
+
+    lines: int = 0
+    duplicated_lines: int = 0
+
+    @property
+    def percent(self) -> float:
+        return 100.0 * self.duplicated_lines / self.lines if self.lines else 0.0
+
+    def add(self, other: "LineCounts") -> None:
+        self.lines += other.lines
+        self.duplicated_lines += other.duplicated_lines
+
+
+@dataclass
+class DuplicationStats:
+    """The duplication of a result, overall and broken down by file, directory and language."""
+
+    total: LineCounts = field(default_factory=LineCounts)
+    by_file: dict[str, LineCounts] = field(default_factory=dict)
+    by_directory: dict[str, LineCounts] = field(default_factory=dict)
+    by_language: dict[str, LineCounts] = field(default_factory=dict)
+
+
+def _duplicated_lines_by_file(result: SimilarityResult) -> dict[Path, set[int]]:
+    covered: dict[Path, set[int]] = defaultdict(set)
+    for group in result.similar_groups:
+        for region in group.regions:
+            covered[region.path].update(range(region.start_line, region.end_line + 1))
+    return covered
+
+
+def _compared_files(result: SimilarityResult) -> dict[Path, str]:
+    """The files that had regions compared, with their language (that of their first region if unknown by name)."""
+    files: dict[Path, str] = {}
+    for signature in result.signatures:
+        region = signature.region
+        files.setdefault(region.path, detect_language(region.path) or region.language)
+    return files
+
+
+def _directories(path: Path, root: Path) -> list[Path]:
+    """The directories holding ``path``, from its own up to ``root``."""
+    return [parent for parent in path.parents if parent == root or root in parent.parents]
+
+
+def _common_root(paths: list[Path]) -> Path:
+    return Path(os.path.commonpath([path.parent for path in paths])) if paths else Path(".")
+
+
+def duplication_stats(result: SimilarityResult) -> DuplicationStats:
+    """Count the compared and duplicated lines of a result, overall and by file, directory and language."""
+    files = _compared_files(result)
+    covered = _duplicated_lines_by_file(result)
+    root = _common_root(list(files))
+    stats = DuplicationStats()
+    for path, language in sorted(files.items()):
+        counts = LineCounts(line_count(path), len(covered.get(path, ())))
+        stats.by_file[str(path)] = counts
+        stats.total.add(counts)
+        stats.by_language.setdefault(language, LineCounts()).add(counts)
+        for directory in _directories(path, root):
+            stats.by_directory.setdefault(str(directory), LineCounts()).add(counts)
+    return stats
+
+
+def _counts_to_dict(counts: LineCounts) -> dict[str, Any]:
+    return {"lines": counts.lines, "duplicated_lines": counts.duplicated_lines, "percent": round(counts.percent, 2)}
+
+
+def most_duplicated(breakdown: dict[str, LineCounts]) -> list[tuple[str, LineCounts]]:
+    """The entries of a breakdown, the most duplicated lines first."""
+    return sorted(breakdown.items(), key=lambda item: (-item[1].duplicated_lines, item[0]))
+
+
+def _rows(key: str, breakdown: dict[str, LineCounts]) -> list[dict[str, Any]]:
+    return [{key: name, **_counts_to_dict(counts)} for name, counts in most_duplicated(breakdown)]
+
+
+def clone_fragment_counts(result: SimilarityResult) -> Counter[tuple[str, str]]:
+    """The instances of the clone classes of a result, by language and region type."""
+    return Counter((region.language, region.region_type) for group in result.similar_groups for region in group.regions)
+
+
+def _fragment_row(key: tuple[str, str], extracted: int, in_clones: int) -> dict[str, Any]:
+    language, region_type = key
+    return {"language": language, "region_type": region_type, "extracted": extracted, "in_clones": in_clones}
+
+
+def fragment_types_to_list(result: SimilarityResult) -> list[dict[str, Any]]:
+    """The regions extracted of each language and region type, and how many are in a clone, as json rows."""
+    in_clones = clone_fragment_counts(result)
+    return [_fragment_row(key, count, in_clones[key]) for key, count in sorted(result.fragment_counts.items())]
+
+
+def stats_to_dict(stats: DuplicationStats) -> dict[str, Any]:
+    """Serialize duplication metrics, as the ``duplication`` section of a json report."""
+    return {
+        **_counts_to_dict(stats.total),
+        "by_language": _rows(BREAKDOWNS["language"], stats.by_language),
+        "by_directory": _rows(BREAKDOWNS["directory"], stats.by_directory),
+        "by_file": _rows(BREAKDOWNS["file"], stats.by_file),
+    }
@@ -0,0 +1,95 @@
+"""OpenTelemetry traces of detect runs, exported over OTLP when an endpoint is configured.
+
+The pipeline stages a run records (walk, parse, extract, shingle, minhash,
+lsh, report) become spans under a span of the whole run, nested by time:
+a stage running within another is its child. Exporting needs the optional
+otel dependencies (``pip install 'treepeat[otel]'``) and the standard
+``OTEL_EXPORTER_OTLP_ENDPOINT`` (or ``OTEL_EXPORTER_OTLP_TRACES_ENDPOINT``);
+the other ``OTEL_*`` variables (headers, service name, ...) apply as usual.
+"""
+
+import logging
+import os
+import time
+from typing import Any
+
+from treepeat.pipeline.verbose_metrics import get_verbose_metrics
+
+logger = logging.getLogger(__name__)
+
+ENDPOINT_VARIABLES = ("OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+
+# A stage span: name, monotonic start time and duration, in seconds
+Span = tuple[str, float, float]
+
+
+def otlp_configured() -> bool:
+    return any(os.environ.get(variable) for variable in ENDPOINT_VARIABLES)
+
+
+def _contains(outer: Span, inner: Span) -> bool:
+    return outer[1] <= inner[1] and inner[1] + inner[2] <= outer[1] + outer[2]
+
+
+def nest_spans(spans: list[Span]) -> list[tuple[Span, int | None]]:
+    """The spans in start order, each with the index (in that order) of the span it runs within (None: none)."""
+    ordered = sorted(spans, key=lambda span: (span[1], -span[2]))
+    nested: list[tuple[Span, int | None]] = []
+    open_spans: list[int] = []
+    for index, span in enumerate(ordered):
+        while open_spans and not _contains(ordered[open_spans[-1]], span):
+            open_spans.pop()
+        nested.append((span, open_spans[-1] if open_spans else None))
+        open_spans.append(index)
+    return nested
+
+
+def _tracer_provider() -> Any:
+    """A tracer provider exporting to the configured OTLP endpoint (ImportError without the otel extra)."""
+    from opentelemetry.exporter.otlp.proto.http.trace_exporter import OTLPSpanExporter
+    from opentelemetry.sdk.resources import SERVICE_NAME, Resource
+    from opentelemetry.sdk.trace import TracerProvider
+    from opentelemetry.sdk.trace.export import BatchSpanProcessor
+
+    resource = Resource.create({SERVICE_NAME: os.environ.get("OTEL_SERVICE_NAME", "treepeat")})
+    provider = TracerProvider(resource=resource)
+    provider.add_span_processor(BatchSpanProcessor(OTLPSpanExporter()))
+    return provider
+
+
+def epoch_ns(monotonic_seconds: float) -> int:
+    """The wall clock time, in nanoseconds since the epoch, of a ``time.monotonic()`` reading."""
+    return time.time_ns() - time.monotonic_ns() + int(monotonic_seconds * 1e9)
+
+
+def _export(provider: Any, spans: list[Span], attributes: dict[str, Any]) -> None:
+    from opentelemetry import trace
+
+    tracer = provider.get_tracer("treepeat")
+    start, end = min(span[1] for span in spans), max(span[1] + span[2] for span in spans)
+    run = tracer.start_span("treepeat.detect", start_time=epoch_ns(start), attributes=attributes)
+    counts = get_verbose_metrics().stage_counts
+    started: list[Any] = []
+    for (stage, stage_start, seconds), parent in nest_spans(spans):
+        context = trace.set_span_in_context(run if parent is None else started[parent])
+        span = tracer.start_span(f"treepeat.{stage}", context=context, start_time=epoch_ns(stage_start))
+        span.set_attribute("treepeat.items", counts.get(stage, 0))
+        span.end(end_time=epoch_ns(stage_start + seconds))
+        started.append(span)
+    run.end(end_time=epoch_ns(end))
+
+
+def export_run_trace(attributes: dict[str, Any]) -> None:
+    """Export the stages recorded by the run that just finished, if an OTLP endpoint is configured."""
+    spans = get_verbose_metrics().stage_spans
+    if not spans or not otlp_configured():
+        return
+    try:
+        provider = _tracer_provider()
+    except ImportError:
+        logger.warning("OTLP export needs the otel extra: pip install 'treepeat[otel]'")
+        return
+    try:
+        _export(provider, spans, attributes)
+    finally:
+        provider.shutdown()
@@ -0,0 +1,83 @@
+from collections.abc import Callable
+from pathlib import Path
+from typing import Any, Iterable, TypeVar
+
+from treepeat.report_diff import CloneSummary, compare_reports, summarize_report
+
+# Modification time (ns) and size of each watched file
+Snapshot = dict[Path, tuple[int, int]]
+
+# Finds the clones under a root involving some files (all files: None), as a json report
+Scan = Callable[[Path, set[Path] | None], dict[str, Any]]
+
+C = TypeVar("C")
+
+
+def take_snapshot(paths: Iterable[Path]) -> Snapshot:
+    """Record the modification time and size of each file (files that vanished meanwhile are left out)."""
+    snapshot: Snapshot = {}
+    for path in paths:
+        try:
+            stat = path.stat()
+        except OSError:
+            continue
+        snapshot[path.resolve()] = (stat.st_mtime_ns, stat.st_size)
+    return snapshot
+
+
+def changed_paths(before: Snapshot, after: Snapshot) -> set[Path]:
+    """Files created, modified or deleted between two snapshots."""
+    return {path for path in before.keys() | after.keys() if before.get(path) != after.get(path)}
+
+
+def clone_paths(report: dict[str, Any]) -> dict[str, set[Path]]:
+    """Map each clone class of a json report to the files holding its copies."""
+    return {
+        clone["fingerprint"]: {Path(instance["path"]).resolve() for instance in clone["instances"]}
+        for clone in report["clone_classes"]
+    }
+
+
+def _is_stale(paths: set[Path], fingerprint: str, fresh: dict[str, Any], changed: set[Path] | None) -> bool:
+    """True if a known clone class, with copies in ``paths``, is superseded by a scan of ``changed`` (None: all)."""
+    return changed is None or fingerprint in fresh or bool(paths & changed)
+
+
+def merge_clones(
+    clones: dict[str, C],
+    paths: dict[str, set[Path]],
+    report: dict[str, Any],
+    fresh: dict[str, C],
+    changed: set[Path] | None,
+) -> dict[str, C]:
+    """Merge the clone classes ``fresh`` of a json report limited to ``changed`` files into ``clones`` and ``paths``.
+
+    Known clone classes with a copy in a changed file are replaced by those of the report; they are returned.
+    """
+    stale = {fp: clone for fp, clone in clones.items() if _is_stale(paths[fp], fp, fresh, changed)}
+    for fingerprint in stale:
+        del clones[fingerprint]
+        del paths[fingerprint]
+    clones.update(fresh)
+    paths.update(clone_paths(report))
+    return stale
+
+
+class CloneState:
+    """The clone classes of a watched tree, kept up to date from scans limited to the changed files."""
+
+    def __init__(self) -> None:
+        self._clones: dict[str, CloneSummary] = {}
+        self._paths: dict[str, set[Path]] = {}
+
+    def __len__(self) -> int:
+        return len(self._clones)
+
+    def update(self, report: dict[str, Any], root: Path, changed: set[Path] | None) -> dict[str, list[Any]]:
+        """Merge a json report of ``root`` limited to ``changed`` files and return what changed (see compare_reports).
+
+        Known clone classes with a copy in a changed file are replaced by those of the report.
+        """
+        fresh = summarize_report(report, root)
+        stale = merge_clones(self._clones, self._paths, report, fresh, changed)
+        return compare_reports(stale, fresh)